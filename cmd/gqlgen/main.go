@@ -0,0 +1,118 @@
+// gqlgen 由 .graphql 操作文件与 schema SDL 生成带类型的 Go 请求/响应代码。
+//
+//	go run ./cmd/gqlgen -schema internal/gql/schema.graphql -out internal/gql/operations_gen.go internal/gql/operations
+//
+// 操作与 schema 不一致时以 "文件:行:列: 原因" 报告并以非零状态退出；
+// -check 仅校验生成结果与磁盘上的文件一致（用于 CI）。
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/railwayapp/cli/internal/gqlgen"
+)
+
+type scalarFlags map[string]string
+
+func (s scalarFlags) String() string { return "" }
+
+func (s scalarFlags) Set(v string) error {
+	name, goType, ok := strings.Cut(v, "=")
+	if !ok || name == "" || goType == "" {
+		return fmt.Errorf("want Name=GoType, got %q", v)
+	}
+	s[name] = goType
+	return nil
+}
+
+func main() {
+	schemaPath := flag.String("schema", "schema.graphql", "schema SDL 文件")
+	outPath := flag.String("out", "operations_gen.go", "输出文件")
+	pkg := flag.String("package", "gql", "生成代码的包名")
+	check := flag.Bool("check", false, "仅检查输出文件是否为最新")
+	scalars := scalarFlags{}
+	flag.Var(scalars, "scalar", "自定义标量映射 Name=GoType（可重复）")
+	flag.Parse()
+
+	if err := run(*schemaPath, *outPath, *pkg, *check, scalars, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(schemaPath, outPath, pkg string, check bool, scalars map[string]string, inputs []string) error {
+	src, err := os.ReadFile(schemaPath)
+	if err != nil {
+		return err
+	}
+	schema, err := gqlgen.ParseSchema(schemaPath, string(src))
+	if err != nil {
+		return err
+	}
+
+	files, err := operationFiles(inputs)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no .graphql operation files given")
+	}
+	doc := &gqlgen.Document{}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		if err := gqlgen.ParseDocument(doc, f, string(b)); err != nil {
+			return err
+		}
+	}
+
+	out, err := gqlgen.Generate(schema, doc, gqlgen.Config{
+		Package: pkg,
+		Scalars: scalars,
+		Command: "go generate ./internal/gql",
+	})
+	if err != nil {
+		return err
+	}
+	if check {
+		cur, err := os.ReadFile(outPath)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(cur, out) {
+			return fmt.Errorf("%s is out of date; run go generate ./internal/gql", outPath)
+		}
+		return nil
+	}
+	return os.WriteFile(outPath, out, 0o644)
+}
+
+// operationFiles 展开目录为其中的 .graphql 文件（按路径排序，保证输出稳定）
+func operationFiles(args []string) ([]string, error) {
+	var out []string
+	for _, a := range args {
+		info, err := os.Stat(a)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			out = append(out, a)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(a, "*.graphql"))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, matches...)
+	}
+	sort.Strings(out)
+	return out, nil
+}
@@ -0,0 +1,227 @@
+// Package archive 将部署目录以 tar.gz 流的形式边打包边上传，供 CLI 与 pkg/railway 共用。
+//
+// 打包前先遍历一次目录统计文件数与总字节数，之后由后台 goroutine 写入 io.Pipe，
+// 上传请求直接读取管道，内存占用与归档大小无关。
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	ignore "github.com/sabhiram/go-gitignore"
+)
+
+// Options 打包参数
+type Options struct {
+	// ProjectRoot 项目根目录，ignore 规则相对于它匹配
+	ProjectRoot string
+	// DeployRoot 需要打包的目录（ProjectRoot 或其子目录）
+	DeployRoot string
+	// PathAsRoot 归档路径以 DeployRoot 为根（默认以 ProjectRoot 为根）
+	PathAsRoot bool
+	// Ignore 为 nil 时只跳过 .git 与 node_modules
+	Ignore *ignore.GitIgnore
+}
+
+// Progress 打包上传进度
+type Progress struct {
+	// Files / TotalFiles 已写入归档的文件数与文件总数
+	Files      int
+	TotalFiles int
+	// Bytes / TotalBytes 已读取的源文件字节数与源文件总字节数，用于估算完成比例
+	Bytes      int64
+	TotalBytes int64
+	// Sent 已发送的压缩后字节数
+	Sent int64
+	// Elapsed 自开始读取以来的时间
+	Elapsed time.Duration
+	// Done 归档已全部发送
+	Done bool
+}
+
+// LoadIgnore 读取 projectRoot 下的 .railwayignore 与 .gitignore；两者都不存在时返回 nil
+func LoadIgnore(projectRoot string) *ignore.GitIgnore {
+	var patterns []string
+	for _, name := range []string{".railwayignore", ".gitignore"} {
+		if b, err := os.ReadFile(filepath.Join(projectRoot, name)); err == nil {
+			patterns = append(patterns, strings.Split(string(b), "\n")...)
+		}
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+	return ignore.CompileIgnoreLines(patterns...)
+}
+
+type file struct {
+	path, name string
+	info       os.FileInfo
+}
+
+// Stream 打包中的归档，实现 io.ReadCloser。Read 返回压缩后的数据；打包出错时 Read 返回该错误。
+type Stream struct {
+	pr         *io.PipeReader
+	onProgress func(Progress)
+
+	mu      sync.Mutex
+	p       Progress
+	started time.Time
+	err     error
+}
+
+// Open 扫描目录并开始打包；onProgress 可为 nil，在读取方的 goroutine 中调用
+func Open(opts Options, onProgress func(Progress)) (*Stream, error) {
+	files, total, err := scan(opts)
+	if err != nil {
+		return nil, fmt.Errorf("archive failed: %w", err)
+	}
+	pr, pw := io.Pipe()
+	s := &Stream{pr: pr, onProgress: onProgress, p: Progress{TotalFiles: len(files), TotalBytes: total}}
+	go func() {
+		err := s.write(pw, files)
+		if err != nil {
+			err = fmt.Errorf("archive failed: %w", err)
+			s.mu.Lock()
+			s.err = err
+			s.mu.Unlock()
+		}
+		pw.CloseWithError(err)
+	}()
+	return s, nil
+}
+
+// scan 按 ignore 规则列出需要打包的普通文件
+func scan(opts Options) ([]file, int64, error) {
+	rootForPrefix := opts.ProjectRoot
+	if opts.PathAsRoot {
+		rootForPrefix = opts.DeployRoot
+	}
+	var files []file
+	var total int64
+	err := filepath.Walk(opts.DeployRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(rootForPrefix, path)
+		if rel == "." {
+			return nil
+		}
+		base := filepath.Base(path)
+		if base == ".git" || base == "node_modules" {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if opts.Ignore != nil {
+			relForIgnore, _ := filepath.Rel(opts.ProjectRoot, path)
+			if opts.Ignore.MatchesPath(filepath.ToSlash(relForIgnore)) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+		if info.Mode().IsRegular() {
+			// 归档路径以 "." 为根
+			files = append(files, file{path: path, name: filepath.ToSlash(filepath.Join(".", rel)), info: info})
+			total += info.Size()
+		}
+		return nil
+	})
+	return files, total, err
+}
+
+func (s *Stream) write(w io.Writer, files []file) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, f := range files {
+		if err := s.addFile(tw, f); err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.p.Files++
+		s.mu.Unlock()
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func (s *Stream) addFile(tw *tar.Writer, f file) error {
+	hdr, err := tar.FileInfoHeader(f.info, "")
+	if err != nil {
+		return err
+	}
+	hdr.Name = f.name
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	in, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	// 文件在扫描后变大时只写入头中记录的长度，避免 tar 报 write too long
+	_, err = io.Copy(tw, &countingReader{r: io.LimitReader(in, hdr.Size), s: s})
+	return err
+}
+
+// countingReader 统计已读取的源文件字节
+type countingReader struct {
+	r io.Reader
+	s *Stream
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	c.s.mu.Lock()
+	c.s.p.Bytes += int64(n)
+	c.s.mu.Unlock()
+	return n, err
+}
+
+// Read 读取压缩后的归档数据
+func (s *Stream) Read(b []byte) (int, error) {
+	n, err := s.pr.Read(b)
+	s.mu.Lock()
+	if s.started.IsZero() {
+		s.started = time.Now()
+	}
+	s.p.Sent += int64(n)
+	s.p.Elapsed = time.Since(s.started)
+	s.p.Done = err == io.EOF
+	p := s.p
+	s.mu.Unlock()
+	if s.onProgress != nil && (n > 0 || p.Done) {
+		s.onProgress(p)
+	}
+	return n, err
+}
+
+// Close 停止打包；上传中止时由 HTTP 客户端调用
+func (s *Stream) Close() error {
+	return s.pr.Close()
+}
+
+// Progress 当前进度
+func (s *Stream) Progress() Progress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.p
+}
+
+// Err 打包过程中的错误（如读取文件失败）；上传失败时用于区分是打包还是网络出错
+func (s *Stream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
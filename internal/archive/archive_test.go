@@ -0,0 +1,188 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// writeTree 在 root 下创建文件，路径使用 /
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// untar 解压归档，返回文件名 → 内容
+func untar(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	out := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return out
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out[hdr.Name] = string(b)
+	}
+}
+
+func names(m map[string]string) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+var projectFiles = map[string]string{
+	".gitignore":              "*.log\ndist/\n",
+	".railwayignore":          "secrets.env\n",
+	"main.go":                 "package main\n",
+	"app/server.go":           "package app\n",
+	"app/debug.log":           "ignored by .gitignore",
+	"app/dist/bundle.js":      "ignored directory",
+	"secrets.env":             "ignored by .railwayignore",
+	"node_modules/x/index.js": "always skipped",
+	".git/HEAD":               "always skipped",
+	"web/package.json":        `{"name":"web"}`,
+	"web/node_modules/y.js":   "always skipped",
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, projectFiles)
+	tests := []struct {
+		name string
+		opts Options
+		want map[string]string
+	}{
+		{"project root with ignore files", Options{ProjectRoot: root, DeployRoot: root, Ignore: LoadIgnore(root)}, map[string]string{
+			".gitignore":       projectFiles[".gitignore"],
+			".railwayignore":   projectFiles[".railwayignore"],
+			"main.go":          projectFiles["main.go"],
+			"app/server.go":    projectFiles["app/server.go"],
+			"web/package.json": projectFiles["web/package.json"],
+		}},
+		{"no ignore rules", Options{ProjectRoot: root, DeployRoot: root}, map[string]string{
+			".gitignore":         projectFiles[".gitignore"],
+			".railwayignore":     projectFiles[".railwayignore"],
+			"main.go":            projectFiles["main.go"],
+			"app/server.go":      projectFiles["app/server.go"],
+			"app/debug.log":      projectFiles["app/debug.log"],
+			"app/dist/bundle.js": projectFiles["app/dist/bundle.js"],
+			"secrets.env":        projectFiles["secrets.env"],
+			"web/package.json":   projectFiles["web/package.json"],
+		}},
+		// 子目录部署：ignore 规则仍相对项目根匹配
+		{"sub directory", Options{ProjectRoot: root, DeployRoot: filepath.Join(root, "app"), Ignore: LoadIgnore(root)}, map[string]string{
+			"app/server.go": projectFiles["app/server.go"],
+		}},
+		{"sub directory as root", Options{ProjectRoot: root, DeployRoot: filepath.Join(root, "app"), PathAsRoot: true, Ignore: LoadIgnore(root)}, map[string]string{
+			"server.go": projectFiles["app/server.go"],
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var last Progress
+			calls := 0
+			s, err := Open(tt.opts, func(p Progress) {
+				calls++
+				last = p
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var wantBytes int64
+			for _, c := range tt.want {
+				wantBytes += int64(len(c))
+			}
+			if p := s.Progress(); p.TotalFiles != len(tt.want) || p.TotalBytes != wantBytes {
+				t.Fatalf("totals = %d files, %d bytes; want %d, %d", p.TotalFiles, p.TotalBytes, len(tt.want), wantBytes)
+			}
+			data, err := io.ReadAll(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.Close()
+			got := untar(t, data)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("archive = %v, want %v", names(got), names(tt.want))
+			}
+			if calls == 0 || !last.Done {
+				t.Fatalf("onProgress called %d times, last = %+v", calls, last)
+			}
+			want := Progress{Files: len(tt.want), TotalFiles: len(tt.want), Bytes: wantBytes, TotalBytes: wantBytes, Sent: int64(len(data)), Done: true}
+			last.Elapsed = 0
+			if last != want {
+				t.Fatalf("final progress = %+v, want %+v", last, want)
+			}
+			if s.Err() != nil {
+				t.Fatal(s.Err())
+			}
+		})
+	}
+}
+
+func TestLoadIgnore(t *testing.T) {
+	if LoadIgnore(t.TempDir()) != nil {
+		t.Fatal("LoadIgnore returned rules for a directory without ignore files")
+	}
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{".railwayignore": "*.tmp\n"})
+	ig := LoadIgnore(root)
+	if ig == nil || !ig.MatchesPath("a/b.tmp") || ig.MatchesPath("b.go") {
+		t.Fatal(".railwayignore rules not applied")
+	}
+}
+
+func TestStreamReportsFileError(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"a.txt": "a", "b.txt": "b"})
+	s, err := Open(Options{ProjectRoot: root, DeployRoot: root}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	// 扫描后删除的文件在打包时报错，读取方与 Err 都能看到
+	if err := os.Remove(filepath.Join(root, "b.txt")); err != nil {
+		t.Fatal(err)
+	}
+	_, err = io.ReadAll(s)
+	if !errors.Is(err, os.ErrNotExist) || !errors.Is(s.Err(), os.ErrNotExist) {
+		t.Fatalf("read err = %v, Err() = %v", err, s.Err())
+	}
+}
+
+func TestOpenMissingDirectory(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	if _, err := Open(Options{ProjectRoot: missing, DeployRoot: missing}, nil); err == nil {
+		t.Fatal("Open succeeded for a missing directory")
+	}
+}
@@ -0,0 +1,714 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// CassetteMode 录制/回放模式
+type CassetteMode int
+
+const (
+	// CassetteReplay 仅回放，未匹配的请求返回 ErrCassetteMiss，不访问网络
+	CassetteReplay CassetteMode = iota
+	// CassetteRecord 访问真实后端并覆盖写入 cassette 文件
+	CassetteRecord
+	// CassetteAuto 文件存在时回放，否则录制
+	CassetteAuto
+)
+
+// ErrCassetteMiss 回放模式下 cassette 中没有匹配的交互
+var ErrCassetteMiss = errors.New("railway: no matching cassette interaction")
+
+// cassetteVersion cassette 文件格式版本
+const cassetteVersion = 1
+
+// redacted 替换敏感值的占位符
+const redacted = "REDACTED"
+
+// redactHeaders 录制时脱敏的请求/响应头
+var redactHeaders = []string{"Authorization", "Project-Access-Token", "Cookie", "Set-Cookie"}
+
+// redactFields 录制时脱敏的 JSON 字段（请求体、响应体与 ws 帧中任意层级）
+var redactFields = map[string]bool{"token": true, "accessToken": true, "refreshToken": true}
+
+// redactSubtrees 其下全部字符串值都会脱敏的 JSON 字段（保留键名）：GetVariables 响应、
+// 变量 upsert 输入、环境配置与模板配置中的服务变量。与 query 并列的 variables 是 GraphQL
+// 请求变量本身，不整体脱敏，只递归处理其中的字段
+var redactSubtrees = map[string]bool{"variables": true, "variableCollection": true}
+
+// Redactor 自定义脱敏规则：path 为字符串值在 JSON 中的键路径（数组下标不计入），
+// 返回 (替换值, true) 时以替换值写入 cassette
+type Redactor func(path []string, value string) (string, bool)
+
+// Interaction 一次 HTTP 请求/响应或一次 WebSocket 订阅
+type Interaction struct {
+	// Kind "http" 或 "ws"
+	Kind     string            `json:"kind"`
+	Request  CassetteRequest   `json:"request"`
+	Response *CassetteResponse `json:"response,omitempty"`
+	// Frames ws 帧（按发生顺序）
+	Frames []CassetteFrame `json:"frames,omitempty"`
+	// Error 传输层错误（如连接被拒）
+	Error string `json:"error,omitempty"`
+
+	used bool
+}
+
+// CassetteRequest 录制的请求；非 JSON 请求体（如 /up 的 tar.gz）仅保存摘要
+type CassetteRequest struct {
+	Method     string          `json:"method"`
+	URL        string          `json:"url"`
+	Header     http.Header     `json:"header,omitempty"`
+	Body       json.RawMessage `json:"body,omitempty"`
+	BodySHA256 string          `json:"bodySha256,omitempty"`
+	BodySize   int64           `json:"bodySize,omitempty"`
+}
+
+// CassetteResponse 录制的响应（ws 为握手响应）
+type CassetteResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// CassetteFrame 一条 graphql-transport-ws 消息
+type CassetteFrame struct {
+	// Direction "send" 或 "recv"
+	Direction string          `json:"direction"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Cassette 录制的交互集合，可被同一 Client 的 HTTP 与 WebSocket 流量共享
+type Cassette struct {
+	Version      int            `json:"version"`
+	Interactions []*Interaction `json:"interactions"`
+
+	mu        sync.Mutex
+	path      string
+	mode      CassetteMode
+	redactors []Redactor
+}
+
+// OpenCassette 打开 cassette；回放模式下文件必须存在，录制模式下从空白开始
+func OpenCassette(path string, mode CassetteMode) (*Cassette, error) {
+	c := &Cassette{Version: cassetteVersion, path: path, mode: mode}
+	if mode == CassetteAuto {
+		if _, err := os.Stat(path); err == nil {
+			c.mode = CassetteReplay
+		} else {
+			c.mode = CassetteRecord
+		}
+	}
+	if c.mode == CassetteRecord {
+		return c, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("open cassette: %w", err)
+	}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("decode cassette %s: %w", path, err)
+	}
+	if c.Version != cassetteVersion {
+		return nil, fmt.Errorf("cassette %s: unsupported version %d", path, c.Version)
+	}
+	return c, nil
+}
+
+// Recording 是否处于录制模式
+func (c *Cassette) Recording() bool {
+	return c.mode == CassetteRecord
+}
+
+// Save 将 cassette 写入文件（先写临时文件再重命名）；回放模式下不做任何事
+func (c *Cassette) Save() error {
+	if !c.Recording() {
+		return nil
+	}
+	c.mu.Lock()
+	b, err := json.MarshalIndent(c, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(c.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, append(b, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// AddRedactor 注册自定义脱敏规则，在内置规则之后按注册顺序应用于请求体、响应体与 ws 帧。
+// 回放时请求同样先脱敏再匹配，因此录制与回放需注册相同的规则；应在发起请求前调用
+func (c *Cassette) AddRedactor(r Redactor) {
+	c.mu.Lock()
+	c.redactors = append(c.redactors, r)
+	c.mu.Unlock()
+}
+
+func (c *Cassette) add(it *Interaction) {
+	c.mu.Lock()
+	c.Interactions = append(c.Interactions, it)
+	c.mu.Unlock()
+}
+
+// match 按顺序查找第一条未使用且方法、路径与查询参数、请求体一致的交互；
+// 主机不参与匹配，录制与回放可以使用不同的 Endpoint（如 httptest 的随机端口）
+func (c *Cassette) match(kind string, req CassetteRequest) (*Interaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := requestURI(req.URL)
+	for _, it := range c.Interactions {
+		if it.used || it.Kind != kind || it.Request.Method != req.Method || requestURI(it.Request.URL) != key {
+			continue
+		}
+		if len(it.Request.Body) > 0 && !bytes.Equal(canonicalJSON(it.Request.Body), canonicalJSON(req.Body)) {
+			continue
+		}
+		// 非 JSON 请求体（如上传的归档）按摘要匹配，内容变化时不会静默回放
+		if it.Request.BodySHA256 != "" && it.Request.BodySHA256 != req.BodySHA256 {
+			continue
+		}
+		it.used = true
+		return it, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrCassetteMiss, req.Method, req.URL)
+}
+
+func requestURI(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return u.RequestURI()
+}
+
+// request 捕获请求；JSON 请求体脱敏后保存，其他请求体仅保存 SHA-256 与长度
+func (c *Cassette) request(method, rawURL string, header http.Header, body []byte) CassetteRequest {
+	cr := CassetteRequest{Method: method, URL: rawURL, Header: redactHeader(header)}
+	if len(body) == 0 {
+		return cr
+	}
+	if json.Valid(body) {
+		cr.Body = c.redact(body)
+		return cr
+	}
+	sum := sha256.Sum256(body)
+	cr.BodySHA256 = hex.EncodeToString(sum[:])
+	cr.BodySize = int64(len(body))
+	return cr
+}
+
+func redactHeader(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	out := h.Clone()
+	for _, k := range redactHeaders {
+		if out.Get(k) != "" {
+			out.Set(k, redacted)
+		}
+	}
+	return out
+}
+
+// redact 按内置与自定义规则脱敏并以排序后的键重新编码；非 JSON 内容原样返回
+func (c *Cassette) redact(b []byte) []byte {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return b
+	}
+	c.mu.Lock()
+	redactors := c.redactors
+	c.mu.Unlock()
+	out, err := json.Marshal(redactValue(v, nil, false, redactors))
+	if err != nil {
+		return b
+	}
+	return out
+}
+
+// redactValue 脱敏 v；all 表示 v 位于 redactSubtrees 字段之下
+func redactValue(v interface{}, path []string, all bool, redactors []Redactor) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		_, isRequest := t["query"].(string)
+		for k, fv := range t {
+			if _, ok := fv.(string); ok && redactFields[k] {
+				t[k] = redacted
+				continue
+			}
+			sub := all || (redactSubtrees[k] && !isRequest)
+			t[k] = redactValue(fv, append(path[:len(path):len(path)], k), sub, redactors)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = redactValue(e, path, all, redactors)
+		}
+	case string:
+		if all {
+			return redacted
+		}
+		for _, r := range redactors {
+			if s, ok := r(path, t); ok {
+				return s
+			}
+		}
+	}
+	return v
+}
+
+// canonicalJSON 以排序后的键重新编码，保证比较与录制结果稳定
+func canonicalJSON(b []byte) []byte {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return b
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return b
+	}
+	return out
+}
+
+// cassetteTransport 录制或回放 HTTP 交互（GraphQL 与 /up 上传）
+type cassetteTransport struct {
+	base     http.RoundTripper
+	cassette *Cassette
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.Body != http.NoBody && !isJSONContent(req.Header) {
+		return t.roundTripStream(req)
+	}
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+	creq := t.cassette.request(req.Method, req.URL.String(), req.Header, body)
+
+	if !t.cassette.Recording() {
+		it, err := t.cassette.match("http", creq)
+		if err != nil {
+			return nil, err
+		}
+		if it.Error != "" {
+			return nil, errors.New(it.Error)
+		}
+		return it.Response.httpResponse(req), nil
+	}
+
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+	resp, err := t.base.RoundTrip(out)
+	return t.record(&Interaction{Kind: "http", Request: creq}, resp, err)
+}
+
+// roundTripStream 处理非 JSON 请求体（/up 的 tar.gz）：边转发边计算 SHA-256，不在内存中缓存整个请求体
+func (t *cassetteTransport) roundTripStream(req *http.Request) (*http.Response, error) {
+	body := newHashingBody(req.Body)
+	if !t.cassette.Recording() {
+		_, err := io.Copy(io.Discard, body)
+		body.Close()
+		if err != nil {
+			return nil, err
+		}
+		creq := t.cassette.request(req.Method, req.URL.String(), req.Header, nil)
+		creq.BodySHA256, creq.BodySize = body.sum()
+		it, err := t.cassette.match("http", creq)
+		if err != nil {
+			return nil, err
+		}
+		if it.Error != "" {
+			return nil, errors.New(it.Error)
+		}
+		return it.Response.httpResponse(req), nil
+	}
+
+	out := req.Clone(req.Context())
+	out.Body = body
+	out.GetBody = nil
+	resp, err := t.base.RoundTrip(out)
+	// Transport 可能在返回后才于另一个 goroutine 中关闭请求体，摘要需等其读完
+	select {
+	case <-body.done:
+	case <-req.Context().Done():
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return nil, req.Context().Err()
+	}
+	creq := t.cassette.request(req.Method, req.URL.String(), req.Header, nil)
+	creq.BodySHA256, creq.BodySize = body.sum()
+	return t.record(&Interaction{Kind: "http", Request: creq}, resp, err)
+}
+
+// record 保存一次真实请求的结果（响应体脱敏后写入 cassette）
+func (t *cassetteTransport) record(it *Interaction, resp *http.Response, err error) (*http.Response, error) {
+	if err != nil {
+		it.Error = err.Error()
+		t.cassette.add(it)
+		_ = t.cassette.Save()
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	it.Response = &CassetteResponse{StatusCode: resp.StatusCode, Header: redactHeader(resp.Header), Body: string(t.cassette.redact(respBody))}
+	t.cassette.add(it)
+	if err := t.cassette.Save(); err != nil {
+		return nil, fmt.Errorf("save cassette: %w", err)
+	}
+	return resp, nil
+}
+
+// isJSONContent 请求体是否为 JSON（GraphQL 请求），其余请求体只保存摘要
+func isJSONContent(h http.Header) bool {
+	return strings.Contains(strings.ToLower(h.Get("Content-Type")), "json")
+}
+
+// hashingBody 转发请求体的同时计算 SHA-256 与长度；Close 后 done 关闭，摘要可用
+type hashingBody struct {
+	r    io.ReadCloser
+	h    hash.Hash
+	n    int64
+	once sync.Once
+	done chan struct{}
+}
+
+func newHashingBody(r io.ReadCloser) *hashingBody {
+	return &hashingBody{r: r, h: sha256.New(), done: make(chan struct{})}
+}
+
+func (b *hashingBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	b.h.Write(p[:n])
+	b.n += int64(n)
+	return n, err
+}
+
+func (b *hashingBody) Close() error {
+	err := b.r.Close()
+	b.once.Do(func() { close(b.done) })
+	return err
+}
+
+// sum 返回已读取内容的十六进制摘要与长度；须在 Close 之后调用
+func (b *hashingBody) sum() (string, int64) {
+	return hex.EncodeToString(b.h.Sum(nil)), b.n
+}
+
+func (r *CassetteResponse) httpResponse(req *http.Request) *http.Response {
+	header := r.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+// wsConn 订阅所需的连接操作，便于在真实连接与 cassette 之间切换
+type wsConn interface {
+	WriteJSON(v interface{}) error
+	ReadJSON(v interface{}) error
+	Close() error
+}
+
+// dialWS 建立 WebSocket 连接；配置 cassette 时录制或回放帧
+func (c *Client) dialWS(ctx context.Context, wsURL string, header http.Header) (wsConn, *http.Response, error) {
+	if c.cassette == nil {
+		conn, resp, err := c.wsDialer().DialContext(ctx, wsURL, header)
+		if err != nil {
+			return nil, resp, err
+		}
+		return conn, resp, nil
+	}
+
+	creq := c.cassette.request(http.MethodGet, wsURL, header, nil)
+	if !c.cassette.Recording() {
+		it, err := c.cassette.match("ws", creq)
+		if err != nil {
+			return nil, nil, err
+		}
+		if it.Response != nil && it.Response.StatusCode != http.StatusSwitchingProtocols {
+			return nil, it.Response.httpResponse(nil), websocket.ErrBadHandshake
+		}
+		if it.Error != "" {
+			return nil, nil, errors.New(it.Error)
+		}
+		return newReplayConn(c.cassette, it.Frames), nil, nil
+	}
+
+	it := &Interaction{Kind: "ws", Request: creq}
+	conn, resp, err := c.wsDialer().DialContext(ctx, wsURL, header)
+	if resp != nil {
+		it.Response = &CassetteResponse{StatusCode: resp.StatusCode, Header: redactHeader(resp.Header)}
+	}
+	if err != nil {
+		if resp != nil && resp.StatusCode != http.StatusSwitchingProtocols {
+			b, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+			it.Response.Body = string(b)
+			resp.Body = io.NopCloser(bytes.NewReader(b))
+		} else {
+			it.Error = err.Error()
+		}
+		c.cassette.add(it)
+		_ = c.cassette.Save()
+		return nil, resp, err
+	}
+	c.cassette.add(it)
+	return &recordConn{conn: conn, it: it, cassette: c.cassette}, resp, nil
+}
+
+// recordConn 透传真实连接并记录收发的帧，Close 时写入 cassette
+type recordConn struct {
+	conn     *websocket.Conn
+	it       *Interaction
+	cassette *Cassette
+}
+
+func (r *recordConn) record(direction string, data []byte) {
+	data = r.cassette.redact(data)
+	r.cassette.mu.Lock()
+	r.it.Frames = append(r.it.Frames, CassetteFrame{Direction: direction, Data: data})
+	r.cassette.mu.Unlock()
+}
+
+func (r *recordConn) WriteJSON(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := r.conn.WriteMessage(websocket.TextMessage, b); err != nil {
+		return err
+	}
+	r.record("send", b)
+	return nil
+}
+
+func (r *recordConn) ReadJSON(v interface{}) error {
+	_, b, err := r.conn.ReadMessage()
+	if err != nil {
+		return err
+	}
+	r.record("recv", b)
+	return json.Unmarshal(b, v)
+}
+
+func (r *recordConn) SetReadDeadline(t time.Time) error { return r.conn.SetReadDeadline(t) }
+
+func (r *recordConn) SetWriteDeadline(t time.Time) error { return r.conn.SetWriteDeadline(t) }
+
+func (r *recordConn) Close() error {
+	err := r.conn.Close()
+	if serr := r.cassette.Save(); serr != nil && err == nil {
+		err = fmt.Errorf("save cassette: %w", serr)
+	}
+	return err
+}
+
+// replayConn 回放录制的 recv 帧。回放时的 subscribe 按脱敏后的内容（query 与变量）对应到
+// 录制时第一个相同且未使用的 subscribe，帧的 ID 替换为回放时的 ID；同一连接上的多路订阅
+// 无论以何种顺序发起，帧都交给内容相同的订阅，同一订阅内保持录制顺序。所属订阅尚未发起的帧
+// 暂时跳过，不阻塞其他订阅。全部帧回放完毕后为仍未结束的订阅补发 complete（录制通常因客户端
+// 取消而结束，没有 complete 帧），随后保持连接直到 Close，而不是模拟断线引发重连；
+// 没有对应录制的 subscribe 返回 ErrCassetteMiss。
+type replayConn struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	cassette  *Cassette
+	frames    []CassetteFrame
+	delivered []bool
+	next      int                   // 第一个未回放的帧
+	subs      []*replaySub          // 录制时的 subscribe（按发送顺序）
+	byID      map[string]*replaySub // 录制 ID -> 订阅
+	pending   []wsMessage           // 待返回的合成 complete
+	closed    bool
+}
+
+// replaySub 录制时的一个订阅
+type replaySub struct {
+	id    string // 录制 ID
+	key   string // 规范化的 subscribe payload
+	live  string // 回放时对应的 ID；为空表示尚未发起
+	ended bool   // 已回放 complete/error
+}
+
+func newReplayConn(c *Cassette, frames []CassetteFrame) *replayConn {
+	r := &replayConn{cassette: c, frames: frames, delivered: make([]bool, len(frames)), byID: map[string]*replaySub{}}
+	r.cond = sync.NewCond(&r.mu)
+	for _, f := range frames {
+		var msg wsMessage
+		if f.Direction == "send" && json.Unmarshal(f.Data, &msg) == nil && msg.Type == wsTypeSubscribe {
+			sub := &replaySub{id: msg.ID, key: string(canonicalJSON(msg.Payload))}
+			r.subs = append(r.subs, sub)
+			r.byID[msg.ID] = sub
+		}
+	}
+	return r
+}
+
+func (r *replayConn) WriteJSON(v interface{}) error {
+	var msg wsMessage
+	switch m := v.(type) {
+	case wsMessage:
+		msg = m
+	case *wsMessage:
+		msg = *m
+	}
+	if msg.Type != wsTypeSubscribe {
+		return nil
+	}
+	// 录制的帧已脱敏，按同样的规则处理后比较
+	key := string(canonicalJSON(r.cassette.redact(msg.Payload)))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, sub := range r.subs {
+		if sub.live == "" && sub.key == key {
+			sub.live = msg.ID
+			r.checkDoneLocked()
+			r.cond.Broadcast()
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: subscribe %s", ErrCassetteMiss, msg.ID)
+}
+
+func (r *replayConn) ReadJSON(v interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for !r.closed {
+		if len(r.pending) > 0 {
+			msg := r.pending[0]
+			r.pending = r.pending[1:]
+			return assignMessage(msg, v)
+		}
+		msg, ok, err := r.nextLocked()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return assignMessage(msg, v)
+		}
+		r.cond.Wait()
+	}
+	return &websocket.CloseError{Code: websocket.CloseNormalClosure, Text: "cassette closed"}
+}
+
+// nextLocked 取出下一个可回放的帧：跳过 send 帧与所属订阅尚未发起的帧
+func (r *replayConn) nextLocked() (wsMessage, bool, error) {
+	for i := r.next; i < len(r.frames); i++ {
+		if r.delivered[i] {
+			continue
+		}
+		f := r.frames[i]
+		if f.Direction != "recv" {
+			r.markLocked(i)
+			continue
+		}
+		var msg wsMessage
+		if err := json.Unmarshal(f.Data, &msg); err != nil {
+			return msg, false, err
+		}
+		if sub := r.byID[msg.ID]; sub != nil {
+			if sub.live == "" {
+				continue
+			}
+			if msg.Type == wsTypeComplete || msg.Type == wsTypeError {
+				sub.ended = true
+			}
+			msg.ID = sub.live
+		}
+		r.markLocked(i)
+		return msg, true, nil
+	}
+	return wsMessage{}, false, nil
+}
+
+// markLocked 标记第 i 帧已回放
+func (r *replayConn) markLocked(i int) {
+	r.delivered[i] = true
+	for r.next < len(r.frames) && r.delivered[r.next] {
+		r.next++
+	}
+	r.checkDoneLocked()
+}
+
+// checkDoneLocked 全部订阅都已发起且帧回放完毕时，为未结束的订阅补发 complete
+func (r *replayConn) checkDoneLocked() {
+	if r.next < len(r.frames) {
+		return
+	}
+	for _, sub := range r.subs {
+		if sub.live == "" {
+			return
+		}
+	}
+	for _, sub := range r.subs {
+		if !sub.ended {
+			sub.ended = true
+			r.pending = append(r.pending, wsMessage{ID: sub.live, Type: wsTypeComplete})
+		}
+	}
+}
+
+func assignMessage(msg wsMessage, v interface{}) error {
+	if m, ok := v.(*wsMessage); ok && m != nil {
+		*m = msg
+		return nil
+	}
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func (r *replayConn) Close() error {
+	r.mu.Lock()
+	r.closed = true
+	r.cond.Broadcast()
+	r.mu.Unlock()
+	return nil
+}
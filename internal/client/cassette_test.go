@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCassetteUploadMatchesBodyDigest(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got = string(b)
+		_, _ = w.Write([]byte(`{"deploymentId":"d1"}`))
+	}))
+	defer srv.Close()
+	path := filepath.Join(t.TempDir(), "up.json")
+
+	rec, err := OpenCassette(path, CassetteRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newTestClient(t, srv.URL, Options{Cassette: rec})
+	// 管道请求体没有长度，录制时只能边转发边计算摘要
+	pr, pw := io.Pipe()
+	go func() {
+		_, _ = io.WriteString(pw, "archive-v1")
+		pw.Close()
+	}()
+	resp, err := c.Upload(context.Background(), "p", "e", "s", pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got != "archive-v1" {
+		t.Fatalf("server received %q", got)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if it := rec.Interactions[0]; it.Request.BodySHA256 == "" || it.Request.BodySize != int64(len("archive-v1")) {
+		t.Fatalf("recorded request = %+v", it.Request)
+	}
+
+	replay := func(body string) error {
+		cas, err := OpenCassette(path, CassetteReplay)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := newTestClient(t, "http://replay.invalid", Options{Cassette: cas})
+		resp, err := c.Upload(context.Background(), "p", "e", "s", strings.NewReader(body))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		if !strings.Contains(string(b), "d1") {
+			t.Fatalf("replayed body = %s", b)
+		}
+		return nil
+	}
+	if err := replay("archive-v1"); err != nil {
+		t.Fatalf("replay same archive: %v", err)
+	}
+	if err := replay("archive-v2"); !errors.Is(err, ErrCassetteMiss) {
+		t.Fatalf("replay changed archive err = %v, want ErrCassetteMiss", err)
+	}
+}
@@ -2,53 +2,161 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/machinebox/graphql"
 	"github.com/railwayapp/cli/internal/config"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// cliVersion 请求头中携带的客户端版本
+const cliVersion = "4.6.1"
+
+const defaultAPITimeout = 30 * time.Second
+
+// uploadResponseTimeout 上传请求体发送完毕后等待响应头的上限。打包与发送阶段不设整体超时
+// （经管道边打包边发送，耗时随项目大小变化），只受调用方 ctx 限制
+var uploadResponseTimeout = 300 * time.Second
+
+// errUploadResponseTimeout 归档已发送完毕但服务端在 uploadResponseTimeout 内没有响应
+var errUploadResponseTimeout = fmt.Errorf("upload: no response after the archive was sent: %w", context.DeadlineExceeded)
+
+// TokenKind 令牌类型，决定认证头的写法
+type TokenKind int
+
+const (
+	// TokenKindNone 不携带认证信息
+	TokenKindNone TokenKind = iota
+	// TokenKindAPI 账户/团队 API Token，使用 authorization: Bearer
+	TokenKindAPI
+	// TokenKindProject 项目访问 Token，使用 project-access-token
+	TokenKindProject
+)
+
+// Credentials 客户端级认证信息
+type Credentials struct {
+	Token string
+	Kind  TokenKind
+}
+
+// Options 客户端选项；零值字段回退到环境变量与配置文件
+type Options struct {
+	// Credentials 为 nil 时，每次请求按 RAILWAY_TOKEN > RAILWAY_API_TOKEN > 配置文件 的顺序解析
+	Credentials *Credentials
+	// Host 为空时使用 cfg.GetHost()
+	Host string
+	// Endpoint Backboard 根地址（如 https://backboard.railway.com），GraphQL、WebSocket 与上传地址均由其派生
+	Endpoint string
+	// HTTPClient 为空时 GraphQL 请求使用 30s 超时的默认客户端；上传不设整体超时（使用其副本并清除 Timeout），
+	// 只在请求体发送完毕后限制等待响应的时间，整体期限由 ctx 决定
+	HTTPClient *http.Client
+	// WebSocketURL 订阅地址，为空时由 Endpoint 派生
+	WebSocketURL string
+	// UploadURL /up 上传的根地址，为空时使用 Endpoint
+	UploadURL string
+	// RateLimit 客户端令牌桶与 429/503 重试策略，零值表示不限速、默认重试 3 次
+	RateLimit RateLimitOptions
+	// Interceptors 依次包裹每次 Query/Mutate 调用，Interceptors[0] 位于最外层
+	Interceptors []Interceptor
+	// TracerProvider 用于创建 span，为 nil 时使用 otel 全局 provider
+	TracerProvider trace.TracerProvider
+	// Cassette 非 nil 时录制或回放全部 HTTP 与 WebSocket 流量
+	Cassette *Cassette
+	// Reconnect 订阅连接断开后的重新订阅策略，零值为最多连续重连 5 次
+	Reconnect ReconnectOptions
+	// Keepalive 订阅连接的心跳与超时检测，零值为每 15s ping 一次、10s 内无响应视为断开
+	Keepalive KeepaliveOptions
+}
+
 // Client 表示GraphQL客户端
 type Client struct {
-	client         *graphql.Client
-	config         *config.Config
-	internalClient *graphql.Client
+	config      *config.Config
+	credentials *Credentials
+	host        string
+	endpoint    string
+	httpClient  *http.Client
+	wsURL       string
+	uploadURL   string
+	rateLimit   RateLimitOptions
+	bucket      *tokenBucket
+	gqlHTTP     *http.Client
+	uploadHTTP  *http.Client
+	invoker     Invoker
+	tracer      trace.Tracer
+	cassette    *Cassette
+	reconnect   ReconnectOptions
+	keepalive   KeepaliveOptions
+	ws          *wsMux
 }
 
 // New 创建新的GraphQL客户端
 func New(cfg *config.Config) (*Client, error) {
-	// 创建HTTP客户端
-	httpClient := &http.Client{
-		Timeout: 30 * time.Second,
+	return NewWithOptions(cfg, Options{})
+}
+
+// NewWithOptions 创建携带独立认证信息与主机的GraphQL客户端
+func NewWithOptions(cfg *config.Config, opts Options) (*Client, error) {
+	c := &Client{
+		config:     cfg,
+		host:       strings.TrimSpace(opts.Host),
+		endpoint:   strings.TrimRight(strings.TrimSpace(opts.Endpoint), "/"),
+		httpClient: opts.HTTPClient,
+		wsURL:      strings.TrimSpace(opts.WebSocketURL),
+		uploadURL:  strings.TrimRight(strings.TrimSpace(opts.UploadURL), "/"),
+		rateLimit:  opts.RateLimit,
+		bucket:     newTokenBucket(opts.RateLimit.RequestsPerSecond, opts.RateLimit.Burst),
+		tracer:     newTracer(opts.TracerProvider),
+		cassette:   opts.Cassette,
+		reconnect:  opts.Reconnect,
+		keepalive:  opts.Keepalive,
+	}
+	c.ws = &wsMux{c: c}
+	if opts.Credentials != nil {
+		creds := *opts.Credentials
+		c.credentials = &creds
 	}
 
-	// 创建GraphQL客户端
-	client := graphql.NewClient(cfg.GetBackboardURL(), graphql.WithHTTPClient(httpClient))
-	internalClient := graphql.NewClient(cfg.GetBackboardInternalURL(), graphql.WithHTTPClient(httpClient))
-	return &Client{
-		client:         client,
-		config:         cfg,
-		internalClient: internalClient,
-	}, nil
+	// 创建HTTP客户端：GraphQL 默认 30s 超时，二者共享同一令牌桶。
+	// 自定义 HTTPClient 的超时面向 API 请求；上传的整体超时会连同打包时间一起计算，因此上传副本不设 Timeout，
+	// 由 Upload 在请求体发送完毕后限制等待响应的时间
+	if c.httpClient != nil {
+		c.gqlHTTP = c.wrapHTTPClient(c.httpClient)
+		upload := *c.httpClient
+		upload.Timeout = 0
+		c.uploadHTTP = c.wrapHTTPClient(&upload)
+	} else {
+		c.gqlHTTP = c.wrapHTTPClient(&http.Client{Timeout: defaultAPITimeout})
+		c.uploadHTTP = c.wrapHTTPClient(&http.Client{})
+	}
+	// tracing 位于最外层，使用户拦截器的耗时与错误计入 span
+	c.invoker = chainInterceptors(append([]Interceptor{c.tracingInterceptor}, opts.Interceptors...), c.run)
+	return c, nil
 }
 
-// NewAuthorized 创建带认证的GraphQL客户端
-func NewAuthorized(cfg *config.Config) (*Client, error) {
-	// 创建HTTP客户端并设置认证头
-	httpClient := &http.Client{
-		Timeout: 30 * time.Second,
+// wrapHTTPClient 复制 hc 并在其 Transport 外层加上限流/重试，不修改调用方传入的客户端
+func (c *Client) wrapHTTPClient(hc *http.Client) *http.Client {
+	cp := *hc
+	base := cp.Transport
+	if base == nil {
+		base = http.DefaultTransport
 	}
+	if c.cassette != nil {
+		base = &cassetteTransport{base: base, cassette: c.cassette}
+	}
+	cp.Transport = &rateLimitTransport{base: base, bucket: c.bucket, opts: c.rateLimit}
+	return &cp
+}
 
-	client := graphql.NewClient(cfg.GetBackboardURL(), graphql.WithHTTPClient(httpClient))
-	internalClient := graphql.NewClient(cfg.GetBackboardInternalURL(), graphql.WithHTTPClient(httpClient))
-
-	return &Client{
-		client:         client,
-		config:         cfg,
-		internalClient: internalClient,
-	}, nil
+// NewAuthorized 创建带认证的GraphQL客户端
+func NewAuthorized(cfg *config.Config) (*Client, error) {
+	return New(cfg)
 }
 
 // NewUnauthorized 创建无认证的GraphQL客户端
@@ -56,21 +164,76 @@ func NewUnauthorized(cfg *config.Config) (*Client, error) {
 	return New(cfg)
 }
 
-// Query 执行GraphQL查询
-func (c *Client) Query(ctx context.Context, query string, variables map[string]interface{}, response interface{}) error {
-	req := graphql.NewRequest(query)
+// Host 返回客户端使用的Railway主机地址
+func (c *Client) Host() string {
+	if c.host != "" {
+		return c.host
+	}
+	return c.config.GetHost()
+}
 
-	// 添加变量
-	if variables != nil {
-		for key, value := range variables {
-			req.Var(key, value)
-		}
+// Endpoint 返回 Backboard 根地址
+func (c *Client) Endpoint() string {
+	if c.endpoint != "" {
+		return c.endpoint
 	}
+	return fmt.Sprintf("https://backboard.%s", c.Host())
+}
 
-	// 设置认证头
-	c.setAuthHeaders(req)
+// BackboardURL 获取Backboard GraphQL端点
+func (c *Client) BackboardURL() string {
+	return c.Endpoint() + "/graphql/v2"
+}
+
+// BackboardInternalURL 获取Backboard内部GraphQL端点
+func (c *Client) BackboardInternalURL() string {
+	return c.Endpoint() + "/graphql/internal"
+}
+
+// WebSocketURL 获取 graphql-transport-ws 订阅地址
+func (c *Client) WebSocketURL() string {
+	if c.wsURL != "" {
+		return c.wsURL
+	}
+	u, err := url.Parse(c.BackboardURL())
+	if err != nil {
+		return fmt.Sprintf("wss://backboard.%s/graphql/v2", c.Host())
+	}
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	default:
+		u.Scheme = "wss"
+	}
+	return u.String()
+}
+
+// UploadURL 获取 /up 上传地址
+func (c *Client) UploadURL(projectID, environmentID, serviceID string) string {
+	base := c.uploadURL
+	if base == "" {
+		base = c.Endpoint()
+	}
+	return fmt.Sprintf("%s/project/%s/environment/%s/up?serviceId=%s", base, url.PathEscape(projectID), url.PathEscape(environmentID), url.QueryEscape(serviceID))
+}
+
+// Credentials 返回当前生效的认证信息
+func (c *Client) Credentials() Credentials {
+	if c.credentials != nil {
+		return *c.credentials
+	}
+	if token := config.GetRailwayToken(); token != nil {
+		return Credentials{Token: *token, Kind: TokenKindProject}
+	}
+	if token := c.config.GetRailwayAuthToken(); token != nil {
+		return Credentials{Token: *token, Kind: TokenKindAPI}
+	}
+	return Credentials{}
+}
 
-	return c.client.Run(ctx, req, response)
+// Query 执行GraphQL查询
+func (c *Client) Query(ctx context.Context, query string, variables map[string]interface{}, response interface{}) error {
+	return c.do(ctx, EndpointPublic, query, variables, response)
 }
 
 // Mutate 执行GraphQL变更
@@ -79,37 +242,135 @@ func (c *Client) Mutate(ctx context.Context, mutation string, variables map[stri
 }
 
 // setAuthHeaders 设置认证头
-func (c *Client) setAuthHeaders(req *graphql.Request) {
+func (c *Client) setAuthHeaders(h http.Header) {
 	// 设置用户代理
-	req.Header.Set("x-source", fmt.Sprintf("railway-cli/%s", "4.6.1"))
-	req.Header.Set("user-agent", fmt.Sprintf("railway-cli/%s", "4.6.1"))
+	h.Set("x-source", fmt.Sprintf("railway-cli/%s", cliVersion))
+	h.Set("user-agent", fmt.Sprintf("railway-cli/%s", cliVersion))
 
 	// 设置认证头
-	if token := config.GetRailwayToken(); token != nil {
-		req.Header.Set("project-access-token", *token)
-	} else if token := c.config.GetRailwayAuthToken(); token != nil {
-		req.Header.Set("authorization", fmt.Sprintf("Bearer %s", *token))
+	creds := c.Credentials()
+	if creds.Token == "" {
+		return
+	}
+	switch creds.Kind {
+	case TokenKindProject:
+		h.Set("project-access-token", creds.Token)
+	case TokenKindAPI:
+		h.Set("authorization", fmt.Sprintf("Bearer %s", creds.Token))
 	}
 }
 
 // QueryInternal 执行内部GraphQL查询
 func (c *Client) QueryInternal(ctx context.Context, query string, variables map[string]interface{}, response interface{}) error {
-	req := graphql.NewRequest(query)
+	return c.do(ctx, EndpointInternal, query, variables, response)
+}
+
+// MutateInternal 执行内部GraphQL变更
+func (c *Client) MutateInternal(ctx context.Context, mutation string, variables map[string]interface{}, response interface{}) error {
+	return c.QueryInternal(ctx, mutation, variables, response)
+}
+
+// Upload 将 tar.gz 归档上传到 /up 端点，调用方负责关闭响应体；非 2xx 响应以 *APIError 返回。
+// body 实现 io.Closer 时，无论成功与否都会被关闭。上传没有整体超时：body 读完后
+// 服务端须在 uploadResponseTimeout 内返回响应头，其余期限由 ctx 决定
+func (c *Client) Upload(ctx context.Context, projectID, environmentID, serviceID string, body io.Reader) (resp *http.Response, err error) {
+	ctx, span := c.tracer.Start(ctx, "railway.up.upload", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(AttrProjectID.String(projectID), AttrEnvironmentID.String(environmentID), AttrServiceID.String(serviceID)))
+	defer func() { EndSpan(span, err) }()
 
-	// 添加变量
-	if variables != nil {
-		for key, value := range variables {
-			req.Var(key, value)
+	ctx, cancel := context.WithCancelCause(ctx)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.UploadURL(projectID, environmentID, serviceID), body)
+	if err != nil {
+		cancel(nil)
+		// 请求未发出时 HTTP 客户端不会关闭 body，需在此关闭以停止后台打包
+		if rc, ok := body.(io.Closer); ok {
+			rc.Close()
 		}
+		return nil, err
 	}
+	if req.Body == nil {
+		req.Body = http.NoBody
+	}
+	// 统计实际发送的字节数（流式归档的 ContentLength 未知），并在读完后开始等待响应的计时
+	counted := newUploadBody(req.Body, func() { cancel(errUploadResponseTimeout) })
+	req.Body, req.GetBody = counted, nil
+	req.Header.Set("Content-Type", "application/octet-stream")
+	c.setAuthHeaders(req.Header)
+	injectTraceContext(ctx, req.Header)
 
-	// 设置认证头
-	c.setAuthHeaders(req)
+	resp, err = c.uploadHTTP.Do(req)
+	counted.stop()
+	if n := counted.sent(); n > 0 {
+		span.SetAttributes(AttrUploadBytes.Int64(n))
+	}
+	if err != nil {
+		if cause := context.Cause(ctx); errors.Is(cause, errUploadResponseTimeout) {
+			err = cause
+		}
+		cancel(nil)
+		return nil, err
+	}
+	span.SetAttributes(AttrHTTPStatusCode.Int(resp.StatusCode))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer cancel(nil)
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return nil, newHTTPError(resp, b)
+	}
+	// 响应体仍依赖该 ctx，关闭响应体时再释放
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: func() { cancel(nil) }}
+	return resp, nil
+}
 
-	return c.internalClient.Run(ctx, req, response)
+// uploadBody 统计已读取的字节数；读到 EOF 后启动等待响应的计时器，超时调用 onTimeout
+type uploadBody struct {
+	io.ReadCloser
+	n     atomic.Int64
+	timer *time.Timer
+
+	mu      sync.Mutex
+	started bool
+	stopped bool
 }
 
-// MutateInternal 执行内部GraphQL变更
-func (c *Client) MutateInternal(ctx context.Context, mutation string, variables map[string]interface{}, response interface{}) error {
-	return c.QueryInternal(ctx, mutation, variables, response)
+func newUploadBody(rc io.ReadCloser, onTimeout func()) *uploadBody {
+	b := &uploadBody{ReadCloser: rc, timer: time.AfterFunc(uploadResponseTimeout, onTimeout)}
+	b.timer.Stop()
+	return b
+}
+
+func (b *uploadBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(int64(n))
+	if err == io.EOF {
+		b.mu.Lock()
+		if !b.started && !b.stopped {
+			b.started = true
+			b.timer.Reset(uploadResponseTimeout)
+		}
+		b.mu.Unlock()
+	}
+	return n, err
+}
+
+// stop 收到响应（或请求失败）后停止计时；之后读到 EOF 也不再计时
+func (b *uploadBody) stop() {
+	b.mu.Lock()
+	b.stopped = true
+	b.timer.Stop()
+	b.mu.Unlock()
+}
+
+func (b *uploadBody) sent() int64 { return b.n.Load() }
+
+// cancelOnClose 关闭响应体时释放请求的 ctx
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel func()
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
 }
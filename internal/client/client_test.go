@@ -0,0 +1,224 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// closeTracker 记录是否被关闭的请求体
+type closeTracker struct {
+	*strings.Reader
+	closed bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestUploadClosesBodyWhenRequestFails(t *testing.T) {
+	c := newTestClient(t, "http://127.0.0.1:1", Options{UploadURL: "http://[::1"})
+	body := &closeTracker{Reader: strings.NewReader("archive")}
+	if _, err := c.Upload(context.Background(), "p", "e", "s", body); err == nil {
+		t.Fatal("Upload succeeded with an invalid URL")
+	}
+	if !body.closed {
+		t.Fatal("body not closed after the request could not be built")
+	}
+}
+
+// recordingTransport 记录每个请求的 URL 与认证头，不访问网络
+type recordingTransport struct {
+	mu   sync.Mutex
+	reqs []*http.Request
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_, _ = io.Copy(io.Discard, req.Body)
+		req.Body.Close()
+	}
+	rt.mu.Lock()
+	rt.reqs = append(rt.reqs, req)
+	rt.mu.Unlock()
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"data":{}}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestClientsKeepTheirOwnCredentialsAndHosts(t *testing.T) {
+	t.Setenv("RAILWAY_TOKEN", "env-project-token")
+	t.Setenv("RAILWAY_API_TOKEN", "env-api-token")
+	rt := &recordingTransport{}
+	hc := &http.Client{Transport: rt}
+	api, err := NewWithOptions(nil, Options{
+		Endpoint:    "https://backboard.a.example",
+		Credentials: &Credentials{Token: "api-token", Kind: TokenKindAPI},
+		HTTPClient:  hc,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	project, err := NewWithOptions(nil, Options{
+		Host:        "b.example",
+		Credentials: &Credentials{Token: "project-token", Kind: TokenKindProject},
+		HTTPClient:  hc,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 两个客户端并发请求，每个请求只携带自己的认证头并发往自己的地址
+	var wg sync.WaitGroup
+	for _, c := range []*Client{api, project} {
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func(c *Client) {
+				defer wg.Done()
+				if err := c.Query(context.Background(), `query Me { me { id } }`, nil, nil); err != nil {
+					t.Error(err)
+				}
+				if err := c.QueryInternal(context.Background(), `query Me { me { id } }`, nil, nil); err != nil {
+					t.Error(err)
+				}
+				resp, err := c.Upload(context.Background(), "p", "e", "s", strings.NewReader("archive"))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				resp.Body.Close()
+			}(c)
+		}
+	}
+	wg.Wait()
+
+	counts := map[string]int{}
+	for _, req := range rt.reqs {
+		auth, pat := req.Header.Get("Authorization"), req.Header.Get("Project-Access-Token")
+		var who string
+		switch {
+		case req.URL.Host == "backboard.a.example" && auth == "Bearer api-token" && pat == "":
+			who = "api"
+		case req.URL.Host == "backboard.b.example" && pat == "project-token" && auth == "":
+			who = "project"
+		default:
+			t.Fatalf("request %s carries authorization %q, project-access-token %q", req.URL, auth, pat)
+		}
+		counts[fmt.Sprintf("%s %s", who, req.URL.Path)]++
+	}
+	for _, who := range []string{"api", "project"} {
+		for _, path := range []string{"/graphql/v2", "/graphql/internal", "/project/p/environment/e/up"} {
+			if n := counts[who+" "+path]; n != 5 {
+				t.Errorf("%s %s: %d requests, want 5", who, path, n)
+			}
+		}
+	}
+}
+
+func TestClientURLs(t *testing.T) {
+	tests := []struct {
+		name                  string
+		opts                  Options
+		backboard, ws, upload string
+	}{
+		{
+			name:      "host",
+			opts:      Options{Host: "railway-staging.com"},
+			backboard: "https://backboard.railway-staging.com/graphql/v2",
+			ws:        "wss://backboard.railway-staging.com/graphql/v2",
+			upload:    "https://backboard.railway-staging.com/project/p/environment/e/up?serviceId=s",
+		},
+		{
+			name:      "endpoint overrides host",
+			opts:      Options{Host: "railway.com", Endpoint: " https://proxy.example/railway/ "},
+			backboard: "https://proxy.example/railway/graphql/v2",
+			ws:        "wss://proxy.example/railway/graphql/v2",
+			upload:    "https://proxy.example/railway/project/p/environment/e/up?serviceId=s",
+		},
+		{
+			name:      "http endpoint uses ws",
+			opts:      Options{Endpoint: "http://127.0.0.1:8080"},
+			backboard: "http://127.0.0.1:8080/graphql/v2",
+			ws:        "ws://127.0.0.1:8080/graphql/v2",
+			upload:    "http://127.0.0.1:8080/project/p/environment/e/up?serviceId=s",
+		},
+		{
+			name:      "explicit websocket url",
+			opts:      Options{Endpoint: "http://127.0.0.1:8080", WebSocketURL: "wss://ws.example/graphql"},
+			backboard: "http://127.0.0.1:8080/graphql/v2",
+			ws:        "wss://ws.example/graphql",
+			upload:    "http://127.0.0.1:8080/project/p/environment/e/up?serviceId=s",
+		},
+		{
+			name:      "explicit upload url",
+			opts:      Options{Host: "railway.com", UploadURL: "https://upload.example/"},
+			backboard: "https://backboard.railway.com/graphql/v2",
+			ws:        "wss://backboard.railway.com/graphql/v2",
+			upload:    "https://upload.example/project/p/environment/e/up?serviceId=s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewWithOptions(nil, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.BackboardURL(); got != tt.backboard {
+				t.Errorf("BackboardURL = %s, want %s", got, tt.backboard)
+			}
+			if got := c.WebSocketURL(); got != tt.ws {
+				t.Errorf("WebSocketURL = %s, want %s", got, tt.ws)
+			}
+			if got := c.UploadURL("p", "e", "s"); got != tt.upload {
+				t.Errorf("UploadURL = %s, want %s", got, tt.upload)
+			}
+		})
+	}
+
+	// ID 中的特殊字符被转义
+	c, _ := NewWithOptions(nil, Options{Endpoint: "https://backboard.railway.com"})
+	if got, want := c.UploadURL("p/1", "e 1", "s&x"), "https://backboard.railway.com/project/p%2F1/environment/e%201/up?serviceId=s%26x"; got != want {
+		t.Errorf("UploadURL = %s, want %s", got, want)
+	}
+}
+
+func TestClientCopiesHTTPClient(t *testing.T) {
+	rt := &recordingTransport{}
+	hc := &http.Client{Transport: rt, Timeout: 10 * time.Second}
+	c, err := NewWithOptions(nil, Options{
+		Endpoint:    "https://backboard.railway.com",
+		Credentials: &Credentials{Token: "t", Kind: TokenKindAPI},
+		HTTPClient:  hc,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Query(context.Background(), `query Me { me { id } }`, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Upload(context.Background(), "p", "e", "s", strings.NewReader("archive"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	// 请求经由调用方的 Transport 发出，但调用方的 *http.Client 本身不被修改
+	if len(rt.reqs) != 2 {
+		t.Fatalf("transport saw %d requests, want 2", len(rt.reqs))
+	}
+	if hc.Transport != rt || hc.Timeout != 10*time.Second {
+		t.Fatalf("caller's client modified: %+v", hc)
+	}
+	if c.gqlHTTP == hc || c.uploadHTTP == hc {
+		t.Fatal("client not copied")
+	}
+}
@@ -0,0 +1,220 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+)
+
+// 哨兵错误：可通过 errors.Is 判断 APIError 的类别
+var (
+	ErrNotFound     = errors.New("railway: not found")
+	ErrUnauthorized = errors.New("railway: unauthorized")
+	ErrRateLimited  = errors.New("railway: rate limited")
+	ErrConflict     = errors.New("railway: conflict")
+)
+
+// GraphQLError 单条 GraphQL 错误
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Locations  []ErrorLocation        `json:"locations,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// ErrorLocation 错误在查询文本中的位置
+type ErrorLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Code 返回 extensions.code（不存在时为空）
+func (e GraphQLError) Code() string {
+	if e.Extensions == nil {
+		return ""
+	}
+	if s, ok := e.Extensions["code"].(string); ok {
+		return s
+	}
+	return ""
+}
+
+// APIError 表示 Backboard 返回的错误（HTTP 非 2xx 或 GraphQL errors 非空）
+type APIError struct {
+	// StatusCode HTTP 状态码
+	StatusCode int
+	// RequestID 响应头中的请求 ID（x-request-id 等），便于向 Railway 反馈问题
+	RequestID string
+	// Errors GraphQL 错误列表
+	Errors []GraphQLError
+	// Body 非 JSON 响应的原始内容（截断后）
+	Body string
+	// Header 响应头（用于读取 Retry-After 等）
+	Header http.Header
+}
+
+// Error 实现 error，格式与早期 "graphql: <message>" 保持一致
+func (e *APIError) Error() string {
+	var b strings.Builder
+	if len(e.Errors) > 0 {
+		b.WriteString("graphql: ")
+		b.WriteString(e.Errors[0].Message)
+		if n := len(e.Errors) - 1; n > 0 {
+			fmt.Fprintf(&b, " (and %d more)", n)
+		}
+	} else {
+		fmt.Fprintf(&b, "railway: http status %d", e.StatusCode)
+		if body := strings.TrimSpace(e.Body); body != "" {
+			b.WriteString(": ")
+			b.WriteString(body)
+		}
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " [request id %s]", e.RequestID)
+	}
+	return b.String()
+}
+
+// Codes 返回所有 GraphQL 错误的 extensions.code
+func (e *APIError) Codes() []string {
+	var out []string
+	for _, ge := range e.Errors {
+		if c := ge.Code(); c != "" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Is 支持 errors.Is(err, ErrNotFound) 等判断
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound, ErrUnauthorized, ErrRateLimited, ErrConflict:
+		return e.kind() == target
+	}
+	return false
+}
+
+// Temporary 报告该错误是否值得重试（限流或服务端 5xx）
+func (e *APIError) Temporary() bool {
+	if e.kind() == ErrRateLimited {
+		return true
+	}
+	return e.StatusCode >= 500 && e.StatusCode != http.StatusNotImplemented
+}
+
+// kind 根据状态码、extensions.code 与消息文本归类
+func (e *APIError) kind() error {
+	switch e.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusConflict:
+		return ErrConflict
+	}
+	for _, ge := range e.Errors {
+		switch strings.ToUpper(ge.Code()) {
+		case "UNAUTHENTICATED", "UNAUTHORIZED", "FORBIDDEN":
+			return ErrUnauthorized
+		case "NOT_FOUND":
+			return ErrNotFound
+		case "RATE_LIMITED", "TOO_MANY_REQUESTS", "RATELIMITED":
+			return ErrRateLimited
+		case "CONFLICT", "ALREADY_EXISTS":
+			return ErrConflict
+		}
+	}
+	// 后端并不总是返回 code，退回到消息匹配
+	for _, ge := range e.Errors {
+		msg := strings.ToLower(ge.Message)
+		switch {
+		case strings.Contains(msg, "not authorized"), strings.Contains(msg, "unauthorized"), strings.Contains(msg, "unauthenticated"):
+			return ErrUnauthorized
+		case strings.Contains(msg, "not found"):
+			return ErrNotFound
+		case strings.Contains(msg, "rate limit"), strings.Contains(msg, "too many requests"):
+			return ErrRateLimited
+		case strings.Contains(msg, "already exists"), strings.Contains(msg, "conflict"):
+			return ErrConflict
+		}
+	}
+	return nil
+}
+
+// requestIDHeaders 依次尝试的请求 ID 响应头
+var requestIDHeaders = []string{"x-request-id", "x-railway-request-id", "cf-ray"}
+
+// newHTTPError 由响应与已读取的响应体构造 APIError（响应体截断保存）
+func newHTTPError(resp *http.Response, body []byte) *APIError {
+	e := &APIError{StatusCode: resp.StatusCode, Header: resp.Header, RequestID: requestID(resp.Header)}
+	const maxBody = 2048
+	if len(body) > maxBody {
+		body = body[:maxBody]
+	}
+	e.Body = string(body)
+	return e
+}
+
+func requestID(h http.Header) string {
+	for _, k := range requestIDHeaders {
+		if v := h.Get(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// IsRetryable 判断错误是否为临时性错误（限流、5xx、网络超时/连接中断）。
+// 只有 context.Canceled 视为最终错误：http.Client.Timeout 等单次请求超时同样包装了
+// context.DeadlineExceeded，仍可重试；调用方应检查自身 ctx.Err() 决定是否继续。
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE)
+}
+
+// IsRetryableMutation 判断非幂等变更失败后能否原样重试：只有请求尚未发出（拨号失败、连接被拒绝、
+// DNS 解析失败）或服务端以限流拒绝处理时才可以。请求发出后的 EOF、连接重置、超时与 5xx
+// 无法确定变更是否已生效，调用方应先确认结果，不能直接再次提交。
+func IsRetryableMutation(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.kind() == ErrRateLimited
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"canceled", context.Canceled, false},
+		{"wrapped canceled", fmt.Errorf("query: %w", context.Canceled), false},
+		{"deadline exceeded", context.DeadlineExceeded, true},
+		{"url timeout", &url.Error{Op: "Post", URL: "https://x", Err: context.DeadlineExceeded}, true},
+		{"rate limited", &APIError{StatusCode: http.StatusTooManyRequests}, true},
+		{"server error", &APIError{StatusCode: http.StatusBadGateway}, true},
+		{"not implemented", &APIError{StatusCode: http.StatusNotImplemented}, false},
+		{"graphql rate limit code", &APIError{StatusCode: 200, Errors: []GraphQLError{{Message: "slow down", Extensions: map[string]interface{}{"code": "RATE_LIMITED"}}}}, true},
+		{"not found", &APIError{StatusCode: http.StatusNotFound}, false},
+		{"unauthorized", &APIError{StatusCode: http.StatusUnauthorized}, false},
+		{"unexpected eof", io.ErrUnexpectedEOF, true},
+		{"conn reset", fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{"conn refused", syscall.ECONNREFUSED, true},
+		{"plain error", errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Fatalf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsRetryableHTTPClientTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer srv.Close()
+	hc := &http.Client{Timeout: 20 * time.Millisecond}
+	_, err := hc.Get(srv.URL)
+	if err == nil {
+		t.Fatal("expected timeout error")
+	}
+	if !IsRetryable(err) {
+		t.Fatalf("http.Client timeout not retryable: %v", err)
+	}
+}
+
+func TestIsRetryableMutation(t *testing.T) {
+	dial := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"canceled", context.Canceled, false},
+		{"dial refused", &url.Error{Op: "Post", URL: "https://x", Err: dial}, true},
+		{"dial timeout", &net.OpError{Op: "dial", Net: "tcp", Err: context.DeadlineExceeded}, true},
+		{"dns", &net.DNSError{Err: "no such host", Name: "x"}, true},
+		{"conn refused", syscall.ECONNREFUSED, true},
+		{"rate limited", &APIError{StatusCode: http.StatusTooManyRequests}, true},
+		// 请求已发出后的错误：变更可能已经生效
+		{"read reset", &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, false},
+		{"unexpected eof", &url.Error{Op: "Post", URL: "https://x", Err: io.ErrUnexpectedEOF}, false},
+		{"eof", io.EOF, false},
+		{"deadline exceeded", context.DeadlineExceeded, false},
+		{"server error", &APIError{StatusCode: http.StatusBadGateway}, false},
+		{"plain error", errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryableMutation(tt.err); got != tt.want {
+				t.Fatalf("IsRetryableMutation(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIErrorKind(t *testing.T) {
+	gql := func(msg, code string) []GraphQLError {
+		ge := GraphQLError{Message: msg}
+		if code != "" {
+			ge.Extensions = map[string]interface{}{"code": code}
+		}
+		return []GraphQLError{ge}
+	}
+	tests := []struct {
+		name string
+		err  *APIError
+		want error
+	}{
+		{"401", &APIError{StatusCode: http.StatusUnauthorized}, ErrUnauthorized},
+		{"403", &APIError{StatusCode: http.StatusForbidden}, ErrUnauthorized},
+		{"404", &APIError{StatusCode: http.StatusNotFound}, ErrNotFound},
+		{"409", &APIError{StatusCode: http.StatusConflict}, ErrConflict},
+		{"429", &APIError{StatusCode: http.StatusTooManyRequests}, ErrRateLimited},
+		{"500", &APIError{StatusCode: http.StatusInternalServerError}, nil},
+		{"code unauthenticated", &APIError{StatusCode: 200, Errors: gql("x", "UNAUTHENTICATED")}, ErrUnauthorized},
+		{"code lowercase not_found", &APIError{StatusCode: 200, Errors: gql("x", "not_found")}, ErrNotFound},
+		{"code too many requests", &APIError{StatusCode: 200, Errors: gql("x", "TOO_MANY_REQUESTS")}, ErrRateLimited},
+		{"code already exists", &APIError{StatusCode: 200, Errors: gql("x", "ALREADY_EXISTS")}, ErrConflict},
+		{"status wins over code", &APIError{StatusCode: http.StatusNotFound, Errors: gql("x", "CONFLICT")}, ErrNotFound},
+		{"message not authorized", &APIError{StatusCode: 200, Errors: gql("Not Authorized", "")}, ErrUnauthorized},
+		{"message not found", &APIError{StatusCode: 200, Errors: gql("Project not found", "")}, ErrNotFound},
+		{"message rate limit", &APIError{StatusCode: 200, Errors: gql("Rate limit exceeded", "")}, ErrRateLimited},
+		{"message already exists", &APIError{StatusCode: 200, Errors: gql("Domain already exists", "")}, ErrConflict},
+		{"unknown code falls back to message", &APIError{StatusCode: 200, Errors: gql("service not found", "INTERNAL")}, ErrNotFound},
+		{"unclassified", &APIError{StatusCode: 200, Errors: gql("something broke", "INTERNAL")}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.kind(); got != tt.want {
+				t.Fatalf("kind() = %v, want %v", got, tt.want)
+			}
+			if tt.want != nil && !errors.Is(fmt.Errorf("wrap: %w", tt.err), tt.want) {
+				t.Fatalf("errors.Is(%v) = false", tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// graphQLRequest GraphQL 请求体
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// graphQLResponse GraphQL 响应体
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []GraphQLError  `json:"errors"`
+}
+
+// do 构造 Operation 并经过拦截器链执行
+func (c *Client) do(ctx context.Context, endpoint, query string, variables map[string]interface{}, response interface{}) error {
+	op := newOperation(endpoint, query, variables, response)
+	switch endpoint {
+	case EndpointInternal:
+		op.URL = c.BackboardInternalURL()
+	default:
+		op.URL = c.BackboardURL()
+	}
+	return c.invoker(ctx, op)
+}
+
+// run 拦截器链末端：发送 GraphQL 请求并将 data 解码到 op.Response（为 nil 时跳过解码）。
+// HTTP 非 2xx 与 GraphQL errors 均以 *APIError 返回；HTTP 200 但带限流错误的响应按 RateLimit 策略重试
+// （429/503 已由 rateLimitTransport 处理）。
+func (c *Client) run(ctx context.Context, op *Operation) error {
+	start := time.Now()
+	defer func() { op.Latency = time.Since(start) }()
+	for attempt := 0; ; attempt++ {
+		err := c.runOnce(ctx, op)
+		var apiErr *APIError
+		if err == nil || attempt >= c.rateLimit.maxRetries() || !errors.As(err, &apiErr) ||
+			apiErr.StatusCode != http.StatusOK || !errors.Is(apiErr, ErrRateLimited) {
+			return err
+		}
+		if err := sleepContext(ctx, c.rateLimit.retryWait(attempt, apiErr.Header)); err != nil {
+			return err
+		}
+	}
+}
+
+// runOnce 执行单次 GraphQL 请求
+func (c *Client) runOnce(ctx context.Context, op *Operation) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	body, err := json.Marshal(graphQLRequest{Query: op.Query, Variables: op.Variables})
+	if err != nil {
+		return fmt.Errorf("graphql: encode body: %w", err)
+	}
+	// 只有查询可在 503 时重放；拦截器可能改写了查询文本，按最终文本判断
+	reqCtx := ctx
+	if typ, _ := ParseOperation(op.Query); typ != "mutation" {
+		reqCtx = withIdempotent(ctx)
+	}
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, op.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Accept", "application/json; charset=utf-8")
+	c.setAuthHeaders(req.Header)
+	injectTraceContext(ctx, req.Header)
+	for k, vs := range op.Header {
+		req.Header.Del(k)
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+
+	res, err := c.gqlHTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	op.StatusCode = res.StatusCode
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("graphql: reading body: %w", err)
+	}
+
+	var gr graphQLResponse
+	if err := json.Unmarshal(raw, &gr); err != nil {
+		if res.StatusCode < 200 || res.StatusCode >= 300 {
+			return newHTTPError(res, raw)
+		}
+		return fmt.Errorf("graphql: decoding response: %w", err)
+	}
+	op.RawResponse = gr.Data
+	if len(gr.Errors) > 0 || res.StatusCode < 200 || res.StatusCode >= 300 {
+		var e *APIError
+		if len(gr.Errors) == 0 {
+			e = newHTTPError(res, raw)
+		} else {
+			e = newHTTPError(res, nil)
+			e.Errors = gr.Errors
+		}
+		return e
+	}
+	if op.Response == nil || len(gr.Data) == 0 || string(gr.Data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(gr.Data, op.Response); err != nil {
+		return fmt.Errorf("graphql: decoding data: %w", err)
+	}
+	return nil
+}
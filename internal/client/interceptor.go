@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// GraphQL 端点标识
+const (
+	EndpointPublic   = "v2"
+	EndpointInternal = "internal"
+)
+
+// Operation 一次 GraphQL 调用；拦截器调用 next 之后可读取响应相关字段
+type Operation struct {
+	// Name 从查询文本解析出的操作名（匿名操作为空）
+	Name string
+	// Type 操作类型：query / mutation / subscription
+	Type string
+	// Query 查询文本
+	Query string
+	// Variables 请求变量（拦截器可修改）
+	Variables map[string]interface{}
+	// Endpoint 端点标识：EndpointPublic 或 EndpointInternal
+	Endpoint string
+	// URL 请求地址
+	URL string
+	// Header 附加请求头，覆盖同名默认头（拦截器可修改）
+	Header http.Header
+
+	// Response 解码目标（调用方传入的指针，可能为 nil）
+	Response interface{}
+	// RawResponse 响应中的 data 原文
+	RawResponse json.RawMessage
+	// StatusCode HTTP 状态码（未发出请求时为 0）
+	StatusCode int
+	// Latency 请求耗时（含限流重试）
+	Latency time.Duration
+}
+
+// Invoker 执行 Operation
+type Invoker func(ctx context.Context, op *Operation) error
+
+// Interceptor 包裹一次 GraphQL 调用；不调用 next 即可短路（如故障注入）
+type Interceptor func(ctx context.Context, op *Operation, next Invoker) error
+
+func newOperation(endpoint, query string, variables map[string]interface{}, response interface{}) *Operation {
+	typ, name := ParseOperation(query)
+	return &Operation{
+		Name:      name,
+		Type:      typ,
+		Query:     query,
+		Variables: variables,
+		Endpoint:  endpoint,
+		Header:    http.Header{},
+		Response:  response,
+	}
+}
+
+// chainInterceptors 组合拦截器，先注册者位于最外层
+func chainInterceptors(interceptors []Interceptor, final Invoker) Invoker {
+	next := final
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		ic, n := interceptors[i], next
+		next = func(ctx context.Context, op *Operation) error {
+			return ic(ctx, op, n)
+		}
+	}
+	return next
+}
+
+var (
+	operationRe = regexp.MustCompile(`^(query|mutation|subscription)\b\s*([_A-Za-z][_0-9A-Za-z]*)?`)
+	commentRe   = regexp.MustCompile(`(?m)#.*$`)
+)
+
+// ParseOperation 从查询文本解析操作类型与操作名；简写形式 "{ ... }" 视为匿名 query
+func ParseOperation(query string) (typ, name string) {
+	q := strings.TrimSpace(commentRe.ReplaceAllString(query, ""))
+	if m := operationRe.FindStringSubmatch(q); m != nil {
+		return m[1], m[2]
+	}
+	return "query", ""
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingServer 记录请求路径与请求头，以固定 data 响应
+type recordingServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	paths   []string
+	headers []http.Header
+}
+
+func newRecordingServer(t *testing.T, delay time.Duration) *recordingServer {
+	t.Helper()
+	s := &recordingServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.paths = append(s.paths, r.URL.Path)
+		s.headers = append(s.headers, r.Header.Clone())
+		s.mu.Unlock()
+		time.Sleep(delay)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"me":{"id":"usr_1"}}}`))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestInterceptorOrder(t *testing.T) {
+	srv := newRecordingServer(t, 0)
+	var trace []string
+	record := func(name string) Interceptor {
+		return func(ctx context.Context, op *Operation, next Invoker) error {
+			trace = append(trace, name+" before")
+			err := next(ctx, op)
+			trace = append(trace, name+" after")
+			return err
+		}
+	}
+	c := newTestClient(t, srv.URL, Options{Interceptors: []Interceptor{record("outer"), record("inner")}})
+	if err := c.Query(context.Background(), `query Me { me { id } }`, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(trace, ","); got != "outer before,inner before,inner after,outer after" {
+		t.Fatalf("order = %s", got)
+	}
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	srv := newRecordingServer(t, 0)
+	injected := errors.New("injected")
+	c := newTestClient(t, srv.URL, Options{Interceptors: []Interceptor{
+		func(ctx context.Context, op *Operation, next Invoker) error {
+			if op.Type == "mutation" {
+				return injected
+			}
+			return next(ctx, op)
+		},
+	}})
+	if err := c.Mutate(context.Background(), `mutation Delete { projectDelete(id: "p") }`, nil, nil); !errors.Is(err, injected) {
+		t.Fatalf("err = %v", err)
+	}
+	if len(srv.paths) != 0 {
+		t.Fatalf("short-circuited call reached the network: %v", srv.paths)
+	}
+}
+
+func TestInterceptorHeaderOverride(t *testing.T) {
+	srv := newRecordingServer(t, 0)
+	c := newTestClient(t, srv.URL, Options{Interceptors: []Interceptor{
+		func(ctx context.Context, op *Operation, next Invoker) error {
+			op.Header.Set("X-Source", "audit")
+			op.Header.Set("X-Request-Id", "req-1")
+			return next(ctx, op)
+		},
+	}})
+	if err := c.Query(context.Background(), `query Me { me { id } }`, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	h := srv.headers[0]
+	// 同名默认头被覆盖而不是追加，认证头保持不变
+	if got := h.Values("X-Source"); len(got) != 1 || got[0] != "audit" {
+		t.Fatalf("X-Source = %v", got)
+	}
+	if h.Get("X-Request-Id") != "req-1" || h.Get("Authorization") != "Bearer test" {
+		t.Fatalf("headers = %v", h)
+	}
+}
+
+func TestInterceptorResponseFields(t *testing.T) {
+	const delay = 20 * time.Millisecond
+	srv := newRecordingServer(t, delay)
+	var ops []*Operation
+	c := newTestClient(t, srv.URL, Options{Interceptors: []Interceptor{
+		func(ctx context.Context, op *Operation, next Invoker) error {
+			err := next(ctx, op)
+			ops = append(ops, op)
+			return err
+		},
+	}})
+	ctx := context.Background()
+	if err := c.Query(ctx, `query Me { me { id } }`, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.QueryInternal(ctx, `query Me { me { id } }`, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []struct{ endpoint, path string }{
+		{EndpointPublic, "/graphql/v2"},
+		{EndpointInternal, "/graphql/internal"},
+	} {
+		op := ops[i]
+		if op.Endpoint != want.endpoint || srv.paths[i] != want.path || op.URL != srv.URL+want.path {
+			t.Fatalf("op %d: endpoint %s, url %s, path %s", i, op.Endpoint, op.URL, srv.paths[i])
+		}
+		if string(op.RawResponse) != `{"me":{"id":"usr_1"}}` || op.StatusCode != http.StatusOK || op.Latency < delay {
+			t.Fatalf("op %d: raw %s, status %d, latency %v", i, op.RawResponse, op.StatusCode, op.Latency)
+		}
+	}
+}
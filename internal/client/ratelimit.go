@@ -0,0 +1,197 @@
+package client
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitOptions 客户端限流与 429/503 重试配置（503 只重试查询等幂等请求，变更与上传不重试）
+type RateLimitOptions struct {
+	// RequestsPerSecond 客户端令牌桶速率，<=0 表示不限速
+	RequestsPerSecond float64
+	// Burst 令牌桶容量，<=0 时取 1
+	Burst int
+	// MaxRetries 遇到 429/503 或限流类 GraphQL 错误时的最大重试次数；0 使用默认值 3，<0 不重试
+	MaxRetries int
+	// MaxRetryWait 单次等待上限（含 Retry-After），0 使用默认值 60s
+	MaxRetryWait time.Duration
+}
+
+const (
+	defaultRateLimitRetries = 3
+	defaultMaxRetryWait     = 60 * time.Second
+	baseRetryBackoff        = 500 * time.Millisecond
+)
+
+func (o RateLimitOptions) maxRetries() int {
+	switch {
+	case o.MaxRetries < 0:
+		return 0
+	case o.MaxRetries == 0:
+		return defaultRateLimitRetries
+	default:
+		return o.MaxRetries
+	}
+}
+
+// retryWait 计算第 attempt 次（从 0 开始）重试前的等待时长：优先 Retry-After，否则指数退避
+func (o RateLimitOptions) retryWait(attempt int, h http.Header) time.Duration {
+	maxWait := o.MaxRetryWait
+	if maxWait <= 0 {
+		maxWait = defaultMaxRetryWait
+	}
+	wait, ok := parseRetryAfter(h)
+	if !ok {
+		wait = time.Duration(float64(baseRetryBackoff) * math.Pow(2, float64(attempt)))
+	}
+	if wait > maxWait {
+		wait = maxWait
+	}
+	return wait
+}
+
+// parseRetryAfter 解析 Retry-After（秒数或 HTTP 日期）
+func parseRetryAfter(h http.Header) (time.Duration, bool) {
+	if h == nil {
+		return 0, false
+	}
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// tokenBucket 简单令牌桶，被同一 Client 的查询、变更、上传与订阅共享
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// Wait 阻塞直到取得一个令牌或 ctx 结束；nil 桶直接放行
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+type idempotentKey struct{}
+
+// withIdempotent 标记 ctx 中发出的请求可安全重放（GraphQL 查询）
+func withIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+// isIdempotent GET/HEAD/OPTIONS 请求或经 withIdempotent 标记的请求
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	ok, _ := req.Context().Value(idempotentKey{}).(bool)
+	return ok
+}
+
+// rateLimitTransport 在每次请求前获取令牌，对 429 与幂等请求的 503 按 Retry-After 重试
+type rateLimitTransport struct {
+	base   http.RoundTripper
+	bucket *tokenBucket
+	opts   RateLimitOptions
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		if err := t.bucket.Wait(ctx); err != nil {
+			return nil, err
+		}
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		// 429 表示请求未被处理，总可以重试；503 时请求可能已部分执行，只重试幂等请求
+		if resp.StatusCode != http.StatusTooManyRequests &&
+			(resp.StatusCode != http.StatusServiceUnavailable || !isIdempotent(req)) {
+			return resp, nil
+		}
+		// 请求体无法重放时直接返回
+		if attempt >= t.opts.maxRetries() || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+			return resp, nil
+		}
+		wait := t.opts.retryWait(attempt, resp.Header)
+		resp.Body.Close()
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
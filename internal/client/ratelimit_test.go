@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, url string, opts Options) *Client {
+	t.Helper()
+	opts.Endpoint = url
+	opts.Credentials = &Credentials{Token: "test", Kind: TokenKindAPI}
+	c, err := NewWithOptions(nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestRetryOnlyIdempotentOn503(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		want   int32
+	}{
+		{"query 503 retried", `query Me { me { id } }`, http.StatusServiceUnavailable, 3},
+		{"mutation 503 not retried", `mutation Del($id: String!) { projectDelete(id: $id) }`, http.StatusServiceUnavailable, 1},
+		{"mutation 429 retried", `mutation Del($id: String!) { projectDelete(id: $id) }`, http.StatusTooManyRequests, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if calls.Add(1) < 3 {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(tt.status)
+					return
+				}
+				_, _ = w.Write([]byte(`{"data":{}}`))
+			}))
+			defer srv.Close()
+			c := newTestClient(t, srv.URL, Options{RateLimit: RateLimitOptions{MaxRetries: 5}})
+			_ = c.Query(context.Background(), tt.query, nil, nil)
+			if got := calls.Load(); got != tt.want {
+				t.Fatalf("calls = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUploadIgnoresShortCustomTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/up") {
+			time.Sleep(150 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte(`{"deploymentId":"d"}`))
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL, Options{HTTPClient: &http.Client{Timeout: 50 * time.Millisecond}})
+	if c.gqlHTTP.Timeout != 50*time.Millisecond {
+		t.Fatalf("api timeout = %v", c.gqlHTTP.Timeout)
+	}
+	// 上传不设整体超时，只限制请求体发送完毕后等待响应的时间
+	if c.uploadHTTP.Timeout != 0 {
+		t.Fatalf("upload timeout = %v, want none", c.uploadHTTP.Timeout)
+	}
+	resp, err := c.Upload(context.Background(), "p", "e", "s", strings.NewReader("archive"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
+
+// slowReader 每次 Read 前等待 delay，模拟边打包边发送的归档
+type slowReader struct {
+	chunks []string
+	delay  time.Duration
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	time.Sleep(r.delay)
+	n := copy(p, r.chunks[0])
+	r.chunks = r.chunks[1:]
+	return n, nil
+}
+
+func TestUploadResponseTimeout(t *testing.T) {
+	defer func(d time.Duration) { uploadResponseTimeout = d }(uploadResponseTimeout)
+	uploadResponseTimeout = 100 * time.Millisecond
+	var respond sync.WaitGroup
+	respond.Add(1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		if r.URL.Query().Get("serviceId") == "stuck" {
+			respond.Wait()
+		}
+		_, _ = w.Write([]byte(`{"deploymentId":"d"}`))
+	}))
+	defer srv.Close()
+	defer respond.Done()
+	c := newTestClient(t, srv.URL, Options{})
+
+	// 打包耗时超过响应超时不影响上传：计时从请求体读完才开始
+	slow := &slowReader{chunks: []string{"a", "b", "c", "d"}, delay: 60 * time.Millisecond}
+	resp, err := c.Upload(context.Background(), "p", "e", "s", slow)
+	if err != nil {
+		t.Fatalf("slow archive: %v", err)
+	}
+	// 响应体在 Upload 返回后仍可读取
+	if b, err := io.ReadAll(resp.Body); err != nil || !strings.Contains(string(b), "deploymentId") {
+		t.Fatalf("body = %q, %v", b, err)
+	}
+	resp.Body.Close()
+
+	// 请求体发送完毕后服务端迟迟不响应
+	start := time.Now()
+	_, err = c.Upload(context.Background(), "p", "e", "stuck", strings.NewReader("archive"))
+	if !errors.Is(err, errUploadResponseTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want response timeout", err)
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Fatalf("timed out after %v", d)
+	}
+}
@@ -0,0 +1,153 @@
+package client
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// ReconnectUnlimited 作为 ReconnectOptions.MaxAttempts 时不限重连次数
+const ReconnectUnlimited = math.MaxInt32
+
+// ReconnectOptions 订阅所在连接断开后的重新订阅策略
+type ReconnectOptions struct {
+	// MaxAttempts 连续重连次数上限（收到数据后清零）；0 使用默认值 5，<0 不重连
+	MaxAttempts int
+	// MinBackoff 首次重连前的等待，0 使用默认值 500ms
+	MinBackoff time.Duration
+	// MaxBackoff 指数退避的等待上限，0 使用默认值 30s
+	MaxBackoff time.Duration
+}
+
+const (
+	defaultReconnectAttempts = 5
+	defaultMinBackoff        = 500 * time.Millisecond
+	defaultMaxBackoff        = 30 * time.Second
+)
+
+func (o ReconnectOptions) maxAttempts() int {
+	switch {
+	case o.MaxAttempts < 0:
+		return 0
+	case o.MaxAttempts == 0:
+		return defaultReconnectAttempts
+	default:
+		return o.MaxAttempts
+	}
+}
+
+// backoff 第 attempt 次（从 1 开始）重连前的等待：指数退避并加入 ±20% 抖动，避免多个订阅同时重连
+func (o ReconnectOptions) backoff(attempt int) time.Duration {
+	min, max := o.MinBackoff, o.MaxBackoff
+	if min <= 0 {
+		min = defaultMinBackoff
+	}
+	if max <= 0 {
+		max = defaultMaxBackoff
+	}
+	wait := time.Duration(float64(min) * math.Pow(2, float64(attempt-1)))
+	if wait > max || wait <= 0 {
+		wait = max
+	}
+	jitter := time.Duration(rand.Int63n(int64(wait)/5 + 1))
+	if rand.Intn(2) == 0 {
+		return wait - jitter
+	}
+	return wait + jitter
+}
+
+// shouldReconnect 仅连接层故障值得重新订阅；认证失败、订阅被服务端拒绝、cassette 未匹配等不重试
+func shouldReconnect(err error) bool {
+	var connErr *ConnectionError
+	if !errors.As(err, &connErr) || errors.Is(err, ErrCassetteMiss) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+	return true
+}
+
+// LogCursor 记录已输出日志的最新时间戳，在重新订阅后丢弃与已输出部分重叠的日志。
+// 重新订阅时服务端会回放最近的日志，Resume 之后早于游标的行、以及与游标同一时间戳且内容
+// 相同的行被视为重复；出现更新的行后恢复正常输出。回放未覆盖到游标（断线期间的日志多于回放
+// 行数）时，第一条更新的行之前可能缺少日志，由 Gap 报告。非并发安全，应在订阅回调中使用。
+type LogCursor struct {
+	last     string
+	lastTime time.Time
+	seen     map[string]struct{}
+	resuming bool
+	overlap  bool
+	gap      bool
+}
+
+// Last 返回最后一条日志的时间戳，尚无日志时为空
+func (c *LogCursor) Last() string { return c.last }
+
+// Resume 标记即将从重新建立的订阅接收回放的日志
+func (c *LogCursor) Resume() {
+	if c.last != "" {
+		c.resuming, c.overlap = true, false
+	}
+}
+
+// Gap 报告最近一次被接受的行之前是否可能缺少断线期间的日志
+func (c *LogCursor) Gap() bool { return c.gap }
+
+// Admit 判断一行日志是否应输出，并推进游标
+func (c *LogCursor) Admit(timestamp, message string) bool {
+	key := timestamp + "\x00" + message
+	t, parsed := parseLogTime(timestamp)
+	cmp := 1
+	if c.last != "" {
+		cmp = c.compare(timestamp, t, parsed)
+	}
+	c.gap = false
+	if c.resuming {
+		switch {
+		case cmp < 0:
+			c.overlap = true
+			return false
+		case cmp == 0:
+			c.overlap = true
+			if _, dup := c.seen[key]; dup {
+				return false
+			}
+		default:
+			c.resuming, c.gap = false, !c.overlap
+		}
+	}
+	switch {
+	case cmp > 0:
+		c.last, c.lastTime = timestamp, t
+		c.seen = map[string]struct{}{key: {}}
+	case cmp == 0:
+		c.seen[key] = struct{}{}
+	}
+	return true
+}
+
+// compare 与游标比较：两端均可解析时按时间，否则按字符串
+func (c *LogCursor) compare(timestamp string, t time.Time, parsed bool) int {
+	if parsed && !c.lastTime.IsZero() {
+		switch {
+		case t.Before(c.lastTime):
+			return -1
+		case t.After(c.lastTime):
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(timestamp, c.last)
+}
+
+func parseLogTime(s string) (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
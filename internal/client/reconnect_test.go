@@ -0,0 +1,256 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestLogCursorAdmit(t *testing.T) {
+	type line struct {
+		ts, msg string
+		want    bool
+	}
+	tests := []struct {
+		name string
+		// resumeAt 在第几行之前调用 Resume（-1 不调用）
+		resumeAt int
+		lines    []line
+	}{
+		{"no resume admits everything", -1, []line{
+			{"2024-01-01T00:00:01Z", "a", true},
+			{"2024-01-01T00:00:01Z", "a", true},
+			{"2024-01-01T00:00:00Z", "older", true},
+		}},
+		{"replay before cursor dropped", 2, []line{
+			{"2024-01-01T00:00:01Z", "a", true},
+			{"2024-01-01T00:00:02Z", "b", true},
+			{"2024-01-01T00:00:01Z", "a", false},
+			{"2024-01-01T00:00:02Z", "b", false},
+			{"2024-01-01T00:00:03Z", "c", true},
+		}},
+		{"same timestamp new message admitted", 2, []line{
+			{"2024-01-01T00:00:01Z", "a", true},
+			{"2024-01-01T00:00:01Z", "b", true},
+			{"2024-01-01T00:00:01Z", "a", false},
+			{"2024-01-01T00:00:01Z", "b", false},
+			{"2024-01-01T00:00:01Z", "c", true},
+		}},
+		{"resume ends at first newer line", 1, []line{
+			{"2024-01-01T00:00:02Z", "a", true},
+			{"2024-01-01T00:00:03Z", "b", true},
+			// 恢复正常输出后不再按游标过滤
+			{"2024-01-01T00:00:01Z", "late", true},
+		}},
+		{"timestamps compare by time", 1, []line{
+			{"2024-01-01T00:00:01.5Z", "a", true},
+			{"2024-01-01T00:00:01.4Z", "older", false},
+			{"2024-01-01T00:00:01.6Z", "newer", true},
+		}},
+		{"unparsable timestamps compare as strings", 1, []line{
+			{"b", "x", true},
+			{"a", "x", false},
+			{"b", "x", false},
+			{"c", "y", true},
+		}},
+		{"resume before any line is a no-op", 0, []line{
+			{"2024-01-01T00:00:01Z", "a", true},
+			{"2024-01-01T00:00:00Z", "older", true},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c LogCursor
+			for i, l := range tt.lines {
+				if i == tt.resumeAt {
+					c.Resume()
+				}
+				if got := c.Admit(l.ts, l.msg); got != l.want {
+					t.Fatalf("line %d Admit(%q, %q) = %v, want %v", i, l.ts, l.msg, got, l.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLogCursorLast(t *testing.T) {
+	var c LogCursor
+	if c.Last() != "" {
+		t.Fatalf("Last() = %q before any line", c.Last())
+	}
+	c.Admit("2024-01-01T00:00:02Z", "a")
+	c.Admit("2024-01-01T00:00:01Z", "older")
+	if c.Last() != "2024-01-01T00:00:02Z" {
+		t.Fatalf("Last() = %q", c.Last())
+	}
+}
+
+func TestLogCursorGap(t *testing.T) {
+	var c LogCursor
+	c.Admit("2024-01-01T00:00:01Z", "a")
+
+	// 回放覆盖到游标：没有间隙
+	c.Resume()
+	c.Admit("2024-01-01T00:00:01Z", "a")
+	if !c.Admit("2024-01-01T00:00:02Z", "b") || c.Gap() {
+		t.Fatal("overlapping replay reported a gap")
+	}
+
+	// 回放只包含更新的行：第一条之后报告间隙，随后的行不再报告
+	c.Resume()
+	if !c.Admit("2024-01-01T00:00:05Z", "e") || !c.Gap() {
+		t.Fatal("replay without overlap did not report a gap")
+	}
+	if !c.Admit("2024-01-01T00:00:06Z", "f") || c.Gap() {
+		t.Fatal("gap reported twice")
+	}
+
+	// 未调用 Resume 时从不报告
+	var fresh LogCursor
+	if fresh.Admit("2024-01-01T00:00:01Z", "a"); fresh.Gap() {
+		t.Fatal("gap without resume")
+	}
+}
+
+func TestShouldReconnect(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain error", errors.New("boom"), false},
+		{"canceled", context.Canceled, false},
+		{"connection lost", &ConnectionError{Err: io.ErrUnexpectedEOF}, true},
+		{"wrapped connection lost", fmt.Errorf("subscribe: %w", &ConnectionError{Err: io.EOF}), true},
+		{"cassette miss", &ConnectionError{Err: fmt.Errorf("%w: subscribe", ErrCassetteMiss)}, false},
+		{"handshake 503", &ConnectionError{Err: &APIError{StatusCode: http.StatusServiceUnavailable}}, true},
+		{"handshake 401", &ConnectionError{Err: &APIError{StatusCode: http.StatusUnauthorized}}, false},
+		{"subscription error frame", &APIError{Errors: []GraphQLError{{Message: "not found"}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldReconnect(tt.err); got != tt.want {
+				t.Fatalf("shouldReconnect(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconnectBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    ReconnectOptions
+		attempt int
+		want    time.Duration
+	}{
+		{"default first", ReconnectOptions{}, 1, defaultMinBackoff},
+		{"default doubles", ReconnectOptions{}, 3, 4 * defaultMinBackoff},
+		{"default cap", ReconnectOptions{}, 20, defaultMaxBackoff},
+		{"custom", ReconnectOptions{MinBackoff: 10 * time.Millisecond, MaxBackoff: time.Second}, 2, 20 * time.Millisecond},
+		{"custom cap", ReconnectOptions{MinBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}, 4, 50 * time.Millisecond},
+		{"overflow capped", ReconnectOptions{MinBackoff: time.Hour, MaxBackoff: 2 * time.Hour}, 80, 2 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ±20% 抖动
+			lo, hi := tt.want-tt.want/5, tt.want+tt.want/5
+			for i := 0; i < 100; i++ {
+				if got := tt.opts.backoff(tt.attempt); got < lo || got > hi {
+					t.Fatalf("backoff(%d) = %v, want within [%v, %v]", tt.attempt, got, lo, hi)
+				}
+			}
+		})
+	}
+}
+
+func TestReconnectMaxAttemptsOption(t *testing.T) {
+	for in, want := range map[int]int{-1: 0, 0: defaultReconnectAttempts, 2: 2, ReconnectUnlimited: ReconnectUnlimited} {
+		if got := (ReconnectOptions{MaxAttempts: in}).maxAttempts(); got != want {
+			t.Errorf("maxAttempts(%d) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+// dropServer 接受 graphql-transport-ws 连接并在订阅后断开；只有第一次连接先发送一帧数据
+func dropServer(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+	var conns int32
+	upgrader := websocket.Upgrader{Subprotocols: []string{"graphql-transport-ws"}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		n := atomic.AddInt32(&conns, 1)
+		var msg wsMessage
+		for conn.ReadJSON(&msg) == nil {
+			switch msg.Type {
+			case "connection_init":
+				_ = conn.WriteJSON(wsMessage{Type: "connection_ack"})
+			case wsTypeSubscribe:
+				if n == 1 {
+					payload, _ := json.Marshal(map[string]interface{}{"data": map[string]int{"n": 1}})
+					_ = conn.WriteJSON(wsMessage{ID: msg.ID, Type: wsTypeNext, Payload: payload})
+				}
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &conns
+}
+
+func TestSubscribeWithReconnectMaxAttempts(t *testing.T) {
+	srv, conns := dropServer(t)
+	c := newTestClient(t, srv.URL, Options{Reconnect: ReconnectOptions{MaxAttempts: 2, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var data int
+	var attempts []int
+	err := c.SubscribeWithReconnect(ctx, `subscription S { n }`, nil,
+		func(json.RawMessage) { data++ },
+		func(error) {},
+		func(attempt int, err error) {
+			attempts = append(attempts, attempt)
+			if !shouldReconnect(err) {
+				t.Errorf("reconnect after non-retryable error %v", err)
+			}
+		})
+	var connErr *ConnectionError
+	if !errors.As(err, &connErr) {
+		t.Fatalf("err = %v, want ConnectionError", err)
+	}
+	// 首次连接的数据使计数清零，此后连续两次重连均失败即放弃
+	if data != 1 || fmt.Sprint(attempts) != "[1 2]" {
+		t.Fatalf("data = %d, reconnect attempts = %v", data, attempts)
+	}
+	if got := atomic.LoadInt32(conns); got != 3 {
+		t.Fatalf("connections = %d, want 3", got)
+	}
+}
+
+func TestSubscribeWithReconnectDisabled(t *testing.T) {
+	srv, conns := dropServer(t)
+	c := newTestClient(t, srv.URL, Options{Reconnect: ReconnectOptions{MaxAttempts: -1}})
+	err := c.SubscribeWithReconnect(context.Background(), `subscription S { n }`, nil, func(json.RawMessage) {}, func(error) {},
+		func(int, error) { t.Error("reconnected with MaxAttempts < 0") })
+	if err == nil || !strings.Contains(err.Error(), "connection lost") {
+		t.Fatalf("err = %v", err)
+	}
+	if got := atomic.LoadInt32(conns); got != 1 {
+		t.Fatalf("connections = %d", got)
+	}
+}
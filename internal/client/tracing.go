@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName OpenTelemetry instrumentation 名称
+const tracerName = "github.com/railwayapp/cli/pkg/railway"
+
+// span 属性键
+const (
+	AttrOperationName   = attribute.Key("graphql.operation.name")
+	AttrOperationType   = attribute.Key("graphql.operation.type")
+	AttrEndpoint        = attribute.Key("railway.endpoint")
+	AttrProjectID       = attribute.Key("railway.project.id")
+	AttrEnvironmentID   = attribute.Key("railway.environment.id")
+	AttrServiceID       = attribute.Key("railway.service.id")
+	AttrDeploymentID    = attribute.Key("railway.deployment.id")
+	AttrHTTPStatusCode  = attribute.Key("http.response.status_code")
+	AttrMessageCount    = attribute.Key("railway.subscription.messages")
+	AttrErrorCount      = attribute.Key("railway.subscription.errors")
+	AttrReconnectCount  = attribute.Key("railway.subscription.reconnects")
+	AttrUploadBytes     = attribute.Key("railway.upload.bytes")
+	AttrArchiveBytes    = attribute.Key("railway.archive.bytes")
+	AttrArchiveFiles    = attribute.Key("railway.archive.files")
+	AttrGraphQLErrCodes = attribute.Key("railway.error.codes")
+)
+
+// Tracer 返回客户端使用的 tracer；未配置 TracerProvider 时使用全局 provider
+func (c *Client) Tracer() trace.Tracer {
+	return c.tracer
+}
+
+func newTracer(tp trace.TracerProvider) trace.Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return tp.Tracer(tracerName, trace.WithInstrumentationVersion(cliVersion))
+}
+
+// idVariableKeys 变量名到 span 属性的映射
+var idVariableKeys = map[string]attribute.Key{
+	"projectId":     AttrProjectID,
+	"environmentId": AttrEnvironmentID,
+	"serviceId":     AttrServiceID,
+	"deploymentId":  AttrDeploymentID,
+}
+
+// IDAttributes 从请求变量（含嵌套的 input 对象）中提取项目/环境/服务/部署 ID
+func IDAttributes(variables map[string]interface{}) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	seen := map[attribute.Key]bool{}
+	var walk func(m map[string]interface{}, depth int)
+	walk = func(m map[string]interface{}, depth int) {
+		for k, v := range m {
+			if nested, ok := v.(map[string]interface{}); ok && depth < 2 {
+				walk(nested, depth+1)
+				continue
+			}
+			key := idVariableKeys[k]
+			s, ok := v.(string)
+			if key == "" || !ok || s == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			attrs = append(attrs, key.String(s))
+		}
+	}
+	walk(variables, 0)
+	return attrs
+}
+
+// operationSpanName 以操作名命名 span，匿名操作退回到操作类型
+func operationSpanName(prefix, typ, name string) string {
+	if name == "" {
+		return prefix + " " + typ
+	}
+	return prefix + " " + name
+}
+
+// injectTraceContext 将当前 span 上下文按全局 propagator 写入请求头
+func injectTraceContext(ctx context.Context, h http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(h))
+}
+
+// EndSpan 记录错误并结束 span；ctx 取消不视为失败
+func EndSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, context.Canceled) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			if cs := apiErr.Codes(); len(cs) > 0 {
+				span.SetAttributes(AttrGraphQLErrCodes.StringSlice(cs))
+			}
+		}
+	}
+	span.End()
+}
+
+// tracingInterceptor 位于拦截器链最外层，为每次 GraphQL 调用创建 client span
+func (c *Client) tracingInterceptor(ctx context.Context, op *Operation, next Invoker) error {
+	attrs := append([]attribute.KeyValue{
+		AttrOperationType.String(op.Type),
+		AttrEndpoint.String(op.Endpoint),
+	}, IDAttributes(op.Variables)...)
+	if op.Name != "" {
+		attrs = append(attrs, AttrOperationName.String(op.Name))
+	}
+	ctx, span := c.tracer.Start(ctx, operationSpanName("railway.graphql", op.Type, op.Name),
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	err := next(ctx, op)
+	if op.StatusCode != 0 {
+		span.SetAttributes(AttrHTTPStatusCode.Int(op.StatusCode))
+	}
+	EndSpan(span, err)
+	return err
+}
@@ -0,0 +1,188 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/railwayapp/cli/internal/client"
+	"github.com/railwayapp/cli/internal/gql"
+	"github.com/railwayapp/cli/pkg/railway/railwaytest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedClient 返回连接 railwaytest 的客户端与记录其 span 的内存 exporter
+func tracedClient(t *testing.T, srv *railwaytest.Server) (*client.Client, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exp := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	c, err := client.NewWithOptions(nil, client.Options{
+		Endpoint:       srv.URL,
+		Credentials:    &client.Credentials{Token: railwaytest.TestToken, Kind: client.TokenKindAPI},
+		TracerProvider: tp,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, exp
+}
+
+// withParent 在 tp 之外创建一个父 span，返回携带它的 ctx 与其 SpanContext
+func withParent(t *testing.T) (context.Context, trace.SpanContext) {
+	t.Helper()
+	tp := sdktrace.NewTracerProvider()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "parent")
+	t.Cleanup(func() { span.End() })
+	return ctx, span.SpanContext()
+}
+
+func findSpan(t *testing.T, exp *tracetest.InMemoryExporter, name string) tracetest.SpanStub {
+	t.Helper()
+	var names []string
+	for _, s := range exp.GetSpans() {
+		if s.Name == name {
+			return s
+		}
+		names = append(names, s.Name)
+	}
+	t.Fatalf("span %q not found in %v", name, names)
+	return tracetest.SpanStub{}
+}
+
+func attrValue(s tracetest.SpanStub, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range s.Attributes {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func checkSpan(t *testing.T, s tracetest.SpanStub, parent trace.SpanContext, want map[attribute.Key]string, wantErr bool) {
+	t.Helper()
+	if s.Parent.SpanID() != parent.SpanID() || s.SpanContext.TraceID() != parent.TraceID() {
+		t.Errorf("%s: parent = %s/%s, want %s/%s", s.Name, s.SpanContext.TraceID(), s.Parent.SpanID(), parent.TraceID(), parent.SpanID())
+	}
+	if s.SpanKind != trace.SpanKindClient {
+		t.Errorf("%s: kind = %v, want client", s.Name, s.SpanKind)
+	}
+	for k, v := range want {
+		got, ok := attrValue(s, k)
+		if !ok || got.Emit() != v {
+			t.Errorf("%s: %s = %q, want %q", s.Name, k, got.Emit(), v)
+		}
+	}
+	if wantErr && s.Status.Code != codes.Error {
+		t.Errorf("%s: status = %v, want error", s.Name, s.Status.Code)
+	}
+	if !wantErr && s.Status.Code == codes.Error {
+		t.Errorf("%s: unexpected error status %q", s.Name, s.Status.Description)
+	}
+}
+
+func TestQuerySpans(t *testing.T) {
+	srv := railwaytest.NewServer()
+	defer srv.Close()
+	p, _ := srv.AddProject("demo")
+	c, exp := tracedClient(t, srv)
+	ctx, parent := withParent(t)
+
+	if err := c.Query(ctx, gql.ProjectQuery, map[string]interface{}{"id": p.ID}, &gql.ProjectResponse{}); err != nil {
+		t.Fatal(err)
+	}
+	checkSpan(t, findSpan(t, exp, "railway.graphql Project"), parent, map[attribute.Key]string{
+		client.AttrOperationType:  "query",
+		client.AttrOperationName:  "Project",
+		client.AttrHTTPStatusCode: "200",
+	}, false)
+
+	exp.Reset()
+	err := c.Query(ctx, gql.ProjectQuery, map[string]interface{}{"id": "missing"}, &gql.ProjectResponse{})
+	if err == nil {
+		t.Fatal("expected not found error")
+	}
+	s := findSpan(t, exp, "railway.graphql Project")
+	checkSpan(t, s, parent, map[attribute.Key]string{client.AttrOperationName: "Project"}, true)
+	if len(s.Events) == 0 || s.Events[0].Name != "exception" {
+		t.Errorf("error not recorded as event: %+v", s.Events)
+	}
+}
+
+func TestSubscribeSpans(t *testing.T) {
+	srv := railwaytest.NewServer()
+	defer srv.Close()
+	p, env := srv.AddProject("demo")
+	svc := srv.AddService(p.ID, "web")
+	d, _ := srv.AddDeployment(svc.ID, env.ID, "BUILDING")
+	_ = srv.AppendBuildLog(d.ID, "step 1", nil)
+	c, exp := tracedClient(t, srv)
+	ctx, parent := withParent(t)
+
+	subCtx, cancel := context.WithCancel(ctx)
+	err := c.Subscribe(subCtx, gql.BuildLogsSub, map[string]interface{}{"deploymentId": d.ID, "filter": "", "limit": 10},
+		func(json.RawMessage) { cancel() }, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v", err)
+	}
+	checkSpan(t, findSpan(t, exp, "railway.subscribe BuildLogs"), parent, map[attribute.Key]string{
+		client.AttrOperationType: "subscription",
+		client.AttrDeploymentID:  d.ID,
+		client.AttrMessageCount:  "1",
+		client.AttrErrorCount:    "0",
+	}, false)
+
+	exp.Reset()
+	err = c.Subscribe(ctx, gql.BuildLogsSub, map[string]interface{}{"deploymentId": "missing", "filter": "", "limit": 10}, nil, nil)
+	if err == nil {
+		t.Fatal("expected subscription error")
+	}
+	checkSpan(t, findSpan(t, exp, "railway.subscribe BuildLogs"), parent, map[attribute.Key]string{client.AttrDeploymentID: "missing"}, true)
+}
+
+func TestUploadSpans(t *testing.T) {
+	srv := railwaytest.NewServer()
+	defer srv.Close()
+	p, env := srv.AddProject("demo")
+	svc := srv.AddService(p.ID, "web")
+	c, exp := tracedClient(t, srv)
+	ctx, parent := withParent(t)
+
+	resp, err := c.Upload(ctx, p.ID, env.ID, svc.ID, strings.NewReader("archive"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	checkSpan(t, findSpan(t, exp, "railway.up.upload"), parent, map[attribute.Key]string{
+		client.AttrProjectID:      p.ID,
+		client.AttrServiceID:      svc.ID,
+		client.AttrUploadBytes:    "7",
+		client.AttrHTTPStatusCode: "200",
+	}, false)
+
+	// 流式归档没有 ContentLength，发送的字节数在读完后记录
+	exp.Reset()
+	pr, pw := io.Pipe()
+	go func() {
+		_, _ = io.WriteString(pw, "tar.gz archive")
+		pw.Close()
+	}()
+	if resp, err = c.Upload(ctx, p.ID, env.ID, svc.ID, pr); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	checkSpan(t, findSpan(t, exp, "railway.up.upload"), parent, map[attribute.Key]string{client.AttrUploadBytes: "14"}, false)
+
+	exp.Reset()
+	if _, err := c.Upload(ctx, p.ID, env.ID, "missing", strings.NewReader("archive")); err == nil {
+		t.Fatal("expected upload error")
+	}
+	checkSpan(t, findSpan(t, exp, "railway.up.upload"), parent, map[attribute.Key]string{client.AttrHTTPStatusCode: "404"}, true)
+}
@@ -6,11 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"net/url"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/railwayapp/cli/internal/config"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // GraphQL WS messages per graphql-transport-ws
@@ -37,115 +38,229 @@ type subscribePayload struct {
 }
 
 type nextPayload struct {
-	Data   json.RawMessage   `json:"data"`
-	Errors []json.RawMessage `json:"errors"`
+	Data   json.RawMessage `json:"data"`
+	Errors []GraphQLError  `json:"errors"`
 }
 
-// Subscribe opens a graphql-transport-ws subscription and yields raw data frames via callback until complete or ctx done.
+// frameError converts the errors carried by a next or error frame into an *APIError so callers
+// see the server's messages and can use errors.Is(err, ErrNotFound) and friends.
+// Error frames carry a bare array of GraphQL errors; anything else is kept as the body.
+func frameError(payload json.RawMessage, errs []GraphQLError) error {
+	if len(errs) == 0 && len(payload) > 0 {
+		if json.Unmarshal(payload, &errs) != nil {
+			var single GraphQLError
+			if json.Unmarshal(payload, &single) == nil && single.Message != "" {
+				errs = []GraphQLError{single}
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return errors.New("graphql ws: subscription error")
+	}
+	return &APIError{Errors: errs}
+}
+
+// Subscribe opens a graphql-transport-ws subscription using credentials resolved from cfg and the environment.
 func Subscribe(ctx context.Context, cfg *config.Config, query string, variables map[string]interface{}, onData func(data json.RawMessage), onError func(err error)) error {
-	// Build URL
-	u := url.URL{Scheme: "wss", Host: fmt.Sprintf("backboard.%s", cfg.GetHost()), Path: "/graphql/v2"}
+	c, err := New(cfg)
+	if err != nil {
+		return err
+	}
+	return c.Subscribe(ctx, query, variables, onData, onError)
+}
 
-	dialer := websocket.Dialer{
+// wsDialer builds a dialer that reuses proxy/TLS settings from a custom HTTP client when available.
+func (c *Client) wsDialer() *websocket.Dialer {
+	dialer := &websocket.Dialer{
 		Proxy:            http.ProxyFromEnvironment,
 		HandshakeTimeout: 30 * time.Second,
 		Subprotocols:     []string{"graphql-transport-ws"},
 	}
+	if c.httpClient != nil {
+		if t, ok := c.httpClient.Transport.(*http.Transport); ok {
+			dialer.Proxy = t.Proxy
+			dialer.TLSClientConfig = t.TLSClientConfig
+			dialer.NetDialContext = t.DialContext
+		}
+	}
+	return dialer
+}
+
+// KeepaliveOptions controls client-initiated pings on the shared subscription socket.
+// Every frame from the server extends the read deadline; if nothing (not even a pong)
+// arrives within Interval+Timeout the socket is treated as dead and torn down, which
+// fails its subscriptions with a *ConnectionError so they can reconnect.
+type KeepaliveOptions struct {
+	// Interval between pings; 0 uses 15s, <0 disables pings and read deadlines.
+	Interval time.Duration
+	// Timeout allowed for the pong (and for each write); 0 uses 10s.
+	Timeout time.Duration
+}
+
+const (
+	defaultPingInterval = 15 * time.Second
+	defaultPongTimeout  = 10 * time.Second
+)
 
-	header := http.Header{}
-	if t := config.GetRailwayToken(); t != nil {
-		header.Set("project-access-token", *t)
-	} else if t := cfg.GetRailwayAuthToken(); t != nil {
-		header.Set("authorization", fmt.Sprintf("Bearer %s", *t))
+func (o KeepaliveOptions) interval() time.Duration {
+	if o.Interval == 0 {
+		return defaultPingInterval
 	}
-	// Helpful headers
-	header.Set("x-source", fmt.Sprintf("railway-cli/%s", "4.6.1"))
-	header.Set("user-agent", fmt.Sprintf("railway-cli/%s", "4.6.1"))
+	return o.Interval
+}
 
-	conn, _, err := dialer.DialContext(ctx, u.String(), header)
-	if err != nil {
-		return err
+func (o KeepaliveOptions) timeout() time.Duration {
+	if o.Timeout <= 0 {
+		return defaultPongTimeout
 	}
-	defer conn.Close()
+	return o.Timeout
+}
 
-	// connection_init
-	initMsg := wsMessage{Type: wsTypeConnectionInit, Payload: json.RawMessage(`{}`)}
-	if err := conn.WriteJSON(initMsg); err != nil {
-		return err
+// ConnectionError reports that the socket carrying a subscription could not be
+// established or was lost. Such subscriptions are re-issued according to the
+// client's ReconnectOptions; once those are exhausted the error is returned.
+type ConnectionError struct {
+	Err error
+}
+
+func (e *ConnectionError) Error() string { return "graphql ws: connection lost: " + e.Err.Error() }
+
+func (e *ConnectionError) Unwrap() error { return e.Err }
+
+// Subscribe opens a graphql-transport-ws subscription and yields raw data frames via callback until complete or ctx done.
+// Concurrent subscriptions share one socket per Client; it is closed when the last of them returns.
+// The whole subscription lifetime is recorded as a single span carrying message and error counts.
+func (c *Client) Subscribe(ctx context.Context, query string, variables map[string]interface{}, onData func(data json.RawMessage), onError func(err error)) error {
+	return c.SubscribeWithReconnect(ctx, query, variables, onData, onError, nil)
+}
+
+// SubscribeWithReconnect is Subscribe with a hook invoked before the subscription is
+// re-issued on a fresh socket after the previous one dropped. attempt counts consecutive
+// reconnects and is reset once data arrives; err is the failure that triggered it.
+// onReconnect may update variables (e.g. to resume a log stream from its last line);
+// the subscription is re-issued with the map as it stands afterwards.
+// All callbacks run on the calling goroutine.
+func (c *Client) SubscribeWithReconnect(ctx context.Context, query string, variables map[string]interface{}, onData func(data json.RawMessage), onError func(err error), onReconnect func(attempt int, err error)) error {
+	typ, name := ParseOperation(query)
+	attrs := append([]attribute.KeyValue{AttrOperationType.String(typ)}, IDAttributes(variables)...)
+	if name != "" {
+		attrs = append(attrs, AttrOperationName.String(name))
 	}
+	ctx, span := c.tracer.Start(ctx, operationSpanName("railway.subscribe", typ, name),
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
 
-	// wait for connection_ack (and ignore pings)
-	acked := false
-	ackDeadline := time.NewTimer(10 * time.Second)
-	for !acked {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ackDeadline.C:
-			return errors.New("graphql ws: timeout waiting for connection_ack")
-		default:
+	var messages, errs, reconnects int64
+	attempt := 0
+	countData := func(data json.RawMessage) {
+		messages++
+		attempt = 0
+		if onData != nil {
+			onData(data)
 		}
-		var msg wsMessage
-		if err := conn.ReadJSON(&msg); err != nil {
-			return err
+	}
+	countError := func(err error) {
+		errs++
+		if onError != nil {
+			onError(err)
 		}
-		switch msg.Type {
-		case wsTypeConnectionAck:
-			acked = true
-		case wsTypePing:
-			// reply pong
-			_ = conn.WriteJSON(wsMessage{Type: wsTypePong, Payload: msg.Payload})
-		case wsTypeError:
-			return errors.New("graphql ws: connection error before ack")
-		case wsTypeNext, wsTypeComplete:
-			// ignore until ack
+	}
+	var err error
+	for {
+		err = c.subscribe(ctx, query, variables, countData, countError)
+		if !shouldReconnect(err) || ctx.Err() != nil || attempt >= c.reconnect.maxAttempts() {
+			break
+		}
+		attempt++
+		reconnects++
+		span.AddEvent("reconnect", trace.WithAttributes(attribute.Int("attempt", attempt), attribute.String("error", err.Error())))
+		if werr := sleepContext(ctx, c.reconnect.backoff(attempt)); werr != nil {
+			err = werr
+			break
 		}
+		if onReconnect != nil {
+			onReconnect(attempt, err)
+		}
+	}
+	var connErr *ConnectionError
+	if errors.As(err, &connErr) {
+		countError(err)
 	}
+	span.SetAttributes(AttrMessageCount.Int64(messages), AttrErrorCount.Int64(errs), AttrReconnectCount.Int64(reconnects))
+	EndSpan(span, err)
+	return err
+}
 
-	// subscribe
-	subID := fmt.Sprintf("%d", time.Now().UnixNano())
-	subPayload, _ := json.Marshal(subscribePayload{Query: query, Variables: variables})
-	subMsg := wsMessage{ID: subID, Type: wsTypeSubscribe, Payload: subPayload}
-	if err := conn.WriteJSON(subMsg); err != nil {
+// subscribe runs one subscription on the client's shared socket; onData and onError must be non-nil.
+// Failures of the socket itself are returned as *ConnectionError without invoking onError.
+func (c *Client) subscribe(ctx context.Context, query string, variables map[string]interface{}, onData func(data json.RawMessage), onError func(err error)) error {
+	sess := c.ws.acquire(ctx)
+	defer c.ws.release(sess)
+	if err := sess.wait(ctx); err != nil {
+		if ctx.Err() == nil && err != errWSRejected {
+			err = &ConnectionError{Err: err}
+		}
 		return err
 	}
+	sub, err := sess.start(query, variables)
+	if err != nil {
+		return &ConnectionError{Err: err}
+	}
 
-	// read loop
-	for {
-		select {
-		case <-ctx.Done():
-			_ = conn.WriteJSON(wsMessage{ID: subID, Type: wsTypeComplete})
-			return ctx.Err()
-		default:
-		}
-		var msg wsMessage
-		if err := conn.ReadJSON(&msg); err != nil {
-			if onError != nil {
-				onError(err)
-			}
-			return err
-		}
+	// handle returns true once the server has finished the subscription
+	handle := func(msg wsMessage) (bool, error) {
 		switch msg.Type {
-		case wsTypePing:
-			_ = conn.WriteJSON(wsMessage{Type: wsTypePong, Payload: msg.Payload})
 		case wsTypeNext:
 			var np nextPayload
-			_ = json.Unmarshal(msg.Payload, &np)
+			if err := json.Unmarshal(msg.Payload, &np); err != nil {
+				onError(fmt.Errorf("graphql ws: decode next payload: %w", err))
+				return false, nil
+			}
 			if len(np.Errors) > 0 {
-				if onError != nil {
-					onError(fmt.Errorf("subscription error"))
+				onError(frameError(nil, np.Errors))
+				if len(np.Data) == 0 || string(np.Data) == "null" {
+					return false, nil
 				}
-				continue
-			}
-			if onData != nil {
-				onData(np.Data)
 			}
+			onData(np.Data)
 		case wsTypeError:
-			if onError != nil {
-				onError(fmt.Errorf("subscription error frame"))
-			}
+			err := frameError(msg.Payload, nil)
+			onError(err)
+			return true, err
 		case wsTypeComplete:
-			return nil
+			return true, nil
+		}
+		return false, nil
+	}
+	// drain handles buffered frames in order. Overflow drops the newest next frames,
+	// so the loss is reported after the frames that were kept.
+	drain := func() (done bool, err error) {
+		msgs, dropped := sub.drain()
+		for _, msg := range msgs {
+			if done, err = handle(msg); done {
+				break
+			}
+		}
+		if dropped > 0 {
+			onError(fmt.Errorf("%w: %d frames", ErrFramesDropped, dropped))
+		}
+		return done, err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			sess.stop(sub.id)
+			return ctx.Err()
+		case <-sub.notify:
+		case <-sess.dead:
+			// deliver frames that arrived before the socket went away
+			if done, err := drain(); done {
+				return err
+			}
+			return &ConnectionError{Err: sess.err}
+		}
+		if done, err := drain(); done {
+			sess.remove(sub.id)
+			return err
 		}
 	}
 }
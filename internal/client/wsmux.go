@@ -0,0 +1,361 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// wsAckTimeout bounds the wait for connection_ack after connection_init.
+const wsAckTimeout = 10 * time.Second
+
+// errWSRejected means the server refused connection_init (typically bad credentials); it is not retried.
+var errWSRejected = errors.New("graphql ws: connection error before ack")
+
+// errKeepaliveTimeout reports a socket that stopped answering pings.
+var errKeepaliveTimeout = errors.New("graphql ws: keepalive timeout, no frames from server")
+
+// deadlineConn is implemented by real sockets (and the recorder wrapping them); replayed
+// cassette connections have no network underneath and skip keepalive.
+type deadlineConn interface {
+	SetReadDeadline(t time.Time) error
+	SetWriteDeadline(t time.Time) error
+}
+
+// errWSClosed is reported to subscribers still attached when a session is shut down.
+var errWSClosed = errors.New("graphql ws: connection closed")
+
+// wsMux keeps at most one graphql-transport-ws socket per Client and multiplexes
+// subscriptions over it by ID. The socket is dialed by the first subscriber and
+// closed when the last one leaves; a broken socket fails every subscription on
+// it and the next Subscribe dials a fresh one.
+type wsMux struct {
+	c    *Client
+	mu   sync.Mutex
+	sess *wsSession
+}
+
+// wsSession is one authenticated socket and the subscriptions running on it.
+type wsSession struct {
+	mux    *wsMux
+	ctx    context.Context
+	cancel context.CancelFunc
+	refs   int           // guarded by mux.mu
+	ready  chan struct{} // closed once connection_ack arrives
+	dead   chan struct{} // closed when the socket is gone; err is set first
+	err    error
+
+	writeMu sync.Mutex
+
+	mu     sync.Mutex
+	conn   wsConn
+	subs   map[string]*wsSub
+	nextID int
+	closed bool
+}
+
+// wsSubQueueLimit caps the next frames buffered for one subscription. A consumer that
+// falls further behind loses frames instead of growing the buffer without bound.
+const wsSubQueueLimit = 1024
+
+// ErrFramesDropped is reported through onError when a subscriber fell more than
+// wsSubQueueLimit frames behind the socket and next frames were discarded.
+var ErrFramesDropped = errors.New("graphql ws: subscriber too slow, frames dropped")
+
+// wsSub buffers frames for one subscription so a slow consumer never stalls the
+// shared read loop. Frames are handled on the subscriber's own goroutine. Only next
+// frames are dropped on overflow; error and complete are always delivered.
+type wsSub struct {
+	id      string
+	mu      sync.Mutex
+	queue   []wsMessage
+	dropped int
+	notify  chan struct{}
+}
+
+func (s *wsSub) push(msg wsMessage) {
+	s.mu.Lock()
+	if msg.Type == wsTypeNext && len(s.queue) >= wsSubQueueLimit {
+		s.dropped++
+	} else {
+		s.queue = append(s.queue, msg)
+	}
+	s.mu.Unlock()
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// drain returns the buffered frames and how many were dropped since the last call.
+func (s *wsSub) drain() ([]wsMessage, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q, n := s.queue, s.dropped
+	s.queue, s.dropped = nil, 0
+	return q, n
+}
+
+// acquire returns the live session, dialing a new one if needed, and takes a reference on it.
+// The dial runs detached from ctx so one subscriber giving up does not fail the others;
+// it is cancelled once every subscriber has released the session.
+func (m *wsMux) acquire(ctx context.Context) *wsSession {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.sess
+	if s == nil || s.isDead() {
+		sctx, cancel := context.WithCancel(context.Background())
+		s = &wsSession{
+			mux:    m,
+			ctx:    sctx,
+			cancel: cancel,
+			ready:  make(chan struct{}),
+			dead:   make(chan struct{}),
+			subs:   map[string]*wsSub{},
+		}
+		m.sess = s
+		header := http.Header{}
+		m.c.setAuthHeaders(header)
+		injectTraceContext(ctx, header)
+		go s.run(header)
+	}
+	s.refs++
+	return s
+}
+
+// release drops a reference; the last one shuts the socket down.
+func (m *wsMux) release(s *wsSession) {
+	m.mu.Lock()
+	s.refs--
+	last := s.refs == 0
+	if last && m.sess == s {
+		m.sess = nil
+	}
+	m.mu.Unlock()
+	if last {
+		s.close(errWSClosed)
+	}
+}
+
+// detach stops handing s to new subscribers.
+func (m *wsMux) detach(s *wsSession) {
+	m.mu.Lock()
+	if m.sess == s {
+		m.sess = nil
+	}
+	m.mu.Unlock()
+}
+
+func (s *wsSession) isDead() bool {
+	select {
+	case <-s.dead:
+		return true
+	default:
+		return false
+	}
+}
+
+// run dials, completes the connection_init handshake and then reads frames until the socket fails.
+func (s *wsSession) run(header http.Header) {
+	c := s.mux.c
+	if err := c.bucket.Wait(s.ctx); err != nil {
+		s.fail(err)
+		return
+	}
+	conn, resp, err := c.dialWS(s.ctx, c.WebSocketURL(), header)
+	if err != nil {
+		// handshake rejected by the server: surface status as APIError
+		if resp != nil && resp.StatusCode != http.StatusSwitchingProtocols {
+			b, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+			err = newHTTPError(resp, b)
+		}
+		s.fail(err)
+		return
+	}
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		conn.Close()
+		return
+	}
+	s.conn = conn
+	s.mu.Unlock()
+
+	if err := s.write(wsMessage{Type: wsTypeConnectionInit, Payload: json.RawMessage(`{}`)}); err != nil {
+		s.fail(err)
+		return
+	}
+	// closing the socket on timeout unblocks the pending read
+	timer := time.AfterFunc(wsAckTimeout, func() {
+		s.fail(errors.New("graphql ws: timeout waiting for connection_ack"))
+	})
+	for acked := false; !acked; {
+		var msg wsMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			timer.Stop()
+			s.fail(err)
+			return
+		}
+		switch msg.Type {
+		case wsTypeConnectionAck:
+			acked = true
+		case wsTypePing:
+			_ = s.write(wsMessage{Type: wsTypePong, Payload: msg.Payload})
+		case wsTypeError:
+			timer.Stop()
+			s.fail(errWSRejected)
+			return
+		}
+	}
+	timer.Stop()
+	close(s.ready)
+
+	ka := c.keepalive
+	dc, alive := conn.(deadlineConn)
+	alive = alive && ka.interval() > 0
+	if alive {
+		_ = dc.SetReadDeadline(time.Now().Add(ka.interval() + ka.timeout()))
+		go s.ping(ka.interval())
+	}
+	for {
+		var msg wsMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			var netErr net.Error
+			if alive && errors.As(err, &netErr) && netErr.Timeout() {
+				err = errKeepaliveTimeout
+			}
+			s.fail(err)
+			return
+		}
+		if alive {
+			_ = dc.SetReadDeadline(time.Now().Add(ka.interval() + ka.timeout()))
+		}
+		switch msg.Type {
+		case wsTypePing:
+			_ = s.write(wsMessage{Type: wsTypePong, Payload: msg.Payload})
+		case wsTypeNext, wsTypeError, wsTypeComplete:
+			s.mu.Lock()
+			sub := s.subs[msg.ID]
+			s.mu.Unlock()
+			if sub != nil {
+				sub.push(msg)
+			}
+		}
+	}
+}
+
+// ping sends a graphql-transport-ws ping every interval until the socket dies.
+// The pong (or any other frame) extends the read deadline set by run.
+func (s *wsSession) ping(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-s.dead:
+			return
+		case <-t.C:
+			if err := s.write(wsMessage{Type: wsTypePing}); err != nil {
+				s.fail(err)
+				return
+			}
+		}
+	}
+}
+
+// wait blocks until the handshake is done.
+func (s *wsSession) wait(ctx context.Context) error {
+	select {
+	case <-s.ready:
+		return nil
+	case <-s.dead:
+		return s.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// start registers a subscription and sends its subscribe frame.
+func (s *wsSession) start(query string, variables map[string]interface{}) (*wsSub, error) {
+	payload, err := json.Marshal(subscribePayload{Query: query, Variables: variables})
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		<-s.dead
+		return nil, s.err
+	}
+	s.nextID++
+	sub := &wsSub{id: strconv.Itoa(s.nextID), notify: make(chan struct{}, 1)}
+	s.subs[sub.id] = sub
+	s.mu.Unlock()
+
+	if err := s.write(wsMessage{ID: sub.id, Type: wsTypeSubscribe, Payload: payload}); err != nil {
+		s.remove(sub.id)
+		return nil, err
+	}
+	return sub, nil
+}
+
+// remove forgets a subscription; frames still arriving for its ID are dropped.
+func (s *wsSession) remove(id string) {
+	s.mu.Lock()
+	delete(s.subs, id)
+	s.mu.Unlock()
+}
+
+// stop ends a subscription from the client side. The complete frame is sent in the
+// background so cancellation returns immediately even if the socket is stuck.
+func (s *wsSession) stop(id string) {
+	s.remove(id)
+	if !s.isDead() {
+		go func() { _ = s.write(wsMessage{ID: id, Type: wsTypeComplete}) }()
+	}
+}
+
+func (s *wsSession) write(msg wsMessage) error {
+	s.mu.Lock()
+	conn := s.conn
+	s.mu.Unlock()
+	if conn == nil {
+		return errWSClosed
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	// a half-open socket must not block writers forever
+	if dc, ok := conn.(deadlineConn); ok {
+		_ = dc.SetWriteDeadline(time.Now().Add(s.mux.c.keepalive.timeout()))
+	}
+	return conn.WriteJSON(msg)
+}
+
+// fail tears the socket down because of err and fails every subscription on it.
+func (s *wsSession) fail(err error) {
+	s.mux.detach(s)
+	s.close(err)
+}
+
+func (s *wsSession) close(err error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	s.err = err
+	conn := s.conn
+	s.mu.Unlock()
+	s.cancel()
+	if conn != nil {
+		conn.Close()
+	}
+	close(s.dead)
+}
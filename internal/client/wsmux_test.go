@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestWSSubQueueBounded(t *testing.T) {
+	sub := &wsSub{id: "1", notify: make(chan struct{}, 1)}
+	for i := 0; i < wsSubQueueLimit+5; i++ {
+		sub.push(wsMessage{ID: "1", Type: wsTypeNext})
+	}
+	sub.push(wsMessage{ID: "1", Type: wsTypeComplete})
+
+	msgs, dropped := sub.drain()
+	if len(msgs) != wsSubQueueLimit+1 || dropped != 5 {
+		t.Fatalf("queued %d, dropped %d", len(msgs), dropped)
+	}
+	if last := msgs[len(msgs)-1]; last.Type != wsTypeComplete {
+		t.Fatalf("last frame = %s, complete must never be dropped", last.Type)
+	}
+	if msgs, dropped := sub.drain(); len(msgs) != 0 || dropped != 0 {
+		t.Fatalf("second drain: %d frames, %d dropped", len(msgs), dropped)
+	}
+}
+
+// silentServer 完成 connection_ack 后不再发送任何帧（也不回复 ping），模拟半开连接；
+// 记录连接数、收到的 ping 数，并在每次收到 subscribe 与连接关闭时通知
+type silentServer struct {
+	*httptest.Server
+	conns, pings atomic.Int32
+	subscribed   chan struct{}
+	closed       chan struct{}
+}
+
+func newSilentServer(t *testing.T) *silentServer {
+	t.Helper()
+	s := &silentServer{subscribed: make(chan struct{}, 8), closed: make(chan struct{}, 8)}
+	upgrader := websocket.Upgrader{Subprotocols: []string{"graphql-transport-ws"}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		s.conns.Add(1)
+		var msg wsMessage
+		for conn.ReadJSON(&msg) == nil {
+			switch msg.Type {
+			case wsTypeConnectionInit:
+				_ = conn.WriteJSON(wsMessage{Type: wsTypeConnectionAck})
+			case wsTypePing:
+				s.pings.Add(1)
+			case wsTypeSubscribe:
+				s.subscribed <- struct{}{}
+			}
+		}
+		s.closed <- struct{}{}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestWSKeepaliveMissingPong(t *testing.T) {
+	srv := newSilentServer(t)
+	ka := KeepaliveOptions{Interval: 50 * time.Millisecond, Timeout: 100 * time.Millisecond}
+	c := newTestClient(t, srv.URL, Options{Keepalive: ka, Reconnect: ReconnectOptions{MaxAttempts: -1}})
+
+	start := time.Now()
+	err := c.Subscribe(context.Background(), `subscription S { n }`, nil, func(json.RawMessage) {}, func(error) {})
+	elapsed := time.Since(start)
+	var connErr *ConnectionError
+	if !errors.As(err, &connErr) || !errors.Is(err, errKeepaliveTimeout) {
+		t.Fatalf("err = %v, want keepalive ConnectionError", err)
+	}
+	// 收不到 pong 时在 Interval+Timeout 后断开，而不是无限等待
+	if elapsed < ka.Interval+ka.Timeout || elapsed > ka.Interval+ka.Timeout+time.Second {
+		t.Fatalf("torn down after %v", elapsed)
+	}
+	if srv.pings.Load() == 0 {
+		t.Fatal("no ping sent")
+	}
+	select {
+	case <-srv.closed:
+	case <-time.After(time.Second):
+		t.Fatal("socket left open after keepalive timeout")
+	}
+}
+
+func TestWSKeepaliveTimeoutReconnects(t *testing.T) {
+	srv := newSilentServer(t)
+	c := newTestClient(t, srv.URL, Options{
+		Keepalive: KeepaliveOptions{Interval: 20 * time.Millisecond, Timeout: 30 * time.Millisecond},
+		Reconnect: ReconnectOptions{MaxAttempts: 1, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+	})
+	var reconnectErr error
+	err := c.SubscribeWithReconnect(context.Background(), `subscription S { n }`, nil, func(json.RawMessage) {}, func(error) {},
+		func(_ int, err error) { reconnectErr = err })
+	// 读超时以 ConnectionError 报告并触发重连，重连后仍超时则返回
+	if !errors.Is(reconnectErr, errKeepaliveTimeout) || !shouldReconnect(reconnectErr) {
+		t.Fatalf("reconnect triggered by %v", reconnectErr)
+	}
+	var connErr *ConnectionError
+	if !errors.As(err, &connErr) || !errors.Is(err, errKeepaliveTimeout) {
+		t.Fatalf("err = %v", err)
+	}
+	if n := srv.conns.Load(); n != 2 {
+		t.Fatalf("connections = %d, want 2", n)
+	}
+}
+
+func TestWSCancelUnblocksStalledRead(t *testing.T) {
+	srv := newSilentServer(t)
+	// 关闭心跳：没有读超时，只能靠 ctx 结束阻塞的订阅
+	c := newTestClient(t, srv.URL, Options{Keepalive: KeepaliveOptions{Interval: -1}})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Subscribe(ctx, `subscription S { n }`, nil, func(json.RawMessage) {}, func(error) {})
+	}()
+	select {
+	case <-srv.subscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not started")
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("err = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Subscribe still blocked after cancel")
+	}
+	// 最后一个订阅者离开后关闭连接
+	select {
+	case <-srv.closed:
+	case <-time.After(time.Second):
+		t.Fatal("socket left open after cancel")
+	}
+}
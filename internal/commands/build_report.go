@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/railwayapp/cli/internal/client"
+	"github.com/railwayapp/cli/internal/logquery"
+	"github.com/railwayapp/cli/pkg/railway/logs"
+)
+
+// fetchBuildFailureReport 取部署最近的构建日志并生成失败摘要
+func fetchBuildFailureReport(ctx context.Context, gqlClient *client.Client, deploymentID string) (*logs.BuildFailureReport, error) {
+	lines, err := logquery.Tail(ctx, gqlClient, logquery.Build, deploymentID, logquery.Query{}, 5000)
+	if err != nil {
+		return nil, err
+	}
+	a := logs.NewBuildAnalyzer()
+	for _, l := range lines {
+		a.Add(l.Time, l.Message)
+	}
+	return a.Report(), nil
+}
+
+// printBuildFailureReport 输出构建失败摘要
+func printBuildFailureReport(w io.Writer, r *logs.BuildFailureReport, deploymentID string) {
+	if r == nil || r.Lines == 0 {
+		return
+	}
+	red := color.New(color.FgRed, color.Bold).SprintFunc()
+	faint := color.New(color.Faint).SprintFunc()
+
+	where := string(r.Phase) + " 阶段"
+	if r.Step != "" {
+		where += "（" + r.Step + "）"
+	}
+	if r.ExitCode != 0 {
+		where += fmt.Sprintf("，退出码 %d", r.ExitCode)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%s %s\n", red("构建失败于"), where)
+
+	phases := make([]string, 0, len(r.Phases))
+	for _, p := range r.Phases {
+		s := fmt.Sprintf("%s %d 行", p.Phase, p.Lines)
+		if !p.Start.IsZero() && p.End.After(p.Start) {
+			s += fmt.Sprintf(" %.1fs", p.End.Sub(p.Start).Seconds())
+		}
+		phases = append(phases, s)
+	}
+	if len(phases) > 0 {
+		fmt.Fprintln(w, faint("  阶段: "+strings.Join(phases, " → ")))
+	}
+
+	if r.Found() {
+		fmt.Fprintf(w, "  错误: %s\n", red(r.Error))
+	} else {
+		fmt.Fprintln(w, "  未识别出明确的错误行，以下为构建日志末尾：")
+	}
+	for _, l := range r.Context {
+		fmt.Fprintf(w, "  %s %s\n", faint("│"), l)
+	}
+	fmt.Fprintln(w, faint("  完整构建日志: railway logs --build --deployment-id "+deploymentID))
+}
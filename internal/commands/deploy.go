@@ -15,48 +15,6 @@ import (
 	"github.com/spf13/cobra"
 )
 
-// 模板配置相关数据结构
-type DeserializedServiceNetworking struct {
-	ServiceDomains map[string]interface{} `json:"serviceDomains,omitempty"`
-	TCPProxies     map[string]interface{} `json:"tcpProxies,omitempty"`
-}
-
-type DeserializedServiceVolumeMount struct {
-	MountPath string `json:"mountPath"`
-}
-
-type DeserializedServiceVariable struct {
-	DefaultValue *string `json:"defaultValue,omitempty"`
-	Value        *string `json:"value,omitempty"`
-	Description  *string `json:"description,omitempty"`
-	IsOptional   *bool   `json:"isOptional,omitempty"`
-}
-
-type DeserializedServiceDeploy struct {
-	HealthcheckPath *string `json:"healthcheckPath,omitempty"`
-	StartCommand    *string `json:"startCommand,omitempty"`
-}
-
-type DeserializedServiceSource struct {
-	Image         *string `json:"image,omitempty"`
-	Repo          *string `json:"repo,omitempty"`
-	RootDirectory *string `json:"rootDirectory,omitempty"`
-}
-
-type DeserializedTemplateService struct {
-	Deploy       *DeserializedServiceDeploy                 `json:"deploy,omitempty"`
-	Icon         *string                                    `json:"icon,omitempty"`
-	Name         string                                     `json:"name"`
-	Networking   *DeserializedServiceNetworking             `json:"networking,omitempty"`
-	Source       *DeserializedServiceSource                 `json:"source,omitempty"`
-	Variables    map[string]*DeserializedServiceVariable    `json:"variables,omitempty"`
-	VolumeMounts map[string]*DeserializedServiceVolumeMount `json:"volumeMounts,omitempty"`
-}
-
-type DeserializedTemplateConfig struct {
-	Services map[string]*DeserializedTemplateService `json:"services,omitempty"`
-}
-
 // NewDeployCommand 创建部署模板命令（对齐 Rust deploy.rs）
 func NewDeployCommand(cfg *config.Config) *cobra.Command {
 	var templates []string
@@ -155,7 +113,7 @@ func fetchAndCreate(
 	}
 
 	// 2. 反序列化模板配置
-	var templateConfig DeserializedTemplateConfig
+	var templateConfig gql.DeserializedTemplateConfig
 	if len(templateDetail.Template.SerializedConfig) > 0 {
 		if err := json.Unmarshal(templateDetail.Template.SerializedConfig, &templateConfig); err != nil {
 			return fmt.Errorf("解析模板配置失败: %w", err)
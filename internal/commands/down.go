@@ -10,6 +10,7 @@ import (
 	"github.com/railwayapp/cli/internal/client"
 	"github.com/railwayapp/cli/internal/config"
 	"github.com/railwayapp/cli/internal/gql"
+	"github.com/railwayapp/cli/internal/pagination"
 	"github.com/railwayapp/cli/internal/util"
 	"github.com/spf13/cobra"
 )
@@ -90,14 +91,13 @@ func runDown(cfg *config.Config, serviceArg, environmentArg string, yes bool) er
 		return fmt.Errorf("未链接服务，请使用 -s/--service 指定服务ID或名称")
 	}
 
-	// 查询部署列表
-	vars := map[string]any{
-		"projectId":     linked.Project,
-		"environmentId": environmentID,
-		"serviceId":     serviceID,
-	}
-	var deps gql.DeploymentsResponse
-	if err := gqlClient.Query(context.Background(), gql.DeploymentsQuery, vars, &deps); err != nil {
+	// 查询全部部署，避免最近一次成功部署落在首页之外
+	deps, err := deploymentsPager(gqlClient, gql.DeploymentsVariables{
+		ProjectID:     linked.Project,
+		EnvironmentID: environmentID,
+		ServiceID:     &serviceID,
+	}).All(context.Background())
+	if err != nil {
 		return err
 	}
 
@@ -107,10 +107,10 @@ func runDown(cfg *config.Config, serviceArg, environmentArg string, yes bool) er
 		Status    string
 		CreatedAt string
 	}
-	nodes := make([]depNode, 0, len(deps.Deployments.Edges))
-	for _, ed := range deps.Deployments.Edges {
-		if strings.EqualFold(ed.Node.Status, "SUCCESS") {
-			nodes = append(nodes, depNode{ID: ed.Node.ID, Status: ed.Node.Status, CreatedAt: ed.Node.CreatedAt})
+	nodes := make([]depNode, 0, len(deps))
+	for _, d := range deps {
+		if strings.EqualFold(d.Status, "SUCCESS") {
+			nodes = append(nodes, depNode{ID: d.ID, Status: d.Status, CreatedAt: d.CreatedAt})
 		}
 	}
 	if len(nodes) == 0 {
@@ -189,3 +189,23 @@ func parseTime(s string) (time.Time, error) {
 	}
 	return time.Time{}, fmt.Errorf("unsupported time: %s", s)
 }
+
+// deploymentsPager 按页列出部署（Deployments 单页有上限），最新的在前
+func deploymentsPager(gqlClient *client.Client, vars gql.DeploymentsVariables) *pagination.Pager[gql.DeploymentNode] {
+	return pagination.New(func(ctx context.Context, first int, after string) ([]gql.DeploymentNode, pagination.PageInfo, error) {
+		vars.First = &first
+		vars.After = nil
+		if after != "" {
+			vars.After = &after
+		}
+		resp, err := gql.Deployments(ctx, gqlClient, vars)
+		if err != nil {
+			return nil, pagination.PageInfo{}, err
+		}
+		out := make([]gql.DeploymentNode, 0, len(resp.Deployments.Edges))
+		for _, e := range resp.Deployments.Edges {
+			out = append(out, e.Node)
+		}
+		return out, pagination.FromGQL(resp.Deployments.PageInfo), nil
+	}, pagination.PageOptions{})
+}
@@ -0,0 +1,18 @@
+package commands
+
+import "fmt"
+
+// ExitError 要求以指定退出码结束进程。命令已输出失败详情，main 不再打印错误
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error { return e.Err }
@@ -2,46 +2,80 @@ package commands
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/railwayapp/cli/internal/client"
 	"github.com/railwayapp/cli/internal/config"
 	"github.com/railwayapp/cli/internal/gql"
+	"github.com/railwayapp/cli/internal/logquery"
+	"github.com/railwayapp/cli/internal/stream"
+	"github.com/railwayapp/cli/pkg/railway/logs"
 	"github.com/spf13/cobra"
 )
 
 func NewLogsCommand(cfg *config.Config) *cobra.Command {
-	var serviceArg string
+	var serviceArgs []string
+	var allServices bool
 	var envArg string
 	var build bool
 	var deployment bool
 	var deploymentID string
 	var jsonOut bool
+	var pretty bool
+	var history logHistoryArgs
+	var filter logFilterArgs
+	var sinks logSinkArgs
 
 	cmd := &cobra.Command{
 		Use:   "logs",
 		Short: "查看部署日志（构建或运行）",
+		Long:  "默认持续跟随最新日志；指定 --since、--until 或 --lines 时查询历史日志，输出后退出。\n使用 --sink 可在输出的同时导出日志，只导出不输出请使用 logs forward。",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runLogs(cfg, serviceArg, envArg, build, deployment, deploymentID, jsonOut)
+			return runLogs(cmd.Context(), cfg, serviceArgs, allServices, envArg, build, deployment, deploymentID, jsonOut, pretty, history, filter, sinks)
 		},
 	}
 
-	cmd.Flags().StringVarP(&serviceArg, "service", "s", "", "服务名称或ID（默认使用已链接服务）")
+	cmd.Flags().StringSliceVarP(&serviceArgs, "service", "s", nil, "服务名称或ID（默认使用已链接服务）；用逗号分隔多个服务时按时间交错显示")
+	cmd.Flags().BoolVarP(&allServices, "all", "a", false, "按时间交错显示环境内全部服务的日志，服务重新部署后自动跟随新部署")
 	cmd.Flags().StringVarP(&envArg, "environment", "e", "", "环境名称或ID（默认使用已链接环境）")
 	cmd.Flags().BoolVarP(&deployment, "deployment", "d", false, "显示部署日志")
 	cmd.Flags().BoolVarP(&build, "build", "b", false, "显示构建日志")
-	cmd.Flags().StringVar(&deploymentID, "deployment-id", "", "指定部署ID（不指定则取最新成功部署）")
-	cmd.Flags().BoolVar(&jsonOut, "json", false, "JSON 格式输出")
+	cmd.Flags().StringVar(&deploymentID, "deployment-id", "", "指定部署ID（不指定则取最近更新的部署，不论其状态）")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "JSON 格式输出：每行一个 {ts, level, service, deployment, msg, fields} 对象，JSON 消息的字段展开到 fields")
+	cmd.Flags().BoolVar(&pretty, "pretty", false, "美化输出：显示时间，按级别着色，JSON 消息展开为高亮的 key=value")
+	cmd.Flags().StringVar(&history.since, "since", "", "只显示该时间之后的日志，如 2h、30m、1d 或 2024-01-02T15:04:05Z（不跟随）")
+	cmd.Flags().StringVar(&history.until, "until", "", "只显示该时间之前的日志，格式同 --since（不跟随）")
+	cmd.Flags().IntVarP(&history.lines, "lines", "n", 0, "显示最近的 N 行后退出（与 --since/--until 组合时为范围内最近的 N 行）")
+	cmd.Flags().StringSliceVar(&filter.levels, "level", nil, "只显示指定级别的日志（debug、info、warn、error，可用逗号分隔多个）")
+	cmd.Flags().StringArrayVar(&filter.attrs, "attr", nil, "只显示属性等于指定值的日志，格式 key=value（可重复）")
+	cmd.Flags().StringArrayVar(&filter.grep, "grep", nil, "只显示包含该文本的日志，不区分大小写（可重复，需全部包含）")
+	addSinkFlags(cmd, &sinks)
 
+	cmd.AddCommand(newLogsForwardCommand(cfg))
+	cmd.AddCommand(newLogsWatchCommand(cfg))
 	return cmd
 }
 
-func runLogs(cfg *config.Config, serviceArg, envArg string, build, deployment bool, deploymentID string, jsonOut bool) error {
-	gqlClient, err := client.NewAuthorized(cfg)
+func runLogs(ctx context.Context, cfg *config.Config, serviceArgs []string, allServices bool, envArg string, build, deployment bool, deploymentID string, jsonOut, pretty bool, history logHistoryArgs, filterArgs logFilterArgs, sinkArgs logSinkArgs) error {
+	if history.lines < 0 {
+		return fmt.Errorf("--lines 不能为负数")
+	}
+	filter, err := filterArgs.build()
+	if err != nil {
+		return err
+	}
+	printer, err := newLogPrinter(jsonOut, pretty)
+	if err != nil {
+		return err
+	}
+	// 长时间跟随日志时网络抖动很常见，不限制重连次数
+	gqlClient, err := client.NewWithOptions(cfg, client.Options{Reconnect: client.ReconnectOptions{MaxAttempts: client.ReconnectUnlimited}})
 	if err != nil {
 		return fmt.Errorf("请先登录: %w", err)
 	}
@@ -73,8 +107,33 @@ func runLogs(cfg *config.Config, serviceArg, envArg string, build, deployment bo
 		return fmt.Errorf("未找到环境: %s", environment)
 	}
 
+	// 多个服务：订阅环境日志
+	if allServices || len(serviceArgs) > 1 {
+		if build || deploymentID != "" || history.set() {
+			return fmt.Errorf("--all 或多个 --service 只能跟随部署日志，不能与 --build、--deployment-id、--since/--until/--lines 同时使用")
+		}
+		names := environmentServices(&projectResp, envID)
+		var services map[string]string
+		if !allServices {
+			if services, err = resolveServices(&projectResp, serviceArgs); err != nil {
+				return err
+			}
+			names = services
+		}
+		sinks, err := openLogSinks(sinkArgs)
+		if err != nil {
+			return err
+		}
+		defer sinks.close()
+		return runLogsMulti(ctx, gqlClient, envID, filter, names, services, printer, sinks)
+	}
+
 	// 服务ID
-	var serviceID string
+	var serviceArg string
+	if len(serviceArgs) == 1 {
+		serviceArg = serviceArgs[0]
+	}
+	var serviceID, serviceName string
 	if strings.TrimSpace(serviceArg) != "" {
 		for _, s := range projectResp.Project.Services.Edges {
 			if eq(s.Node.ID, serviceArg) || eq(s.Node.Name, serviceArg) {
@@ -90,72 +149,265 @@ func runLogs(cfg *config.Config, serviceArg, envArg string, build, deployment bo
 	} else {
 		return fmt.Errorf("未链接服务，请使用 --service 指定")
 	}
-
-	// 查询该服务的部署列表（使用我们已有 Deployments 查询）
-	var deploymentsResp gql.DeploymentsResponse
-	if err := gqlClient.Query(context.Background(), gql.DeploymentsQuery, map[string]any{
-		"projectId":     linked.Project,
-		"environmentId": envID,
-		"serviceId":     serviceID,
-	}, &deploymentsResp); err != nil {
-		return fmt.Errorf("获取部署列表失败: %w", err)
+	for _, s := range projectResp.Project.Services.Edges {
+		if s.Node.ID == serviceID {
+			serviceName = s.Node.Name
+		}
 	}
+	tags := map[string]string{"projectId": linked.Project, "environmentId": envID, "serviceId": serviceID, "serviceName": serviceName}
 
-	// 过滤成功部署并按创建时间排序（没有时间字段，这里按顺序使用返回顺序；若需要可扩展查询）
-	// 简单起见：若未指定 deploymentID，取 edges[0] 作为最新（后端通常返回按时间倒序）
+	// 未指定 deploymentID 时取 UpdatedAt 最新的部署：最近重新部署或状态变化的部署未必是最新创建的，
+	// 因此分页取全部部署后排序，而不是只取按创建时间倒序的首条
+	var status string
 	if deploymentID == "" {
-		if len(deploymentsResp.Deployments.Edges) == 0 {
+		deps, err := deploymentsPager(gqlClient, gql.DeploymentsVariables{
+			ProjectID:     linked.Project,
+			EnvironmentID: envID,
+			ServiceID:     &serviceID,
+		}).All(ctx)
+		if err != nil {
+			return fmt.Errorf("获取部署列表失败: %w", err)
+		}
+		if len(deps) == 0 {
 			return fmt.Errorf("没有找到任何部署")
 		}
-		// 尝试按 UpdatedAt 排序
-		sort.SliceStable(deploymentsResp.Deployments.Edges, func(i, j int) bool {
-			return deploymentsResp.Deployments.Edges[i].Node.UpdatedAt > deploymentsResp.Deployments.Edges[j].Node.UpdatedAt
+		sort.SliceStable(deps, func(i, j int) bool {
+			return deps[i].UpdatedAt > deps[j].UpdatedAt
 		})
-		deploymentID = deploymentsResp.Deployments.Edges[0].Node.ID
+		deploymentID = deps[0].ID
+		status = deps[0].Status
 	}
 
 	// 如果未指定类型，默认：失败部署显示构建日志；否则显示部署日志
 	// 这里简化为：优先 build，如果未指定则展示部署日志
-
-	ctx := context.Background()
-	// 构建日志订阅
+	tags["deploymentId"] = deploymentID
 	if build && !deployment {
-		vars := map[string]interface{}{"deploymentId": deploymentID, "filter": "", "limit": 500}
-		return client.Subscribe(ctx, cfg, gql.BuildLogsSub, vars, func(data json.RawMessage) {
-			if jsonOut {
-				fmt.Println(string(data))
-				return
+		printer.attrs = false
+	} else {
+		// 构建失败的部署没有运行日志，先给出构建失败摘要
+		if status == "" {
+			if resp, err := gql.DeploymentStatus(ctx, gqlClient, gql.DeploymentStatusVariables{ID: deploymentID}); err == nil {
+				status = resp.Deployment.Status
 			}
-			var pl gql.BuildLogsPayload
-			if err := json.Unmarshal(data, &pl); err == nil {
-				for _, l := range pl.BuildLogs {
-					fmt.Println(l.Message)
-				}
+		}
+		if strings.EqualFold(status, "FAILED") {
+			if report, err := fetchBuildFailureReport(ctx, gqlClient, deploymentID); err == nil {
+				printBuildFailureReport(os.Stderr, report, deploymentID)
 			}
-		}, func(err error) { fmt.Fprintf(os.Stderr, "构建日志订阅错误: %v\n", err) })
-	}
-
-	// 部署日志订阅
-	vars := map[string]interface{}{"deploymentId": deploymentID, "filter": "", "limit": 500}
-	return client.Subscribe(ctx, cfg, gql.DeploymentLogsSub, vars, func(data json.RawMessage) {
-		if jsonOut {
-			fmt.Println(string(data))
-			return
-		}
-		var pl gql.DeploymentLogsPayload
-		if err := json.Unmarshal(data, &pl); err == nil {
-			for _, l := range pl.DeploymentLogs {
-				// 简化格式：message 以及属性 key=value
-				b := strings.Builder{}
-				b.WriteString(l.Message)
-				for _, a := range l.Attributes {
-					b.WriteString(" ")
-					b.WriteString(a.Key)
-					b.WriteString("=")
-					b.WriteString(a.Value)
+		}
+	}
+
+	if history.set() {
+		kind := logquery.Deployment
+		if build && !deployment {
+			kind = logquery.Build
+		}
+		if len(sinkArgs.specs) > 0 {
+			return fmt.Errorf("--sink 只能用于跟随日志，不能与 --since/--until/--lines 同时使用")
+		}
+		return printLogHistory(ctx, gqlClient, kind, deploymentID, filter, history, printer, tags)
+	}
+
+	sinks, err := openLogSinks(sinkArgs)
+	if err != nil {
+		return err
+	}
+	defer sinks.close()
+	lines := followDeploymentLogs(ctx, gqlClient, build && !deployment, deploymentID, filter, tags)
+	defer lines.Close()
+	for lines.Next() {
+		l := lines.Value()
+		sinks.send(ctx, l.record)
+		printer.print(l.record, l.attrs)
+	}
+	if err := ignoreCanceled(lines.Err()); err != nil {
+		return fmt.Errorf("日志订阅失败: %w", err)
+	}
+	return nil
+}
+
+// followLine 跟随模式下的一行日志；attrs 保持服务端返回的顺序，用于输出
+type followLine struct {
+	record logs.Record
+	attrs  []logAttr
+}
+
+// followDeploymentLogs 订阅构建（build 为 true）或部署日志。断线后自动重新订阅：从最后一条日志继续，
+// 丢弃服务端回放的重叠行，回放不足以覆盖断线期间的日志时在 stderr 提示；无法解析的帧与订阅错误结束流并由 Err 返回
+func followDeploymentLogs(ctx context.Context, gqlClient *client.Client, build bool, deploymentID, filter string, tags map[string]string) *stream.Stream[followLine] {
+	var cursor client.LogCursor
+	onReconnect := func(attempt int, err error) {
+		fmt.Fprintf(os.Stderr, "日志连接断开（%v），正在重连（第 %d 次）...\n", err, attempt)
+		cursor.Resume()
+	}
+	// 订阅只能回放最近 500 行，无法从断线位置继续
+	admit := func(timestamp, message string) bool {
+		if !cursor.Admit(timestamp, message) {
+			return false
+		}
+		if cursor.Gap() {
+			fmt.Fprintln(os.Stderr, "断线期间的部分日志可能已丢失")
+		}
+		return true
+	}
+	line := func(timestamp, message string, attrs []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}) followLine {
+		t, _ := time.Parse(time.RFC3339Nano, timestamp)
+		l := followLine{record: logs.Record{Time: t, Message: message, Tags: tags, Attributes: make(map[string]string, len(attrs))}}
+		for _, a := range attrs {
+			l.record.Attributes[a.Key] = a.Value
+			l.attrs = append(l.attrs, logAttr{a.Key, a.Value})
+		}
+		return l
+	}
+	vars := map[string]interface{}{"deploymentId": deploymentID, "filter": filter, "limit": 500}
+	if build {
+		return stream.Subscribe(ctx, gqlClient, gql.BuildLogsSub, vars, func(pl gql.BuildLogsPayload, emit func(followLine) bool) {
+			for _, l := range pl.BuildLogs {
+				if admit(l.Timestamp, l.Message) && !emit(line(l.Timestamp, l.Message, l.Attributes)) {
+					return
 				}
-				fmt.Println(b.String())
 			}
+		}, onReconnect)
+	}
+	return stream.Subscribe(ctx, gqlClient, gql.DeploymentLogsSub, vars, func(pl gql.DeploymentLogsPayload, emit func(followLine) bool) {
+		for _, l := range pl.DeploymentLogs {
+			if admit(l.Timestamp, l.Message) && !emit(line(l.Timestamp, l.Message, l.Attributes)) {
+				return
+			}
+		}
+	}, onReconnect)
+}
+
+// ignoreCanceled Ctrl-C 结束跟随属于正常退出
+func ignoreCanceled(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// logFilterArgs 日志过滤参数（--level/--attr/--grep），各条件之间为 AND
+type logFilterArgs struct {
+	levels []string
+	attrs  []string
+	grep   []string
+}
+
+// build 生成后端过滤表达式；未指定任何条件时为空串
+func (a logFilterArgs) build() (string, error) {
+	f := logs.Filter()
+	if len(a.levels) > 0 {
+		f.Level(a.levels...)
+	}
+	for _, kv := range a.attrs {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return "", fmt.Errorf("--attr 格式应为 key=value: %q", kv)
+		}
+		f.Attr(strings.TrimSpace(k), v)
+	}
+	for _, g := range a.grep {
+		f.Contains(g)
+	}
+	return f.Build()
+}
+
+// logHistoryArgs 历史日志查询参数（--since/--until/--lines）
+type logHistoryArgs struct {
+	since string
+	until string
+	lines int
+}
+
+func (a logHistoryArgs) set() bool {
+	return a.since != "" || a.until != "" || a.lines > 0
+}
+
+// printLogHistory 查询并输出历史日志。指定 --lines（或只指定 --until）时从范围末尾向前取最近的 N 行；
+// 只指定 --since 时从起点向后输出范围内的全部日志。
+func printLogHistory(ctx context.Context, gqlClient *client.Client, kind logquery.Kind, deploymentID, filter string, args logHistoryArgs, printer *logPrinter, tags map[string]string) error {
+	now := time.Now()
+	q := logquery.Query{Filter: filter}
+	var err error
+	if args.since != "" {
+		if q.Start, err = parseTimeArg(args.since, now); err != nil {
+			return fmt.Errorf("--since: %w", err)
+		}
+	}
+	if args.until != "" {
+		if q.End, err = parseTimeArg(args.until, now); err != nil {
+			return fmt.Errorf("--until: %w", err)
+		}
+	}
+	if !q.Start.IsZero() && !q.End.IsZero() && q.End.Before(q.Start) {
+		return fmt.Errorf("--until 早于 --since")
+	}
+
+	var lines []logquery.Line
+	if args.lines > 0 || q.Start.IsZero() {
+		// 从末尾向前翻页，凑够 N 行
+		want := args.lines
+		if want == 0 {
+			want = logquery.DefaultLimit
+		}
+		if lines, err = logquery.Tail(ctx, gqlClient, kind, deploymentID, q, want); err != nil {
+			return fmt.Errorf("查询日志失败: %w", err)
+		}
+	} else {
+		for {
+			page, err := logquery.Fetch(ctx, gqlClient, kind, deploymentID, q)
+			if err != nil {
+				return fmt.Errorf("查询日志失败: %w", err)
+			}
+			// 范围可能很大，逐页打印
+			printHistoryLines(printer, tags, page.Lines)
+			if !page.HasMore || len(page.Lines) == 0 {
+				break
+			}
+			q.After = page.After
 		}
-	}, func(err error) { fmt.Fprintf(os.Stderr, "部署日志订阅错误: %v\n", err) })
+	}
+	printHistoryLines(printer, tags, lines)
+	return nil
+}
+
+// printHistoryLines 与跟随模式使用相同的输出格式
+func printHistoryLines(printer *logPrinter, tags map[string]string, lines []logquery.Line) {
+	for _, l := range lines {
+		r := logs.Record{Time: l.Time, Severity: l.Severity, Message: l.Message, Tags: tags, Attributes: make(map[string]string, len(l.Attributes))}
+		attrs := make([]logAttr, 0, len(l.Attributes))
+		for _, a := range l.Attributes {
+			r.Attributes[a.Key] = a.Value
+			attrs = append(attrs, logAttr{a.Key, a.Value})
+		}
+		printer.print(r, attrs)
+	}
+}
+
+// parseTimeArg 解析相对时长（2h、30m、1d，表示距 now 多久之前）或绝对时间（RFC3339、本地时间 2006-01-02 15:04[:05]、2006-01-02）
+func parseTimeArg(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("时长不能为负数: %s", s)
+		}
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无法解析时间 %q（支持 2h、30m、1d、RFC3339 或 2006-01-02 15:04）", s)
 }
@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/railwayapp/cli/pkg/railway/logs"
+)
+
+// logAttr 日志属性，保留服务端返回的顺序
+type logAttr struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// logFormat 日志输出格式
+type logFormat int
+
+const (
+	// logFormatText 消息与 key=value 属性（默认）
+	logFormatText logFormat = iota
+	// logFormatJSON 每行一个 logs.Entry（{ts, level, service, deployment, msg, fields}）
+	logFormatJSON
+	// logFormatPretty 时间、着色的级别、消息与高亮的关键字段
+	logFormatPretty
+)
+
+// logPrinter 按所选格式输出日志；非并发安全
+type logPrinter struct {
+	format logFormat
+	// attrs 文本格式是否附带属性（构建日志不附带）
+	attrs bool
+	// prefix 非 nil 时在每行前加服务名前缀（多服务模式）
+	prefix func(service string) string
+}
+
+func newLogPrinter(jsonOut, pretty bool) (*logPrinter, error) {
+	p := &logPrinter{attrs: true}
+	switch {
+	case jsonOut && pretty:
+		return nil, fmt.Errorf("--json 与 --pretty 不能同时使用")
+	case jsonOut:
+		p.format = logFormatJSON
+	case pretty:
+		p.format = logFormatPretty
+	}
+	return p, nil
+}
+
+// print 输出一行；attrs 为文本格式使用的有序属性
+func (p *logPrinter) print(rec logs.Record, attrs []logAttr) {
+	switch p.format {
+	case logFormatJSON:
+		b, err := json.Marshal(rec.Normalize())
+		if err == nil {
+			fmt.Println(string(b))
+		}
+		return
+	case logFormatPretty:
+		fmt.Println(p.pretty(rec.Normalize()))
+		return
+	}
+	b := strings.Builder{}
+	if p.prefix != nil {
+		b.WriteString(p.prefix(rec.Tags["serviceName"]))
+		b.WriteString(" ")
+	}
+	b.WriteString(rec.Message)
+	if p.attrs {
+		for _, a := range attrs {
+			b.WriteString(" ")
+			b.WriteString(a.Key)
+			b.WriteString("=")
+			b.WriteString(a.Value)
+		}
+	}
+	fmt.Println(b.String())
+}
+
+var (
+	levelColors = map[string]*color.Color{
+		"error": color.New(color.FgRed, color.Bold),
+		"warn":  color.New(color.FgYellow, color.Bold),
+		"info":  color.New(color.FgGreen),
+		"debug": color.New(color.FgBlue),
+	}
+	faint    = color.New(color.Faint)
+	keyColor = color.New(color.FgCyan)
+	keyBold  = color.New(color.FgCyan, color.Bold)
+	errColor = color.New(color.FgRed)
+)
+
+// prettyKeyFields 优先显示并加粗的常见字段
+var prettyKeyFields = []string{"method", "path", "status", "statusCode", "duration", "latency", "error", "err"}
+
+// pretty 生成 "15:04:05.000 ERROR [svc] 消息 key=value ..."：级别按严重程度着色，常见字段排在前面并高亮，
+// error/err 与 5xx/4xx 状态码标红/黄
+func (p *logPrinter) pretty(e logs.Entry) string {
+	b := strings.Builder{}
+	if !e.Time.IsZero() {
+		b.WriteString(faint.Sprint(e.Time.Local().Format("15:04:05.000")))
+		b.WriteString(" ")
+	}
+	lvl := fmt.Sprintf("%-5s", strings.ToUpper(e.Level))
+	if c, ok := levelColors[e.Level]; ok {
+		lvl = c.Sprint(lvl)
+	}
+	b.WriteString(lvl)
+	if p.prefix != nil {
+		b.WriteString(" ")
+		b.WriteString(p.prefix(e.Service))
+	}
+	b.WriteString(" ")
+	b.WriteString(e.Message)
+
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	rank := func(k string) int {
+		for i, f := range prettyKeyFields {
+			if f == k {
+				return i
+			}
+		}
+		return len(prettyKeyFields)
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return rank(keys[i]) < rank(keys[j]) })
+	for _, k := range keys {
+		v := prettyValue(e.Fields[k])
+		b.WriteString(" ")
+		if rank(k) < len(prettyKeyFields) {
+			b.WriteString(keyBold.Sprint(k + "="))
+		} else {
+			b.WriteString(keyColor.Sprint(k + "="))
+		}
+		switch {
+		case k == "error" || k == "err":
+			v = errColor.Sprint(v)
+		case k == "status" || k == "statusCode":
+			if n, err := strconv.Atoi(v); err == nil && n >= 500 {
+				v = levelColors["error"].Sprint(v)
+			} else if err == nil && n >= 400 {
+				v = levelColors["warn"].Sprint(v)
+			}
+		}
+		b.WriteString(v)
+	}
+	return b.String()
+}
+
+// prettyValue 字符串含空白时加引号，其余值以 JSON 表示
+func prettyValue(v any) string {
+	if s, ok := v.(string); ok {
+		if s == "" || strings.ContainsAny(s, " \t\n\"") {
+			return strconv.Quote(s)
+		}
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
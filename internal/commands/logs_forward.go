@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/railwayapp/cli/internal/client"
+	"github.com/railwayapp/cli/internal/config"
+	"github.com/railwayapp/cli/internal/gql"
+	"github.com/railwayapp/cli/internal/stream"
+	"github.com/railwayapp/cli/pkg/railway/logs"
+	"github.com/spf13/cobra"
+)
+
+// newLogsForwardCommand railway logs forward：持续将环境日志导出到 --sink，不输出到终端
+func newLogsForwardCommand(cfg *config.Config) *cobra.Command {
+	var envArg string
+	var services []string
+	var filter logFilterArgs
+	var sinks logSinkArgs
+
+	cmd := &cobra.Command{
+		Use:   "forward",
+		Short: "持续将环境日志导出到文件、syslog 或 Loki",
+		Long:  "订阅环境内全部服务（或 --service 指定的服务）的日志并写入 --sink，断线自动重连，适合作为常驻进程运行；Ctrl-C 时写完缓冲的日志后退出。",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLogsForward(cmd.Context(), cfg, envArg, services, filter, sinks)
+		},
+	}
+
+	cmd.Flags().StringVarP(&envArg, "environment", "e", "", "环境名称或ID（默认使用已链接环境）")
+	cmd.Flags().StringSliceVarP(&services, "service", "s", nil, "只导出这些服务的日志（名称或ID，可用逗号分隔多个；默认全部服务）")
+	cmd.Flags().StringSliceVar(&filter.levels, "level", nil, "只导出指定级别的日志（debug、info、warn、error，可用逗号分隔多个）")
+	cmd.Flags().StringArrayVar(&filter.attrs, "attr", nil, "只导出属性等于指定值的日志，格式 key=value（可重复）")
+	cmd.Flags().StringArrayVar(&filter.grep, "grep", nil, "只导出包含该文本的日志，不区分大小写（可重复，需全部包含）")
+	addSinkFlags(cmd, &sinks)
+	_ = cmd.MarkFlagRequired("sink")
+	return cmd
+}
+
+func runLogsForward(ctx context.Context, cfg *config.Config, envArg string, serviceArgs []string, filterArgs logFilterArgs, sinkArgs logSinkArgs) error {
+	filter, err := filterArgs.build()
+	if err != nil {
+		return err
+	}
+	gqlClient, err := client.NewWithOptions(cfg, client.Options{Reconnect: client.ReconnectOptions{MaxAttempts: client.ReconnectUnlimited}})
+	if err != nil {
+		return fmt.Errorf("请先登录: %w", err)
+	}
+	projectResp, envID, err := resolveLinkedEnvironment(ctx, gqlClient, cfg, envArg)
+	if err != nil {
+		return err
+	}
+
+	// 环境日志订阅不支持按服务过滤，在客户端按 serviceId 标签筛选
+	var wanted map[string]string
+	if len(serviceArgs) > 0 {
+		if wanted, err = resolveServices(projectResp, serviceArgs); err != nil {
+			return err
+		}
+	}
+	names := map[string]string{}
+	for _, s := range projectResp.Project.Services.Edges {
+		names[s.Node.ID] = s.Node.Name
+	}
+
+	sinks, err := openLogSinks(sinkArgs)
+	if err != nil {
+		return err
+	}
+	defer sinks.close()
+
+	fmt.Fprintf(os.Stderr, "正在导出日志到 %s（Ctrl-C 停止）\n", strings.Join(sinkArgs.specs, ", "))
+	return subscribeEnvironmentRecords(ctx, gqlClient, envID, filter, 0, names, func(r logs.Record, _ []logAttr) {
+		if _, ok := wanted[r.Tags["serviceId"]]; wanted != nil && !ok {
+			return
+		}
+		sinks.send(ctx, r)
+	})
+}
+
+// resolveLinkedEnvironment 查询已链接项目，并将 envArg（为空时使用已链接环境）解析为环境 ID
+func resolveLinkedEnvironment(ctx context.Context, gqlClient *client.Client, cfg *config.Config, envArg string) (*gql.ProjectResponse, string, error) {
+	linked, err := cfg.GetLinkedProject()
+	if err != nil {
+		return nil, "", err
+	}
+	environment := envArg
+	if strings.TrimSpace(environment) == "" {
+		environment = linked.Environment
+	}
+	var projectResp gql.ProjectResponse
+	if err := gqlClient.Query(ctx, gql.ProjectQuery, map[string]any{"id": linked.Project}, &projectResp); err != nil {
+		return nil, "", fmt.Errorf("获取项目失败: %w", err)
+	}
+	for _, e := range projectResp.Project.Environments.Edges {
+		if eq(e.Node.ID, environment) || eq(e.Node.Name, environment) {
+			return &projectResp, e.Node.ID, nil
+		}
+	}
+	return nil, "", fmt.Errorf("未找到环境: %s", environment)
+}
+
+// subscribeEnvironmentRecords 持续订阅环境日志（断线无限重连，以 afterDate 从最后一条日志继续并丢弃重复行），
+// 将每行转换为 logs.Record 交给 onRecord，attrs 为按服务端顺序排列的属性；
+// names 为 serviceId → 服务名，用于填充 serviceName 标签；beforeLimit 为订阅开始时回放的历史行数
+func subscribeEnvironmentRecords(ctx context.Context, gqlClient *client.Client, envID, filter string, beforeLimit int, names map[string]string, onRecord func(r logs.Record, attrs []logAttr)) error {
+	var cursor client.LogCursor
+	vars := map[string]interface{}{"environmentId": envID, "filter": filter, "beforeLimit": beforeLimit}
+	onReconnect := func(attempt int, err error) {
+		fmt.Fprintf(os.Stderr, "日志连接断开（%v），正在重连（第 %d 次）...\n", err, attempt)
+		gql.ResumeEnvironmentLogs(vars, cursor.Last())
+		cursor.Resume()
+	}
+	type envLine struct {
+		record logs.Record
+		attrs  []logAttr
+	}
+	lines := stream.Subscribe(ctx, gqlClient, gql.EnvironmentLogsSub, vars, func(pl gql.EnvironmentLogsPayload, emit func(envLine) bool) {
+		for _, l := range pl.EnvironmentLogs {
+			tags := map[string]string{}
+			for k, v := range map[string]*string{
+				"projectId":            l.Tags.ProjectID,
+				"environmentId":        l.Tags.EnvironmentID,
+				"pluginId":             l.Tags.PluginID,
+				"serviceId":            l.Tags.ServiceID,
+				"deploymentId":         l.Tags.DeploymentID,
+				"deploymentInstanceId": l.Tags.DeploymentInstanceID,
+				"snapshotId":           l.Tags.SnapshotID,
+			} {
+				if v != nil && *v != "" {
+					tags[k] = *v
+				}
+			}
+			// 环境日志混合多个服务，同一时间戳的行按来源区分
+			if !cursor.Admit(l.Timestamp, tags["deploymentInstanceId"]+"\x00"+l.Message) {
+				continue
+			}
+			if cursor.Gap() {
+				fmt.Fprintln(os.Stderr, "断线期间的部分日志可能已丢失")
+			}
+			if name := names[tags["serviceId"]]; name != "" {
+				tags["serviceName"] = name
+			}
+			t, _ := time.Parse(time.RFC3339Nano, l.Timestamp)
+			line := envLine{record: logs.Record{Time: t, Severity: l.Severity, Message: l.Message, Tags: tags, Attributes: make(map[string]string, len(l.Attributes))}}
+			for _, a := range l.Attributes {
+				line.attrs = append(line.attrs, logAttr{a.Key, a.Value})
+				line.record.Attributes[a.Key] = a.Value
+			}
+			if !emit(line) {
+				return
+			}
+		}
+	}, onReconnect)
+	defer lines.Close()
+	for lines.Next() {
+		l := lines.Value()
+		onRecord(l.record, l.attrs)
+	}
+	if err := ignoreCanceled(lines.Err()); err != nil {
+		return fmt.Errorf("环境日志订阅失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/railwayapp/cli/internal/client"
+	"github.com/railwayapp/cli/internal/gql"
+	"github.com/railwayapp/cli/pkg/railway/logs"
+)
+
+// resolveServices 将名称或ID解析为 serviceId → 服务名
+func resolveServices(projectResp *gql.ProjectResponse, args []string) (map[string]string, error) {
+	out := map[string]string{}
+	for _, arg := range args {
+		if strings.TrimSpace(arg) == "" {
+			continue
+		}
+		found := false
+		for _, s := range projectResp.Project.Services.Edges {
+			if eq(s.Node.ID, arg) || eq(s.Node.Name, arg) {
+				out[s.Node.ID] = s.Node.Name
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("未找到服务: %s", arg)
+		}
+	}
+	return out, nil
+}
+
+// environmentServices 返回环境中部署了实例的全部服务；项目响应不含实例信息时返回全部服务
+func environmentServices(projectResp *gql.ProjectResponse, envID string) map[string]string {
+	all := map[string]string{}
+	inEnv := map[string]string{}
+	for _, s := range projectResp.Project.Services.Edges {
+		all[s.Node.ID] = s.Node.Name
+		for _, si := range s.Node.ServiceInstances.Edges {
+			if si.Node.EnvironmentID == envID {
+				inEnv[s.Node.ID] = s.Node.Name
+			}
+		}
+	}
+	if len(inEnv) == 0 {
+		return all
+	}
+	return inEnv
+}
+
+// multiLogLine 环境日志中的一行，附带来源服务
+type multiLogLine struct {
+	time         time.Time
+	serviceID    string
+	service      string
+	deploymentID string
+	record       logs.Record
+	attributes   []logAttr
+
+	arrived time.Time
+	seq     int
+}
+
+// runLogsMulti 跟随环境内多个服务（services 为 nil 时为全部服务）的部署日志，按时间戳交错输出。
+// 环境日志订阅覆盖环境内所有部署，服务重新部署后新部署的日志会自动出现。
+func runLogsMulti(ctx context.Context, gqlClient *client.Client, envID, filter string, names, services map[string]string, printer *logPrinter, sinks *logSinks) error {
+	width := 0
+	for _, n := range names {
+		width = max(width, len(n))
+	}
+	prefix := servicePrefixer(width)
+
+	// 每个服务出现过的部署，服务出现新部署时提示（emit 只在 logMerger.run 中调用，无需加锁）
+	seenDeployments := map[string]map[string]bool{}
+	printer.prefix = prefix
+	m := &logMerger{window: 500 * time.Millisecond, emit: func(l multiLogLine) {
+		if seen := seenDeployments[l.serviceID]; l.deploymentID != "" && !seen[l.deploymentID] {
+			if seen == nil {
+				seenDeployments[l.serviceID] = map[string]bool{}
+			} else if printer.format != logFormatJSON {
+				fmt.Fprintf(os.Stderr, "%s 新部署 %s\n", prefix(l.service), l.deploymentID)
+			}
+			seenDeployments[l.serviceID][l.deploymentID] = true
+		}
+		printer.print(l.record, l.attributes)
+	}}
+	mergeCtx, stopMerge := context.WithCancel(context.Background())
+	mergeDone := make(chan struct{})
+	go func() {
+		m.run(mergeCtx)
+		close(mergeDone)
+	}()
+	defer func() {
+		stopMerge()
+		<-mergeDone
+	}()
+
+	// 订阅开始时回放最近的历史行，与单服务跟随一致
+	return subscribeEnvironmentRecords(ctx, gqlClient, envID, filter, 500, names, func(r logs.Record, attrs []logAttr) {
+		serviceID := r.Tags["serviceId"]
+		if services != nil {
+			if _, ok := services[serviceID]; !ok {
+				return
+			}
+		}
+		// 不在 names 中的服务（如新建服务）以 ID 前缀代替服务名
+		name := r.Tags["serviceName"]
+		if name == "" {
+			name = serviceID
+			if len(name) > 8 {
+				name = name[:8]
+			}
+			r.Tags["serviceName"] = name
+		}
+		sinks.send(ctx, r)
+		m.add(multiLogLine{time: r.Time, serviceID: serviceID, service: name, deploymentID: r.Tags["deploymentId"], record: r, attributes: attrs})
+	})
+}
+
+// servicePrefixColors 服务名前缀的配色；不含红色以免与错误混淆
+var servicePrefixColors = []color.Attribute{color.FgCyan, color.FgGreen, color.FgYellow, color.FgBlue, color.FgMagenta, color.FgHiCyan, color.FgHiGreen, color.FgHiYellow, color.FgHiBlue, color.FgHiMagenta}
+
+// servicePrefixer 返回生成 "[name]" 前缀的函数：按服务名哈希选色，同一服务每次运行颜色相同，名称按 width 对齐
+func servicePrefixer(width int) func(name string) string {
+	cache := map[string]string{}
+	var mu sync.Mutex
+	return func(name string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		if p, ok := cache[name]; ok {
+			return p
+		}
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(name))
+		c := color.New(servicePrefixColors[h.Sum32()%uint32(len(servicePrefixColors))], color.Bold)
+		p := c.Sprintf("%-*s", width+2, "["+name+"]")
+		cache[name] = p
+		return p
+	}
+}
+
+// logMerger 将多个服务的日志按时间戳排序后输出。订阅按到达顺序推送，不同服务的日志可能乱序，
+// 因此每行最多暂存 window，期间到达的更早日志会排在它之前。
+type logMerger struct {
+	window time.Duration
+	emit   func(multiLogLine)
+
+	mu      sync.Mutex
+	pending []multiLogLine
+	seq     int
+}
+
+func (m *logMerger) add(l multiLogLine) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seq++
+	l.arrived, l.seq = time.Now(), m.seq
+	m.pending = append(m.pending, l)
+}
+
+// run 定期输出暂存已满 window 的日志，ctx 结束时输出全部剩余日志
+func (m *logMerger) run(ctx context.Context) {
+	ticker := time.NewTicker(m.window / 5)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			m.flush(time.Time{})
+			return
+		case now := <-ticker.C:
+			m.flush(now.Add(-m.window))
+		}
+	}
+}
+
+// flush 按时间戳输出到达时间不晚于 cutoff 的日志（cutoff 为零值时输出全部）；
+// 排在前面的日志尚未暂存满 window 时，之后的日志也继续等待以保持顺序
+func (m *logMerger) flush(cutoff time.Time) {
+	m.mu.Lock()
+	sort.SliceStable(m.pending, func(i, j int) bool {
+		a, b := m.pending[i], m.pending[j]
+		if !a.time.Equal(b.time) {
+			return a.time.Before(b.time)
+		}
+		return a.seq < b.seq
+	})
+	n := 0
+	for n < len(m.pending) && (cutoff.IsZero() || !m.pending[n].arrived.After(cutoff)) {
+		n++
+	}
+	out := append([]multiLogLine(nil), m.pending[:n]...)
+	m.pending = append(m.pending[:0], m.pending[n:]...)
+	m.mu.Unlock()
+	for _, l := range out {
+		m.emit(l)
+	}
+}
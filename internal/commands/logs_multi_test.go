@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/railwayapp/cli/pkg/railway/logs"
+)
+
+// mergerLog 记录 logMerger 输出的消息
+type mergerLog struct {
+	mu  sync.Mutex
+	out []string
+}
+
+func (l *mergerLog) emit(line multiLogLine) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out = append(l.out, line.record.Message)
+}
+
+func (l *mergerLog) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return strings.Join(l.out, ",")
+}
+
+var mergeBase = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func mergeLine(service string, offset time.Duration, msg string) multiLogLine {
+	t := mergeBase.Add(offset)
+	return multiLogLine{time: t, serviceID: service, service: service, record: logs.Record{Time: t, Message: msg}}
+}
+
+func TestLogMergerReordersWithinWindow(t *testing.T) {
+	var out mergerLog
+	m := &logMerger{window: time.Second, emit: out.emit}
+	m.add(mergeLine("api", 3*time.Millisecond, "api-3"))
+	m.add(mergeLine("web", 1*time.Millisecond, "web-1"))
+	m.add(mergeLine("api", 4*time.Millisecond, "api-4"))
+	m.add(mergeLine("web", 2*time.Millisecond, "web-2"))
+	m.flush(time.Now())
+	if got := out.String(); got != "web-1,web-2,api-3,api-4" {
+		t.Fatalf("output = %s", got)
+	}
+}
+
+func TestLogMergerHoldsLinesBehindPendingEarlierLine(t *testing.T) {
+	var out mergerLog
+	m := &logMerger{window: time.Second, emit: out.emit}
+	m.add(mergeLine("api", 5*time.Millisecond, "api-5"))
+	cutoff := time.Now()
+	time.Sleep(2 * time.Millisecond)
+	// 晚到但时间更早的行未暂存满 window，已满 window 的 api-5 也需等待
+	m.add(mergeLine("web", time.Millisecond, "web-1"))
+	m.flush(cutoff)
+	if got := out.String(); got != "" {
+		t.Fatalf("output before window = %s", got)
+	}
+	m.flush(time.Time{})
+	if got := out.String(); got != "web-1,api-5" {
+		t.Fatalf("output = %s", got)
+	}
+}
+
+func TestLogMergerStableAtEqualTimestamps(t *testing.T) {
+	var out mergerLog
+	m := &logMerger{window: time.Second, emit: out.emit}
+	// 同一时间戳的行保持到达顺序，跨多次 flush 也不变
+	for _, msg := range []string{"api-a", "web-a", "api-b", "worker-a", "web-b"} {
+		m.add(mergeLine(strings.SplitN(msg, "-", 2)[0], 0, msg))
+	}
+	m.add(mergeLine("api", -time.Millisecond, "api-early"))
+	m.flush(time.Now())
+	for _, msg := range []string{"web-c", "api-c"} {
+		m.add(mergeLine(strings.SplitN(msg, "-", 2)[0], 0, msg))
+	}
+	m.flush(time.Time{})
+	if got := out.String(); got != "api-early,api-a,web-a,api-b,worker-a,web-b,web-c,api-c" {
+		t.Fatalf("output = %s", got)
+	}
+}
+
+func TestLogMergerRun(t *testing.T) {
+	var out mergerLog
+	m := &logMerger{window: 50 * time.Millisecond, emit: out.emit}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		m.run(ctx)
+		close(done)
+	}()
+	m.add(mergeLine("api", 2*time.Millisecond, "api-2"))
+	m.add(mergeLine("web", time.Millisecond, "web-1"))
+	deadline := time.Now().Add(5 * time.Second)
+	for out.String() != "web-1,api-2" {
+		if time.Now().After(deadline) {
+			t.Fatalf("output = %s", out.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	// ctx 结束时立即输出全部剩余行
+	m.add(mergeLine("api", 4*time.Millisecond, "api-4"))
+	m.add(mergeLine("web", 3*time.Millisecond, "web-3"))
+	cancel()
+	<-done
+	if got := out.String(); got != "web-1,api-2,web-3,api-4" {
+		t.Fatalf("output = %s", got)
+	}
+}
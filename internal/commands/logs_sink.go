@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/railwayapp/cli/pkg/railway/logs"
+	"github.com/spf13/cobra"
+)
+
+// logSinkArgs 日志导出参数（--sink/--overflow）
+type logSinkArgs struct {
+	specs    []string
+	overflow string
+}
+
+// logSinks --sink 指定的导出目标，每个目标有独立的缓冲区
+type logSinks struct {
+	specs []string
+	bufs  []*logs.Buffer
+}
+
+// openLogSinks 打开全部导出目标；未指定 --sink 时返回 nil
+func openLogSinks(args logSinkArgs) (*logSinks, error) {
+	if len(args.specs) == 0 {
+		return nil, nil
+	}
+	var policy logs.OverflowPolicy
+	switch strings.ToLower(args.overflow) {
+	case "", "block":
+		policy = logs.Block
+	case "drop-newest":
+		policy = logs.DropNewest
+	case "drop-oldest":
+		policy = logs.DropOldest
+	default:
+		return nil, fmt.Errorf("--overflow 应为 block、drop-newest 或 drop-oldest: %q", args.overflow)
+	}
+	s := &logSinks{}
+	for _, spec := range args.specs {
+		sink, err := logs.OpenSink(spec)
+		if err != nil {
+			s.close()
+			return nil, err
+		}
+		spec := spec
+		s.specs = append(s.specs, spec)
+		s.bufs = append(s.bufs, logs.NewBuffer(sink, logs.BufferOptions{
+			Overflow: policy,
+			OnError:  func(err error) { fmt.Fprintf(os.Stderr, "日志导出失败（%s）: %v\n", spec, err) },
+		}))
+	}
+	return s, nil
+}
+
+// send 写入所有目标；block 策略下目标跟不上时阻塞（对订阅施加背压），直到 ctx 取消
+func (s *logSinks) send(ctx context.Context, r logs.Record) {
+	if s == nil {
+		return
+	}
+	for _, b := range s.bufs {
+		_ = b.Send(ctx, r)
+	}
+}
+
+// close 写完缓冲的日志并关闭目标，报告丢弃的条数
+func (s *logSinks) close() {
+	if s == nil {
+		return
+	}
+	for i, b := range s.bufs {
+		if err := b.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "关闭日志导出目标失败（%s）: %v\n", s.specs[i], err)
+		}
+		if n := b.Dropped() + b.Failed(); n > 0 {
+			fmt.Fprintf(os.Stderr, "日志导出（%s）共丢弃 %d 条（缓冲区已满 %d，写入失败 %d）\n", s.specs[i], n, b.Dropped(), b.Failed())
+		}
+	}
+}
+
+// addSinkFlags 注册 --sink/--overflow
+func addSinkFlags(cmd *cobra.Command, args *logSinkArgs) {
+	cmd.Flags().StringArrayVar(&args.specs, "sink", nil, "导出到目标（可重复）：文件路径或 file:///path?max_size=100MB&max_files=5、syslog+udp://host:514、syslog+tcp://host:601、loki+http://host:3100?label.env=prod")
+	cmd.Flags().StringVar(&args.overflow, "overflow", "block", "导出缓冲区已满时：block（阻塞读取日志）、drop-newest、drop-oldest")
+}
@@ -0,0 +1,224 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/railwayapp/cli/internal/client"
+	"github.com/railwayapp/cli/internal/config"
+	"github.com/railwayapp/cli/pkg/railway/logs"
+	"github.com/spf13/cobra"
+)
+
+// logWatchArgs railway logs watch 参数
+type logWatchArgs struct {
+	envArg   string
+	services []string
+	filter   logFilterArgs
+	match    string
+	exec     string
+	webhook  string
+	dedup    time.Duration
+	rate     string
+	timeout  time.Duration
+}
+
+// newLogsWatchCommand railway logs watch：日志匹配正则时执行命令或调用 webhook
+func newLogsWatchCommand(cfg *config.Config) *cobra.Command {
+	var args logWatchArgs
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "日志匹配时执行命令或调用 webhook",
+		Long: `订阅环境内全部服务（或 --service 指定的服务）的日志，消息匹配 --match 时执行 --exec 命令和/或向 --webhook 发送 POST。
+
+命令通过环境变量获得匹配信息：RAILWAY_LOG_MESSAGE、RAILWAY_LOG_MATCH、RAILWAY_LOG_LEVEL、RAILWAY_LOG_TIMESTAMP、
+RAILWAY_LOG_SUPPRESSED、RAILWAY_SERVICE_ID、RAILWAY_SERVICE_NAME、RAILWAY_DEPLOYMENT_ID、RAILWAY_ENVIRONMENT_ID、RAILWAY_PROJECT_ID；
+标准输入与 webhook 请求体为同一 JSON：{ts, level, service, deployment, msg, fields, match, groups, suppressed, tags}。
+同一服务的相同消息（数字不计）在 --dedup 窗口内只触发一次，触发频率受 --rate 限制；断线自动重连。`,
+		Example: `  railway logs watch --match 'OOM|out of memory' --exec ./page.sh
+  railway logs watch -s api --match 'status=5\d\d' --webhook http://localhost:9000/hook --rate 5/10m`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runLogsWatch(cmd.Context(), cfg, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&args.match, "match", "", "匹配日志消息的正则表达式（Go 语法）")
+	cmd.Flags().StringVar(&args.exec, "exec", "", "匹配时执行的命令（通过 shell 执行）")
+	cmd.Flags().StringVar(&args.webhook, "webhook", "", "匹配时以 JSON 发送 POST 的地址")
+	cmd.Flags().DurationVar(&args.dedup, "dedup", time.Minute, "去重窗口：同一服务的相同消息在窗口内只触发一次（0 不去重）")
+	cmd.Flags().StringVar(&args.rate, "rate", "10/1m", "最多触发频率，格式 N/时长（如 5/10m），0 不限制")
+	cmd.Flags().DurationVar(&args.timeout, "timeout", 30*time.Second, "单次命令或 webhook 的超时时间")
+	cmd.Flags().StringVarP(&args.envArg, "environment", "e", "", "环境名称或ID（默认使用已链接环境）")
+	cmd.Flags().StringSliceVarP(&args.services, "service", "s", nil, "只监视这些服务的日志（名称或ID，可用逗号分隔多个；默认全部服务）")
+	cmd.Flags().StringSliceVar(&args.filter.levels, "level", nil, "只监视指定级别的日志（debug、info、warn、error，可用逗号分隔多个）")
+	cmd.Flags().StringArrayVar(&args.filter.attrs, "attr", nil, "只监视属性等于指定值的日志，格式 key=value（可重复）")
+	cmd.Flags().StringArrayVar(&args.filter.grep, "grep", nil, "只监视包含该文本的日志，由服务端过滤（可重复，需全部包含）")
+	_ = cmd.MarkFlagRequired("match")
+	return cmd
+}
+
+func runLogsWatch(ctx context.Context, cfg *config.Config, args logWatchArgs) error {
+	if args.exec == "" && args.webhook == "" {
+		return fmt.Errorf("请至少指定 --exec 或 --webhook")
+	}
+	re, err := regexp.Compile(args.match)
+	if err != nil {
+		return fmt.Errorf("--match: %w", err)
+	}
+	rate, per, err := parseRate(args.rate)
+	if err != nil {
+		return fmt.Errorf("--rate: %w", err)
+	}
+	trigger, err := logs.NewTrigger(logs.TriggerOptions{Match: re, Dedup: args.dedup, Rate: rate, Per: per})
+	if err != nil {
+		return err
+	}
+	filter, err := args.filter.build()
+	if err != nil {
+		return err
+	}
+	gqlClient, err := client.NewWithOptions(cfg, client.Options{Reconnect: client.ReconnectOptions{MaxAttempts: client.ReconnectUnlimited}})
+	if err != nil {
+		return fmt.Errorf("请先登录: %w", err)
+	}
+	projectResp, envID, err := resolveLinkedEnvironment(ctx, gqlClient, cfg, args.envArg)
+	if err != nil {
+		return err
+	}
+	var wanted map[string]string
+	if len(args.services) > 0 {
+		if wanted, err = resolveServices(projectResp, args.services); err != nil {
+			return err
+		}
+	}
+	names := map[string]string{}
+	for _, s := range projectResp.Project.Services.Edges {
+		names[s.Node.ID] = s.Node.Name
+	}
+
+	// 处理程序在单独的 goroutine 中依次执行，慢速命令不阻塞订阅；积压过多时丢弃
+	events := make(chan *logs.TriggerEvent, 64)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ev := range events {
+			if err := handleWatchEvent(ctx, args, ev); err != nil {
+				fmt.Fprintf(os.Stderr, "处理匹配日志失败: %v\n", err)
+			}
+		}
+	}()
+	defer func() {
+		close(events)
+		<-done
+	}()
+
+	fmt.Fprintf(os.Stderr, "正在监视日志，匹配 %s（Ctrl-C 停止）\n", re)
+	return subscribeEnvironmentRecords(ctx, gqlClient, envID, filter, 0, names, func(r logs.Record, _ []logAttr) {
+		if _, ok := wanted[r.Tags["serviceId"]]; wanted != nil && !ok {
+			return
+		}
+		ev, ok := trigger.Check(r)
+		if !ok {
+			return
+		}
+		select {
+		case events <- ev:
+		default:
+			// 丢弃的事件计入下一次触发的 suppressed
+			trigger.Drop(ev)
+			fmt.Fprintf(os.Stderr, "处理程序积压，丢弃匹配日志: %s\n", ev.Message)
+		}
+	})
+}
+
+// handleWatchEvent 执行命令并调用 webhook
+func handleWatchEvent(ctx context.Context, args logWatchArgs, ev *logs.TriggerEvent) error {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, args.timeout)
+	defer cancel()
+
+	var errs []string
+	if args.exec != "" {
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.CommandContext(ctx, "cmd", "/C", args.exec)
+		} else {
+			cmd = exec.CommandContext(ctx, "sh", "-c", args.exec)
+		}
+		cmd.Env = append(os.Environ(),
+			"RAILWAY_LOG_MESSAGE="+ev.Message,
+			"RAILWAY_LOG_MATCH="+ev.Match,
+			"RAILWAY_LOG_LEVEL="+ev.Level,
+			"RAILWAY_LOG_TIMESTAMP="+ev.Time.Format(time.RFC3339Nano),
+			"RAILWAY_LOG_SUPPRESSED="+strconv.Itoa(ev.Suppressed),
+			"RAILWAY_SERVICE_ID="+ev.Tags["serviceId"],
+			"RAILWAY_SERVICE_NAME="+ev.Tags["serviceName"],
+			"RAILWAY_DEPLOYMENT_ID="+ev.Tags["deploymentId"],
+			"RAILWAY_ENVIRONMENT_ID="+ev.Tags["environmentId"],
+			"RAILWAY_PROJECT_ID="+ev.Tags["projectId"],
+		)
+		cmd.Stdin = bytes.NewReader(payload)
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			errs = append(errs, fmt.Sprintf("--exec: %v", err))
+		}
+	}
+	if args.webhook != "" {
+		if err := postWebhook(ctx, args.webhook, payload); err != nil {
+			errs = append(errs, fmt.Sprintf("--webhook: %v", err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func postWebhook(ctx context.Context, url string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s", resp.Status)
+	}
+	return nil
+}
+
+// parseRate 解析 N/时长（如 10/1m）；只写 N 时按每分钟计，0 表示不限制
+func parseRate(s string) (int, time.Duration, error) {
+	n, d, ok := strings.Cut(strings.TrimSpace(s), "/")
+	count, err := strconv.Atoi(n)
+	if err != nil || count < 0 {
+		return 0, 0, fmt.Errorf("无效的频率 %q", s)
+	}
+	per := time.Minute
+	if ok {
+		if per, err = time.ParseDuration(d); err != nil || per <= 0 {
+			return 0, 0, fmt.Errorf("无效的频率 %q", s)
+		}
+	}
+	return count, per, nil
+}
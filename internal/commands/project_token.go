@@ -187,12 +187,12 @@ func runProjectTokenList(cfg *config.Config, projectID string) error {
 	var after string
 	total := 0
 	for {
-		variables := map[string]any{
-			"projectId": projectID,
-			"after":     nullIfEmpty(after),
+		variables := gql.ProjectTokensVariables{ProjectID: projectID}
+		if after != "" {
+			variables.After = &after
 		}
-		var resp gql.ProjectTokensResponse
-		if err := gqlClient.Query(context.Background(), gql.ProjectTokensQuery, variables, &resp); err != nil {
+		resp, err := gql.ProjectTokens(context.Background(), gqlClient, variables)
+		if err != nil {
 			return err
 		}
 		if len(resp.ProjectTokens.Edges) == 0 && total == 0 {
@@ -229,13 +229,6 @@ func extractIDToken(v any) (string, string, bool) {
 	return id, token, id != "" && token != ""
 }
 
-func nullIfEmpty(s string) any {
-	if strings.TrimSpace(s) == "" {
-		return nil
-	}
-	return s
-}
-
 func str(v any) string {
 	if s, ok := v.(string); ok {
 		return s
@@ -1,24 +1,16 @@
 package commands
 
 import (
-	"archive/tar"
-	"bytes"
-	"compress/gzip"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
-	"path/filepath"
+	"sort"
 	"strings"
-	"time"
+	"sync"
 
-	"github.com/briandowns/spinner"
-	"github.com/railwayapp/cli/internal/client"
 	"github.com/railwayapp/cli/internal/config"
-	"github.com/railwayapp/cli/internal/gql"
-	ignore "github.com/sabhiram/go-gitignore"
+	"github.com/railwayapp/cli/pkg/railway"
 	"github.com/spf13/cobra"
 )
 
@@ -40,7 +32,13 @@ func NewUpCommand(cfg *config.Config) *cobra.Command {
 		Short: "部署当前项目",
 		Long:  "将当前项目打包上传到Railway并触发部署。",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runUp(cfg, path, detach, ci, service, environment, noGitignore, pathAsRoot, verbose)
+			err := runUp(cmd.Context(), cfg, path, detach, ci, service, environment, noGitignore, pathAsRoot, verbose)
+			var exit *ExitError
+			if errors.As(err, &exit) {
+				// 失败详情已输出，只需以退出码结束
+				cmd.SilenceErrors, cmd.SilenceUsage = true, true
+			}
+			return err
 		},
 	}
 
@@ -56,9 +54,7 @@ func NewUpCommand(cfg *config.Config) *cobra.Command {
 	return cmd
 }
 
-func runUp(cfg *config.Config, path string, detach, ci bool, service, environment string, noGitignore, pathAsRoot, verbose bool) error {
-	httpClient := &http.Client{Timeout: 300 * time.Second}
-
+func runUp(ctx context.Context, cfg *config.Config, path string, detach, ci bool, service, environment string, noGitignore, pathAsRoot, verbose bool) error {
 	linked, err := cfg.GetLinkedProject()
 	if err != nil {
 		return fmt.Errorf("未找到已链接的项目: %w", err)
@@ -71,270 +67,108 @@ func runUp(cfg *config.Config, path string, detach, ci bool, service, environmen
 		service = *linked.Service
 	}
 
-	projectDir := linked.ProjectPath
-	deployRoot := projectDir
-	if path != "" {
-		if !filepath.IsAbs(path) {
-			deployRoot = filepath.Join(projectDir, path)
-		} else {
-			deployRoot = path
-		}
-	}
-
-	// 加载 ignore 规则
-	var gi *ignore.GitIgnore
-	if !noGitignore {
-		var patterns []string
-		// .railwayignore
-		if b, err := os.ReadFile(filepath.Join(projectDir, ".railwayignore")); err == nil {
-			lines := strings.Split(string(b), "\n")
-			patterns = append(patterns, lines...)
-		}
-		// .gitignore
-		if b, err := os.ReadFile(filepath.Join(projectDir, ".gitignore")); err == nil {
-			lines := strings.Split(string(b), "\n")
-			patterns = append(patterns, lines...)
-		}
-		if len(patterns) > 0 {
-			gi = ignore.CompileIgnoreLines(patterns...)
-		}
-	}
-
-	if verbose {
-		fmt.Println("Indexing & archiving...")
-	}
-
-	// 创建 tar.gz 归档
-	buf := &bytes.Buffer{}
-	gz := gzip.NewWriter(buf)
-	tw := tar.NewWriter(gz)
-
-	addFile := func(absPath, arcPath string, info os.FileInfo) error {
-		hdr, err := tar.FileInfoHeader(info, "")
-		if err != nil {
-			return err
-		}
-		hdr.Name = arcPath
-		if err := tw.WriteHeader(hdr); err != nil {
-			return err
-		}
-		f, err := os.Open(absPath)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
-		if _, err := io.Copy(tw, f); err != nil {
-			return err
-		}
-		return nil
+	rc, err := railway.New(railway.WithEnvironment(string(cfg.Environment())))
+	if err != nil {
+		return fmt.Errorf("请先登录: %w", err)
 	}
 
-	rootForPrefix := projectDir
-	if pathAsRoot {
-		rootForPrefix = deployRoot
-	}
+	ciMode := ci || config.IsCI()
+	return deployUp(ctx, rc, railway.UpParams{
+		ProjectID:     linked.Project,
+		EnvironmentID: environment,
+		ServiceID:     service,
+		ProjectRoot:   linked.ProjectPath,
+		Path:          path,
+		NoGitignore:   noGitignore,
+		PathAsRoot:    pathAsRoot,
+		Verbose:       verbose,
+		Detach:        detach || (!isTerminalStdout() && !ciMode),
+		CI:            ciMode,
+	})
+}
 
-	err = filepath.Walk(deployRoot, func(p string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		relFromRoot := p
-		if !pathAsRoot {
-			// 归档前缀统一从项目根开始
-			r, _ := filepath.Rel(rootForPrefix, p)
-			relFromRoot = r
+// deployUp 通过 Client.Up 打包上传并跟随部署，输出进度、日志与状态。部署失败、崩溃或被移除时
+// 输出构建失败摘要并返回退出码为 1 的 *ExitError；CI 模式下构建完成即返回
+func deployUp(ctx context.Context, rc *railway.Client, p railway.UpParams) error {
+	// 边打包边上传，归档不在内存中缓存
+	bar := newUploadProgressBar(os.Stderr)
+	var (
+		mu       sync.Mutex
+		progress railway.UpProgress
+	)
+	p.OnProgress = func(pr railway.UpProgress) {
+		mu.Lock()
+		progress = pr
+		mu.Unlock()
+		bar.update(pr)
+	}
+	p.OnUploaded = func(_, logsURL string) {
+		mu.Lock()
+		pr := progress
+		mu.Unlock()
+		bar.finish(pr)
+		if logsURL != "" {
+			fmt.Printf("  Build Logs: %s\n", logsURL)
 		} else {
-			// 使用 path 作为归档前缀
-			r, _ := filepath.Rel(rootForPrefix, p)
-			relFromRoot = r
-		}
-		if relFromRoot == "." {
-			return nil
-		}
-
-		// 忽略 .git 与 node_modules
-		base := filepath.Base(p)
-		if base == ".git" || base == "node_modules" {
-			if info.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
+			fmt.Println("  Build Logs: (响应未返回 logsUrl)")
 		}
-
-		// 应用 ignore 规则（相对项目根）
-		if gi != nil {
-			relForIgnore, _ := filepath.Rel(projectDir, p)
-			relForIgnore = filepath.ToSlash(relForIgnore)
-			if gi.MatchesPath(relForIgnore) {
-				if info.IsDir() {
-					return filepath.SkipDir
-				}
-				return nil
-			}
-		}
-
-		if info.Mode().IsRegular() {
-			// 归档路径以 "." 为根
-			arcPath := filepath.ToSlash(filepath.Join(".", relFromRoot))
-			return addFile(p, arcPath, info)
-		}
-		return nil
-	})
-	if err != nil {
-		return fmt.Errorf("打包失败: %w", err)
-	}
-	if err := tw.Close(); err != nil {
-		return err
-	}
-	if err := gz.Close(); err != nil {
-		return err
-	}
-
-	if verbose {
-		fmt.Printf("archive bytes: %d\n", buf.Len())
-	}
-
-	host := cfg.GetHost()
-	uploadURL := fmt.Sprintf("https://backboard.%s/project/%s/environment/%s/up?serviceId=%s", host, linked.Project, environment, service)
-
-	// 直接发送 tar.gz 数据
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, uploadURL, bytes.NewReader(buf.Bytes()))
-	if err != nil {
-		return err
-	}
-	req.Header.Set("Content-Type", "application/octet-stream")
-	if t := config.GetRailwayToken(); t != nil {
-		req.Header.Set("project-access-token", *t)
-	} else if t := cfg.GetRailwayAuthToken(); t != nil {
-		req.Header.Set("authorization", fmt.Sprintf("Bearer %s", *t))
-	}
-	req.Header.Set("x-source", fmt.Sprintf("railway-cli/%s", "4.6.1"))
-	req.Header.Set("user-agent", fmt.Sprintf("railway-cli/%s", "4.6.1"))
-
-	sp := spinner.New(spinner.CharSets[14], 90*time.Millisecond)
-	sp.Suffix = " Uploading"
-	sp.Start()
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		sp.Stop()
-		return fmt.Errorf("上传失败: %w", err)
 	}
-	defer resp.Body.Close()
-	sp.Stop()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		b, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("上传失败，状态码 %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
-	}
-
-	// 解析响应体（兼容下划线和驼峰）
-	bodyBytes, _ := io.ReadAll(resp.Body)
-	var raw map[string]any
-	_ = json.Unmarshal(bodyBytes, &raw)
 
-	getStr := func(keys ...string) string {
-		for _, k := range keys {
-			if v, ok := raw[k]; ok {
-				if s, ok := v.(string); ok {
-					return s
-				}
-			}
+	p.OnBuildLog = func(line string) { fmt.Println(line) }
+	if !p.CI {
+		p.OnDeploymentLogLine = func(l railway.LogLine) {
+			fmt.Println(formatAttrLog(l.Message, sortedAttrs(l.Attributes)))
 		}
-		return ""
 	}
-
-	deploymentID := getStr("deployment_id", "deploymentId")
-	logsURL := getStr("logs_url", "logsUrl")
-
-	if logsURL != "" {
-		fmt.Printf("  Build Logs: %s\n", logsURL)
-	} else {
-		fmt.Println("  Build Logs: (响应未返回 logsUrl)")
-	}
-
-	if detach {
+	p.OnStatus = func(status string) {
+		switch railway.DeploymentStatus(status) {
+		case railway.DeploymentStatusDeploying:
+			if p.CI {
+				fmt.Println("Build complete")
+			}
+		case railway.DeploymentStatusSuccess:
+			fmt.Println("Deploy complete")
+		case railway.DeploymentStatusSkipped:
+			fmt.Println("Deploy skipped")
+		case railway.DeploymentStatusFailed:
+			fmt.Println("Deploy failed")
+		case railway.DeploymentStatusCrashed:
+			fmt.Println("Deploy crashed")
+		case railway.DeploymentStatusRemoved:
+			fmt.Println("Deploy removed")
+		}
+	}
+
+	deploymentID, _, err := rc.Up(ctx, p)
+	bar.stop()
+	if err == nil {
 		return nil
 	}
-
-	ciMode := ci || config.IsCI()
-	if !isTerminalStdout() && !ciMode {
-		return nil
+	var failed *railway.DeploymentFailedError
+	if errors.As(err, &failed) {
+		printBuildFailureReport(os.Stderr, failed.Report, failed.DeploymentID)
+		return &ExitError{Code: 1, Err: err}
+	}
+	if errors.Is(err, railway.ErrDeploymentFailed) {
+		return &ExitError{Code: 1, Err: err}
 	}
-
 	if deploymentID == "" {
-		fmt.Println("警告：未获取到deployment_id，日志订阅可能无法开始")
-		return nil
+		return fmt.Errorf("上传失败: %w", err)
 	}
+	return fmt.Errorf("跟随部署失败: %w", err)
+}
 
-	// 并发启动日志订阅与状态订阅
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// 构建日志
-	go func() {
-		vars := map[string]interface{}{"deploymentId": deploymentID, "filter": "", "limit": 500}
-		_ = client.Subscribe(ctx, cfg, gql.BuildLogsSub, vars, func(data json.RawMessage) {
-			var pl gql.BuildLogsPayload
-			if err := json.Unmarshal(data, &pl); err == nil {
-				for _, l := range pl.BuildLogs {
-					fmt.Println(l.Message)
-					if ciMode && strings.HasPrefix(l.Message, "No changed files matched patterns") {
-						cancel()
-						os.Exit(0)
-					}
-				}
-			}
-		}, func(err error) { fmt.Fprintf(os.Stderr, "构建日志订阅错误: %v\n", err) })
-	}()
-
-	// 部署日志（非CI模式）
-	if !ciMode {
-		go func() {
-			vars := map[string]interface{}{"deploymentId": deploymentID, "filter": "", "limit": 500}
-			_ = client.Subscribe(ctx, cfg, gql.DeploymentLogsSub, vars, func(data json.RawMessage) {
-				var pl gql.DeploymentLogsPayload
-				if err := json.Unmarshal(data, &pl); err == nil {
-					for _, l := range pl.DeploymentLogs {
-						fmt.Println(formatAttrLog(l.Message, l.Attributes))
-					}
-				}
-			}, func(err error) { fmt.Fprintf(os.Stderr, "部署日志订阅错误: %v\n", err) })
-		}()
+// sortedAttrs 按键排序的日志属性
+func sortedAttrs(m map[string]string) []logAttr {
+	attrs := make([]logAttr, 0, len(m))
+	for k, v := range m {
+		attrs = append(attrs, logAttr{k, v})
 	}
-
-	// 状态订阅
-	statusDone := make(chan struct{})
-	go func() {
-		vars := map[string]interface{}{"id": deploymentID}
-		_ = client.Subscribe(ctx, cfg, gql.DeploymentStatusSub, vars, func(data json.RawMessage) {
-			var st gql.DeploymentStatusPayload
-			if err := json.Unmarshal(data, &st); err == nil {
-				switch strings.ToUpper(st.Deployment.Status) {
-				case "SUCCESS":
-					fmt.Println("Deploy complete")
-					if ciMode {
-						os.Exit(0)
-					}
-					close(statusDone)
-				case "FAILED":
-					fmt.Println("Deploy failed")
-					os.Exit(1)
-				case "CRASHED":
-					fmt.Println("Deploy crashed")
-					os.Exit(1)
-				}
-			}
-		}, func(err error) { fmt.Fprintf(os.Stderr, "状态订阅错误: %v\n", err) })
-	}()
-
-	<-statusDone
-	return nil
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Key < attrs[j].Key })
+	return attrs
 }
 
-func formatAttrLog(message string, attrs []struct {
-	Key   string `json:"key"`
-	Value string `json:"value"`
-}) string {
+func formatAttrLog(message string, attrs []logAttr) string {
 	if len(attrs) == 0 {
 		return message
 	}
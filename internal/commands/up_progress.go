@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/railwayapp/cli/internal/archive"
+)
+
+// uploadProgressBar 在终端中显示打包上传进度：完成比例、已发送大小与吞吐量。
+// 非终端输出时只在结束时打印一行汇总。
+type uploadProgressBar struct {
+	w        io.Writer
+	tty      bool
+	interval time.Duration
+
+	mu       sync.Mutex
+	last     time.Time
+	rendered bool
+}
+
+func newUploadProgressBar(w *os.File) *uploadProgressBar {
+	tty := false
+	if fi, err := w.Stat(); err == nil {
+		tty = fi.Mode()&os.ModeCharDevice != 0
+	}
+	return &uploadProgressBar{w: w, tty: tty, interval: 100 * time.Millisecond}
+}
+
+// update 作为 archive.Open 的进度回调；按 interval 节流重绘
+func (b *uploadProgressBar) update(p archive.Progress) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.tty || (!p.Done && time.Since(b.last) < b.interval) {
+		return
+	}
+	b.last = time.Now()
+	b.rendered = true
+	fmt.Fprintf(b.w, "\r\033[K%s", renderUploadProgress(p, 30))
+}
+
+// stop 清除进度条，之后不再重绘
+func (b *uploadProgressBar) stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.rendered {
+		fmt.Fprint(b.w, "\r\033[K")
+	}
+	b.rendered, b.tty = false, false
+}
+
+// finish 结束进度显示并输出汇总
+func (b *uploadProgressBar) finish(p archive.Progress) {
+	b.stop()
+	fmt.Fprintf(b.w, "已上传 %d 个文件，%s（源文件 %s），用时 %s，%s/s\n",
+		p.TotalFiles, formatBytes(p.Sent), formatBytes(p.TotalBytes), p.Elapsed.Round(100*time.Millisecond), formatBytes(throughput(p)))
+}
+
+// renderUploadProgress 生成 "上传中 [=======>      ]  45%  12.3 MiB  3.2 MiB/s"；
+// 完成比例按已读取的源文件字节估算，压缩后的总大小事先未知
+func renderUploadProgress(p archive.Progress, width int) string {
+	ratio := 1.0
+	if p.TotalBytes > 0 {
+		ratio = float64(p.Bytes) / float64(p.TotalBytes)
+	}
+	if p.Done || ratio > 1 {
+		ratio = 1
+	}
+	filled := int(ratio * float64(width))
+	bar := strings.Repeat("=", filled)
+	if filled < width {
+		bar += ">" + strings.Repeat(" ", width-filled-1)
+	}
+	return fmt.Sprintf("上传中 [%s] %3.0f%%  %s  %s/s", bar, ratio*100, formatBytes(p.Sent), formatBytes(throughput(p)))
+}
+
+func throughput(p archive.Progress) int64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+	return int64(float64(p.Sent) / p.Elapsed.Seconds())
+}
+
+// formatBytes 以 1024 进制格式化字节数
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/railwayapp/cli/pkg/railway"
+	"github.com/railwayapp/cli/pkg/railway/railwaytest"
+)
+
+// newUpFixture 创建没有自动推进的假服务器、项目与待上传目录
+func newUpFixture(t *testing.T) (*railwaytest.Server, *railway.Client, railway.UpParams) {
+	t.Helper()
+	srv := railwaytest.NewServer()
+	t.Cleanup(srv.Close)
+	c, err := railway.New(srv.ClientOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, env := srv.AddProject("demo")
+	svc := srv.AddService(p.ID, "web")
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return srv, c, railway.UpParams{ProjectID: p.ID, EnvironmentID: env.ID, ServiceID: svc.ID, ProjectRoot: root}
+}
+
+// drive 等待部署出现后依次写入构建日志并设置状态
+func drive(ctx context.Context, srv *railwaytest.Server, p railway.UpParams, buildLogs []string, statuses ...string) {
+	for ctx.Err() == nil {
+		if ds := srv.Deployments(p.EnvironmentID, p.ServiceID); len(ds) > 0 {
+			for _, l := range buildLogs {
+				_ = srv.AppendBuildLog(ds[0].ID, l, nil)
+			}
+			for _, s := range statuses {
+				_ = srv.SetDeploymentStatus(ds[0].ID, s)
+			}
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDeployUpResult(t *testing.T) {
+	tests := []struct {
+		name      string
+		ci        bool
+		buildLogs []string
+		statuses  []string
+		wantExit  bool
+	}{
+		// CI 模式在构建完成（进入 DEPLOYING）时退出，不等待 SUCCESS
+		{"ci build complete", true, []string{"Building image"}, []string{"BUILDING", "DEPLOYING"}, false},
+		{"ci no changes", true, []string{"No changed files matched patterns: src/**"}, nil, false},
+		{"ci build failed", true, []string{"error: go build failed"}, []string{"FAILED"}, true},
+		{"ci removed", true, nil, []string{"REMOVED"}, true},
+		{"success", false, []string{"Building image"}, []string{"DEPLOYING", "SUCCESS"}, false},
+		{"skipped", false, nil, []string{"SKIPPED"}, false},
+		{"crashed", false, nil, []string{"DEPLOYING", "CRASHED"}, true},
+		{"removed", false, nil, []string{"REMOVED"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c, p := newUpFixture(t)
+			p.CI = tt.ci
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			go drive(ctx, srv, p, tt.buildLogs, tt.statuses...)
+
+			err := deployUp(ctx, c, p)
+			if ctx.Err() != nil {
+				t.Fatalf("deployUp did not return before timeout: %v", err)
+			}
+			if !tt.wantExit {
+				if err != nil {
+					t.Fatalf("deployUp = %v", err)
+				}
+				return
+			}
+			var exit *ExitError
+			if !errors.As(err, &exit) || exit.Code != 1 {
+				t.Fatalf("deployUp = %v, want exit code 1", err)
+			}
+			if !errors.Is(err, railway.ErrDeploymentFailed) {
+				t.Fatalf("deployUp = %v, want ErrDeploymentFailed", err)
+			}
+		})
+	}
+}
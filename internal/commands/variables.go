@@ -130,44 +130,15 @@ func runVariables(cfg *config.Config, serviceArg, envArg string, kv bool, setPai
 	}
 
 	// 查询变量
-	// 由于 GraphQL 查询 VariablesForServiceDeployment 的返回是 map-like，这里沿用我们的 gql.VariablesForServiceDeploymentQuery 并解析
-	var varsResp map[string]any
-	if err := gqlClient.Query(context.Background(), gql.VariablesForServiceDeploymentQuery, map[string]any{
-		"projectId":     linked.Project,
-		"environmentId": environmentID,
-		"serviceId":     serviceID,
-	}, &varsResp); err != nil {
+	varsResp, err := gql.VariablesForServiceDeployment(context.Background(), gqlClient, gql.VariablesForServiceDeploymentVariables{
+		ProjectID:     linked.Project,
+		EnvironmentID: environmentID,
+		ServiceID:     serviceID,
+	})
+	if err != nil {
 		return fmt.Errorf("获取变量失败: %w", err)
 	}
-
-	// 兼容不同根键
-	var varsMap map[string]any
-	if v, ok := varsResp["variables"]; ok {
-		if m, ok := v.(map[string]any); ok {
-			varsMap = m
-		}
-	}
-	if varsMap == nil {
-		if v, ok := varsResp["variablesForServiceDeployment"]; ok {
-			if m, ok := v.(map[string]any); ok {
-				varsMap = m
-			}
-		}
-	}
-	if varsMap == nil {
-		return fmt.Errorf("响应格式不支持")
-	}
-
-	// 转换为 map[string]string（过滤空值）
-	out := map[string]string{}
-	for k, v := range varsMap {
-		if v == nil {
-			continue
-		}
-		if s, ok := v.(string); ok {
-			out[k] = s
-		}
-	}
+	out := varsResp.Variables
 	if len(out) == 0 {
 		fmt.Println("No variables found")
 		return nil
@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/mitchellh/go-homedir"
 )
@@ -51,11 +52,16 @@ type RailwayConfig struct {
 type Config struct {
 	rootConfig     RailwayConfig
 	rootConfigPath string
+	environment    Environment
 }
 
-// New 创建新的配置实例
+// New 创建新的配置实例（环境取自 RAILWAY_ENV）
 func New() (*Config, error) {
-	env := GetEnvironment()
+	return NewForEnvironment(GetEnvironment())
+}
+
+// NewForEnvironment 创建指定环境的配置实例，不读取也不修改 RAILWAY_ENV
+func NewForEnvironment(env Environment) (*Config, error) {
 	var configFile string
 
 	switch env {
@@ -76,6 +82,7 @@ func New() (*Config, error) {
 
 	config := &Config{
 		rootConfigPath: configPath,
+		environment:    env,
 		rootConfig: RailwayConfig{
 			Projects: make(map[string]LinkedProject),
 			User:     RailwayUser{},
@@ -98,8 +105,12 @@ func New() (*Config, error) {
 
 // GetEnvironment 获取当前环境
 func GetEnvironment() Environment {
-	env := os.Getenv("RAILWAY_ENV")
-	switch env {
+	return ParseEnvironment(os.Getenv("RAILWAY_ENV"))
+}
+
+// ParseEnvironment 将字符串解析为环境，未知值回退到 production
+func ParseEnvironment(env string) Environment {
+	switch strings.TrimSpace(env) {
 	case "production":
 		return EnvironmentProduction
 	case "staging":
@@ -144,9 +155,19 @@ func (c *Config) GetRailwayAuthToken() *string {
 	return nil
 }
 
+// Environment 返回配置实例所属环境
+func (c *Config) Environment() Environment {
+	return c.environment
+}
+
 // GetHost 获取Railway主机地址
 func (c *Config) GetHost() string {
-	switch GetEnvironment() {
+	return HostForEnvironment(c.environment)
+}
+
+// HostForEnvironment 返回环境对应的Railway主机地址
+func HostForEnvironment(env Environment) string {
+	switch env {
 	case EnvironmentProduction:
 		return "railway.com"
 	case EnvironmentStaging:
@@ -0,0 +1,20 @@
+package gql
+
+import "context"
+
+// operations/*.graphql 中的操作由 cmd/gqlgen 生成到 operations_gen.go，
+// 生成时会按 schema.graphql 校验字段、参数与变量类型。
+//
+// queries.go、mutations.go 与 subscriptions.go 中仍为手写操作：它们涉及的环境配置补丁、
+// 模板、工作流、卷与登录会话等类型不在 schema.graphql（公开 schema 的手工维护子集）中，
+// 部分操作还按服务端能力保留了互不兼容的变体（如 ServiceInstanceStop 与
+// ServiceInstanceStopByParams），无法用同一份 schema 校验。新增操作请写在 operations/ 下。
+//go:generate go run ../../cmd/gqlgen -schema schema.graphql -out operations_gen.go operations
+
+// Executor 执行 GraphQL 请求，*client.Client 实现该接口
+type Executor interface {
+	Query(ctx context.Context, query string, variables map[string]interface{}, response interface{}) error
+	QueryInternal(ctx context.Context, query string, variables map[string]interface{}, response interface{}) error
+	Mutate(ctx context.Context, mutation string, variables map[string]interface{}, response interface{}) error
+	MutateInternal(ctx context.Context, mutation string, variables map[string]interface{}, response interface{}) error
+}
@@ -201,71 +201,6 @@ type ProjectTokenResponse struct {
 	} `json:"projectToken"`
 }
 
-// Deployments GraphQL查询
-const DeploymentsQuery = `
-query Deployments($projectId: String!, $environmentId: String!, $serviceId: String) {
-  deployments(
-    input: {
-      projectId: $projectId
-      environmentId: $environmentId
-      serviceId: $serviceId
-    }
-    first: 20
-  ) {
-    edges {
-      node {
-        id
-        status
-        createdAt
-        updatedAt
-        staticUrl
-        url
-        service {
-          id
-          name
-        }
-      }
-    }
-  }
-}
-`
-
-// DeploymentsResponse 部署列表响应
-type DeploymentsResponse struct {
-	Deployments struct {
-		Edges []struct {
-			Node struct {
-				ID        string  `json:"id"`
-				Status    string  `json:"status"`
-				CreatedAt string  `json:"createdAt"`
-				UpdatedAt string  `json:"updatedAt"`
-				StaticURL *string `json:"staticUrl"`
-				URL       *string `json:"url"`
-				Service   struct {
-					ID   string `json:"id"`
-					Name string `json:"name"`
-				} `json:"service"`
-			} `json:"node"`
-		} `json:"edges"`
-	} `json:"deployments"`
-}
-
-// VariablesForServiceDeployment GraphQL查询
-const VariablesForServiceDeploymentQuery = `
-query VariablesForServiceDeployment($projectId: String!, $environmentId: String!, $serviceId: String!) {
-  variables(
-    projectId: $projectId
-    environmentId: $environmentId
-    serviceId: $serviceId
-  )
-}
-`
-
-// VariablesResponse 环境变量响应
-type VariablesResponse struct {
-	Variables map[string]*string `json:"variables"`
-}
-
 // Domains 查询
 const DomainsQuery = `
 query Domains($environmentId: String!, $projectId: String!, $serviceId: String!) {
@@ -458,76 +393,6 @@ type UserProjectsFullResponse struct {
 	} `json:"me"`
 }
 
-// ProjectTokens GraphQL查询（分页列表）
-const ProjectTokensQuery = `
-query ProjectTokens($projectId: String!, $after: String) {
-  projectTokens(projectId: $projectId, first: 50, after: $after) {
-    edges {
-      cursor
-      node {
-        id
-        name
-        project {
-          id
-          name
-        }
-        environment {
-          id
-          name
-        }
-      }
-    }
-    pageInfo {
-      hasNextPage
-      endCursor
-    }
-  }
-}
-`
-
-// ProjectTokensResponse 项目令牌列表响应
-type ProjectTokensResponse struct {
-	ProjectTokens struct {
-		Edges []struct {
-			Cursor string `json:"cursor"`
-			Node   struct {
-				ID          string                    `json:"id"`
-				Name        string                    `json:"name"`
-				Project     struct{ ID, Name string } `json:"project"`
-				Environment struct{ ID, Name string } `json:"environment"`
-			} `json:"node"`
-		} `json:"edges"`
-		PageInfo struct {
-			HasNextPage bool    `json:"hasNextPage"`
-			EndCursor   *string `json:"endCursor"`
-		} `json:"pageInfo"`
-	} `json:"projectTokens"`
-}
-
-// BackupsResponse 备份列表响应
-type BackupsResponse struct {
-	Backups struct {
-		Edges []struct {
-			Cursor string `json:"cursor"`
-			Node   struct {
-				ID        string `json:"id"`
-				Name      string `json:"name"`
-				CreatedAt string `json:"createdAt"`
-				Status    string `json:"status"`
-				Size      *int64 `json:"size"`
-				Service   struct {
-					ID   string `json:"id"`
-					Name string `json:"name"`
-				} `json:"service"`
-			} `json:"node"`
-		} `json:"edges"`
-		PageInfo struct {
-			HasNextPage bool    `json:"hasNextPage"`
-			EndCursor   *string `json:"endCursor"`
-		} `json:"pageInfo"`
-	} `json:"backups"`
-}
-
 // WorkflowStatus GraphQL查询
 const WorkflowStatusQuery = `
 query WorkflowStatus($workflowId: String!) {
@@ -940,3 +805,40 @@ type AllProjectUsageResponse struct {
 		} `json:"edges"`
 	} `json:"projects"`
 }
+
+// MutationFieldsQuery 内省 Mutation 根字段（参数名与返回类型），用于探测后端支持的变更形态
+const MutationFieldsQuery = `
+query MutationFields {
+  __schema {
+    mutationType {
+      fields {
+        name
+        args { name }
+        type { name kind ofType { name kind } }
+      }
+    }
+  }
+}
+`
+
+// MutationFieldsResponse Mutation 根字段内省响应
+type MutationFieldsResponse struct {
+	Schema struct {
+		MutationType *struct {
+			Fields []struct {
+				Name string `json:"name"`
+				Args []struct {
+					Name string `json:"name"`
+				} `json:"args"`
+				Type struct {
+					Name   *string `json:"name"`
+					Kind   string  `json:"kind"`
+					OfType *struct {
+						Name *string `json:"name"`
+						Kind string  `json:"kind"`
+					} `json:"ofType"`
+				} `json:"type"`
+			} `json:"fields"`
+		} `json:"mutationType"`
+	} `json:"__schema"`
+}
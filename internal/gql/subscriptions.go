@@ -122,3 +122,19 @@ type EnvironmentLogsPayload struct {
 		} `json:"attributes"`
 	} `json:"environmentLogs"`
 }
+
+// EnvironmentLogsResumeLimit 环境日志重新订阅时从断线位置起补取的最大行数
+const EnvironmentLogsResumeLimit = 1000
+
+// ResumeEnvironmentLogs 改写 EnvironmentLogsSub 的变量，使重新订阅从 after（含）起补取断线期间的日志后继续实时推送；
+// after 为空（尚未收到日志）时保持原变量
+func ResumeEnvironmentLogs(vars map[string]interface{}, after string) {
+	if after == "" {
+		return
+	}
+	vars["afterDate"] = after
+	vars["afterLimit"] = EnvironmentLogsResumeLimit
+	vars["beforeLimit"] = 0
+	delete(vars, "beforeDate")
+	delete(vars, "anchorDate")
+}
@@ -0,0 +1,43 @@
+package gql
+
+// 模板配置（Template.serializedConfig 的 JSON 结构）相关数据结构，供 deploy 命令与 SDK 共用
+type DeserializedServiceNetworking struct {
+	ServiceDomains map[string]interface{} `json:"serviceDomains,omitempty"`
+	TCPProxies     map[string]interface{} `json:"tcpProxies,omitempty"`
+}
+
+type DeserializedServiceVolumeMount struct {
+	MountPath string `json:"mountPath"`
+}
+
+type DeserializedServiceVariable struct {
+	DefaultValue *string `json:"defaultValue,omitempty"`
+	Value        *string `json:"value,omitempty"`
+	Description  *string `json:"description,omitempty"`
+	IsOptional   *bool   `json:"isOptional,omitempty"`
+}
+
+type DeserializedServiceDeploy struct {
+	HealthcheckPath *string `json:"healthcheckPath,omitempty"`
+	StartCommand    *string `json:"startCommand,omitempty"`
+}
+
+type DeserializedServiceSource struct {
+	Image         *string `json:"image,omitempty"`
+	Repo          *string `json:"repo,omitempty"`
+	RootDirectory *string `json:"rootDirectory,omitempty"`
+}
+
+type DeserializedTemplateService struct {
+	Deploy       *DeserializedServiceDeploy                 `json:"deploy,omitempty"`
+	Icon         *string                                    `json:"icon,omitempty"`
+	Name         string                                     `json:"name"`
+	Networking   *DeserializedServiceNetworking             `json:"networking,omitempty"`
+	Source       *DeserializedServiceSource                 `json:"source,omitempty"`
+	Variables    map[string]*DeserializedServiceVariable    `json:"variables,omitempty"`
+	VolumeMounts map[string]*DeserializedServiceVolumeMount `json:"volumeMounts,omitempty"`
+}
+
+type DeserializedTemplateConfig struct {
+	Services map[string]*DeserializedTemplateService `json:"services,omitempty"`
+}
@@ -0,0 +1,307 @@
+// Package gqlgen 读取 GraphQL schema（SDL）与 .graphql 操作文件，
+// 校验操作与 schema 一致后生成带类型的 Go 请求/响应代码（见 cmd/gqlgen）。
+package gqlgen
+
+import "strings"
+
+// Type GraphQL 类型引用，如 String!、[Deployment!]
+type Type struct {
+	Name    string // 列表类型为空
+	Elem    *Type  // 非 nil 表示列表
+	NonNull bool
+	Pos     Pos
+}
+
+// NamedType 去掉列表与非空修饰后的类型名
+func (t *Type) NamedType() string {
+	for t.Elem != nil {
+		t = t.Elem
+	}
+	return t.Name
+}
+
+func (t *Type) String() string {
+	var s string
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	} else {
+		s = t.Name
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// TypeKind schema 类型种类
+type TypeKind int
+
+const (
+	KindScalar TypeKind = iota
+	KindObject
+	KindInterface
+	KindUnion
+	KindEnum
+	KindInput
+)
+
+func (k TypeKind) String() string {
+	return [...]string{"scalar", "type", "interface", "union", "enum", "input"}[k]
+}
+
+// Definition schema 中的命名类型
+type Definition struct {
+	Kind       TypeKind
+	Name       string
+	Fields     []*FieldDef   // object / interface
+	Inputs     []*InputValue // input
+	Interfaces []string
+	Members    []string // union
+	EnumValues []string
+	Pos        Pos
+}
+
+// Field 按名称查找字段
+func (d *Definition) Field(name string) *FieldDef {
+	for _, f := range d.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Input 按名称查找输入字段
+func (d *Definition) Input(name string) *InputValue {
+	for _, f := range d.Inputs {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// IsLeaf 标量与枚举没有子选择集
+func (d *Definition) IsLeaf() bool { return d.Kind == KindScalar || d.Kind == KindEnum }
+
+// IsComposite object / interface / union 需要子选择集
+func (d *Definition) IsComposite() bool {
+	return d.Kind == KindObject || d.Kind == KindInterface || d.Kind == KindUnion
+}
+
+// FieldDef 对象字段
+type FieldDef struct {
+	Name string
+	Args []*InputValue
+	Type *Type
+	Pos  Pos
+}
+
+// Arg 按名称查找参数
+func (f *FieldDef) Arg(name string) *InputValue {
+	for _, a := range f.Args {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// InputValue 字段参数或 input 字段
+type InputValue struct {
+	Name       string
+	Type       *Type
+	HasDefault bool
+	Pos        Pos
+}
+
+// Schema 解析后的 schema
+type Schema struct {
+	Types        map[string]*Definition
+	Query        string
+	Mutation     string
+	Subscription string
+}
+
+// builtinScalars GraphQL 内置标量
+var builtinScalars = []string{"String", "Int", "Float", "Boolean", "ID"}
+
+// RootType 返回操作类型对应的根类型
+func (s *Schema) RootType(operation string) *Definition {
+	switch operation {
+	case "query":
+		return s.Types[s.Query]
+	case "mutation":
+		return s.Types[s.Mutation]
+	case "subscription":
+		return s.Types[s.Subscription]
+	}
+	return nil
+}
+
+// possible 判断 sub 是否可作为 super 的具体类型（相同、实现接口或属于联合）
+func (s *Schema) possible(super, sub string) bool {
+	if super == sub {
+		return true
+	}
+	d := s.Types[super]
+	if d == nil {
+		return false
+	}
+	switch d.Kind {
+	case KindUnion:
+		for _, m := range d.Members {
+			if m == sub {
+				return true
+			}
+		}
+	case KindInterface:
+		if sd := s.Types[sub]; sd != nil {
+			for _, i := range sd.Interfaces {
+				if i == super {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// overlaps 判断两个复合类型是否存在共同的具体类型（片段展开的适用性）
+func (s *Schema) overlaps(a, b string) bool {
+	if s.possible(a, b) || s.possible(b, a) {
+		return true
+	}
+	for name := range s.Types {
+		if s.possible(a, name) && s.possible(b, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Document 一组操作文件
+type Document struct {
+	Operations []*Operation
+	Fragments  []*Fragment
+}
+
+// Fragment 查找命名片段
+func (d *Document) Fragment(name string) *Fragment {
+	for _, f := range d.Fragments {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Operation query / mutation / subscription 定义
+type Operation struct {
+	Type       string
+	Name       string
+	Variables  []*VariableDef
+	Selections []Selection
+	// Endpoint 由操作上方的 "# endpoint: internal" 注释指定，默认 public
+	Endpoint string
+	// Doc 操作上方的其余注释
+	Doc    string
+	Source string
+	Pos    Pos
+}
+
+// VariableDef 操作变量
+type VariableDef struct {
+	Name       string
+	Type       *Type
+	HasDefault bool
+	Pos        Pos
+}
+
+// Fragment 命名片段，生成同名的 Go 类型
+type Fragment struct {
+	Name       string
+	On         string
+	Selections []Selection
+	Doc        string
+	Source     string
+	Pos        Pos
+}
+
+// Selection 字段、片段展开或内联片段
+type Selection interface{ position() Pos }
+
+// Field 字段选择
+type Field struct {
+	Alias      string
+	Name       string
+	Arguments  []*Argument
+	Selections []Selection
+	Pos        Pos
+}
+
+// ResponseKey 响应中的键（别名优先）
+func (f *Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// FragmentSpread ...Name
+type FragmentSpread struct {
+	Name string
+	Pos  Pos
+}
+
+// InlineFragment ... on Type { }
+type InlineFragment struct {
+	On         string
+	Selections []Selection
+	Pos        Pos
+}
+
+func (f *Field) position() Pos          { return f.Pos }
+func (f *FragmentSpread) position() Pos { return f.Pos }
+func (f *InlineFragment) position() Pos { return f.Pos }
+
+// Argument 字段参数
+type Argument struct {
+	Name  string
+	Value *Value
+	Pos   Pos
+}
+
+// ValueKind 字面量种类
+type ValueKind int
+
+const (
+	ValueVariable ValueKind = iota
+	ValueInt
+	ValueFloat
+	ValueString
+	ValueBoolean
+	ValueNull
+	ValueEnum
+	ValueList
+	ValueObject
+)
+
+// Value 参数值
+type Value struct {
+	Kind   ValueKind
+	Raw    string // 变量名 / 字面量文本
+	List   []*Value
+	Fields []*ObjectField
+	Pos    Pos
+}
+
+// ObjectField 对象字面量中的字段
+type ObjectField struct {
+	Name  string
+	Value *Value
+	Pos   Pos
+}
+
+// trimSource 去掉首尾空白，便于拼接生成的文档常量
+func trimSource(s string) string { return strings.TrimSpace(s) }
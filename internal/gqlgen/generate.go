@@ -0,0 +1,432 @@
+package gqlgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Config 代码生成参数
+type Config struct {
+	// Package 生成文件的包名
+	Package string
+	// Scalars 自定义标量到 Go 类型的映射，覆盖 DefaultScalars
+	Scalars map[string]string
+	// Command 写入文件头的生成命令（便于提示如何重新生成）
+	Command string
+}
+
+// DefaultScalars 内置及常用自定义标量的 Go 类型
+var DefaultScalars = map[string]string{
+	"String":               "string",
+	"ID":                   "string",
+	"Int":                  "int",
+	"Float":                "float64",
+	"Boolean":              "bool",
+	"DateTime":             "string",
+	"BigInt":               "int64",
+	"JSON":                 "json.RawMessage",
+	"EnvironmentVariables": "map[string]string",
+}
+
+// Generate 校验并生成 Go 源码（已 gofmt）
+func Generate(s *Schema, doc *Document, cfg Config) ([]byte, error) {
+	if err := Validate(s, doc); err != nil {
+		return nil, err
+	}
+	g := &generator{s: s, doc: doc, cfg: cfg, scalars: map[string]string{}, inputs: map[string]bool{}}
+	for k, v := range DefaultScalars {
+		g.scalars[k] = v
+	}
+	for k, v := range cfg.Scalars {
+		g.scalars[k] = v
+	}
+	src, err := g.generate()
+	if err != nil {
+		return nil, err
+	}
+	out, err := format.Source(src)
+	if err != nil {
+		return nil, fmt.Errorf("gofmt generated code: %w\n%s", err, src)
+	}
+	return out, nil
+}
+
+type generator struct {
+	s       *Schema
+	doc     *Document
+	cfg     Config
+	scalars map[string]string
+	inputs  map[string]bool
+	body    bytes.Buffer
+	err     error
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.body, format, args...)
+}
+
+func (g *generator) fail(pos Pos, format string, args ...interface{}) {
+	if g.err == nil {
+		g.err = errorf(pos, format, args...)
+	}
+}
+
+func (g *generator) generate() ([]byte, error) {
+	for _, f := range g.doc.Fragments {
+		on := g.s.Types[f.On]
+		doc := f.Doc
+		if doc == "" {
+			doc = fmt.Sprintf("片段 %s（on %s）", f.Name, f.On)
+		}
+		g.printf("// %s %s\n", f.Name, doc)
+		g.printf("type %s %s\n\n", f.Name, g.structType(on, f.Selections))
+	}
+	for _, op := range g.doc.Operations {
+		g.operation(op)
+	}
+	// 变量引用到的 input 类型（含嵌套）
+	var inputs []string
+	for name := range g.inputs {
+		inputs = append(inputs, name)
+	}
+	sort.Strings(inputs)
+	for i := 0; i < len(inputs); i++ {
+		g.inputStruct(g.s.Types[inputs[i]], &inputs)
+	}
+	if g.err != nil {
+		return nil, g.err
+	}
+
+	var head bytes.Buffer
+	head.WriteString("// Code generated by cmd/gqlgen. DO NOT EDIT.\n")
+	if g.cfg.Command != "" {
+		fmt.Fprintf(&head, "// 重新生成：%s\n", g.cfg.Command)
+	}
+	fmt.Fprintf(&head, "\npackage %s\n\n", g.cfg.Package)
+	body := g.body.String()
+	var imports []string
+	if strings.Contains(body, "context.Context") {
+		imports = append(imports, `"context"`)
+	}
+	if strings.Contains(body, "json.RawMessage") {
+		imports = append(imports, `"encoding/json"`)
+	}
+	if len(imports) > 0 {
+		fmt.Fprintf(&head, "import (\n%s\n)\n\n", strings.Join(imports, "\n"))
+	}
+	head.WriteString(body)
+	return head.Bytes(), nil
+}
+
+var operationSuffix = map[string]string{"query": "Query", "mutation": "Mutation", "subscription": "Subscription"}
+
+func (g *generator) operation(op *Operation) {
+	constName := op.Name + operationSuffix[op.Type]
+	respName := op.Name + "Response"
+	if op.Type == "subscription" {
+		respName = op.Name + "Payload"
+	}
+
+	// 文档常量：操作 + 递归引用的片段
+	src := op.Source
+	for _, f := range g.fragmentsOf(op.Selections) {
+		src += "\n\n" + f.Source
+	}
+	if strings.Contains(src, "`") {
+		g.fail(op.Pos, "operation %s contains a backquote", op.Name)
+		return
+	}
+	doc := op.Doc
+	if doc == "" {
+		doc = op.Name + " " + op.Type
+	}
+	g.printf("// %s %s\n", constName, doc)
+	g.printf("const %s = `\n%s\n`\n\n", constName, src)
+
+	if len(op.Variables) > 0 {
+		g.printf("// %sVariables %s 的变量\n", op.Name, op.Name)
+		g.printf("type %sVariables struct {\n", op.Name)
+		for _, vd := range op.Variables {
+			tag := vd.Name
+			if !vd.Type.NonNull {
+				tag += ",omitempty"
+			}
+			g.printf("%s %s `json:\"%s\"`\n", goName(vd.Name), g.inputType(vd.Type, vd.Pos), tag)
+		}
+		g.printf("}\n\n")
+		g.printf("// Map 转换为请求变量；未设置的可空变量不发送（由服务端使用默认值）\n")
+		g.printf("func (v %sVariables) Map() map[string]interface{} {\n", op.Name)
+		g.printf("m := make(map[string]interface{}, %d)\n", len(op.Variables))
+		for _, vd := range op.Variables {
+			field := "v." + goName(vd.Name)
+			switch {
+			case vd.Type.NonNull:
+				g.printf("m[%q] = %s\n", vd.Name, field)
+			case g.nilable(vd.Type):
+				g.printf("if %s != nil {\nm[%q] = %s\n}\n", field, vd.Name, field)
+			default:
+				g.printf("if %s != nil {\nm[%q] = *%s\n}\n", field, vd.Name, field)
+			}
+		}
+		g.printf("return m\n}\n\n")
+	}
+
+	root := g.s.RootType(op.Type)
+	g.printf("// %s %s 的响应\n", respName, op.Name)
+	g.printf("type %s %s\n\n", respName, g.structType(root, op.Selections))
+
+	if op.Type == "subscription" {
+		return
+	}
+	method := map[string]string{"query": "Query", "mutation": "Mutate"}[op.Type]
+	if op.Endpoint == "internal" {
+		method += "Internal"
+	}
+	params, vars := "", "nil"
+	if len(op.Variables) > 0 {
+		params, vars = fmt.Sprintf(", v %sVariables", op.Name), "v.Map()"
+	}
+	g.printf("// %s 执行 %s %s\n", op.Name, op.Name, op.Type)
+	g.printf("func %s(ctx context.Context, c Executor%s) (*%s, error) {\n", op.Name, params, respName)
+	g.printf("var resp %s\n", respName)
+	g.printf("if err := c.%s(ctx, %s, %s, &resp); err != nil {\nreturn nil, err\n}\n", method, constName, vars)
+	g.printf("return &resp, nil\n}\n\n")
+}
+
+// fragmentsOf 按首次出现顺序返回选择集递归引用的片段
+func (g *generator) fragmentsOf(sels []Selection) []*Fragment {
+	var out []*Fragment
+	seen := map[string]bool{}
+	var walk func([]Selection)
+	walk = func(sels []Selection) {
+		for _, sel := range sels {
+			switch sel := sel.(type) {
+			case *Field:
+				walk(sel.Selections)
+			case *InlineFragment:
+				walk(sel.Selections)
+			case *FragmentSpread:
+				if seen[sel.Name] {
+					continue
+				}
+				seen[sel.Name] = true
+				f := g.doc.Fragment(sel.Name)
+				out = append(out, f)
+				walk(f.Selections)
+			}
+		}
+	}
+	walk(sels)
+	return out
+}
+
+// outField 合并片段后的响应字段
+type outField struct {
+	key  string
+	def  *FieldDef // __typename 为 nil
+	sels []Selection
+}
+
+// collect 将字段、内联片段与片段展开合并为扁平字段列表（同名响应键合并子选择集）
+func (g *generator) collect(parent *Definition, sels []Selection, out *[]*outField, index map[string]*outField) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *Field:
+			key := sel.ResponseKey()
+			if of, ok := index[key]; ok {
+				of.sels = append(of.sels, sel.Selections...)
+				continue
+			}
+			of := &outField{key: key, sels: append([]Selection(nil), sel.Selections...)}
+			if sel.Name != "__typename" {
+				of.def = parent.Field(sel.Name)
+			}
+			index[key] = of
+			*out = append(*out, of)
+		case *InlineFragment:
+			cond := parent
+			if sel.On != "" {
+				cond = g.s.Types[sel.On]
+			}
+			g.collect(cond, sel.Selections, out, index)
+		case *FragmentSpread:
+			f := g.doc.Fragment(sel.Name)
+			g.collect(g.s.Types[f.On], f.Selections, out, index)
+		}
+	}
+}
+
+func (g *generator) structType(parent *Definition, sels []Selection) string {
+	var fields []*outField
+	g.collect(parent, sels, &fields, map[string]*outField{})
+	var b strings.Builder
+	b.WriteString("struct {\n")
+	for _, f := range fields {
+		typ := "string"
+		if f.def != nil {
+			typ = g.outputType(f.def.Type, f.sels)
+		}
+		fmt.Fprintf(&b, "%s %s `json:\"%s\"`\n", goName(f.key), typ, f.key)
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+func (g *generator) outputType(t *Type, sels []Selection) string {
+	if t.Elem != nil {
+		return "[]" + g.outputType(t.Elem, sels)
+	}
+	d := g.s.Types[t.Name]
+	var base string
+	nilable := false
+	switch {
+	case d.Kind == KindScalar:
+		base, nilable = g.scalar(d.Name, t.Pos)
+	case d.Kind == KindEnum:
+		base = "string"
+	default:
+		// 选择集仅为单个同类型片段展开时直接使用片段类型
+		if len(sels) == 1 {
+			if fs, ok := sels[0].(*FragmentSpread); ok && g.doc.Fragment(fs.Name).On == d.Name {
+				base = fs.Name
+				break
+			}
+		}
+		base = g.structType(d, sels)
+	}
+	if !t.NonNull && !nilable {
+		return "*" + base
+	}
+	return base
+}
+
+// inputType 变量与 input 字段的 Go 类型；引用的 input 类型会被记录并生成
+func (g *generator) inputType(t *Type, pos Pos) string {
+	if t.Elem != nil {
+		return "[]" + g.inputType(t.Elem, pos)
+	}
+	d := g.s.Types[t.Name]
+	var base string
+	nilable := false
+	switch d.Kind {
+	case KindScalar:
+		base, nilable = g.scalar(d.Name, pos)
+	case KindEnum:
+		base = "string"
+	case KindInput:
+		base = d.Name
+		g.inputs[d.Name] = true
+	}
+	if !t.NonNull && !nilable {
+		return "*" + base
+	}
+	return base
+}
+
+func (g *generator) inputStruct(d *Definition, queue *[]string) {
+	g.printf("// %s GraphQL input %s\n", d.Name, d.Name)
+	g.printf("type %s struct {\n", d.Name)
+	for _, f := range d.Inputs {
+		before := len(g.inputs)
+		typ := g.inputType(f.Type, f.Pos)
+		if len(g.inputs) != before {
+			// 嵌套 input 追加到队列末尾
+			for name := range g.inputs {
+				if !containsString(*queue, name) {
+					*queue = append(*queue, name)
+				}
+			}
+		}
+		tag := f.Name
+		if !f.Type.NonNull {
+			tag += ",omitempty"
+		}
+		g.printf("%s %s `json:\"%s\"`\n", goName(f.Name), typ, tag)
+	}
+	g.printf("}\n\n")
+}
+
+// nilable 可空变量对应的 Go 类型本身可为 nil（切片、映射、RawMessage）时不再加指针
+func (g *generator) nilable(t *Type) bool {
+	if t.Elem != nil {
+		return true
+	}
+	d := g.s.Types[t.Name]
+	if d.Kind != KindScalar {
+		return false
+	}
+	_, n := g.scalar(d.Name, t.Pos)
+	return n
+}
+
+func (g *generator) scalar(name string, pos Pos) (goType string, nilable bool) {
+	goType, ok := g.scalars[name]
+	if !ok {
+		g.fail(pos, "no Go type for scalar %q; pass -scalar %s=<GoType>", name, name)
+		return "interface{}", true
+	}
+	nilable = strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[") || goType == "json.RawMessage" || goType == "interface{}"
+	return goType, nilable
+}
+
+// initialisms 按 Go 命名习惯整体大写的词
+var initialisms = map[string]bool{
+	"API": true, "CPU": true, "DNS": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true,
+	"JSON": true, "MB": true, "GB": true, "SHA": true, "SSL": true, "TCP": true, "TLS": true,
+	"UI": true, "URI": true, "URL": true, "UUID": true,
+}
+
+// goName 将 GraphQL 名称转换为导出的 Go 标识符（projectId → ProjectID，staticUrl → StaticURL）
+func goName(s string) string {
+	var words []string
+	var cur []rune
+	rs := []rune(s)
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, string(cur))
+			cur = nil
+		}
+	}
+	for i, r := range rs {
+		switch {
+		case r == '_' || r == '-':
+			flush()
+			continue
+		case unicode.IsUpper(r) && len(cur) > 0:
+			prevLower := unicode.IsLower(cur[len(cur)-1]) || unicode.IsDigit(cur[len(cur)-1])
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if prevLower || nextLower {
+				flush()
+			}
+		}
+		cur = append(cur, r)
+	}
+	flush()
+	var b strings.Builder
+	for _, w := range words {
+		if up := strings.ToUpper(w); initialisms[up] {
+			b.WriteString(up)
+			continue
+		}
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	if b.Len() == 0 {
+		return "X"
+	}
+	return b.String()
+}
+
+func containsString(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
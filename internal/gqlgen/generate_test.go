@@ -0,0 +1,69 @@
+package gqlgen
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+)
+
+// go test ./internal/gqlgen -run Generate -update 重新生成 testdata/operations.golden
+var update = flag.Bool("update", false, "rewrite testdata/operations.golden")
+
+func TestGenerateGolden(t *testing.T) {
+	s := loadSchema(t)
+	b, err := os.ReadFile("testdata/operations.graphql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := &Document{}
+	if err := ParseDocument(doc, "operations.graphql", string(b)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Generate(s, doc, Config{Package: "gql", Command: "gqlgen -schema schema.graphql operations.graphql"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const golden = "testdata/operations.golden"
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("generated code differs from %s (run with -update):\n%s", golden, got)
+	}
+}
+
+func TestGenerateScalarOverride(t *testing.T) {
+	s := loadSchema(t)
+	doc := &Document{}
+	src := `query Deployment($id: String!) { deployment(id: $id) { createdAt } }`
+	if err := ParseDocument(doc, "q.graphql", src); err != nil {
+		t.Fatal(err)
+	}
+	out, err := Generate(s, doc, Config{Package: "gql", Scalars: map[string]string{"DateTime": "time.Time"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "time.Time") {
+		t.Fatalf("DateTime override not applied:\n%s", out)
+	}
+}
+
+func TestGenerateRejectsInvalid(t *testing.T) {
+	s := loadSchema(t)
+	doc := &Document{}
+	if err := ParseDocument(doc, "q.graphql", `query Q { deployment(id: "x") { nope } }`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Generate(s, doc, Config{Package: "gql"}); err == nil || !strings.Contains(err.Error(), `cannot query field "nope"`) {
+		t.Fatalf("err = %v", err)
+	}
+}
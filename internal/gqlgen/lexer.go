@@ -0,0 +1,254 @@
+package gqlgen
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Pos 源文件中的位置（行列从 1 开始）
+type Pos struct {
+	File   string
+	Line   int
+	Column int
+	Offset int
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// Error 带位置的解析或校验错误
+type Error struct {
+	Pos Pos
+	Msg string
+}
+
+func (e *Error) Error() string { return e.Pos.String() + ": " + e.Msg }
+
+func errorf(pos Pos, format string, args ...interface{}) *Error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokPunct
+	tokName
+	tokInt
+	tokFloat
+	tokString
+)
+
+type token struct {
+	kind  tokenKind
+	value string // 字符串 token 为解码后的值
+	pos   Pos
+	end   int // 结束字节偏移
+}
+
+// comment 源文件中的 # 注释（不含 #）
+type comment struct {
+	line int
+	text string
+}
+
+type lexer struct {
+	src      string
+	file     string
+	off      int
+	line     int
+	lineOff  int
+	comments []comment
+}
+
+func newLexer(file, src string) *lexer {
+	return &lexer{src: src, file: file, line: 1}
+}
+
+func (l *lexer) pos() Pos {
+	return Pos{File: l.file, Line: l.line, Column: utf8.RuneCountInString(l.src[l.lineOff:l.off]) + 1, Offset: l.off}
+}
+
+func (l *lexer) newline() {
+	l.line++
+	l.lineOff = l.off
+}
+
+// skip 跳过空白、逗号、BOM 与注释
+func (l *lexer) skip() {
+	for l.off < len(l.src) {
+		switch c := l.src[l.off]; c {
+		case ' ', '\t', ',':
+			l.off++
+		case '\n':
+			l.off++
+			l.newline()
+		case '\r':
+			l.off++
+			if l.off < len(l.src) && l.src[l.off] == '\n' {
+				l.off++
+			}
+			l.newline()
+		case '#':
+			start := l.off + 1
+			for l.off < len(l.src) && l.src[l.off] != '\n' && l.src[l.off] != '\r' {
+				l.off++
+			}
+			l.comments = append(l.comments, comment{line: l.line, text: strings.TrimSpace(l.src[start:l.off])})
+		default:
+			if strings.HasPrefix(l.src[l.off:], "\uFEFF") {
+				l.off += len("\uFEFF")
+				continue
+			}
+			return
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	l.skip()
+	pos := l.pos()
+	if l.off >= len(l.src) {
+		return token{kind: tokEOF, pos: pos, end: l.off}, nil
+	}
+	c := l.src[l.off]
+	switch {
+	case strings.IndexByte("!$&()=:@[]{}|", c) >= 0:
+		l.off++
+		return token{kind: tokPunct, value: string(c), pos: pos, end: l.off}, nil
+	case c == '.':
+		if strings.HasPrefix(l.src[l.off:], "...") {
+			l.off += 3
+			return token{kind: tokPunct, value: "...", pos: pos, end: l.off}, nil
+		}
+		return token{}, errorf(pos, "unexpected character %q", c)
+	case c == '_' || isLetter(c):
+		start := l.off
+		for l.off < len(l.src) && (l.src[l.off] == '_' || isLetter(l.src[l.off]) || isDigit(l.src[l.off])) {
+			l.off++
+		}
+		return token{kind: tokName, value: l.src[start:l.off], pos: pos, end: l.off}, nil
+	case c == '-' || isDigit(c):
+		return l.number(pos)
+	case c == '"':
+		if strings.HasPrefix(l.src[l.off:], `"""`) {
+			return l.blockString(pos)
+		}
+		return l.string(pos)
+	}
+	return token{}, errorf(pos, "unexpected character %q", c)
+}
+
+func (l *lexer) number(pos Pos) (token, error) {
+	start := l.off
+	kind := tokInt
+	if l.src[l.off] == '-' {
+		l.off++
+	}
+	digits := func() int {
+		n := 0
+		for l.off < len(l.src) && isDigit(l.src[l.off]) {
+			l.off++
+			n++
+		}
+		return n
+	}
+	if digits() == 0 {
+		return token{}, errorf(pos, "invalid number")
+	}
+	if l.off < len(l.src) && l.src[l.off] == '.' {
+		kind = tokFloat
+		l.off++
+		if digits() == 0 {
+			return token{}, errorf(pos, "invalid number")
+		}
+	}
+	if l.off < len(l.src) && (l.src[l.off] == 'e' || l.src[l.off] == 'E') {
+		kind = tokFloat
+		l.off++
+		if l.off < len(l.src) && (l.src[l.off] == '+' || l.src[l.off] == '-') {
+			l.off++
+		}
+		if digits() == 0 {
+			return token{}, errorf(pos, "invalid number")
+		}
+	}
+	return token{kind: kind, value: l.src[start:l.off], pos: pos, end: l.off}, nil
+}
+
+func (l *lexer) string(pos Pos) (token, error) {
+	l.off++ // "
+	var b strings.Builder
+	for l.off < len(l.src) {
+		c := l.src[l.off]
+		switch {
+		case c == '"':
+			l.off++
+			return token{kind: tokString, value: b.String(), pos: pos, end: l.off}, nil
+		case c == '\n' || c == '\r':
+			return token{}, errorf(pos, "unterminated string")
+		case c == '\\':
+			if l.off+1 >= len(l.src) {
+				return token{}, errorf(pos, "unterminated string")
+			}
+			esc := l.src[l.off+1]
+			l.off += 2
+			switch esc {
+			case '"', '\\', '/':
+				b.WriteByte(esc)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if l.off+4 > len(l.src) {
+					return token{}, errorf(pos, "invalid unicode escape")
+				}
+				var r rune
+				if _, err := fmt.Sscanf(l.src[l.off:l.off+4], "%04x", &r); err != nil {
+					return token{}, errorf(pos, "invalid unicode escape")
+				}
+				b.WriteRune(r)
+				l.off += 4
+			default:
+				return token{}, errorf(pos, "invalid escape \\%c", esc)
+			}
+		default:
+			b.WriteByte(c)
+			l.off++
+		}
+	}
+	return token{}, errorf(pos, "unterminated string")
+}
+
+func (l *lexer) blockString(pos Pos) (token, error) {
+	l.off += 3
+	start := l.off
+	for l.off < len(l.src) {
+		switch {
+		case strings.HasPrefix(l.src[l.off:], `\"""`):
+			l.off += 4
+		case strings.HasPrefix(l.src[l.off:], `"""`):
+			raw := l.src[start:l.off]
+			l.off += 3
+			return token{kind: tokString, value: strings.ReplaceAll(raw, `\"""`, `"""`), pos: pos, end: l.off}, nil
+		case l.src[l.off] == '\n':
+			l.off++
+			l.newline()
+		default:
+			l.off++
+		}
+	}
+	return token{}, errorf(pos, "unterminated block string")
+}
+
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
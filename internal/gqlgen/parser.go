@@ -0,0 +1,572 @@
+package gqlgen
+
+import (
+	"strings"
+)
+
+type parser struct {
+	lex  *lexer
+	tok  token
+	prev token
+	err  error
+}
+
+func newParser(file, src string) *parser {
+	p := &parser{lex: newLexer(file, src)}
+	p.advance()
+	return p
+}
+
+func (p *parser) advance() {
+	if p.err != nil {
+		return
+	}
+	p.prev = p.tok
+	t, err := p.lex.next()
+	if err != nil {
+		p.err = err
+		p.tok = token{kind: tokEOF, pos: p.lex.pos()}
+		return
+	}
+	p.tok = t
+}
+
+func (p *parser) fail(pos Pos, format string, args ...interface{}) {
+	if p.err == nil {
+		p.err = errorf(pos, format, args...)
+	}
+	p.tok = token{kind: tokEOF, pos: pos}
+}
+
+func (p *parser) peek(punct string) bool {
+	return p.tok.kind == tokPunct && p.tok.value == punct
+}
+
+func (p *parser) peekName(name string) bool {
+	return p.tok.kind == tokName && p.tok.value == name
+}
+
+func (p *parser) skip(punct string) bool {
+	if p.peek(punct) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(punct string) {
+	if !p.skip(punct) {
+		p.fail(p.tok.pos, "expected %q, found %s", punct, describe(p.tok))
+	}
+}
+
+func (p *parser) name() string {
+	if p.tok.kind != tokName {
+		p.fail(p.tok.pos, "expected name, found %s", describe(p.tok))
+		return ""
+	}
+	v := p.tok.value
+	p.advance()
+	return v
+}
+
+func (p *parser) keyword(kw string) {
+	if !p.peekName(kw) {
+		p.fail(p.tok.pos, "expected %q, found %s", kw, describe(p.tok))
+		return
+	}
+	p.advance()
+}
+
+func describe(t token) string {
+	switch t.kind {
+	case tokEOF:
+		return "end of file"
+	case tokString:
+		return "string"
+	}
+	return "\"" + t.value + "\""
+}
+
+// ---- 类型引用与值 ----
+
+func (p *parser) typeRef() *Type {
+	pos := p.tok.pos
+	var t *Type
+	if p.skip("[") {
+		elem := p.typeRef()
+		p.expect("]")
+		t = &Type{Elem: elem, Pos: pos}
+	} else {
+		t = &Type{Name: p.name(), Pos: pos}
+	}
+	if p.skip("!") {
+		t.NonNull = true
+	}
+	return t
+}
+
+func (p *parser) value(constant bool) *Value {
+	pos := p.tok.pos
+	switch p.tok.kind {
+	case tokPunct:
+		switch p.tok.value {
+		case "$":
+			if constant {
+				p.fail(pos, "variables are not allowed here")
+				return nil
+			}
+			p.advance()
+			return &Value{Kind: ValueVariable, Raw: p.name(), Pos: pos}
+		case "[":
+			p.advance()
+			v := &Value{Kind: ValueList, Pos: pos}
+			for !p.skip("]") {
+				if p.tok.kind == tokEOF {
+					p.fail(p.tok.pos, "unterminated list")
+					return v
+				}
+				v.List = append(v.List, p.value(constant))
+			}
+			return v
+		case "{":
+			p.advance()
+			v := &Value{Kind: ValueObject, Pos: pos}
+			for !p.skip("}") {
+				if p.tok.kind == tokEOF {
+					p.fail(p.tok.pos, "unterminated object")
+					return v
+				}
+				fpos := p.tok.pos
+				name := p.name()
+				p.expect(":")
+				v.Fields = append(v.Fields, &ObjectField{Name: name, Value: p.value(constant), Pos: fpos})
+			}
+			return v
+		}
+	case tokInt:
+		v := &Value{Kind: ValueInt, Raw: p.tok.value, Pos: pos}
+		p.advance()
+		return v
+	case tokFloat:
+		v := &Value{Kind: ValueFloat, Raw: p.tok.value, Pos: pos}
+		p.advance()
+		return v
+	case tokString:
+		v := &Value{Kind: ValueString, Raw: p.tok.value, Pos: pos}
+		p.advance()
+		return v
+	case tokName:
+		v := &Value{Raw: p.tok.value, Pos: pos}
+		switch p.tok.value {
+		case "true", "false":
+			v.Kind = ValueBoolean
+		case "null":
+			v.Kind = ValueNull
+		default:
+			v.Kind = ValueEnum
+		}
+		p.advance()
+		return v
+	}
+	p.fail(pos, "unexpected %s", describe(p.tok))
+	return nil
+}
+
+// directives 解析并忽略指令（@include/@skip/@deprecated 等）
+func (p *parser) directives(constant bool) {
+	for p.skip("@") {
+		p.name()
+		if p.skip("(") {
+			for !p.skip(")") {
+				if p.tok.kind == tokEOF {
+					p.fail(p.tok.pos, "unterminated arguments")
+					return
+				}
+				p.name()
+				p.expect(":")
+				p.value(constant)
+			}
+		}
+	}
+}
+
+// ---- SDL ----
+
+// ParseSchema 解析 SDL；未声明 schema { } 时根类型默认为 Query / Mutation / Subscription
+func ParseSchema(file, src string) (*Schema, error) {
+	p := newParser(file, src)
+	s := &Schema{Types: map[string]*Definition{}}
+	for _, name := range builtinScalars {
+		s.Types[name] = &Definition{Kind: KindScalar, Name: name}
+	}
+	explicitRoots := false
+	for p.tok.kind != tokEOF {
+		if p.tok.kind == tokString { // 描述
+			p.advance()
+			continue
+		}
+		pos := p.tok.pos
+		kw := p.name()
+		switch kw {
+		case "schema":
+			explicitRoots = true
+			p.directives(true)
+			p.expect("{")
+			for !p.skip("}") {
+				if p.tok.kind == tokEOF {
+					p.fail(p.tok.pos, "unterminated schema definition")
+					break
+				}
+				op := p.name()
+				p.expect(":")
+				name := p.name()
+				switch op {
+				case "query":
+					s.Query = name
+				case "mutation":
+					s.Mutation = name
+				case "subscription":
+					s.Subscription = name
+				default:
+					p.fail(pos, "unknown operation type %q", op)
+				}
+			}
+		case "scalar":
+			d := &Definition{Kind: KindScalar, Name: p.name(), Pos: pos}
+			p.directives(true)
+			p.define(s, d)
+		case "type", "interface":
+			d := &Definition{Kind: KindObject, Name: p.name(), Pos: pos}
+			if kw == "interface" {
+				d.Kind = KindInterface
+			}
+			if p.peekName("implements") {
+				p.advance()
+				p.skip("&")
+				d.Interfaces = append(d.Interfaces, p.name())
+				for p.skip("&") {
+					d.Interfaces = append(d.Interfaces, p.name())
+				}
+			}
+			p.directives(true)
+			d.Fields = p.fieldDefs()
+			p.define(s, d)
+		case "union":
+			d := &Definition{Kind: KindUnion, Name: p.name(), Pos: pos}
+			p.directives(true)
+			p.expect("=")
+			p.skip("|")
+			d.Members = append(d.Members, p.name())
+			for p.skip("|") {
+				d.Members = append(d.Members, p.name())
+			}
+			p.define(s, d)
+		case "enum":
+			d := &Definition{Kind: KindEnum, Name: p.name(), Pos: pos}
+			p.directives(true)
+			p.expect("{")
+			for !p.skip("}") {
+				if p.tok.kind == tokEOF {
+					p.fail(p.tok.pos, "unterminated enum")
+					break
+				}
+				if p.tok.kind == tokString {
+					p.advance()
+					continue
+				}
+				d.EnumValues = append(d.EnumValues, p.name())
+				p.directives(true)
+			}
+			p.define(s, d)
+		case "input":
+			d := &Definition{Kind: KindInput, Name: p.name(), Pos: pos}
+			p.directives(true)
+			p.expect("{")
+			for !p.skip("}") {
+				if p.tok.kind == tokEOF {
+					p.fail(p.tok.pos, "unterminated input")
+					break
+				}
+				d.Inputs = append(d.Inputs, p.inputValue())
+			}
+			p.define(s, d)
+		case "directive":
+			p.expect("@")
+			p.name()
+			if p.skip("(") {
+				for !p.skip(")") {
+					if p.tok.kind == tokEOF {
+						break
+					}
+					p.inputValue()
+				}
+			}
+			if p.peekName("repeatable") {
+				p.advance()
+			}
+			p.keyword("on")
+			p.skip("|")
+			p.name()
+			for p.skip("|") {
+				p.name()
+			}
+		default:
+			if p.err == nil {
+				p.fail(pos, "unsupported schema definition %q", kw)
+			}
+		}
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	if !explicitRoots {
+		for _, root := range []struct {
+			name string
+			dst  *string
+		}{{"Query", &s.Query}, {"Mutation", &s.Mutation}, {"Subscription", &s.Subscription}} {
+			if _, ok := s.Types[root.name]; ok {
+				*root.dst = root.name
+			}
+		}
+	}
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (p *parser) define(s *Schema, d *Definition) {
+	if d.Name == "" {
+		return
+	}
+	if prev, ok := s.Types[d.Name]; ok && prev.Pos.File != "" {
+		p.fail(d.Pos, "type %q already defined at %s", d.Name, prev.Pos)
+		return
+	}
+	s.Types[d.Name] = d
+}
+
+func (p *parser) fieldDefs() []*FieldDef {
+	var out []*FieldDef
+	if !p.skip("{") {
+		return nil
+	}
+	for !p.skip("}") {
+		if p.tok.kind == tokEOF {
+			p.fail(p.tok.pos, "unterminated field list")
+			return out
+		}
+		if p.tok.kind == tokString {
+			p.advance()
+			continue
+		}
+		f := &FieldDef{Pos: p.tok.pos, Name: p.name()}
+		if p.skip("(") {
+			for !p.skip(")") {
+				if p.tok.kind == tokEOF {
+					p.fail(p.tok.pos, "unterminated arguments")
+					return out
+				}
+				f.Args = append(f.Args, p.inputValue())
+			}
+		}
+		p.expect(":")
+		f.Type = p.typeRef()
+		p.directives(true)
+		out = append(out, f)
+	}
+	return out
+}
+
+func (p *parser) inputValue() *InputValue {
+	if p.tok.kind == tokString {
+		p.advance()
+	}
+	iv := &InputValue{Pos: p.tok.pos, Name: p.name()}
+	p.expect(":")
+	iv.Type = p.typeRef()
+	if p.skip("=") {
+		iv.HasDefault = true
+		p.value(true)
+	}
+	p.directives(true)
+	return iv
+}
+
+// check 校验 schema 中引用的类型均已定义
+func (s *Schema) check() error {
+	var ref func(t *Type) error
+	ref = func(t *Type) error {
+		if t.Elem != nil {
+			return ref(t.Elem)
+		}
+		if _, ok := s.Types[t.Name]; !ok {
+			return errorf(t.Pos, "unknown type %q", t.Name)
+		}
+		return nil
+	}
+	for _, d := range s.Types {
+		for _, f := range d.Fields {
+			if err := ref(f.Type); err != nil {
+				return err
+			}
+			for _, a := range f.Args {
+				if err := ref(a.Type); err != nil {
+					return err
+				}
+			}
+		}
+		for _, f := range d.Inputs {
+			if err := ref(f.Type); err != nil {
+				return err
+			}
+		}
+		for _, m := range append(append([]string(nil), d.Members...), d.Interfaces...) {
+			if _, ok := s.Types[m]; !ok {
+				return errorf(d.Pos, "unknown type %q", m)
+			}
+		}
+	}
+	if s.Query == "" || s.Types[s.Query] == nil {
+		return errorf(Pos{}, "schema has no query type")
+	}
+	return nil
+}
+
+// ---- 操作文档 ----
+
+// ParseDocument 解析一个 .graphql 操作文件并追加到 doc
+func ParseDocument(doc *Document, file, src string) error {
+	p := newParser(file, src)
+	for p.tok.kind != tokEOF {
+		pos := p.tok.pos
+		switch {
+		case p.peekName("query"), p.peekName("mutation"), p.peekName("subscription"):
+			op := &Operation{Type: p.tok.value, Pos: pos}
+			op.Endpoint, op.Doc = leadingComments(p.lex.comments, pos.Line)
+			p.advance()
+			if p.tok.kind != tokName {
+				p.fail(p.tok.pos, "operations must be named")
+				break
+			}
+			op.Name = p.name()
+			if p.skip("(") {
+				for !p.skip(")") {
+					if p.tok.kind == tokEOF {
+						p.fail(p.tok.pos, "unterminated variable definitions")
+						break
+					}
+					vpos := p.tok.pos
+					p.expect("$")
+					v := &VariableDef{Name: p.name(), Pos: vpos}
+					p.expect(":")
+					v.Type = p.typeRef()
+					if p.skip("=") {
+						v.HasDefault = true
+						p.value(true)
+					}
+					p.directives(true)
+					op.Variables = append(op.Variables, v)
+				}
+			}
+			p.directives(false)
+			op.Selections = p.selectionSet()
+			op.Source = trimSource(src[pos.Offset:p.prev.end])
+			doc.Operations = append(doc.Operations, op)
+		case p.peekName("fragment"):
+			p.advance()
+			f := &Fragment{Pos: pos}
+			_, f.Doc = leadingComments(p.lex.comments, pos.Line)
+			f.Name = p.name()
+			p.keyword("on")
+			f.On = p.name()
+			p.directives(false)
+			f.Selections = p.selectionSet()
+			f.Source = trimSource(src[pos.Offset:p.prev.end])
+			doc.Fragments = append(doc.Fragments, f)
+		case p.peek("{"):
+			p.fail(pos, "anonymous operations are not supported; name the operation")
+		default:
+			p.fail(pos, "unexpected %s", describe(p.tok))
+		}
+	}
+	return p.err
+}
+
+// leadingComments 读取紧邻定义上方的注释：
+// "endpoint: internal" 指定端点，其余行作为生成代码的文档注释
+func leadingComments(comments []comment, line int) (endpoint, doc string) {
+	endpoint = "public"
+	var lines []string
+	for i := len(comments) - 1; i >= 0; i-- {
+		c := comments[i]
+		if c.line >= line {
+			continue
+		}
+		if c.line < line-1 {
+			break
+		}
+		line = c.line
+		if v, ok := strings.CutPrefix(c.text, "endpoint:"); ok {
+			endpoint = strings.TrimSpace(v)
+			continue
+		}
+		lines = append([]string{c.text}, lines...)
+	}
+	return endpoint, strings.Join(lines, " ")
+}
+
+func (p *parser) selectionSet() []Selection {
+	var out []Selection
+	p.expect("{")
+	for !p.skip("}") {
+		if p.tok.kind == tokEOF {
+			p.fail(p.tok.pos, "unterminated selection set")
+			return out
+		}
+		pos := p.tok.pos
+		if p.skip("...") {
+			if p.peekName("on") {
+				p.advance()
+				f := &InlineFragment{On: p.name(), Pos: pos}
+				p.directives(false)
+				f.Selections = p.selectionSet()
+				out = append(out, f)
+				continue
+			}
+			if p.peek("{") || p.peek("@") {
+				f := &InlineFragment{Pos: pos}
+				p.directives(false)
+				f.Selections = p.selectionSet()
+				out = append(out, f)
+				continue
+			}
+			out = append(out, &FragmentSpread{Name: p.name(), Pos: pos})
+			p.directives(false)
+			continue
+		}
+		f := &Field{Pos: pos, Name: p.name()}
+		if p.skip(":") {
+			f.Alias, f.Name = f.Name, p.name()
+		}
+		if p.skip("(") {
+			for !p.skip(")") {
+				if p.tok.kind == tokEOF {
+					p.fail(p.tok.pos, "unterminated arguments")
+					return out
+				}
+				apos := p.tok.pos
+				name := p.name()
+				p.expect(":")
+				f.Arguments = append(f.Arguments, &Argument{Name: name, Value: p.value(false), Pos: apos})
+			}
+		}
+		p.directives(false)
+		if p.peek("{") {
+			f.Selections = p.selectionSet()
+		}
+		out = append(out, f)
+	}
+	return out
+}
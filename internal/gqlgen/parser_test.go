@@ -0,0 +1,179 @@
+package gqlgen
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func loadSchema(t *testing.T) *Schema {
+	t.Helper()
+	b, err := os.ReadFile("testdata/schema.graphql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := ParseSchema("schema.graphql", string(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestParseSchema(t *testing.T) {
+	s := loadSchema(t)
+	if s.Query != "Query" || s.Mutation != "Mutation" || s.Subscription != "Subscription" {
+		t.Fatalf("roots = %q %q %q", s.Query, s.Mutation, s.Subscription)
+	}
+	kinds := map[string]TypeKind{
+		"String": KindScalar, "DateTime": KindScalar, "Status": KindEnum, "DeployInput": KindInput,
+		"Node": KindInterface, "Deployment": KindObject, "SearchResult": KindUnion,
+	}
+	for name, want := range kinds {
+		if d := s.Types[name]; d == nil || d.Kind != want {
+			t.Errorf("%s: got %v, want %s", name, d, want)
+		}
+	}
+	if got := s.Types["Status"].EnumValues; strings.Join(got, ",") != "BUILDING,SUCCESS,FAILED" {
+		t.Errorf("enum values = %v", got)
+	}
+	if got := s.Types["SearchResult"].Members; strings.Join(got, ",") != "Deployment,Service" {
+		t.Errorf("union members = %v", got)
+	}
+	search := s.Types["Query"].Field("search")
+	if search == nil || search.Type.String() != "[SearchResult!]!" || search.Type.NamedType() != "SearchResult" {
+		t.Fatalf("search = %+v", search)
+	}
+	if first := search.Arg("first"); first == nil || !first.HasDefault || first.Type.String() != "Int" {
+		t.Errorf("search(first) = %+v", first)
+	}
+	if in := s.Types["DeployInput"].Input("tags"); in == nil || in.Type.String() != "[String!]" {
+		t.Errorf("DeployInput.tags = %+v", in)
+	}
+	if !s.possible("Node", "Deployment") || s.possible("Node", "Query") || !s.overlaps("SearchResult", "Service") {
+		t.Error("interface/union membership")
+	}
+}
+
+func TestParseSchemaRoots(t *testing.T) {
+	s, err := ParseSchema("s.graphql", `
+schema { query: Root }
+type Root { ok: Boolean }
+type Query { ignored: Boolean }
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Query != "Root" || s.Mutation != "" {
+		t.Fatalf("roots = %q %q", s.Query, s.Mutation)
+	}
+}
+
+func TestParseDocument(t *testing.T) {
+	b, err := os.ReadFile("testdata/operations.graphql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := &Document{}
+	if err := ParseDocument(doc, "operations.graphql", string(b)); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Operations) != 4 || len(doc.Fragments) != 1 {
+		t.Fatalf("%d operations, %d fragments", len(doc.Operations), len(doc.Fragments))
+	}
+	dep := doc.Operations[0]
+	if dep.Type != "query" || dep.Name != "Deployment" || dep.Endpoint != "public" || dep.Doc != "部署详情" {
+		t.Errorf("Deployment = %+v", dep)
+	}
+	if !strings.HasPrefix(dep.Source, "query Deployment(") || !strings.HasSuffix(dep.Source, "}") {
+		t.Errorf("source = %q", dep.Source)
+	}
+	if dep.Pos.Line != 7 || dep.Pos.Column != 1 {
+		t.Errorf("pos = %s", dep.Pos)
+	}
+	search := doc.Operations[1]
+	if search.Endpoint != "internal" || search.Doc != "按名称搜索部署与服务" {
+		t.Errorf("Search endpoint/doc = %q %q", search.Endpoint, search.Doc)
+	}
+	if len(search.Variables) != 2 || search.Variables[1].Name != "first" || search.Variables[1].Type.String() != "Int" {
+		t.Errorf("Search variables = %+v", search.Variables)
+	}
+	f := search.Selections[0].(*Field)
+	if len(f.Selections) != 3 {
+		t.Fatalf("search selections = %d", len(f.Selections))
+	}
+	if inl, ok := f.Selections[1].(*InlineFragment); !ok || inl.On != "Deployment" {
+		t.Errorf("inline fragment = %+v", f.Selections[1])
+	}
+	if doc.Fragment("ServiceFields") == nil || doc.Fragment("Missing") != nil {
+		t.Error("Fragment lookup")
+	}
+}
+
+func TestParseAliasAndValues(t *testing.T) {
+	doc := &Document{}
+	err := ParseDocument(doc, "q.graphql", `query Q($id: String! = "x") {
+  a: deployment(id: $id) { id }
+  b: search(term: """block
+string""", first: -3) { __typename }
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := doc.Operations[0]
+	if !op.Variables[0].HasDefault {
+		t.Error("default value not recorded")
+	}
+	a, b := op.Selections[0].(*Field), op.Selections[1].(*Field)
+	if a.ResponseKey() != "a" || a.Name != "deployment" || b.ResponseKey() != "b" {
+		t.Errorf("aliases = %q/%q %q", a.ResponseKey(), a.Name, b.ResponseKey())
+	}
+	if len(b.Arguments) != 2 || b.Arguments[0].Value.Kind != ValueString || b.Arguments[1].Value.Kind != ValueInt {
+		t.Errorf("args = %+v", b.Arguments)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{"anonymous", `{ deployment(id: "x") { id } }`, "q.graphql:1:1: anonymous operations are not supported"},
+		{"unnamed", `query { a }`, "q.graphql:1:7: operations must be named"},
+		{"unterminated selection", "query Q {\n  a {\n    b\n", "q.graphql:4:1: unterminated selection set"},
+		{"unterminated string", `query Q { a(x: "abc) }`, "q.graphql:1:16: unterminated string"},
+		{"bad escape", `query Q { a(x: "\q") }`, `q.graphql:1:16: invalid escape \q`},
+		{"bad character", "query Q { a ? }", `q.graphql:1:13: unexpected character '?'`},
+		{"bad number", "query Q { a(x: 1.) }", "q.graphql:1:16: invalid number"},
+		{"variable in default", "query Q($a: Int = $b) { a }", "q.graphql:1:19: variables are not allowed here"},
+		{"missing colon", "query Q($a Int) { a }", `q.graphql:1:12: expected ":", found "Int"`},
+		{"stray token", "query Q { a } }", `q.graphql:1:15: unexpected "}"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ParseDocument(&Document{}, "q.graphql", tt.src)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{"unknown field type", "type Query { a: Missing }", `s.graphql:1:17: unknown type "Missing"`},
+		{"unknown union member", "type Query { a: Int }\nunion U = Query | Nope", `s.graphql:2:1: unknown type "Nope"`},
+		{"duplicate type", "type Query { a: Int }\ntype Query { b: Int }", `s.graphql:2:1: type "Query" already defined at s.graphql:1:1`},
+		{"no query", "type Mutation { a: Int }", "schema has no query type"},
+		{"unsupported", "type Query { a: Int }\nextend type Query { b: Int }", `s.graphql:2:1: unsupported schema definition "extend"`},
+		{"unknown root", "schema { fetch: Query }\ntype Query { a: Int }", `s.graphql:1:1: unknown operation type "fetch"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSchema("s.graphql", tt.src)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,358 @@
+package gqlgen
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Validate 校验操作文档与 schema 一致：字段与参数存在、必填参数已提供、变量已声明且类型兼容、
+// 叶子/复合字段的选择集正确、片段存在且可应用。返回按位置排序的全部错误
+func Validate(s *Schema, doc *Document) error {
+	v := &validator{s: s, doc: doc}
+	v.run()
+	if len(v.errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(v.errs, func(i, j int) bool {
+		a, b := v.errs[i].Pos, v.errs[j].Pos
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	errs := make([]error, len(v.errs))
+	for i, e := range v.errs {
+		errs[i] = e
+	}
+	return errors.Join(errs...)
+}
+
+type validator struct {
+	s    *Schema
+	doc  *Document
+	errs []*Error
+
+	// 当前操作上下文
+	vars map[string]*VariableDef
+	used map[string]bool
+}
+
+func (v *validator) errorf(pos Pos, format string, args ...interface{}) {
+	v.errs = append(v.errs, errorf(pos, format, args...))
+}
+
+func (v *validator) run() {
+	names := map[string]Pos{}
+	for _, op := range v.doc.Operations {
+		if prev, ok := names[op.Name]; ok {
+			v.errorf(op.Pos, "operation %q already defined at %s", op.Name, prev)
+		}
+		names[op.Name] = op.Pos
+	}
+	frags := map[string]Pos{}
+	for _, f := range v.doc.Fragments {
+		if prev, ok := frags[f.Name]; ok {
+			v.errorf(f.Pos, "fragment %q already defined at %s", f.Name, prev)
+		}
+		frags[f.Name] = f.Pos
+		d := v.s.Types[f.On]
+		switch {
+		case d == nil:
+			v.errorf(f.Pos, "fragment %q: unknown type %q", f.Name, f.On)
+		case !d.IsComposite():
+			v.errorf(f.Pos, "fragment %q cannot condition on %s %q", f.Name, d.Kind, f.On)
+		}
+	}
+	for _, f := range v.doc.Fragments {
+		v.checkFragmentCycle(f, map[string]bool{})
+	}
+
+	for _, op := range v.doc.Operations {
+		root := v.s.RootType(op.Type)
+		if root == nil {
+			v.errorf(op.Pos, "schema does not support %s operations", op.Type)
+			continue
+		}
+		if op.Endpoint != "public" && op.Endpoint != "internal" {
+			v.errorf(op.Pos, "unknown endpoint %q (want public or internal)", op.Endpoint)
+		}
+		v.vars = map[string]*VariableDef{}
+		v.used = map[string]bool{}
+		for _, vd := range op.Variables {
+			if _, dup := v.vars[vd.Name]; dup {
+				v.errorf(vd.Pos, "variable $%s declared twice", vd.Name)
+			}
+			v.vars[vd.Name] = vd
+			d := v.s.Types[vd.Type.NamedType()]
+			switch {
+			case d == nil:
+				v.errorf(vd.Type.Pos, "variable $%s: unknown type %q", vd.Name, vd.Type.NamedType())
+			case d.Kind != KindScalar && d.Kind != KindEnum && d.Kind != KindInput:
+				v.errorf(vd.Type.Pos, "variable $%s: %s %q is not an input type", vd.Name, d.Kind, d.Name)
+			}
+		}
+		v.selections(root, op.Selections, map[string]bool{})
+		for _, vd := range op.Variables {
+			if !v.used[vd.Name] {
+				v.errorf(vd.Pos, "variable $%s is declared but never used in %s", vd.Name, op.Name)
+			}
+		}
+	}
+}
+
+func (v *validator) checkFragmentCycle(f *Fragment, visiting map[string]bool) {
+	if visiting[f.Name] {
+		v.errorf(f.Pos, "fragment %q spreads itself", f.Name)
+		return
+	}
+	visiting[f.Name] = true
+	defer delete(visiting, f.Name)
+	var walk func(sels []Selection)
+	walk = func(sels []Selection) {
+		for _, sel := range sels {
+			switch sel := sel.(type) {
+			case *Field:
+				walk(sel.Selections)
+			case *InlineFragment:
+				walk(sel.Selections)
+			case *FragmentSpread:
+				if next := v.doc.Fragment(sel.Name); next != nil {
+					v.checkFragmentCycle(next, visiting)
+				}
+			}
+		}
+	}
+	walk(f.Selections)
+}
+
+// selections 校验 parent 类型上的选择集；seen 防止片段循环导致的无限递归
+func (v *validator) selections(parent *Definition, sels []Selection, seen map[string]bool) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *Field:
+			v.field(parent, sel)
+		case *InlineFragment:
+			cond := parent
+			if sel.On != "" {
+				cond = v.s.Types[sel.On]
+				if cond == nil {
+					v.errorf(sel.Pos, "unknown type %q", sel.On)
+					continue
+				}
+				if !cond.IsComposite() || !v.s.overlaps(parent.Name, cond.Name) {
+					v.errorf(sel.Pos, "fragment on %q can never apply to %q", cond.Name, parent.Name)
+					continue
+				}
+			}
+			v.selections(cond, sel.Selections, seen)
+		case *FragmentSpread:
+			f := v.doc.Fragment(sel.Name)
+			if f == nil {
+				v.errorf(sel.Pos, "unknown fragment %q", sel.Name)
+				continue
+			}
+			cond := v.s.Types[f.On]
+			if cond == nil || !cond.IsComposite() {
+				continue // 已在片段定义处报告
+			}
+			if !v.s.overlaps(parent.Name, cond.Name) {
+				v.errorf(sel.Pos, "fragment %q on %q can never apply to %q", f.Name, f.On, parent.Name)
+				continue
+			}
+			if seen[f.Name] {
+				continue
+			}
+			seen[f.Name] = true
+			v.selections(cond, f.Selections, seen)
+			delete(seen, f.Name)
+		}
+	}
+}
+
+func (v *validator) field(parent *Definition, f *Field) {
+	if f.Name == "__typename" {
+		if len(f.Selections) > 0 {
+			v.errorf(f.Pos, "field \"__typename\" must not have a selection")
+		}
+		return
+	}
+	if parent.Kind == KindUnion {
+		v.errorf(f.Pos, "cannot query field %q on union %q; use an inline fragment", f.Name, parent.Name)
+		return
+	}
+	def := parent.Field(f.Name)
+	if def == nil {
+		v.errorf(f.Pos, "cannot query field %q on type %q", f.Name, parent.Name)
+		return
+	}
+	for _, a := range f.Arguments {
+		ad := def.Arg(a.Name)
+		if ad == nil {
+			v.errorf(a.Pos, "unknown argument %q on field %s.%s", a.Name, parent.Name, f.Name)
+			continue
+		}
+		v.value(a.Value, ad.Type, ad.HasDefault, fmt.Sprintf("argument %q of %s.%s", a.Name, parent.Name, f.Name))
+	}
+	for _, ad := range def.Args {
+		if ad.Type.NonNull && !ad.HasDefault && !hasArg(f.Arguments, ad.Name) {
+			v.errorf(f.Pos, "field %s.%s: required argument %q (%s) not provided", parent.Name, f.Name, ad.Name, ad.Type)
+		}
+	}
+	d := v.s.Types[def.Type.NamedType()]
+	switch {
+	case d.IsLeaf() && len(f.Selections) > 0:
+		v.errorf(f.Pos, "field %q of type %q must not have a selection", f.Name, def.Type)
+	case d.IsComposite() && len(f.Selections) == 0:
+		v.errorf(f.Pos, "field %q of type %q must have a selection of subfields", f.Name, def.Type)
+	case d.IsComposite():
+		v.selections(d, f.Selections, map[string]bool{})
+	}
+}
+
+func hasArg(args []*Argument, name string) bool {
+	for _, a := range args {
+		if a.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// value 校验参数值与期望类型兼容
+func (v *validator) value(val *Value, want *Type, hasDefault bool, what string) {
+	if val == nil {
+		return
+	}
+	if val.Kind == ValueVariable {
+		vd, ok := v.vars[val.Raw]
+		if !ok {
+			v.errorf(val.Pos, "variable $%s is not declared", val.Raw)
+			return
+		}
+		v.used[val.Raw] = true
+		if !variableFits(vd, want, hasDefault) {
+			v.errorf(val.Pos, "variable $%s of type %s cannot be used for %s (%s)", val.Raw, vd.Type, what, want)
+		}
+		return
+	}
+	if val.Kind == ValueNull {
+		if want.NonNull {
+			v.errorf(val.Pos, "null is not allowed for %s (%s)", what, want)
+		}
+		return
+	}
+	if want.Elem != nil {
+		if val.Kind != ValueList {
+			// 单值可自动包装为列表
+			v.value(val, want.Elem, false, what)
+			return
+		}
+		for _, item := range val.List {
+			v.value(item, want.Elem, false, what)
+		}
+		return
+	}
+	d := v.s.Types[want.Name]
+	switch d.Kind {
+	case KindEnum:
+		if val.Kind != ValueEnum || !contains(d.EnumValues, val.Raw) {
+			v.errorf(val.Pos, "%s expects enum %s, found %q", what, d.Name, val.Raw)
+		}
+	case KindInput:
+		if val.Kind != ValueObject {
+			v.errorf(val.Pos, "%s expects input object %s", what, d.Name)
+			return
+		}
+		for _, of := range val.Fields {
+			fd := d.Input(of.Name)
+			if fd == nil {
+				v.errorf(of.Pos, "unknown field %q on input %s", of.Name, d.Name)
+				continue
+			}
+			v.value(of.Value, fd.Type, fd.HasDefault, fmt.Sprintf("field %q of %s", of.Name, d.Name))
+		}
+		for _, fd := range d.Inputs {
+			if fd.Type.NonNull && !fd.HasDefault && !hasObjectField(val.Fields, fd.Name) {
+				v.errorf(val.Pos, "input %s: required field %q not provided", d.Name, fd.Name)
+			}
+		}
+	case KindScalar:
+		ok := true
+		switch d.Name {
+		case "Int":
+			ok = val.Kind == ValueInt
+		case "Float":
+			ok = val.Kind == ValueInt || val.Kind == ValueFloat
+		case "Boolean":
+			ok = val.Kind == ValueBoolean
+		case "String":
+			ok = val.Kind == ValueString
+		case "ID":
+			ok = val.Kind == ValueString || val.Kind == ValueInt
+		}
+		if !ok {
+			v.errorf(val.Pos, "%s expects %s", what, d.Name)
+		}
+	}
+}
+
+// variableFits 变量类型可用于目标位置：可空变量不能传给非空位置（除非变量或位置带默认值）
+func variableFits(vd *VariableDef, want *Type, locationDefault bool) bool {
+	have := vd.Type
+	if want.NonNull && !have.NonNull {
+		if !vd.HasDefault && !locationDefault {
+			return false
+		}
+		w := *want
+		w.NonNull = false
+		want = &w
+	}
+	return typeFits(have, want)
+}
+
+func typeFits(have, want *Type) bool {
+	if want.NonNull {
+		if !have.NonNull {
+			return false
+		}
+		return typeFits(stripNonNull(have), stripNonNull(want))
+	}
+	if have.NonNull {
+		return typeFits(stripNonNull(have), want)
+	}
+	if want.Elem != nil {
+		if have.Elem == nil {
+			return false
+		}
+		return typeFits(have.Elem, want.Elem)
+	}
+	return have.Elem == nil && have.Name == want.Name
+}
+
+func stripNonNull(t *Type) *Type {
+	c := *t
+	c.NonNull = false
+	return &c
+}
+
+func hasObjectField(fields []*ObjectField, name string) bool {
+	for _, f := range fields {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
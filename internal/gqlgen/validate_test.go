@@ -0,0 +1,72 @@
+package gqlgen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	s := loadSchema(t)
+	tests := []struct {
+		name string
+		src  string
+		want []string // 按位置排序的全部错误；空表示通过
+	}{
+		{"valid", `query Q($id: String!) { deployment(id: $id) { id service { name } } }`, nil},
+		{"unknown field", `query Q { deployment(id: "x") { id nope } }`,
+			[]string{`q.graphql:1:36: cannot query field "nope" on type "Deployment"`}},
+		{"unknown argument", `query Q { deployment(id: "x", limit: 1) { id } }`,
+			[]string{`q.graphql:1:31: unknown argument "limit" on field Query.deployment`}},
+		{"missing required argument", `query Q { deployment { id } }`,
+			[]string{`q.graphql:1:11: field Query.deployment: required argument "id" (String!) not provided`}},
+		{"leaf with selection", `query Q { deployment(id: "x") { id { x } } }`,
+			[]string{`q.graphql:1:33: field "id" of type "ID!" must not have a selection`}},
+		{"composite without selection", `query Q { deployment(id: "x") }`,
+			[]string{`q.graphql:1:11: field "deployment" of type "Deployment" must have a selection of subfields`}},
+		{"undeclared variable", `query Q { deployment(id: $id) { id } }`,
+			[]string{`q.graphql:1:26: variable $id is not declared`}},
+		{"unused variable", `query Q($id: String!, $x: Int) { deployment(id: $id) { id } }`,
+			[]string{`q.graphql:1:23: variable $x is declared but never used in Q`}},
+		{"nullable variable for non-null argument", `query Q($id: String) { deployment(id: $id) { id } }`,
+			[]string{`q.graphql:1:39: variable $id of type String cannot be used for argument "id" of Query.deployment (String!)`}},
+		{"output type variable", `query Q($d: Deployment) { deployment(id: "x") { id } }`,
+			[]string{`q.graphql:1:9: variable $d is declared but never used in Q`,
+				`q.graphql:1:13: variable $d: type "Deployment" is not an input type`}},
+		{"field on union", `query Q { search(term: "x") { id } }`,
+			[]string{`q.graphql:1:31: cannot query field "id" on union "SearchResult"; use an inline fragment`}},
+		{"impossible inline fragment", `query Q { deployment(id: "x") { ... on Service { id } } }`,
+			[]string{`q.graphql:1:33: fragment on "Service" can never apply to "Deployment"`}},
+		{"unknown fragment", `query Q { deployment(id: "x") { ...Missing } }`,
+			[]string{`q.graphql:1:33: unknown fragment "Missing"`}},
+		{"self-spreading fragment", "fragment F on Service { ...F }\nquery Q { deployment(id: \"x\") { service { ...F } } }",
+			[]string{`q.graphql:1:1: fragment "F" spreads itself`}},
+		{"bad enum literal", `mutation M { deploy(input: {projectId: "p", status: DONE}) { id } }`,
+			[]string{`q.graphql:1:53: field "status" of DeployInput expects enum Status, found "DONE"`}},
+		{"missing input field", `mutation M { deploy(input: {status: SUCCESS}) { id } }`,
+			[]string{`q.graphql:1:28: input DeployInput: required field "projectId" not provided`}},
+		{"unknown input field", `mutation M { deploy(input: {projectId: "p", env: "x"}) { id } }`,
+			[]string{`q.graphql:1:45: unknown field "env" on input DeployInput`}},
+		{"null for non-null", `query Q { deployment(id: null) { id } }`,
+			[]string{`q.graphql:1:26: null is not allowed for argument "id" of Query.deployment (String!)`}},
+		{"duplicate operation", "query Q { deployment(id: \"x\") { id } }\nquery Q { search(term: \"x\") { __typename } }",
+			[]string{`q.graphql:2:1: operation "Q" already defined at q.graphql:1:1`}},
+		{"bad endpoint", "# endpoint: admin\nquery Q { deployment(id: \"x\") { id } }",
+			[]string{`q.graphql:2:1: unknown endpoint "admin" (want public or internal)`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := &Document{}
+			if err := ParseDocument(doc, "q.graphql", tt.src); err != nil {
+				t.Fatal(err)
+			}
+			err := Validate(s, doc)
+			var got []string
+			if err != nil {
+				got = strings.Split(err.Error(), "\n")
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Fatalf("errors:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,310 @@
+// Package logquery 按时间范围分页查询历史日志，供 CLI 与 pkg/railway 共用。
+//
+// 后端每次查询只接受一个时间锚点：向后翻页时给定起点取最早的若干行，向前翻页时给定终点取最近的若干行；
+// 另一端的边界、游标去重与排序都在客户端完成。
+package logquery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/railwayapp/cli/internal/gql"
+)
+
+// Kind 日志种类
+type Kind int
+
+const (
+	Build Kind = iota
+	Deployment
+	Environment
+)
+
+// DefaultLimit 未指定 Limit 时每页的行数（与订阅的默认值一致）
+const DefaultLimit = 500
+
+// Query 历史日志查询条件
+//
+// 翻页方向：设置 After 或（未设置 Before、Backward 时）设置 Start 表示从旧到新，其余情况从 End（默认当前时间）
+// 向前取最近的行。无论方向如何，返回的行都按时间升序排列。
+type Query struct {
+	// Filter 后端日志过滤表达式
+	Filter string
+	// Start / End 时间范围（闭区间），零值表示不限
+	Start time.Time
+	End   time.Time
+	// Before / After 分页游标，取自上一页的 Page.Before / Page.After，二者最多设置一个
+	Before string
+	After  string
+	// Limit 每页最多返回的行数，0 使用 DefaultLimit
+	Limit int
+	// Backward 设置 Start 时仍从 End 向前取最近的行（Start 只作为下界）
+	Backward bool
+}
+
+// Forward 是否从旧到新翻页
+func (q Query) Forward() bool {
+	return q.After != "" || (q.Before == "" && !q.Backward && !q.Start.IsZero())
+}
+
+// Attribute 日志属性
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Line 一行日志
+type Line struct {
+	Timestamp  string
+	Time       time.Time
+	Message    string
+	Severity   string
+	Attributes []Attribute
+	// Tags 环境日志的来源标签，仅包含非空值
+	Tags map[string]string
+}
+
+// Page 一页日志
+type Page struct {
+	Lines []Line
+	// Before 指向本页第一行，作为 Query.Before 取更早的一页；本页为空时沿用查询的游标
+	Before string
+	// After 指向本页最后一行，作为 Query.After 取更新的一页
+	After string
+	// HasMore 查询方向上是否可能还有更多日志
+	HasMore bool
+}
+
+// cursor 游标：时间戳以及该时间戳上已返回的行数（同一时间戳的行可能跨页）
+type cursor struct {
+	ts string
+	t  time.Time
+	n  int
+}
+
+func (c cursor) String() string {
+	if c.ts == "" {
+		return ""
+	}
+	return c.ts + "#" + strconv.Itoa(c.n)
+}
+
+func parseCursor(s string) (cursor, error) {
+	if s == "" {
+		return cursor{}, nil
+	}
+	ts, n, ok := strings.Cut(s, "#")
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if !ok || err != nil {
+		return cursor{}, fmt.Errorf("invalid log cursor %q", s)
+	}
+	count, err := strconv.Atoi(n)
+	if err != nil || count < 0 {
+		return cursor{}, fmt.Errorf("invalid log cursor %q", s)
+	}
+	return cursor{ts: ts, t: t, n: count}, nil
+}
+
+// Fetch 查询一页日志；id 为部署 ID（Build/Deployment）或环境 ID（Environment）
+func Fetch(ctx context.Context, exec gql.Executor, kind Kind, id string, q Query) (*Page, error) {
+	if q.Before != "" && q.After != "" {
+		return nil, errors.New("log query: Before and After are mutually exclusive")
+	}
+	if !q.Start.IsZero() && !q.End.IsZero() && q.End.Before(q.Start) {
+		return nil, errors.New("log query: End is before Start")
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	forward := q.Forward()
+	cur, err := parseCursor(q.After + q.Before)
+	if err != nil {
+		return nil, err
+	}
+
+	// 锚点取游标与范围边界中更靠内的一个；游标处已返回的行会再次出现，多取 n 行后丢弃
+	anchor := q.End
+	if forward {
+		anchor = q.Start
+	}
+	if cur.ts != "" && (anchor.IsZero() || (forward && !cur.t.Before(anchor)) || (!forward && !cur.t.After(anchor))) {
+		anchor = cur.t
+	} else {
+		cur = cursor{}
+	}
+	var date *string
+	if !anchor.IsZero() {
+		s := anchor.UTC().Format(time.RFC3339Nano)
+		date = &s
+	}
+	fetchLimit := limit + cur.n
+	var filter *string
+	if q.Filter != "" {
+		filter = &q.Filter
+	}
+
+	var lines []Line
+	switch kind {
+	case Build, Deployment:
+		v := gql.DeploymentLogHistoryVariables{DeploymentID: id, Filter: filter, Limit: &fetchLimit}
+		if forward {
+			v.StartDate = date
+		} else {
+			v.EndDate = date
+		}
+		var raw []gql.LogFields
+		if kind == Build {
+			resp, err := gql.BuildLogHistory(ctx, exec, gql.BuildLogHistoryVariables(v))
+			if err != nil {
+				return nil, err
+			}
+			raw = resp.BuildLogs
+		} else {
+			resp, err := gql.DeploymentLogHistory(ctx, exec, v)
+			if err != nil {
+				return nil, err
+			}
+			raw = resp.DeploymentLogs
+		}
+		for _, l := range raw {
+			lines = append(lines, newLine(l, nil))
+		}
+	case Environment:
+		v := gql.EnvironmentLogHistoryVariables{EnvironmentID: id, Filter: filter}
+		if forward {
+			v.AfterDate, v.AfterLimit = date, &fetchLimit
+		} else {
+			v.BeforeDate, v.BeforeLimit = date, &fetchLimit
+		}
+		resp, err := gql.EnvironmentLogHistory(ctx, exec, v)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range resp.EnvironmentLogs {
+			tags := map[string]string{}
+			if t := l.Tags; t != nil {
+				for k, v := range map[string]*string{
+					"projectId": t.ProjectID, "environmentId": t.EnvironmentID, "pluginId": t.PluginID,
+					"serviceId": t.ServiceID, "deploymentId": t.DeploymentID,
+					"deploymentInstanceId": t.DeploymentInstanceID, "snapshotId": t.SnapshotID,
+				} {
+					if v != nil && *v != "" {
+						tags[k] = *v
+					}
+				}
+			}
+			lines = append(lines, newLine(gql.LogFields{Timestamp: l.Timestamp, Message: l.Message, Severity: l.Severity, Attributes: l.Attributes}, tags))
+		}
+	default:
+		return nil, fmt.Errorf("log query: unknown kind %d", kind)
+	}
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].Time.Before(lines[j].Time) })
+	hasMore := len(lines) >= fetchLimit
+
+	// 丢弃游标处已返回过的行
+	if cur.n > 0 {
+		if forward {
+			skip := 0
+			for skip < len(lines) && skip < cur.n && lines[skip].Time.Equal(cur.t) {
+				skip++
+			}
+			lines = lines[skip:]
+		} else {
+			end := len(lines)
+			for len(lines)-end < cur.n && end > 0 && lines[end-1].Time.Equal(cur.t) {
+				end--
+			}
+			lines = lines[:end]
+		}
+	}
+	// 另一端的范围边界在客户端裁剪；越界说明该方向已到尽头
+	if forward && !q.End.IsZero() {
+		n := sort.Search(len(lines), func(i int) bool { return lines[i].Time.After(q.End) })
+		if n < len(lines) {
+			lines, hasMore = lines[:n], false
+		}
+	}
+	if !forward && !q.Start.IsZero() {
+		n := sort.Search(len(lines), func(i int) bool { return !lines[i].Time.Before(q.Start) })
+		if n > 0 {
+			lines, hasMore = lines[n:], false
+		}
+	}
+	if len(lines) > limit {
+		if forward {
+			lines = lines[:limit]
+		} else {
+			lines = lines[len(lines)-limit:]
+		}
+	}
+
+	page := &Page{Lines: lines, HasMore: hasMore}
+	if len(lines) == 0 {
+		page.Before, page.After = q.Before, q.After
+		return page, nil
+	}
+	// 沿查询方向继续翻页时，游标处的行数需累加此前各页在同一时间戳上返回的行
+	before, after := cur, cursor{}
+	if forward {
+		before, after = cursor{}, cur
+	}
+	page.Before = edgeCursor(lines, 0, before).String()
+	page.After = edgeCursor(lines, len(lines)-1, after).String()
+	return page, nil
+}
+
+// Tail 从 q.End（默认当前时间）向前翻页，取 [q.Start, q.End] 内最近的 n 行，按时间升序返回
+func Tail(ctx context.Context, exec gql.Executor, kind Kind, id string, q Query, n int) ([]Line, error) {
+	q.Before, q.After, q.Backward = "", "", true
+	var pages [][]Line
+	for got := 0; got < n; {
+		q.Limit = min(n-got, DefaultLimit)
+		page, err := Fetch(ctx, exec, kind, id, q)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, page.Lines)
+		got += len(page.Lines)
+		if !page.HasMore || len(page.Lines) == 0 {
+			break
+		}
+		q.Before = page.Before
+	}
+	var lines []Line
+	for i := len(pages) - 1; i >= 0; i-- {
+		lines = append(lines, pages[i]...)
+	}
+	return lines, nil
+}
+
+// edgeCursor 以 lines[i]（首行或末行）的时间戳生成游标；与 prev 同一时间戳时累加其行数
+func edgeCursor(lines []Line, i int, prev cursor) cursor {
+	c := cursor{ts: lines[i].Timestamp, t: lines[i].Time}
+	for _, l := range lines {
+		if l.Time.Equal(c.t) {
+			c.n++
+		}
+	}
+	if prev.ts != "" && prev.t.Equal(c.t) {
+		c.n += prev.n
+	}
+	return c
+}
+
+func newLine(l gql.LogFields, tags map[string]string) Line {
+	t, _ := time.Parse(time.RFC3339Nano, l.Timestamp)
+	line := Line{Timestamp: l.Timestamp, Time: t, Message: l.Message, Tags: tags}
+	if l.Severity != nil {
+		line.Severity = *l.Severity
+	}
+	for _, a := range l.Attributes {
+		line.Attributes = append(line.Attributes, Attribute{Key: a.Key, Value: a.Value})
+	}
+	return line
+}
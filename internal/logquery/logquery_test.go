@@ -0,0 +1,263 @@
+package logquery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+var base = time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+// fakeLog 后端中的一行日志
+type fakeLog struct {
+	t   time.Time
+	msg string
+}
+
+// fakeExecutor 模拟后端的历史日志查询：startDate 取不早于该时间的最早 limit 行，
+// endDate（或未指定时）取不晚于该时间的最近 limit 行，均按时间升序返回
+type fakeExecutor struct {
+	logs  []fakeLog // 按时间升序
+	calls []map[string]interface{}
+}
+
+// at 生成日志：offsets[i] 为第 i 行相对 base 的秒数
+func at(offsets ...int) *fakeExecutor {
+	f := &fakeExecutor{}
+	for i, s := range offsets {
+		f.logs = append(f.logs, fakeLog{t: base.Add(time.Duration(s) * time.Second), msg: fmt.Sprintf("l%d", i)})
+	}
+	return f
+}
+
+func (f *fakeExecutor) Query(ctx context.Context, query string, variables map[string]interface{}, response interface{}) error {
+	f.calls = append(f.calls, variables)
+	limit, _ := variables["limit"].(int)
+	var sel []fakeLog
+	if s, ok := variables["startDate"].(string); ok {
+		start, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return err
+		}
+		i := sort.Search(len(f.logs), func(i int) bool { return !f.logs[i].t.Before(start) })
+		sel = f.logs[i:min(i+limit, len(f.logs))]
+	} else {
+		end := base.Add(time.Hour)
+		if s, ok := variables["endDate"].(string); ok {
+			var err error
+			if end, err = time.Parse(time.RFC3339Nano, s); err != nil {
+				return err
+			}
+		}
+		j := sort.Search(len(f.logs), func(i int) bool { return f.logs[i].t.After(end) })
+		sel = f.logs[max(0, j-limit):j]
+	}
+	out := make([]map[string]interface{}, 0, len(sel))
+	for _, l := range sel {
+		out = append(out, map[string]interface{}{"timestamp": l.t.Format(time.RFC3339Nano), "message": l.msg})
+	}
+	field := "deploymentLogs"
+	if strings.Contains(query, "buildLogs") {
+		field = "buildLogs"
+	}
+	b, err := json.Marshal(map[string]interface{}{field: out})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, response)
+}
+
+func (f *fakeExecutor) QueryInternal(ctx context.Context, query string, variables map[string]interface{}, response interface{}) error {
+	return f.Query(ctx, query, variables, response)
+}
+
+func (f *fakeExecutor) Mutate(context.Context, string, map[string]interface{}, interface{}) error {
+	return errors.New("unexpected mutation")
+}
+
+func (f *fakeExecutor) MutateInternal(context.Context, string, map[string]interface{}, interface{}) error {
+	return errors.New("unexpected mutation")
+}
+
+func messages(lines []Line) string {
+	var s []string
+	for _, l := range lines {
+		s = append(s, l.Message)
+	}
+	return strings.Join(s, ",")
+}
+
+func sec(n int) time.Time { return base.Add(time.Duration(n) * time.Second) }
+
+// 同一时间戳的多行跨页时，游标记录该时间戳上已返回的行数，不重复也不遗漏
+var sharedTimestamps = []int{0, 1, 1, 1, 1, 2, 3, 3, 4}
+
+func TestFetchForwardPaging(t *testing.T) {
+	want := "l0,l1,l2,l3,l4,l5,l6,l7,l8"
+	for _, limit := range []int{1, 2, 3, 4, 100} {
+		t.Run(fmt.Sprintf("limit %d", limit), func(t *testing.T) {
+			exec := at(sharedTimestamps...)
+			q := Query{Start: base, Limit: limit}
+			var got []Line
+			for i := 0; ; i++ {
+				if i > 20 {
+					t.Fatal("paging does not terminate")
+				}
+				page, err := Fetch(context.Background(), exec, Deployment, "d", q)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(page.Lines) > limit {
+					t.Fatalf("page has %d lines, limit %d", len(page.Lines), limit)
+				}
+				got = append(got, page.Lines...)
+				if !page.HasMore || len(page.Lines) == 0 {
+					break
+				}
+				q.After = page.After
+			}
+			if messages(got) != want {
+				t.Fatalf("lines = %s, want %s", messages(got), want)
+			}
+		})
+	}
+}
+
+func TestFetchBackwardPaging(t *testing.T) {
+	want := "l0,l1,l2,l3,l4,l5,l6,l7,l8"
+	for _, limit := range []int{1, 2, 3, 4, 100} {
+		t.Run(fmt.Sprintf("limit %d", limit), func(t *testing.T) {
+			exec := at(sharedTimestamps...)
+			q := Query{Limit: limit}
+			var got []Line
+			for i := 0; ; i++ {
+				if i > 20 {
+					t.Fatal("paging does not terminate")
+				}
+				page, err := Fetch(context.Background(), exec, Build, "d", q)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(append([]Line{}, page.Lines...), got...)
+				if !page.HasMore || len(page.Lines) == 0 {
+					break
+				}
+				q.Before = page.Before
+			}
+			if messages(got) != want {
+				t.Fatalf("lines = %s, want %s", messages(got), want)
+			}
+		})
+	}
+}
+
+func TestFetchRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		q        Query
+		want     string
+		hasMore  bool
+		wantDate string // 发给后端的锚点（startDate 或 endDate）
+	}{
+		{"forward within range", Query{Start: sec(1), End: sec(2), Limit: 10}, "l1,l2,l3,l4,l5", false, "startDate=" + sec(1).Format(time.RFC3339Nano)},
+		{"backward within range", Query{Start: sec(1), End: sec(3), Backward: true, Limit: 3}, "l5,l6,l7", true, "endDate=" + sec(3).Format(time.RFC3339Nano)},
+		{"backward clipped at start", Query{Start: sec(3), End: sec(4), Backward: true, Limit: 10}, "l6,l7,l8", false, "endDate=" + sec(4).Format(time.RFC3339Nano)},
+		// 游标早于 Start：从 Start 开始，忽略游标中的计数
+		{"after cursor before start", Query{Start: sec(3), After: sec(1).Format(time.RFC3339Nano) + "#4", Limit: 10}, "l6,l7,l8", false, "startDate=" + sec(3).Format(time.RFC3339Nano)},
+		// 游标晚于 End：从 End 向前
+		{"before cursor after end", Query{End: sec(1), Before: sec(3).Format(time.RFC3339Nano) + "#1", Limit: 2}, "l3,l4", true, "endDate=" + sec(1).Format(time.RFC3339Nano)},
+		// 游标在范围内：从游标继续，跳过已返回的行
+		{"after cursor inside range", Query{Start: sec(0), End: sec(3), After: sec(1).Format(time.RFC3339Nano) + "#2", Limit: 10}, "l3,l4,l5,l6,l7", false, "startDate=" + sec(1).Format(time.RFC3339Nano)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := at(sharedTimestamps...)
+			page, err := Fetch(context.Background(), exec, Deployment, "d", tt.q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if messages(page.Lines) != tt.want || page.HasMore != tt.hasMore {
+				t.Errorf("lines = %s (hasMore %v), want %s (hasMore %v)", messages(page.Lines), page.HasMore, tt.want, tt.hasMore)
+			}
+			key, val, _ := strings.Cut(tt.wantDate, "=")
+			if got := exec.calls[0][key]; got != val {
+				t.Errorf("%s = %v, want %s (vars %v)", key, got, val, exec.calls[0])
+			}
+		})
+	}
+}
+
+func TestFetchErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		q    Query
+		want string
+	}{
+		{"both cursors", Query{Before: "a", After: "b"}, "mutually exclusive"},
+		{"end before start", Query{Start: sec(2), End: sec(1)}, "End is before Start"},
+		{"bad cursor", Query{After: "yesterday"}, `invalid log cursor "yesterday"`},
+		{"negative count", Query{After: sec(1).Format(time.RFC3339Nano) + "#-1"}, "invalid log cursor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Fetch(context.Background(), at(0), Build, "d", tt.q)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTail(t *testing.T) {
+	// 1200 行，每 3 行共享一个时间戳，页边界会落在同一时间戳内
+	offsets := make([]int, 1200)
+	for i := range offsets {
+		offsets[i] = i / 3
+	}
+	tests := []struct {
+		name      string
+		q         Query
+		n         int
+		first     string
+		count     int
+		maxLimits []int // 每次请求的 limit 上限（不含游标处多取的行）
+	}{
+		{"more than DefaultLimit", Query{}, DefaultLimit*2 + 100, "l100", 1100, []int{DefaultLimit, DefaultLimit, 100}},
+		{"more than available", Query{}, 5000, "l0", 1200, []int{DefaultLimit, DefaultLimit, DefaultLimit}},
+		{"bounded by start", Query{Start: sec(300)}, 2000, "l900", 300, []int{DefaultLimit}},
+		{"ending at end", Query{End: sec(199)}, 10, "l590", 10, []int{10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := at(offsets...)
+			lines, err := Tail(context.Background(), exec, Deployment, "d", tt.q, tt.n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(lines) != tt.count || lines[0].Message != tt.first {
+				t.Fatalf("got %d lines starting at %s, want %d starting at %s", len(lines), lines[0].Message, tt.count, tt.first)
+			}
+			for i := 1; i < len(lines); i++ {
+				var a, b int
+				fmt.Sscanf(lines[i-1].Message, "l%d", &a)
+				fmt.Sscanf(lines[i].Message, "l%d", &b)
+				if b != a+1 {
+					t.Fatalf("gap or duplicate between %s and %s", lines[i-1].Message, lines[i].Message)
+				}
+			}
+			if len(exec.calls) != len(tt.maxLimits) {
+				t.Fatalf("%d requests, want %d", len(exec.calls), len(tt.maxLimits))
+			}
+			for i, c := range exec.calls {
+				if limit := c["limit"].(int); limit > tt.maxLimits[i]+3 {
+					t.Errorf("request %d limit = %d, want <= %d", i, limit, tt.maxLimits[i]+3)
+				}
+			}
+		})
+	}
+}
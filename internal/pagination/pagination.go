@@ -0,0 +1,132 @@
+// Package pagination 基于 Relay 游标（first/after + pageInfo）的通用分页器，供 CLI 与 pkg/railway 共用。
+// 对外的类型说明统一写在 pkg/railway/pagination.go（DefaultPageSize、PageOptions、PageInfo 为其别名，
+// PageFunc 与 Pager 由其包装），这里只注释实现细节。
+package pagination
+
+import (
+	"context"
+
+	"github.com/railwayapp/cli/internal/gql"
+)
+
+// DefaultPageSize 见 railway.DefaultPageSize
+const DefaultPageSize = 50
+
+// PageOptions 见 railway.PageOptions
+type PageOptions struct {
+	PageSize int
+	MaxItems int
+}
+
+// PageInfo 见 railway.PageInfo
+type PageInfo struct {
+	HasNextPage bool
+	EndCursor   string
+}
+
+// PageFunc 见 railway.PageFunc
+type PageFunc[T any] func(ctx context.Context, first int, after string) ([]T, PageInfo, error)
+
+// Pager 见 railway.Pager
+type Pager[T any] struct {
+	fetch PageFunc[T]
+	opts  PageOptions
+	after string
+	seen  int
+	done  bool
+}
+
+// New 以 fetch 构造分页器
+func New[T any](fetch PageFunc[T], opts PageOptions) *Pager[T] {
+	if opts.PageSize <= 0 {
+		opts.PageSize = DefaultPageSize
+	}
+	return &Pager[T]{fetch: fetch, opts: opts}
+}
+
+// More 是否还有未拉取的页
+func (p *Pager[T]) More() bool { return !p.done }
+
+// Next 拉取下一页；没有更多数据时返回 (nil, nil)。出错时不更新游标（见 railway.Pager）
+func (p *Pager[T]) Next(ctx context.Context) ([]T, error) {
+	if p.done {
+		return nil, nil
+	}
+	first := p.opts.PageSize
+	remaining := p.opts.MaxItems - p.seen
+	if p.opts.MaxItems > 0 && remaining < first {
+		first = remaining
+	}
+	items, info, err := p.fetch(ctx, first, p.after)
+	if err != nil {
+		return nil, err
+	}
+	if p.opts.MaxItems > 0 && len(items) > remaining {
+		items = items[:remaining]
+	}
+	p.seen += len(items)
+	// 游标未前进时终止，避免后端异常导致死循环
+	if !info.HasNextPage || info.EndCursor == "" || info.EndCursor == p.after ||
+		(p.opts.MaxItems > 0 && p.seen >= p.opts.MaxItems) {
+		p.done = true
+	}
+	p.after = info.EndCursor
+	return items, nil
+}
+
+// Pages 逐页迭代；yield 返回 false 时停止，出错时 yield 一次 (nil, err) 后停止
+func (p *Pager[T]) Pages(ctx context.Context) func(yield func([]T, error) bool) {
+	return func(yield func([]T, error) bool) {
+		for p.More() {
+			items, err := p.Next(ctx)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(items, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Items 逐条迭代，按需拉取后续页
+func (p *Pager[T]) Items(ctx context.Context) func(yield func(T, error) bool) {
+	return func(yield func(T, error) bool) {
+		p.Pages(ctx)(func(items []T, err error) bool {
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return false
+			}
+			for _, it := range items {
+				if !yield(it, nil) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+}
+
+// All 拉取剩余全部页（受 MaxItems 限制）并合并返回
+func (p *Pager[T]) All(ctx context.Context) ([]T, error) {
+	var out []T
+	for p.More() {
+		items, err := p.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, items...)
+	}
+	return out, nil
+}
+
+// FromGQL 将 GraphQL pageInfo 转换为 PageInfo
+func FromGQL(pi gql.PageInfo) PageInfo {
+	out := PageInfo{HasNextPage: pi.HasNextPage}
+	if pi.EndCursor != nil {
+		out.EndCursor = *pi.EndCursor
+	}
+	return out
+}
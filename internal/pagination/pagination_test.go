@@ -0,0 +1,210 @@
+package pagination
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// fakeConn 以偏移量为游标的内存连接，记录每次请求的 first/after
+type fakeConn struct {
+	items []int
+	calls []string
+	// failAt 第几次调用（从 1 开始）返回错误
+	failAt int
+	// info 非 nil 时覆盖返回的 pageInfo
+	info func(after string, end int) PageInfo
+}
+
+var errFetch = errors.New("fetch failed")
+
+func (f *fakeConn) fetch(_ context.Context, first int, after string) ([]int, PageInfo, error) {
+	f.calls = append(f.calls, strconv.Itoa(first)+"@"+after)
+	if len(f.calls) == f.failAt {
+		return nil, PageInfo{}, errFetch
+	}
+	start := 0
+	if after != "" {
+		start, _ = strconv.Atoi(after)
+	}
+	end := start + first
+	if end > len(f.items) {
+		end = len(f.items)
+	}
+	if f.info != nil {
+		return f.items[start:end], f.info(after, end), nil
+	}
+	return f.items[start:end], PageInfo{HasNextPage: end < len(f.items), EndCursor: strconv.Itoa(end)}, nil
+}
+
+func seq(n int) []int {
+	out := make([]int, n)
+	for i := range out {
+		out[i] = i
+	}
+	return out
+}
+
+func TestPagerAll(t *testing.T) {
+	tests := []struct {
+		name      string
+		items     int
+		opts      PageOptions
+		wantLen   int
+		wantCalls []string
+	}{
+		{"single page", 3, PageOptions{PageSize: 5}, 3, []string{"5@"}},
+		{"exact pages", 4, PageOptions{PageSize: 2}, 4, []string{"2@", "2@2"}},
+		{"default page size", 60, PageOptions{}, 60, []string{"50@", "50@50"}},
+		{"max items shrinks last request", 10, PageOptions{PageSize: 4, MaxItems: 6}, 6, []string{"4@", "2@4"}},
+		{"max items below page size", 10, PageOptions{PageSize: 4, MaxItems: 3}, 3, []string{"3@"}},
+		{"empty connection", 0, PageOptions{PageSize: 4}, 0, []string{"4@"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeConn{items: seq(tt.items)}
+			got, err := New(f.fetch, tt.opts).All(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.wantLen || (tt.wantLen > 0 && !reflect.DeepEqual(got, seq(tt.items)[:tt.wantLen])) {
+				t.Fatalf("items = %v, want first %d of %d", got, tt.wantLen, tt.items)
+			}
+			if !reflect.DeepEqual(f.calls, tt.wantCalls) {
+				t.Fatalf("calls = %v, want %v", f.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestPagerTruncatesOversizedPage(t *testing.T) {
+	// 后端忽略 first 返回整页时仍按 MaxItems 截断
+	f := &fakeConn{items: seq(10), info: func(string, int) PageInfo { return PageInfo{HasNextPage: true, EndCursor: "10"} }}
+	p := New(func(ctx context.Context, _ int, after string) ([]int, PageInfo, error) {
+		return f.fetch(ctx, 10, after)
+	}, PageOptions{PageSize: 10, MaxItems: 4})
+	got, err := p.All(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, seq(4)) || p.More() {
+		t.Fatalf("items = %v, More = %v", got, p.More())
+	}
+}
+
+func TestPagerStopsOnStalledCursor(t *testing.T) {
+	tests := []struct {
+		name string
+		info func(after string, end int) PageInfo
+		want []string
+	}{
+		{"empty end cursor", func(string, int) PageInfo { return PageInfo{HasNextPage: true} }, []string{"2@"}},
+		{"cursor does not advance", func(after string, end int) PageInfo {
+			if after == "" {
+				return PageInfo{HasNextPage: true, EndCursor: "2"}
+			}
+			return PageInfo{HasNextPage: true, EndCursor: after}
+		}, []string{"2@", "2@2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeConn{items: seq(6), info: tt.info}
+			p := New(f.fetch, PageOptions{PageSize: 2})
+			if _, err := p.All(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if p.More() || !reflect.DeepEqual(f.calls, tt.want) {
+				t.Fatalf("More = %v, calls = %v, want %v", p.More(), f.calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestPagerNextAfterDone(t *testing.T) {
+	f := &fakeConn{items: seq(1)}
+	p := New(f.fetch, PageOptions{PageSize: 5})
+	if _, err := p.Next(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	items, err := p.Next(context.Background())
+	if items != nil || err != nil || len(f.calls) != 1 {
+		t.Fatalf("Next after done = %v, %v (calls %v)", items, err, f.calls)
+	}
+}
+
+func TestPagerItemsStopsEarly(t *testing.T) {
+	f := &fakeConn{items: seq(10)}
+	p := New(f.fetch, PageOptions{PageSize: 3})
+	var got []int
+	p.Items(context.Background())(func(v int, err error) bool {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, v)
+		return len(got) < 4
+	})
+	if !reflect.DeepEqual(got, seq(4)) {
+		t.Fatalf("items = %v", got)
+	}
+	// 只拉取到第 4 条所在的页
+	if want := []string{"3@", "3@3"}; !reflect.DeepEqual(f.calls, want) {
+		t.Fatalf("calls = %v, want %v", f.calls, want)
+	}
+}
+
+func TestPagerPagesStopsEarly(t *testing.T) {
+	f := &fakeConn{items: seq(10)}
+	pages := 0
+	New(f.fetch, PageOptions{PageSize: 3}).Pages(context.Background())(func(items []int, err error) bool {
+		pages++
+		return false
+	})
+	if pages != 1 || len(f.calls) != 1 {
+		t.Fatalf("pages = %d, calls = %v", pages, f.calls)
+	}
+}
+
+func TestPagerMidPageError(t *testing.T) {
+	t.Run("items yields error once", func(t *testing.T) {
+		f := &fakeConn{items: seq(10), failAt: 2}
+		var got []int
+		var errs []error
+		New(f.fetch, PageOptions{PageSize: 3}).Items(context.Background())(func(v int, err error) bool {
+			if err != nil {
+				errs = append(errs, err)
+				return true
+			}
+			got = append(got, v)
+			return true
+		})
+		if !reflect.DeepEqual(got, seq(3)) || len(errs) != 1 || !errors.Is(errs[0], errFetch) {
+			t.Fatalf("items = %v, errs = %v", got, errs)
+		}
+	})
+	t.Run("all returns error", func(t *testing.T) {
+		f := &fakeConn{items: seq(10), failAt: 2}
+		if got, err := New(f.fetch, PageOptions{PageSize: 3}).All(context.Background()); !errors.Is(err, errFetch) || got != nil {
+			t.Fatalf("All = %v, %v", got, err)
+		}
+	})
+	t.Run("next retries same page", func(t *testing.T) {
+		f := &fakeConn{items: seq(10), failAt: 2}
+		p := New(f.fetch, PageOptions{PageSize: 3})
+		ctx := context.Background()
+		if _, err := p.Next(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := p.Next(ctx); !errors.Is(err, errFetch) {
+			t.Fatalf("second Next err = %v", err)
+		}
+		items, err := p.Next(ctx)
+		if err != nil || !reflect.DeepEqual(items, []int{3, 4, 5}) {
+			t.Fatalf("retry = %v, %v", items, err)
+		}
+		if want := []string{"3@", "3@3", "3@3"}; !reflect.DeepEqual(f.calls, want) {
+			t.Fatalf("calls = %v, want %v", f.calls, want)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+// Package stream 订阅结果流（用法与 bufio.Scanner 相同），供 CLI 与 pkg/railway 共用。
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Stream 订阅结果流。run 通过 fail 报告的第一个错误（或 run 的返回值）结束流并由 Err 返回；
+// 正常结束或调用 Close 时 Err 为 nil。Next/Value 不可并发调用
+type Stream[T any] struct {
+	ch     chan T
+	cur    T
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu     sync.Mutex
+	err    error
+	closed bool
+}
+
+// Buffer 消费者处理较慢时缓存的条目数，超出后订阅回调阻塞等待
+const Buffer = 256
+
+// New 在后台运行 run 直到其返回或 Close。run 通过 emit 产出条目（流已结束时返回 false），
+// 通过 fail 报告错误并结束流。
+func New[T any](ctx context.Context, run func(ctx context.Context, emit func(T) bool, fail func(error)) error) *Stream[T] {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &Stream[T]{ch: make(chan T, Buffer), cancel: cancel, done: make(chan struct{})}
+	emit := func(v T) bool {
+		select {
+		case s.ch <- v:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	go func() {
+		defer close(s.done)
+		defer close(s.ch)
+		if err := run(ctx, emit, s.fail); err != nil {
+			s.fail(err)
+		}
+	}()
+	return s
+}
+
+// fail 记录第一个错误并停止订阅；Close 之后的错误（多为 context.Canceled）被忽略
+func (s *Stream[T]) fail(err error) {
+	s.mu.Lock()
+	if s.err == nil && !s.closed {
+		s.err = err
+	}
+	s.mu.Unlock()
+	s.cancel()
+}
+
+// Next 阻塞直到下一个条目可用；流结束时返回 false
+func (s *Stream[T]) Next() bool {
+	v, ok := <-s.ch
+	if !ok {
+		var zero T
+		s.cur = zero
+		return false
+	}
+	s.cur = v
+	return true
+}
+
+// Value 返回最近一次 Next 取得的条目
+func (s *Stream[T]) Value() T { return s.cur }
+
+// Err 返回结束流的错误；应在 Next 返回 false 之后调用
+func (s *Stream[T]) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close 取消订阅并等待后台 goroutine 退出，可重复调用
+func (s *Stream[T]) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.cancel()
+	// 丢弃未读取的条目，使阻塞在 emit 上的回调尽快退出
+	for range s.ch {
+	}
+	<-s.done
+	return nil
+}
+
+// Subscriber 支持断线重连的 GraphQL 订阅，*client.Client 实现该接口
+type Subscriber interface {
+	SubscribeWithReconnect(ctx context.Context, query string, variables map[string]interface{}, onData func(data json.RawMessage), onError func(err error), onReconnect func(attempt int, err error)) error
+}
+
+// Subscribe 订阅 query：每个数据帧解码为 P 后交给 each，由 each 通过 emit 产出条目。
+// 无法解码的帧、订阅错误帧与重连耗尽后的连接错误都会结束流并由 Err 返回。
+// each 与 onReconnect（可为 nil）在同一 goroutine 中调用，可共享状态（如 client.LogCursor）而无需加锁
+func Subscribe[P, T any](ctx context.Context, c Subscriber, query string, variables map[string]interface{}, each func(p P, emit func(T) bool), onReconnect func(attempt int, err error)) *Stream[T] {
+	var cfg Config[T]
+	if onReconnect != nil {
+		cfg.OnReconnect = func(attempt int, err error, _ func(T) bool) { onReconnect(attempt, err) }
+	}
+	return SubscribeWith(ctx, c, query, variables, each, cfg)
+}
+
+// Config SubscribeWith 的可选行为
+type Config[T any] struct {
+	// OnReconnect 重新订阅前调用，可通过 emit 补发断线期间错过的条目，也可修改传给 SubscribeWith 的
+	// variables 以调整重新订阅的范围；与 each 在同一 goroutine 中调用
+	OnReconnect func(attempt int, err error, emit func(T) bool)
+	// SkipFrameError 非 nil 时，无法解码的帧、订阅错误帧与丢帧（client.ErrFramesDropped）交给它后继续订阅，
+	// 不结束流；服务端以 error 消息结束订阅、订阅被拒绝或重连耗尽时仍由 Err 返回
+	SkipFrameError func(err error)
+}
+
+// SubscribeWith 与 Subscribe 相同，按 cfg 处理重连与帧级错误
+func SubscribeWith[P, T any](ctx context.Context, c Subscriber, query string, variables map[string]interface{}, each func(p P, emit func(T) bool), cfg Config[T]) *Stream[T] {
+	return New(ctx, func(ctx context.Context, emit func(T) bool, fail func(error)) error {
+		report := fail
+		if cfg.SkipFrameError != nil {
+			report = cfg.SkipFrameError
+		}
+		var onReconnect func(int, error)
+		if cfg.OnReconnect != nil {
+			onReconnect = func(attempt int, err error) { cfg.OnReconnect(attempt, err, emit) }
+		}
+		return c.SubscribeWithReconnect(ctx, query, variables, func(data json.RawMessage) {
+			var p P
+			if err := json.Unmarshal(data, &p); err != nil {
+				report(fmt.Errorf("decode subscription payload: %w", err))
+				return
+			}
+			each(p, emit)
+		}, report, onReconnect)
+	})
+}
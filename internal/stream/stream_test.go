@@ -0,0 +1,139 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeSubscriber 依次投递 frames；error 类型的元素通过 onError 报告
+type fakeSubscriber struct {
+	frames    []interface{}
+	reconnect bool
+}
+
+func (f *fakeSubscriber) SubscribeWithReconnect(ctx context.Context, query string, variables map[string]interface{}, onData func(data json.RawMessage), onError func(err error), onReconnect func(attempt int, err error)) error {
+	for i, fr := range f.frames {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if f.reconnect && i == 1 && onReconnect != nil {
+			onReconnect(1, errors.New("socket closed"))
+		}
+		switch v := fr.(type) {
+		case error:
+			onError(v)
+		case string:
+			onData(json.RawMessage(v))
+		}
+	}
+	return nil
+}
+
+type payload struct {
+	Lines []string `json:"lines"`
+}
+
+func collect(s *Stream[string]) []string {
+	defer s.Close()
+	var got []string
+	for s.Next() {
+		got = append(got, s.Value())
+	}
+	return got
+}
+
+func each(p payload, emit func(string) bool) {
+	for _, l := range p.Lines {
+		if !emit(l) {
+			return
+		}
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	tests := []struct {
+		name    string
+		frames  []interface{}
+		want    string
+		wantErr string
+	}{
+		{"complete", []interface{}{`{"lines":["a","b"]}`, `{"lines":["c"]}`}, "a,b,c", ""},
+		{"malformed frame", []interface{}{`{"lines":["a"]}`, `{"lines":"oops"}`, `{"lines":["c"]}`}, "a", "decode subscription payload"},
+		{"subscription error", []interface{}{`{"lines":["a"]}`, errors.New("graphql: boom")}, "a", "graphql: boom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Subscribe(context.Background(), &fakeSubscriber{frames: tt.frames}, "subscription", nil, each, nil)
+			got := strings.Join(collect(s), ",")
+			if got != tt.want {
+				t.Errorf("lines = %q, want %q", got, tt.want)
+			}
+			err := s.Err()
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSubscribeReconnectSameGoroutine(t *testing.T) {
+	var resumed []string
+	var seen []string
+	sub := &fakeSubscriber{frames: []interface{}{`{"lines":["a"]}`, `{"lines":["b"]}`}, reconnect: true}
+	s := Subscribe(context.Background(), sub, "subscription", nil, func(p payload, emit func(string) bool) {
+		seen = append(seen, p.Lines...)
+		each(p, emit)
+	}, func(int, error) { resumed = append(resumed, strings.Join(seen, ",")) })
+	if got := strings.Join(collect(s), ","); got != "a,b" {
+		t.Fatalf("lines = %q", got)
+	}
+	if len(resumed) != 1 || resumed[0] != "a" {
+		t.Fatalf("onReconnect saw %v", resumed)
+	}
+}
+
+func TestCloseIgnoresLaterErrors(t *testing.T) {
+	ctx := context.Background()
+	s := New(ctx, func(ctx context.Context, emit func(int) bool, fail func(error)) error {
+		for i := 0; emit(i); i++ {
+		}
+		return ctx.Err()
+	})
+	if !s.Next() || s.Value() != 0 {
+		t.Fatal("first value")
+	}
+	s.Close()
+	if err := s.Err(); err != nil {
+		t.Fatalf("err after Close = %v", err)
+	}
+}
+
+func TestSubscribeWithSkipFrameError(t *testing.T) {
+	var skipped []string
+	frames := []interface{}{`{"lines":["a"]}`, `{"lines":"oops"}`, errors.New("graphql: boom"), `{"lines":["c"]}`}
+	s := SubscribeWith(context.Background(), &fakeSubscriber{frames: frames}, "subscription", nil, each, Config[string]{
+		SkipFrameError: func(err error) { skipped = append(skipped, err.Error()) },
+	})
+	if got := strings.Join(collect(s), ","); got != "a,c" {
+		t.Fatalf("lines = %q", got)
+	}
+	if err := s.Err(); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if len(skipped) != 2 || !strings.Contains(skipped[0], "decode subscription payload") || skipped[1] != "graphql: boom" {
+		t.Fatalf("skipped = %v", skipped)
+	}
+}
+
+func TestSubscribeWithReconnectEmits(t *testing.T) {
+	sub := &fakeSubscriber{frames: []interface{}{`{"lines":["a"]}`, `{"lines":["b"]}`}, reconnect: true}
+	s := SubscribeWith(context.Background(), sub, "subscription", nil, each, Config[string]{
+		OnReconnect: func(attempt int, _ error, emit func(string) bool) { emit("resumed") },
+	})
+	if got := strings.Join(collect(s), ","); got != "a,resumed,b" {
+		t.Fatalf("lines = %q", got)
+	}
+}
@@ -0,0 +1,28 @@
+package railway
+
+import (
+	"context"
+
+	"github.com/railwayapp/cli/internal/logquery"
+	"github.com/railwayapp/cli/pkg/railway/logs"
+)
+
+// BuildFailureReport 构建失败摘要：出错阶段、构建步骤、首个致命错误块与退出码
+type BuildFailureReport = logs.BuildFailureReport
+
+// buildReportLines 生成摘要时最多分析的构建日志行数（从末尾向前）
+const buildReportLines = 5000
+
+// AnalyzeBuildFailure 取部署最近的构建日志，划分阶段（install、build、push）并定位首个致命错误。
+// 跟随构建时也可以将 BuildLogStream 的每一行交给 logs.NewBuildAnalyzer 增量分析。
+func (c *Client) AnalyzeBuildFailure(ctx context.Context, deploymentID string) (*BuildFailureReport, error) {
+	lines, err := logquery.Tail(ctx, c.gqlClient, logquery.Build, deploymentID, logquery.Query{}, buildReportLines)
+	if err != nil {
+		return nil, err
+	}
+	a := logs.NewBuildAnalyzer()
+	for _, l := range lines {
+		a.Add(l.Time, l.Message)
+	}
+	return a.Report(), nil
+}
@@ -0,0 +1,291 @@
+package railway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	iclient "github.com/railwayapp/cli/internal/client"
+	igql "github.com/railwayapp/cli/internal/gql"
+)
+
+// ErrUnsupported 后端 schema 中不存在某项能力的任何已知变体（配合 errors.Is 使用）
+var ErrUnsupported = errors.New("railway: operation not supported by backend")
+
+// errNotInSchema 内省结果中不存在该变体，未发送请求
+var errNotInSchema = errors.New("not present in schema")
+
+// errRejected 变更执行成功但后端返回 false / 空结果
+var errRejected = errors.New("backend returned false")
+
+// VariantAttempt 单个变体的尝试记录
+type VariantAttempt struct {
+	// Variant 变体描述，如 "serviceInstanceStop(input)"
+	Variant string
+	// Err 失败原因；因 schema 中不存在而跳过时为 "not present in schema"
+	Err error
+}
+
+// CapabilityError 某项能力的所有候选变体均失败，Attempts 按尝试顺序列出每个变体及原因。
+// errors.Is(err, ErrNotFound) 等会在各次尝试的错误上匹配；
+// 若每个变体都不在 schema 中或被 schema 校验拒绝，errors.Is(err, ErrUnsupported) 为 true
+type CapabilityError struct {
+	Capability string
+	Attempts   []VariantAttempt
+}
+
+func (e *CapabilityError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "railway: %s failed", e.Capability)
+	for i, a := range e.Attempts {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		fmt.Fprintf(&b, "%s: %v", a.Variant, a.Err)
+	}
+	return b.String()
+}
+
+// Unwrap 返回各次尝试的错误
+func (e *CapabilityError) Unwrap() []error {
+	out := make([]error, 0, len(e.Attempts))
+	for _, a := range e.Attempts {
+		out = append(out, a.Err)
+	}
+	return out
+}
+
+// Is 支持 errors.Is(err, ErrUnsupported)
+func (e *CapabilityError) Is(target error) bool {
+	if target != ErrUnsupported || len(e.Attempts) == 0 {
+		return false
+	}
+	for _, a := range e.Attempts {
+		if !errors.Is(a.Err, errNotInSchema) && !isSchemaError(a.Err) {
+			return false
+		}
+	}
+	return true
+}
+
+// variant 一项能力的候选 GraphQL 形态
+type variant struct {
+	name  string
+	field string   // Mutation 根字段
+	args  []string // 使用到的参数名
+	// returnsBool 为 true 时要求根字段返回 Boolean（区分同名字段的标量/对象返回形态）
+	returnsBool bool
+	// returnsObject 为 true 时要求根字段返回对象类型
+	returnsObject bool
+	// call 执行变更，返回后端是否确认成功
+	call func(ctx context.Context) (bool, error)
+}
+
+type mutationField struct {
+	args map[string]bool
+	typ  string
+	kind string
+}
+
+// capabilities 每个 Client 一份：首次使用时内省 Mutation 根字段，并记住每项能力最近成功的变体
+type capabilities struct {
+	mu     sync.Mutex
+	probed bool
+	// probing 非 nil 时有内省请求进行中，结束时关闭；内省在锁外执行，不阻塞 supported、learn 等查询
+	probing   chan struct{}
+	mutations map[string]mutationField // nil 表示后端未开放内省
+	learned   map[string]string
+}
+
+func newCapabilities() *capabilities {
+	return &capabilities{learned: map[string]string{}}
+}
+
+// probeCapabilities 内省一次 schema；内省被禁用或失败时退化为按成功经验学习。
+// 并发调用只发送一次内省请求，其余调用等待其结果
+func (c *Client) probeCapabilities(ctx context.Context) {
+	caps := c.caps
+	caps.mu.Lock()
+	if caps.probed {
+		caps.mu.Unlock()
+		return
+	}
+	if wait := caps.probing; wait != nil {
+		caps.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+		}
+		return
+	}
+	done := make(chan struct{})
+	caps.probing = done
+	caps.mu.Unlock()
+
+	mutations, probed := c.introspectMutations(ctx)
+
+	caps.mu.Lock()
+	caps.probing = nil
+	if probed {
+		caps.probed = true
+		caps.mutations = mutations
+	}
+	caps.mu.Unlock()
+	close(done)
+}
+
+// introspectMutations 查询 Mutation 根字段；probed 为 false 表示调用方取消或临时错误，下次再探测
+func (c *Client) introspectMutations(ctx context.Context) (mutations map[string]mutationField, probed bool) {
+	var resp igql.MutationFieldsResponse
+	if err := c.gqlClient.Query(ctx, igql.MutationFieldsQuery, nil, &resp); err != nil {
+		return nil, ctx.Err() == nil && !iclient.IsRetryable(err)
+	}
+	if resp.Schema.MutationType == nil {
+		return nil, true
+	}
+	mutations = make(map[string]mutationField, len(resp.Schema.MutationType.Fields))
+	for _, f := range resp.Schema.MutationType.Fields {
+		mf := mutationField{args: make(map[string]bool, len(f.Args)), kind: f.Type.Kind}
+		if f.Type.Name != nil {
+			mf.typ = *f.Type.Name
+		}
+		if f.Type.Kind == "NON_NULL" && f.Type.OfType != nil {
+			mf.kind = f.Type.OfType.Kind
+			if f.Type.OfType.Name != nil {
+				mf.typ = *f.Type.OfType.Name
+			}
+		}
+		for _, a := range f.Args {
+			mf.args[a.Name] = true
+		}
+		mutations[f.Name] = mf
+	}
+	return mutations, true
+}
+
+// supported 判断变体是否存在于 schema；known 为 false 表示无法判断（未内省）
+func (caps *capabilities) supported(v variant) (ok, known bool) {
+	caps.mu.Lock()
+	defer caps.mu.Unlock()
+	if caps.mutations == nil {
+		return true, false
+	}
+	mf, found := caps.mutations[v.field]
+	if !found {
+		return false, true
+	}
+	for _, a := range v.args {
+		if !mf.args[a] {
+			return false, true
+		}
+	}
+	if v.returnsBool && mf.typ != "Boolean" {
+		return false, true
+	}
+	if v.returnsObject && mf.kind != "OBJECT" {
+		return false, true
+	}
+	return true, true
+}
+
+// ordered 将已学习到的变体排在最前
+func (caps *capabilities) ordered(capability string, variants []variant) []variant {
+	caps.mu.Lock()
+	name := caps.learned[capability]
+	caps.mu.Unlock()
+	if name == "" {
+		return variants
+	}
+	out := make([]variant, 0, len(variants))
+	for _, v := range variants {
+		if v.name == name {
+			out = append(out, v)
+		}
+	}
+	for _, v := range variants {
+		if v.name != name {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func (caps *capabilities) learn(capability, name string) {
+	caps.mu.Lock()
+	defer caps.mu.Unlock()
+	caps.learned[capability] = name
+}
+
+func (caps *capabilities) forget(capability, name string) {
+	caps.mu.Lock()
+	defer caps.mu.Unlock()
+	if caps.learned[capability] == name {
+		delete(caps.learned, capability)
+	}
+}
+
+func (caps *capabilities) isLearned(capability, name string) bool {
+	caps.mu.Lock()
+	defer caps.mu.Unlock()
+	return caps.learned[capability] == name
+}
+
+// invokeCapability 按 schema 与已学习结果选择变体执行。
+// 已确认存在的变体因业务错误（如 NOT_FOUND、权限）失败或后端返回 false 时直接返回，不再改用其余形态；
+// 只有 schema 校验错误才继续尝试下一个变体。
+// alternatives 为 true 表示各变体作用于不同对象（如服务域名 / 自定义域名），失败后继续尝试
+func (c *Client) invokeCapability(ctx context.Context, capability string, alternatives bool, variants []variant) error {
+	c.probeCapabilities(ctx)
+	cerr := &CapabilityError{Capability: capability}
+	for _, v := range c.caps.ordered(capability, variants) {
+		ok, known := c.caps.supported(v)
+		if !ok {
+			cerr.Attempts = append(cerr.Attempts, VariantAttempt{Variant: v.name, Err: errNotInSchema})
+			continue
+		}
+		confirmed, err := v.call(ctx)
+		if err == nil && confirmed {
+			c.caps.learn(capability, v.name)
+			return nil
+		}
+		if err == nil {
+			err = errRejected
+		}
+		cerr.Attempts = append(cerr.Attempts, VariantAttempt{Variant: v.name, Err: err})
+		if ctx.Err() != nil {
+			break
+		}
+		if isSchemaError(err) {
+			c.caps.forget(capability, v.name)
+			continue
+		}
+		if !alternatives && (known || c.caps.isLearned(capability, v.name)) {
+			break
+		}
+	}
+	return cerr
+}
+
+// isSchemaError 判断错误是否为 GraphQL 校验错误（字段/参数/选择集与 schema 不符）
+func isSchemaError(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, ge := range apiErr.Errors {
+		if strings.EqualFold(ge.Code(), "GRAPHQL_VALIDATION_FAILED") {
+			return true
+		}
+		msg := strings.ToLower(ge.Message)
+		for _, s := range []string{"cannot query field", "unknown argument", "unknown type", "has no subfields", "must have a selection", "is required, but it was not provided"} {
+			if strings.Contains(msg, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
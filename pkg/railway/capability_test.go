@@ -0,0 +1,241 @@
+package railway_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/railwayapp/cli/pkg/railway"
+	"github.com/railwayapp/cli/pkg/railway/railwaytest"
+)
+
+// capabilityFixture 返回连接 srv 的 Client 以及一个服务实例
+func capabilityFixture(t *testing.T, srv *railwaytest.Server) (c *railway.Client, serviceID, environmentID string) {
+	t.Helper()
+	c, err := railway.New(srv.ClientOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, env := srv.AddProject("demo")
+	return c, srv.AddService(p.ID, "web").ID, env.ID
+}
+
+// operations 返回第 from 个请求之后的操作名
+func operations(srv *railwaytest.Server, from int) []string {
+	var ops []string
+	for _, r := range srv.Requests()[from:] {
+		ops = append(ops, r.Operation)
+	}
+	return ops
+}
+
+// schemaRejects 模拟后端 schema 校验拒绝某个变更形态
+func schemaRejects(operation string) railwaytest.HandlerFunc {
+	return func(map[string]interface{}) (interface{}, error) {
+		return nil, railwaytest.Errorf("GRAPHQL_VALIDATION_FAILED", "Cannot query field in %s", operation)
+	}
+}
+
+func TestCapabilitySelectsVariantFromSchema(t *testing.T) {
+	srv := railwaytest.NewServer(railwaytest.WithMutationSchema(
+		railwaytest.MutationField{Name: "serviceInstanceScale", Args: []string{"serviceId", "environmentId", "replicas"}, Type: "Boolean"},
+		railwaytest.MutationField{Name: "deploymentStop", Args: []string{"id"}, Type: "Boolean"},
+	))
+	defer srv.Close()
+	c, serviceID, envID := capabilityFixture(t, srv)
+	ctx := context.Background()
+
+	if err := c.StopServiceInstance(ctx, serviceID, envID); err != nil {
+		t.Fatal(err)
+	}
+	// 只发送 schema 中存在的形态
+	if got := strings.Join(operations(srv, 0), ","); got != "MutationFields,ServiceInstanceScaleByParams" {
+		t.Fatalf("operations = %s", got)
+	}
+	if si, _ := srv.ServiceInstance(serviceID, envID); si.Replicas != 0 {
+		t.Fatalf("replicas = %d", si.Replicas)
+	}
+
+	// 同名字段按返回类型区分形态；内省只进行一次
+	d, err := srv.AddDeployment(serviceID, envID, "SUCCESS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := len(srv.Requests())
+	if err := c.StopDeployment(ctx, d.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(operations(srv, n), ","); got != "DeploymentStopSimple" {
+		t.Fatalf("operations = %s", got)
+	}
+}
+
+func TestCapabilityLearnsAndForgetsVariant(t *testing.T) {
+	srv := railwaytest.NewServer(railwaytest.WithoutIntrospection())
+	defer srv.Close()
+	c, serviceID, envID := capabilityFixture(t, srv)
+	ctx := context.Background()
+	srv.Handle("ServiceInstanceStop", schemaRejects("ServiceInstanceStop"))
+	srv.Handle("ServiceInstanceScale", schemaRejects("ServiceInstanceScale"))
+
+	// 未开放内省：依次尝试，记住第一个成功的形态
+	if err := c.StopServiceInstance(ctx, serviceID, envID); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(operations(srv, 0), ","); got != "MutationFields,ServiceInstanceStop,ServiceInstanceScale,ServiceInstanceStopByParams" {
+		t.Fatalf("first call operations = %s", got)
+	}
+	n := len(srv.Requests())
+	if err := c.StopServiceInstance(ctx, serviceID, envID); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(operations(srv, n), ","); got != "ServiceInstanceStopByParams" {
+		t.Fatalf("learned call operations = %s", got)
+	}
+
+	// 已学习的形态被 schema 校验拒绝后忘记它，继续尝试并学习新的形态
+	srv.Handle("ServiceInstanceStopByParams", schemaRejects("ServiceInstanceStopByParams"))
+	n = len(srv.Requests())
+	if err := c.StopServiceInstance(ctx, serviceID, envID); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(operations(srv, n), ","); got != "ServiceInstanceStopByParams,ServiceInstanceStop,ServiceInstanceScale,ServiceInstanceScaleByParams" {
+		t.Fatalf("relearn call operations = %s", got)
+	}
+	n = len(srv.Requests())
+	if err := c.StopServiceInstance(ctx, serviceID, envID); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(operations(srv, n), ","); got != "ServiceInstanceScaleByParams" {
+		t.Fatalf("relearned call operations = %s", got)
+	}
+}
+
+func TestCapabilityErrorWhenNoVariantLeft(t *testing.T) {
+	t.Run("not in schema", func(t *testing.T) {
+		srv := railwaytest.NewServer(railwaytest.WithMutationSchema(
+			railwaytest.MutationField{Name: "projectDelete", Args: []string{"id"}, Type: "Boolean"},
+		))
+		defer srv.Close()
+		c, serviceID, envID := capabilityFixture(t, srv)
+
+		err := c.StopServiceInstance(context.Background(), serviceID, envID)
+		var cerr *railway.CapabilityError
+		if !errors.As(err, &cerr) || !errors.Is(err, railway.ErrUnsupported) {
+			t.Fatalf("err = %v, want unsupported CapabilityError", err)
+		}
+		if len(cerr.Attempts) != 4 {
+			t.Fatalf("attempts = %+v", cerr.Attempts)
+		}
+		for _, a := range cerr.Attempts {
+			if a.Err == nil || a.Err.Error() != "not present in schema" {
+				t.Errorf("%s: %v", a.Variant, a.Err)
+			}
+		}
+		if got := strings.Join(operations(srv, 0), ","); got != "MutationFields" {
+			t.Fatalf("operations = %s", got)
+		}
+	})
+
+	t.Run("all rejected by validation", func(t *testing.T) {
+		srv := railwaytest.NewServer(railwaytest.WithoutIntrospection())
+		defer srv.Close()
+		c, serviceID, envID := capabilityFixture(t, srv)
+		ops := []string{"ServiceInstanceStop", "ServiceInstanceScale", "ServiceInstanceStopByParams", "ServiceInstanceScaleByParams"}
+		for _, op := range ops {
+			srv.Handle(op, schemaRejects(op))
+		}
+
+		err := c.StopServiceInstance(context.Background(), serviceID, envID)
+		var cerr *railway.CapabilityError
+		if !errors.As(err, &cerr) || !errors.Is(err, railway.ErrUnsupported) {
+			t.Fatalf("err = %v, want unsupported CapabilityError", err)
+		}
+		if len(cerr.Attempts) != len(ops) {
+			t.Fatalf("attempts = %+v", cerr.Attempts)
+		}
+		for i, op := range ops {
+			if !strings.Contains(cerr.Attempts[i].Err.Error(), op) {
+				t.Errorf("attempt %d = %v, want %s", i, cerr.Attempts[i].Err, op)
+			}
+		}
+	})
+
+	t.Run("business error stops", func(t *testing.T) {
+		srv := railwaytest.NewServer()
+		defer srv.Close()
+		c, _, envID := capabilityFixture(t, srv)
+
+		// 默认 schema 中存在 serviceInstanceStop(input)，NOT_FOUND 时不再改用其它形态
+		err := c.StopServiceInstance(context.Background(), "svc_missing", envID)
+		var cerr *railway.CapabilityError
+		if !errors.As(err, &cerr) || !errors.Is(err, railway.ErrNotFound) || errors.Is(err, railway.ErrUnsupported) {
+			t.Fatalf("err = %v, want NOT_FOUND CapabilityError", err)
+		}
+		if got := strings.Join(operations(srv, 0), ","); got != "MutationFields,ServiceInstanceStop" {
+			t.Fatalf("operations = %s", got)
+		}
+	})
+}
+
+func TestCapabilityRejectedVariantDoesNotFallThrough(t *testing.T) {
+	srv := railwaytest.NewServer()
+	defer srv.Close()
+	c, serviceID, envID := capabilityFixture(t, srv)
+	srv.Handle("ServiceInstanceStop", func(map[string]interface{}) (interface{}, error) {
+		return map[string]interface{}{"serviceInstanceStop": false}, nil
+	})
+
+	// serviceInstanceStop 返回 false 时不能退化为 serviceInstanceScale(replicas: 0)
+	err := c.StopServiceInstance(context.Background(), serviceID, envID)
+	var cerr *railway.CapabilityError
+	if !errors.As(err, &cerr) || len(cerr.Attempts) != 1 || cerr.Attempts[0].Variant != "serviceInstanceStop(input)" {
+		t.Fatalf("err = %v", err)
+	}
+	if got := strings.Join(operations(srv, 0), ","); got != "MutationFields,ServiceInstanceStop" {
+		t.Fatalf("operations = %s", got)
+	}
+}
+
+func TestCapabilityProbesOnceConcurrently(t *testing.T) {
+	srv := railwaytest.NewServer()
+	defer srv.Close()
+	c, serviceID, envID := capabilityFixture(t, srv)
+	arrived, release := make(chan struct{}, 1), make(chan struct{})
+	srv.Handle("MutationFields", func(map[string]interface{}) (interface{}, error) {
+		arrived <- struct{}{}
+		<-release
+		return nil, railwaytest.Errorf("GRAPHQL_VALIDATION_FAILED", "GraphQL introspection is not allowed")
+	})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- c.StopServiceInstance(context.Background(), serviceID, envID)
+		}()
+	}
+	// 内省进行中其余调用等待其结果，而不是各自再探测
+	<-arrived
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	probes := 0
+	for _, op := range operations(srv, 0) {
+		if op == "MutationFields" {
+			probes++
+		}
+	}
+	if probes != 1 {
+		t.Fatalf("introspection requests = %d, want 1", probes)
+	}
+}
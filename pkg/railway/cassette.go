@@ -0,0 +1,40 @@
+package railway
+
+import (
+	iclient "github.com/railwayapp/cli/internal/client"
+)
+
+// CassetteMode 录制/回放模式
+type CassetteMode = iclient.CassetteMode
+
+const (
+	// CassetteReplay 仅回放，不访问网络；未匹配的请求返回 ErrCassetteMiss
+	CassetteReplay = iclient.CassetteReplay
+	// CassetteRecord 访问真实后端并写入 cassette 文件
+	CassetteRecord = iclient.CassetteRecord
+	// CassetteAuto 文件存在时回放，否则录制
+	CassetteAuto = iclient.CassetteAuto
+)
+
+// ErrCassetteMiss 回放模式下没有匹配的录制交互
+var ErrCassetteMiss = iclient.ErrCassetteMiss
+
+// CassetteRedactor 自定义脱敏规则：path 为字符串值在 JSON 中的键路径（数组下标不计入），
+// 返回 (替换值, true) 时以替换值写入 cassette
+type CassetteRedactor = iclient.Redactor
+
+// WithCassette 录制或回放该 Client 的全部流量：GraphQL 请求/响应、graphql-transport-ws 帧与 /up 上传。
+// 录制时认证头、token 类字段以及 variables 下的全部变量值会被脱敏，上传的归档仅保存 SHA-256 与大小；
+// 回放按请求顺序匹配方法、URL 与请求体，可在没有 Railway 账号的 CI 中重放 SDK 调用。
+func WithCassette(path string, mode CassetteMode) Option {
+	return func(o *options) {
+		o.cassettePath = path
+		o.cassetteMode = mode
+	}
+}
+
+// WithCassetteRedactor 为 WithCassette 追加脱敏规则，在内置规则之后按顺序应用于请求体、响应体与订阅帧。
+// 回放时请求同样先脱敏再匹配，录制与回放应使用相同的规则
+func WithCassetteRedactor(fn CassetteRedactor) Option {
+	return func(o *options) { o.redactors = append(o.redactors, fn) }
+}
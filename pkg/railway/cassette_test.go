@@ -0,0 +1,304 @@
+package railway_test
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/railwayapp/cli/pkg/railway"
+	"github.com/railwayapp/cli/pkg/railway/railwaytest"
+)
+
+// go test ./pkg/railway -run Cassette -record 以 railwaytest 重新录制 testdata 中的 cassette
+var record = flag.Bool("record", false, "re-record testdata cassettes against railwaytest")
+
+// replayEndpoint 回放时使用的地址；匹配不比较主机，请求不会离开进程
+const replayEndpoint = "http://replay.invalid"
+
+const secretURL = "postgres://user:supersecret@db/x"
+
+// fixtureClient 回放 testdata/name；-record 时改为在 srv 上重新录制。
+// railwaytest 的 ID 是确定的，回放时仍准备同样的 srv，以便测试取得与录制时相同的 ID
+func fixtureClient(t *testing.T, name string, srv *railwaytest.Server) *railway.Client {
+	t.Helper()
+	mode := railway.CassetteReplay
+	if *record {
+		mode = railway.CassetteRecord
+	}
+	return cassetteClient(t, filepath.Join("testdata", name), mode, srv)
+}
+
+// cassetteClient 录制模式下连接 srv，回放模式下连接不可达的 replayEndpoint
+func cassetteClient(t *testing.T, path string, mode railway.CassetteMode, srv *railwaytest.Server, opts ...railway.Option) *railway.Client {
+	t.Helper()
+	if mode == railway.CassetteRecord {
+		opts = append(srv.ClientOptions(), opts...)
+	} else {
+		opts = append([]railway.Option{railway.WithEndpoint(replayEndpoint), railway.WithAPIToken("replay")}, opts...)
+	}
+	c, err := railway.New(append(opts, railway.WithCassette(path, mode))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestCassetteDown(t *testing.T) {
+	srv := railwaytest.NewServer()
+	defer srv.Close()
+	p, env := srv.AddProject("demo")
+	svc := srv.AddService(p.ID, "web")
+	if _, err := srv.AddDeployment(svc.ID, env.ID, "SUCCESS"); err != nil {
+		t.Fatal(err)
+	}
+	c := fixtureClient(t, "down.json", srv)
+
+	if err := c.Down(context.Background(), p.ID, env.ID, svc.ID); err != nil {
+		t.Fatal(err)
+	}
+	if *record {
+		return
+	}
+	// 回放不会重复使用已匹配的交互
+	if err := c.Down(context.Background(), p.ID, env.ID, svc.ID); !errors.Is(err, railway.ErrCassetteMiss) {
+		t.Fatalf("second Down err = %v, want ErrCassetteMiss", err)
+	}
+}
+
+func TestCassetteEnsureServiceSleepApplication(t *testing.T) {
+	srv := railwaytest.NewServer()
+	defer srv.Close()
+	p, env := srv.AddProject("demo")
+	svc := srv.AddService(p.ID, "web")
+	srv.Handle("environmentConfig", func(map[string]interface{}) (interface{}, error) {
+		return map[string]interface{}{
+			"environment": map[string]interface{}{
+				"id": env.ID,
+				"config": map[string]interface{}{"services": map[string]interface{}{svc.ID: map[string]interface{}{
+					"deploy":    map[string]interface{}{"sleepApplication": false},
+					"variables": map[string]interface{}{"DATABASE_URL": map[string]interface{}{"value": secretURL}},
+				}}},
+				"serviceInstances": map[string]interface{}{"edges": []interface{}{}},
+				"volumeInstances":  map[string]interface{}{"edges": []interface{}{}},
+			},
+			"environmentStagedChanges": map[string]interface{}{"id": "stg_1", "status": "STAGED", "patch": map[string]interface{}{}},
+		}, nil
+	})
+	srv.Handle("stageEnvironmentChanges", func(map[string]interface{}) (interface{}, error) {
+		return map[string]interface{}{"environmentStageChanges": map[string]interface{}{"id": "stg_2"}}, nil
+	})
+	srv.Handle("environmentPatchCommitStaged", func(map[string]interface{}) (interface{}, error) {
+		return map[string]interface{}{"environmentPatchCommitStaged": "cmt_1"}, nil
+	})
+	c := fixtureClient(t, "ensure_sleep.json", srv)
+
+	changed, stageID, commitID, err := c.EnsureServiceSleepApplication(context.Background(), env.ID, svc.ID, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed || stageID == nil || *stageID != "stg_2" || commitID == nil || *commitID != "cmt_1" {
+		t.Fatalf("changed=%v stage=%v commit=%v", changed, stageID, commitID)
+	}
+	assertNoSecret(t, "testdata/ensure_sleep.json", secretURL)
+}
+
+func TestCassetteDeployTemplateWithConfig(t *testing.T) {
+	srv := railwaytest.NewServer()
+	defer srv.Close()
+	p, env := srv.AddProject("demo")
+	srv.Handle("TemplateDetail", func(map[string]interface{}) (interface{}, error) {
+		return map[string]interface{}{"template": map[string]interface{}{
+			"id":   "tpl_1",
+			"name": "postgres",
+			"serializedConfig": map[string]interface{}{"services": map[string]interface{}{"svc-a": map[string]interface{}{
+				"name": "Postgres",
+				"variables": map[string]interface{}{
+					"PGPORT":       map[string]interface{}{"defaultValue": "5432"},
+					"DATABASE_URL": map[string]interface{}{"isOptional": false},
+				},
+			}}},
+		}}, nil
+	})
+	c := fixtureClient(t, "template_deploy.json", srv)
+
+	res, err := c.DeployTemplateWithConfig(context.Background(), railway.TemplateDeployOptions{
+		ProjectID:     p.ID,
+		EnvironmentID: env.ID,
+		TemplateCode:  "postgres",
+		Variables:     map[string]string{"Postgres.DATABASE_URL": secretURL},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ProjectID != p.ID || res.WorkflowID == "" {
+		t.Fatalf("result = %+v", res)
+	}
+	assertNoSecret(t, "testdata/template_deploy.json", secretURL)
+}
+
+func TestCassetteRedactsVariables(t *testing.T) {
+	srv := railwaytest.NewServer()
+	defer srv.Close()
+	p, env := srv.AddProject("acme-internal")
+	svc := srv.AddService(p.ID, "web")
+	srv.SetVariables(p.ID, env.ID, svc.ID, map[string]string{"DATABASE_URL": secretURL})
+	path := filepath.Join(t.TempDir(), "variables.json")
+	// 自定义规则：项目名
+	redactName := railway.WithCassetteRedactor(func(path []string, value string) (string, bool) {
+		if strings.HasPrefix(value, "acme-") {
+			return "acme-REDACTED", true
+		}
+		return "", false
+	})
+	ctx := context.Background()
+
+	c := cassetteClient(t, path, railway.CassetteRecord, srv, redactName)
+	vars, err := c.GetVariables(ctx, p.ID, env.ID, svc.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vars["DATABASE_URL"] != secretURL {
+		t.Fatalf("live variables = %v", vars)
+	}
+	if err := c.UpsertVariables(ctx, p.ID, env.ID, &svc.ID, false, map[string]string{"API_KEY": "sk-live-123"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetProject(ctx, p.ID); err != nil {
+		t.Fatal(err)
+	}
+	assertNoSecret(t, path, "supersecret", "sk-live-123", "acme-internal")
+
+	c = cassetteClient(t, path, railway.CassetteReplay, nil, redactName)
+	vars, err = c.GetVariables(ctx, p.ID, env.ID, svc.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vars["DATABASE_URL"] != "REDACTED" {
+		t.Fatalf("replayed variables = %v", vars)
+	}
+	// 请求体按同样的规则脱敏后匹配，变量值不同也能回放
+	if err := c.UpsertVariables(ctx, p.ID, env.ID, &svc.ID, false, map[string]string{"API_KEY": "sk-other"}); err != nil {
+		t.Fatal(err)
+	}
+	proj, err := c.GetProject(ctx, p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proj.Name != "acme-REDACTED" {
+		t.Fatalf("replayed project name = %q", proj.Name)
+	}
+}
+
+func TestCassetteReplayEndsSubscription(t *testing.T) {
+	srv := railwaytest.NewServer()
+	defer srv.Close()
+	p, env := srv.AddProject("demo")
+	svc := srv.AddService(p.ID, "web")
+	d, err := srv.AddDeployment(svc.ID, env.ID, "BUILDING")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = srv.AppendBuildLog(d.ID, "step 1", nil)
+	_ = srv.AppendBuildLog(d.ID, "step 2", nil)
+	path := filepath.Join(t.TempDir(), "logs.json")
+
+	// 录制在读到两行后由客户端取消，cassette 中没有 complete 帧
+	c := cassetteClient(t, path, railway.CassetteRecord, srv)
+	ctx, cancel := context.WithCancel(context.Background())
+	s := c.BuildLogStream(ctx, d.ID, "", 10)
+	for n := 0; n < 2 && s.Next(); n++ {
+	}
+	cancel()
+	for s.Next() {
+	}
+	s.Close()
+
+	c = cassetteClient(t, path, railway.CassetteReplay, nil)
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s = c.BuildLogStream(ctx, d.ID, "", 10)
+	defer s.Close()
+	var got []string
+	for s.Next() {
+		got = append(got, s.Value().Message)
+	}
+	if err := s.Err(); err != nil {
+		t.Fatalf("replay err = %v after %v", err, got)
+	}
+	if strings.Join(got, ",") != "step 1,step 2" {
+		t.Fatalf("replayed lines = %v", got)
+	}
+}
+
+func TestCassetteReplayConcurrentSubscriptions(t *testing.T) {
+	srv := railwaytest.NewServer()
+	defer srv.Close()
+	p, env := srv.AddProject("demo")
+	svc := srv.AddService(p.ID, "web")
+	d, err := srv.AddDeployment(svc.ID, env.ID, "SUCCESS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = srv.AppendBuildLog(d.ID, "build A", nil)
+	_ = srv.AppendDeployLog(d.ID, "deploy B", nil)
+	path := filepath.Join(t.TempDir(), "concurrent.json")
+
+	// 两路订阅共享同一连接；录制时读到各自的一行后取消
+	c := cassetteClient(t, path, railway.CassetteRecord, srv)
+	ctx, cancel := context.WithCancel(context.Background())
+	build := c.BuildLogStream(ctx, d.ID, "", 10)
+	deploy := c.DeploymentLogStream(ctx, d.ID, "", 10)
+	if !build.Next() || !deploy.Next() {
+		t.Fatalf("record: build err %v, deploy err %v", build.Err(), deploy.Err())
+	}
+	cancel()
+	build.Close()
+	deploy.Close()
+
+	// 回放时以相反顺序发起订阅，帧仍按订阅内容交给对应的订阅
+	c = cassetteClient(t, path, railway.CassetteReplay, nil)
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	deploy = c.DeploymentLogStream(ctx, d.ID, "", 10)
+	build = c.BuildLogStream(ctx, d.ID, "", 10)
+	collect := func(s *railway.LogStream) <-chan string {
+		ch := make(chan string, 1)
+		go func() {
+			defer s.Close()
+			var got []string
+			for s.Next() {
+				got = append(got, s.Value().Message)
+			}
+			if err := s.Err(); err != nil {
+				got = append(got, "err: "+err.Error())
+			}
+			ch <- strings.Join(got, ",")
+		}()
+		return ch
+	}
+	gotDeploy, gotBuild := collect(deploy), collect(build)
+	if got := <-gotBuild; got != "build A" {
+		t.Errorf("replayed build logs = %q", got)
+	}
+	if got := <-gotDeploy; got != "deploy B" {
+		t.Errorf("replayed deploy logs = %q", got)
+	}
+}
+
+func assertNoSecret(t *testing.T, path string, secrets ...string) {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range secrets {
+		if strings.Contains(string(b), s) {
+			t.Errorf("%s contains %q", path, s)
+		}
+	}
+}
@@ -1,29 +1,41 @@
 package railway
 
 import (
-	"os"
+	"net/http"
 	"strings"
 
 	iclient "github.com/railwayapp/cli/internal/client"
 	"github.com/railwayapp/cli/internal/config"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Option 用于配置 Client
 type Option func(*options)
 
 type options struct {
-	apiToken     *string
-	projectToken *string
-	environment  *string
+	apiToken       *string
+	projectToken   *string
+	environment    *string
+	endpoint       string
+	httpClient     *http.Client
+	wsURL          string
+	uploadURL      string
+	rateLimit      RateLimitOptions
+	reconnect      ReconnectOptions
+	keepalive      KeepaliveOptions
+	interceptors   []Interceptor
+	tracerProvider trace.TracerProvider
+	cassettePath   string
+	cassetteMode   CassetteMode
+	redactors      []CassetteRedactor
 }
 
-// WithAPIToken 使用 API Token（优先级：RAILWAY_TOKEN > RAILWAY_API_TOKEN > 配置文件 token）
-// 注意：此实现通过进程环境变量注入，避免写入本地配置文件。
+// WithAPIToken 使用 API Token（仅作用于当前 Client，不修改进程环境变量）
 func WithAPIToken(token string) Option {
 	return func(o *options) { o.apiToken = &token }
 }
 
-// WithProjectToken 使用项目访问 Token（将通过环境变量 RAILWAY_TOKEN 注入）
+// WithProjectToken 使用项目访问 Token（同时提供时优先于 WithAPIToken）
 func WithProjectToken(token string) Option {
 	return func(o *options) { o.projectToken = &token }
 }
@@ -33,38 +45,113 @@ func WithEnvironment(env string) Option {
 	return func(o *options) { o.environment = &env }
 }
 
+// WithEndpoint 指定 Backboard 根地址（如 https://backboard.railway.com 或 httptest 服务地址），
+// GraphQL（/graphql/v2、/graphql/internal）、WebSocket 订阅与 /up 上传地址默认均由其派生
+func WithEndpoint(endpoint string) Option {
+	return func(o *options) { o.endpoint = endpoint }
+}
+
+// WithHTTPClient 使用自定义 http.Client 发送 GraphQL 请求与上传；若其 Transport 为 *http.Transport，
+// WebSocket 拨号也会复用其代理与 TLS 配置
+func WithHTTPClient(hc *http.Client) Option {
+	return func(o *options) { o.httpClient = hc }
+}
+
+// WithWebSocketURL 指定 graphql-transport-ws 订阅地址（如 wss://gateway.example.com/graphql/v2）
+func WithWebSocketURL(wsURL string) Option {
+	return func(o *options) { o.wsURL = wsURL }
+}
+
+// WithUploadURL 指定 /up 上传的根地址，实际请求为 {uploadURL}/project/{id}/environment/{id}/up
+func WithUploadURL(uploadURL string) Option {
+	return func(o *options) { o.uploadURL = uploadURL }
+}
+
+// RateLimitOptions 客户端令牌桶与 429/503 重试配置
+type RateLimitOptions = iclient.RateLimitOptions
+
+// WithRateLimit 配置客户端令牌桶（由该 Client 的所有查询、变更、上传与订阅共享）以及
+// 遇到 429/503 或限流类 GraphQL 错误时按 Retry-After 重试的策略
+func WithRateLimit(rl RateLimitOptions) Option {
+	return func(o *options) { o.rateLimit = rl }
+}
+
+// ReconnectOptions 订阅连接断开后的重新订阅策略
+type ReconnectOptions = iclient.ReconnectOptions
+
+// ReconnectUnlimited 作为 ReconnectOptions.MaxAttempts 时不限重连次数
+const ReconnectUnlimited = iclient.ReconnectUnlimited
+
+// WithReconnect 配置订阅断线后的指数退避重连（默认最多连续 5 次，MaxAttempts<0 关闭）。
+// 日志订阅重连后从最后一条日志继续并去重，状态订阅重连后补发当前状态
+func WithReconnect(rc ReconnectOptions) Option {
+	return func(o *options) { o.reconnect = rc }
+}
+
+// KeepaliveOptions 订阅连接的心跳配置
+type KeepaliveOptions = iclient.KeepaliveOptions
+
+// WithKeepalive 配置订阅连接的客户端心跳：每 Interval 发送 ping（默认 15s，<0 关闭），
+// Interval+Timeout（Timeout 默认 10s）内未收到任何帧即判定连接失效并断开，由重连策略接管
+func WithKeepalive(ka KeepaliveOptions) Option {
+	return func(o *options) { o.keepalive = ka }
+}
+
 // Client 面向外部使用者的 Railway 客户端
 type Client struct {
 	cfg       *config.Config
 	gqlClient *iclient.Client
+	caps      *capabilities
 }
 
-// New 创建 Client。若提供 WithAPIToken，将通过环境变量注入 token。
+// New 创建 Client。未通过选项提供的 token/环境以 RAILWAY_TOKEN、RAILWAY_API_TOKEN、RAILWAY_ENV 及配置文件为默认值。
 func New(opts ...Option) (*Client, error) {
 	var o options
 	for _, fn := range opts {
 		fn(&o)
 	}
 
-	if o.apiToken != nil && strings.TrimSpace(*o.apiToken) != "" {
-		_ = os.Setenv("RAILWAY_API_TOKEN", *o.apiToken)
+	env := config.GetEnvironment()
+	if o.environment != nil && strings.TrimSpace(*o.environment) != "" {
+		env = config.ParseEnvironment(*o.environment)
 	}
-	if o.projectToken != nil && strings.TrimSpace(*o.projectToken) != "" {
-		_ = os.Setenv("RAILWAY_TOKEN", *o.projectToken)
+
+	copts := iclient.Options{
+		Endpoint:       o.endpoint,
+		HTTPClient:     o.httpClient,
+		WebSocketURL:   o.wsURL,
+		UploadURL:      o.uploadURL,
+		RateLimit:      o.rateLimit,
+		Reconnect:      o.reconnect,
+		Keepalive:      o.keepalive,
+		Interceptors:   o.interceptors,
+		TracerProvider: o.tracerProvider,
 	}
-	if o.environment != nil && strings.TrimSpace(*o.environment) != "" {
-		_ = os.Setenv("RAILWAY_ENV", strings.TrimSpace(*o.environment))
+	if o.projectToken != nil && strings.TrimSpace(*o.projectToken) != "" {
+		copts.Credentials = &iclient.Credentials{Token: strings.TrimSpace(*o.projectToken), Kind: iclient.TokenKindProject}
+	} else if o.apiToken != nil && strings.TrimSpace(*o.apiToken) != "" {
+		copts.Credentials = &iclient.Credentials{Token: strings.TrimSpace(*o.apiToken), Kind: iclient.TokenKindAPI}
 	}
 
-	cfg, err := config.New()
+	cfg, err := config.NewForEnvironment(env)
 	if err != nil {
 		return nil, err
 	}
-	gqlc, err := iclient.NewAuthorized(cfg)
+	if o.cassettePath != "" {
+		cassette, err := iclient.OpenCassette(o.cassettePath, o.cassetteMode)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range o.redactors {
+			cassette.AddRedactor(r)
+		}
+		copts.Cassette = cassette
+	}
+	gqlc, err := iclient.NewWithOptions(cfg, copts)
 	if err != nil {
 		return nil, err
 	}
-	return &Client{cfg: cfg, gqlClient: gqlc}, nil
+	return &Client{cfg: cfg, gqlClient: gqlc, caps: newCapabilities()}, nil
 }
 
 func getString(m map[string]any, keys ...string) string {
@@ -84,3 +171,11 @@ func nullIfEmpty(s string) any {
 	}
 	return s
 }
+
+// optString 空串视为未设置，用于生成的 *Variables 中的可空字段
+func optString(s string) *string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	return &s
+}
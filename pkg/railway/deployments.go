@@ -2,34 +2,43 @@ package railway
 
 import (
 	"context"
-	"strings"
 
 	igql "github.com/railwayapp/cli/internal/gql"
 )
 
 // Deployment 部署信息（最小字段）
 type Deployment struct {
-	ID      string
-	Status  string
-	URL     *string
-	Service Service
+	ID        string
+	Status    string
+	URL       *string
+	CreatedAt string
+	UpdatedAt string
+	Service   Service
 }
 
-// ListDeployments 列出部署
+// ListDeployments 列出服务（或环境内全部服务）的所有部署，最新的在前
 func (c *Client) ListDeployments(ctx context.Context, projectID, environmentID string, serviceID *string) ([]Deployment, error) {
-	vars := map[string]any{"projectId": projectID, "environmentId": environmentID, "serviceId": nil}
-	if serviceID != nil && strings.TrimSpace(*serviceID) != "" {
-		vars["serviceId"] = *serviceID
-	}
-	var resp igql.DeploymentsResponse
-	if err := c.gqlClient.Query(ctx, igql.DeploymentsQuery, vars, &resp); err != nil {
-		return nil, err
-	}
-	out := make([]Deployment, 0, len(resp.Deployments.Edges))
-	for _, e := range resp.Deployments.Edges {
-		out = append(out, Deployment{ID: e.Node.ID, Status: e.Node.Status, URL: e.Node.URL, Service: Service{ID: e.Node.Service.ID, Name: e.Node.Service.Name}})
-	}
-	return out, nil
+	return c.DeploymentsPager(projectID, environmentID, serviceID, PageOptions{}).All(ctx)
+}
+
+// DeploymentsPager 按页列出部署；serviceID 为空时列出环境内全部服务的部署
+func (c *Client) DeploymentsPager(projectID, environmentID string, serviceID *string, opts PageOptions) *Pager[Deployment] {
+	return NewPager(func(ctx context.Context, first int, after string) ([]Deployment, PageInfo, error) {
+		vars := igql.DeploymentsVariables{ProjectID: projectID, EnvironmentID: environmentID, First: &first, After: optString(after)}
+		if serviceID != nil {
+			vars.ServiceID = optString(*serviceID)
+		}
+		resp, err := igql.Deployments(ctx, c.gqlClient, vars)
+		if err != nil {
+			return nil, PageInfo{}, err
+		}
+		out := make([]Deployment, 0, len(resp.Deployments.Edges))
+		for _, e := range resp.Deployments.Edges {
+			n := e.Node
+			out = append(out, Deployment{ID: n.ID, Status: n.Status, URL: n.URL, CreatedAt: n.CreatedAt, UpdatedAt: n.UpdatedAt, Service: Service{ID: n.Service.ID, Name: n.Service.Name}})
+		}
+		return out, pageInfo(resp.Deployments.PageInfo), nil
+	}, opts)
 }
 
 // DeployServiceInstance 触发服务实例部署
@@ -2,7 +2,6 @@ package railway
 
 import (
 	"context"
-	"fmt"
 
 	igql "github.com/railwayapp/cli/internal/gql"
 )
@@ -141,21 +140,28 @@ func (c *Client) CreateCustomDomain(ctx context.Context, projectID, environmentI
 
 // DeleteDomain 删除服务域名或自定义域名（传入 ID，内部尝试两种删除）
 func (c *Client) DeleteDomain(ctx context.Context, id string) error {
-	var raw1 struct {
-		ServiceDomainDelete bool `json:"serviceDomainDelete"`
-	}
-	if err := c.gqlClient.Mutate(ctx, igql.ServiceDomainDeleteMutation, map[string]any{"id": id}, &raw1); err == nil {
-		if raw1.ServiceDomainDelete {
-			return nil
-		}
-	}
-	var raw2 struct {
-		CustomDomainDelete bool `json:"customDomainDelete"`
-	}
-	if err := c.gqlClient.Mutate(ctx, igql.CustomDomainDeleteMutation, map[string]any{"id": id}, &raw2); err == nil {
-		if raw2.CustomDomainDelete {
-			return nil
-		}
-	}
-	return fmt.Errorf("failed to delete domain: %s", id)
+	vars := map[string]any{"id": id}
+	// 服务域名与自定义域名是不同对象，某一变体返回 NOT_FOUND 时仍需尝试另一个
+	return c.invokeCapability(ctx, "delete domain", true, []variant{
+		{
+			name: "serviceDomainDelete(id)", field: "serviceDomainDelete", args: []string{"id"},
+			call: func(ctx context.Context) (bool, error) {
+				var resp struct {
+					ServiceDomainDelete bool `json:"serviceDomainDelete"`
+				}
+				err := c.gqlClient.Mutate(ctx, igql.ServiceDomainDeleteMutation, vars, &resp)
+				return resp.ServiceDomainDelete, err
+			},
+		},
+		{
+			name: "customDomainDelete(id)", field: "customDomainDelete", args: []string{"id"},
+			call: func(ctx context.Context) (bool, error) {
+				var resp struct {
+					CustomDomainDelete bool `json:"customDomainDelete"`
+				}
+				err := c.gqlClient.Mutate(ctx, igql.CustomDomainDeleteMutation, vars, &resp)
+				return resp.CustomDomainDelete, err
+			},
+		},
+	})
 }
@@ -2,7 +2,6 @@ package railway
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -11,77 +10,84 @@ import (
 	igql "github.com/railwayapp/cli/internal/gql"
 )
 
-// StopServiceInstance 尝试停止指定环境下的服务实例（多种 GraphQL 变体容错）
+// StopServiceInstance 停止指定环境下的服务实例。
+// 后端存在 stop/scale=0 及 input/参数式多种形态，由能力探测选择可用变体；全部失败时返回 *CapabilityError
 func (c *Client) StopServiceInstance(ctx context.Context, serviceID, environmentID string) error {
-	// 优先 input 形式：serviceInstanceStop
-	var resp igql.ServiceInstanceStopResponse
-	if err := c.gqlClient.Mutate(ctx, igql.ServiceInstanceStopMutation, map[string]any{"input": igql.ServiceInstanceStopInput{ServiceID: serviceID, EnvironmentID: environmentID}}, &resp); err == nil {
-		if resp.ServiceInstanceStop {
-			return nil
-		}
-	}
-
-	// 退路：缩放为 0 副本
-	var scaleResp igql.ServiceInstanceScaleResponse
-	if err := c.gqlClient.Mutate(ctx, igql.ServiceInstanceScaleMutation, map[string]any{"input": igql.ServiceInstanceScaleInput{ServiceID: serviceID, EnvironmentID: environmentID, Replicas: 0}}, &scaleResp); err == nil {
-		if scaleResp.ServiceInstanceScale {
-			return nil
-		}
-	}
-
-	// 退路：参数式 stop
-	var paramResp igql.ServiceInstanceStopResponse
-	if err := c.gqlClient.Mutate(ctx, igql.ServiceInstanceStopByParamsMutation, map[string]any{"serviceId": serviceID, "environmentId": environmentID}, &paramResp); err == nil {
-		if paramResp.ServiceInstanceStop {
-			return nil
-		}
-	}
-
-	// 退路：参数式 scale=0
-	var paramScaleResp igql.ServiceInstanceScaleResponse
-	if err := c.gqlClient.Mutate(ctx, igql.ServiceInstanceScaleByParamsMutation, map[string]any{"serviceId": serviceID, "environmentId": environmentID}, &paramScaleResp); err == nil {
-		if paramScaleResp.ServiceInstanceScale {
-			return nil
-		}
-	}
-
-	return errors.New("stop service instance not supported by backend")
+	input := map[string]any{"serviceId": serviceID, "environmentId": environmentID}
+	return c.invokeCapability(ctx, "stop service instance", false, []variant{
+		{
+			name: "serviceInstanceStop(input)", field: "serviceInstanceStop", args: []string{"input"},
+			call: func(ctx context.Context) (bool, error) {
+				var resp igql.ServiceInstanceStopResponse
+				err := c.gqlClient.Mutate(ctx, igql.ServiceInstanceStopMutation, map[string]any{"input": igql.ServiceInstanceStopInput{ServiceID: serviceID, EnvironmentID: environmentID}}, &resp)
+				return resp.ServiceInstanceStop, err
+			},
+		},
+		{
+			name: "serviceInstanceScale(input, replicas: 0)", field: "serviceInstanceScale", args: []string{"input"},
+			call: func(ctx context.Context) (bool, error) {
+				var resp igql.ServiceInstanceScaleResponse
+				err := c.gqlClient.Mutate(ctx, igql.ServiceInstanceScaleMutation, map[string]any{"input": igql.ServiceInstanceScaleInput{ServiceID: serviceID, EnvironmentID: environmentID, Replicas: 0}}, &resp)
+				return resp.ServiceInstanceScale, err
+			},
+		},
+		{
+			name: "serviceInstanceStop(serviceId, environmentId)", field: "serviceInstanceStop", args: []string{"serviceId", "environmentId"},
+			call: func(ctx context.Context) (bool, error) {
+				var resp igql.ServiceInstanceStopResponse
+				err := c.gqlClient.Mutate(ctx, igql.ServiceInstanceStopByParamsMutation, input, &resp)
+				return resp.ServiceInstanceStop, err
+			},
+		},
+		{
+			name: "serviceInstanceScale(serviceId, environmentId, replicas: 0)", field: "serviceInstanceScale", args: []string{"serviceId", "environmentId", "replicas"},
+			call: func(ctx context.Context) (bool, error) {
+				var resp igql.ServiceInstanceScaleResponse
+				err := c.gqlClient.Mutate(ctx, igql.ServiceInstanceScaleByParamsMutation, input, &resp)
+				return resp.ServiceInstanceScale, err
+			},
+		},
+	})
 }
 
-// StopDeployment 尝试停止/取消指定部署（多种 GraphQL 变体容错）
+// StopDeployment 停止/取消指定部署，由能力探测在 deploymentStop（对象/布尔返回）、deploymentCancel、deploymentAbort 中选择；
+// 全部失败时返回 *CapabilityError
 func (c *Client) StopDeployment(ctx context.Context, deploymentID string) error {
-	// 尝试返回对象形式
-	var resp igql.DeploymentStopResponse
-	if err := c.gqlClient.Mutate(ctx, igql.DeploymentStopMutation, map[string]any{"id": deploymentID}, &resp); err == nil {
-		if resp.DeploymentStop.ID != "" {
-			return nil
-		}
-	}
-
-	// 布尔返回
-	var simpleResp igql.DeploymentStopSimpleResponse
-	if err := c.gqlClient.Mutate(ctx, igql.DeploymentStopSimpleMutation, map[string]any{"id": deploymentID}, &simpleResp); err == nil {
-		if simpleResp.DeploymentStop {
-			return nil
-		}
-	}
-
-	// 兼容其他命名
-	var cancelResp igql.DeploymentCancelResponse
-	if err := c.gqlClient.Mutate(ctx, igql.DeploymentCancelMutation, map[string]any{"id": deploymentID}, &cancelResp); err == nil {
-		if cancelResp.DeploymentCancel {
-			return nil
-		}
-	}
-
-	var abortResp igql.DeploymentAbortResponse
-	if err := c.gqlClient.Mutate(ctx, igql.DeploymentAbortMutation, map[string]any{"id": deploymentID}, &abortResp); err == nil {
-		if abortResp.DeploymentAbort {
-			return nil
-		}
-	}
-
-	return fmt.Errorf("failed to stop deployment %s: backend not supported", deploymentID)
+	vars := map[string]any{"id": deploymentID}
+	return c.invokeCapability(ctx, "stop deployment", false, []variant{
+		{
+			name: "deploymentStop(id) { id }", field: "deploymentStop", args: []string{"id"}, returnsObject: true,
+			call: func(ctx context.Context) (bool, error) {
+				var resp igql.DeploymentStopResponse
+				err := c.gqlClient.Mutate(ctx, igql.DeploymentStopMutation, vars, &resp)
+				return resp.DeploymentStop.ID != "", err
+			},
+		},
+		{
+			name: "deploymentStop(id): Boolean", field: "deploymentStop", args: []string{"id"}, returnsBool: true,
+			call: func(ctx context.Context) (bool, error) {
+				var resp igql.DeploymentStopSimpleResponse
+				err := c.gqlClient.Mutate(ctx, igql.DeploymentStopSimpleMutation, vars, &resp)
+				return resp.DeploymentStop, err
+			},
+		},
+		{
+			name: "deploymentCancel(id)", field: "deploymentCancel", args: []string{"id"},
+			call: func(ctx context.Context) (bool, error) {
+				var resp igql.DeploymentCancelResponse
+				err := c.gqlClient.Mutate(ctx, igql.DeploymentCancelMutation, vars, &resp)
+				return resp.DeploymentCancel, err
+			},
+		},
+		{
+			name: "deploymentAbort(id)", field: "deploymentAbort", args: []string{"id"},
+			call: func(ctx context.Context) (bool, error) {
+				var resp igql.DeploymentAbortResponse
+				err := c.gqlClient.Mutate(ctx, igql.DeploymentAbortMutation, vars, &resp)
+				return resp.DeploymentAbort, err
+			},
+		},
+	})
 }
 
 // DeleteDeployment 删除部署（对齐 CLI down 子命令行为）
@@ -100,26 +106,21 @@ func (c *Client) DeleteDeployment(ctx context.Context, deploymentID string) erro
 
 // Down 删除最近一次成功的部署（参考 internal/commands/down.go 行为）
 func (c *Client) Down(ctx context.Context, projectID, environmentID, serviceID string) error {
-	// 查询部署（需要 createdAt 字段便于排序）
-	var deps igql.DeploymentsResponse
-	vars := map[string]any{
-		"projectId":     projectID,
-		"environmentId": environmentID,
-		"serviceId":     serviceID,
-	}
-	if err := c.gqlClient.Query(ctx, igql.DeploymentsQuery, vars, &deps); err != nil {
+	// 遍历全部分页，避免最近一次成功部署落在首页之外
+	deps, err := c.ListDeployments(ctx, projectID, environmentID, &serviceID)
+	if err != nil {
 		return err
 	}
 
 	type depNode struct{ ID, Status, CreatedAt string }
-	nodes := make([]depNode, 0, len(deps.Deployments.Edges))
-	for _, ed := range deps.Deployments.Edges {
-		if strings.EqualFold(ed.Node.Status, "SUCCESS") {
-			nodes = append(nodes, depNode{ID: ed.Node.ID, Status: ed.Node.Status, CreatedAt: ed.Node.CreatedAt})
+	nodes := make([]depNode, 0, len(deps))
+	for _, d := range deps {
+		if strings.EqualFold(d.Status, "SUCCESS") {
+			nodes = append(nodes, depNode{ID: d.ID, Status: d.Status, CreatedAt: d.CreatedAt})
 		}
 	}
 	if len(nodes) == 0 {
-		return fmt.Errorf("no successful deployment found")
+		return fmt.Errorf("no successful deployment: %w", ErrNotFound)
 	}
 
 	parse := func(s string) (time.Time, bool) {
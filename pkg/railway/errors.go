@@ -0,0 +1,28 @@
+package railway
+
+import (
+	iclient "github.com/railwayapp/cli/internal/client"
+)
+
+// APIError Backboard 返回的错误，携带 GraphQL 错误列表、extensions.code、HTTP 状态码与请求 ID。
+// 可通过 errors.As(err, &apiErr) 取出详情，或通过 errors.Is(err, ErrNotFound) 等判断类别。
+type APIError = iclient.APIError
+
+// GraphQLError 单条 GraphQL 错误
+type GraphQLError = iclient.GraphQLError
+
+// 错误类别（与 errors.Is 搭配使用）
+var (
+	ErrNotFound     = iclient.ErrNotFound
+	ErrUnauthorized = iclient.ErrUnauthorized
+	ErrRateLimited  = iclient.ErrRateLimited
+	ErrConflict     = iclient.ErrConflict
+)
+
+// ErrFramesDropped 订阅的消费者过慢，缓冲区溢出后丢弃了部分消息；由 Stream.Err 返回
+var ErrFramesDropped = iclient.ErrFramesDropped
+
+// IsRetryable 判断错误是否为临时性错误（限流、5xx、网络超时/连接中断）
+func IsRetryable(err error) bool {
+	return iclient.IsRetryable(err)
+}
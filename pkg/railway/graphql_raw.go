@@ -2,7 +2,6 @@ package railway
 
 import (
 	"context"
-	"fmt"
 
 	igql "github.com/railwayapp/cli/internal/gql"
 )
@@ -17,64 +16,76 @@ func (c *Client) GraphQLMutate(ctx context.Context, mutation string, variables m
 	return c.gqlClient.Mutate(ctx, mutation, variables, out)
 }
 
-// 底层 raw：CreateProjectToken
+// 底层 raw：CreateProjectToken（input / 参数式两种形态，由能力探测选择）
 func (c *Client) createProjectTokenRaw(ctx context.Context, projectID, environmentID, name string) (string, error) {
-	var resp igql.ProjectTokenCreateResponse
-	input := igql.ProjectTokenCreateInput{Name: name, ProjectID: projectID, EnvironmentID: environmentID}
-	if err := c.gqlClient.Mutate(ctx, igql.ProjectTokenCreateMutation, map[string]any{"input": input}, &resp); err == nil {
-		if resp.ProjectTokenCreate != "" {
-			return resp.ProjectTokenCreate, nil
-		}
-	}
-	// fallback
-	var resp2 igql.ProjectTokenCreateResponse
-	if err := c.gqlClient.Mutate(ctx, igql.ProjectTokenCreateByParamsMutation, map[string]any{"projectId": projectID, "environmentId": environmentID, "name": name}, &resp2); err != nil {
+	var token string
+	err := c.invokeCapability(ctx, "create project token", false, []variant{
+		{
+			name: "projectTokenCreate(input)", field: "projectTokenCreate", args: []string{"input"},
+			call: func(ctx context.Context) (bool, error) {
+				var resp igql.ProjectTokenCreateResponse
+				input := igql.ProjectTokenCreateInput{Name: name, ProjectID: projectID, EnvironmentID: environmentID}
+				err := c.gqlClient.Mutate(ctx, igql.ProjectTokenCreateMutation, map[string]any{"input": input}, &resp)
+				token = resp.ProjectTokenCreate
+				return token != "", err
+			},
+		},
+		{
+			name: "projectTokenCreate(projectId, environmentId, name)", field: "projectTokenCreate", args: []string{"projectId", "environmentId", "name"},
+			call: func(ctx context.Context) (bool, error) {
+				var resp igql.ProjectTokenCreateResponse
+				err := c.gqlClient.Mutate(ctx, igql.ProjectTokenCreateByParamsMutation, map[string]any{"projectId": projectID, "environmentId": environmentID, "name": name}, &resp)
+				token = resp.ProjectTokenCreate
+				return token != "", err
+			},
+		},
+	})
+	if err != nil {
 		return "", err
 	}
-	if resp2.ProjectTokenCreate != "" {
-		return resp2.ProjectTokenCreate, nil
-	}
-	return "", fmt.Errorf("project token create failed")
+	return token, nil
 }
 
-// 底层 raw：DeleteProjectToken
+// 底层 raw：DeleteProjectToken（id / input 两种形态，由能力探测选择）
 func (c *Client) deleteProjectTokenRaw(ctx context.Context, tokenID string) error {
-	var resp igql.ProjectTokenDeleteResponse
-	if err := c.gqlClient.Mutate(ctx, igql.ProjectTokenDeleteMutation, map[string]any{"id": tokenID}, &resp); err == nil {
-		if resp.ProjectTokenDelete {
-			return nil
-		}
-	}
-	var resp2 igql.ProjectTokenDeleteResponse
-	input := igql.ProjectTokenDeleteInput{ID: tokenID}
-	if err := c.gqlClient.Mutate(ctx, igql.ProjectTokenDeleteByInputMutation, map[string]any{"input": input}, &resp2); err != nil {
-		return err
-	}
-	if !resp2.ProjectTokenDelete {
-		return fmt.Errorf("project token delete failed")
-	}
-	return nil
+	return c.invokeCapability(ctx, "delete project token", false, []variant{
+		{
+			name: "projectTokenDelete(id)", field: "projectTokenDelete", args: []string{"id"},
+			call: func(ctx context.Context) (bool, error) {
+				var resp igql.ProjectTokenDeleteResponse
+				err := c.gqlClient.Mutate(ctx, igql.ProjectTokenDeleteMutation, map[string]any{"id": tokenID}, &resp)
+				return resp.ProjectTokenDelete, err
+			},
+		},
+		{
+			name: "projectTokenDelete(input)", field: "projectTokenDelete", args: []string{"input"},
+			call: func(ctx context.Context) (bool, error) {
+				var resp igql.ProjectTokenDeleteResponse
+				err := c.gqlClient.Mutate(ctx, igql.ProjectTokenDeleteByInputMutation, map[string]any{"input": igql.ProjectTokenDeleteInput{ID: tokenID}}, &resp)
+				return resp.ProjectTokenDelete, err
+			},
+		},
+	})
 }
 
 // 底层 raw：ListProjectTokens
 func (c *Client) listProjectTokensRaw(ctx context.Context, projectID string) ([]ProjectToken, error) {
-	after := ""
-	var out []ProjectToken
-	for {
-		vars := map[string]any{"projectId": projectID, "after": nullIfEmpty(after)}
-		var resp igql.ProjectTokensResponse
-		if err := c.gqlClient.Query(ctx, igql.ProjectTokensQuery, vars, &resp); err != nil {
-			return nil, err
+	return c.ProjectTokensPager(projectID, PageOptions{}).All(ctx)
+}
+
+// ProjectTokensPager 按页列出项目访问令牌
+func (c *Client) ProjectTokensPager(projectID string, opts PageOptions) *Pager[ProjectToken] {
+	return NewPager(func(ctx context.Context, first int, after string) ([]ProjectToken, PageInfo, error) {
+		resp, err := igql.ProjectTokens(ctx, c.gqlClient, igql.ProjectTokensVariables{ProjectID: projectID, First: &first, After: optString(after)})
+		if err != nil {
+			return nil, PageInfo{}, err
 		}
+		out := make([]ProjectToken, 0, len(resp.ProjectTokens.Edges))
 		for _, e := range resp.ProjectTokens.Edges {
 			out = append(out, ProjectToken{ID: e.Node.ID, Name: e.Node.Name, ProjectID: e.Node.Project.ID, ProjectName: e.Node.Project.Name, EnvironmentID: e.Node.Environment.ID, EnvironmentName: e.Node.Environment.Name})
 		}
-		if !resp.ProjectTokens.PageInfo.HasNextPage || resp.ProjectTokens.PageInfo.EndCursor == nil || *resp.ProjectTokens.PageInfo.EndCursor == "" {
-			break
-		}
-		after = *resp.ProjectTokens.PageInfo.EndCursor
-	}
-	return out, nil
+		return out, pageInfo(resp.ProjectTokens.PageInfo), nil
+	}, opts)
 }
 
 // 底层 raw：CurrentProjectFromToken
@@ -4,8 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
 	"time"
+
+	iclient "github.com/railwayapp/cli/internal/client"
 )
 
 // RetryOption 控制幂等/重试行为
@@ -16,8 +17,9 @@ type RetryOption struct {
 
 func defaultRetry() RetryOption { return RetryOption{MaxAttempts: 3, Backoff: 500 * time.Millisecond} }
 
-// withRetry 在临时错误时重试
-func withRetry(ctx context.Context, opt RetryOption, fn func() error) error {
+// withRetry 在 retryable 报告的临时错误时重试。fn 每次都先查询现状再变更时可用 IsRetryable；
+// 直接提交非幂等变更时须用 iclient.IsRetryableMutation，只在请求未发出时重试，避免重复创建
+func withRetry(ctx context.Context, opt RetryOption, retryable func(error) bool, fn func() error) error {
 	if opt.MaxAttempts <= 0 {
 		opt = defaultRetry()
 	}
@@ -25,9 +27,7 @@ func withRetry(ctx context.Context, opt RetryOption, fn func() error) error {
 	for attempt := 1; attempt <= opt.MaxAttempts; attempt++ {
 		if err := fn(); err != nil {
 			last = err
-			// 简单的可重试判断：网络错误/后端临时错误关键字
-			msg := strings.ToLower(err.Error())
-			if attempt < opt.MaxAttempts && (strings.Contains(msg, "timeout") || strings.Contains(msg, "temporary") || strings.Contains(msg, "connection") || strings.Contains(msg, "transient")) {
+			if attempt < opt.MaxAttempts && ctx.Err() == nil && retryable(err) {
 				select {
 				case <-ctx.Done():
 					return ctx.Err()
@@ -45,10 +45,11 @@ func withRetry(ctx context.Context, opt RetryOption, fn func() error) error {
 	return last
 }
 
-// EnsureService 存在即返回，不存在则创建（幂等）
+// EnsureService 存在即返回，不存在则创建（幂等）。
+// 创建请求发出后失败（结果未知）时，重试会先重新查询，已创建的服务直接返回而不会再次创建
 func (c *Client) EnsureService(ctx context.Context, projectID, serviceName string, retry RetryOption) (*Service, error) {
 	var out *Service
-	err := withRetry(ctx, retry, func() error {
+	err := withRetry(ctx, retry, IsRetryable, func() error {
 		// 查询现有服务
 		p, err := c.GetProject(ctx, projectID)
 		if err != nil {
@@ -72,10 +73,10 @@ func (c *Client) EnsureService(ctx context.Context, projectID, serviceName strin
 	return out, err
 }
 
-// EnsureEnvironment 存在即返回，不存在则创建（幂等）
+// EnsureEnvironment 存在即返回，不存在则创建（幂等）；与 EnsureService 相同，重试前先重新查询
 func (c *Client) EnsureEnvironment(ctx context.Context, projectID, envName string, retry RetryOption) (*Environment, error) {
 	var out *Environment
-	err := withRetry(ctx, retry, func() error {
+	err := withRetry(ctx, retry, IsRetryable, func() error {
 		p, err := c.GetProject(ctx, projectID)
 		if err != nil {
 			return err
@@ -99,7 +100,7 @@ func (c *Client) EnsureEnvironment(ctx context.Context, projectID, envName strin
 
 // EnsureVariables 以幂等方式应用变量（默认非 replace）。若 replace=true，则确保最终值与 desired 一致。
 func (c *Client) EnsureVariables(ctx context.Context, projectID, environmentID, serviceID string, desired map[string]string, replace bool, retry RetryOption) error {
-	return withRetry(ctx, retry, func() error {
+	return withRetry(ctx, retry, IsRetryable, func() error {
 		current, err := c.GetVariables(ctx, projectID, environmentID, serviceID)
 		if err != nil {
 			return err
@@ -108,26 +109,36 @@ func (c *Client) EnsureVariables(ctx context.Context, projectID, environmentID,
 	})
 }
 
-// EnsureUp 幂等部署：若上传成功且状态已是 SUCCESS 则不阻塞；否则订阅直到成功/失败。
-// 注意：无法避免实际创建新的 deployment，但允许通过回调/状态判断达到“成功状态幂等”。
+// EnsureUp 上传并部署：只有上传会重试（每次重试以 Detach 方式调用 Up），
+// 上传成功后按 p.Detach 与 p.CI 跟随一次部署，跟随期间的错误直接返回而不会重新上传。
+// 每次成功的上传都会创建新的 deployment，因此只在请求未发出或被限流时重试；
+// 归档发出后的连接中断或等待响应超时直接返回，重复调用 EnsureUp 也并不幂等。
 func (c *Client) EnsureUp(ctx context.Context, p UpParams, retry RetryOption) (string, string, error) {
-	var depID, logs string
-	err := withRetry(ctx, retry, func() error {
-		d, l, err := c.Up(ctx, p)
+	upload := p
+	upload.Detach = true
+	var depID, logsURL string
+	err := withRetry(ctx, retry, iclient.IsRetryableMutation, func() error {
+		d, l, err := c.Up(ctx, upload)
 		if err != nil {
 			return err
 		}
-		depID, logs = d, l
+		depID, logsURL = d, l
 		return nil
 	})
-	return depID, logs, err
+	if err != nil || p.Detach {
+		return depID, logsURL, err
+	}
+	if depID == "" {
+		return depID, logsURL, fmt.Errorf("upload response did not include a deployment id")
+	}
+	return depID, logsURL, c.followUp(ctx, p, depID, logsURL)
 }
 
-// EnsureProjectToken 若不存在同名 Token，则创建；否则直接返回新建的 Token 字符串（注意：后端一般不返回旧 Token 明文）
+// EnsureProjectToken 创建 Token 并返回其明文（后端不返回已有 Token 的明文，无法先查询再复用）。
+// 只在请求未发出或被限流时重试；请求发出后失败时 Token 可能已创建，直接返回错误以免重复创建
 func (c *Client) EnsureProjectToken(ctx context.Context, projectID, environmentID, name string, retry RetryOption) (string, error) {
 	var token string
-	err := withRetry(ctx, retry, func() error {
-		// 无法拿旧 token 明文，直接创建并返回；若后端去重同名，则此操作本身是幂等的
+	err := withRetry(ctx, retry, iclient.IsRetryableMutation, func() error {
 		t, err := c.CreateProjectToken(ctx, projectID, environmentID, name)
 		if err != nil {
 			return err
@@ -138,11 +149,11 @@ func (c *Client) EnsureProjectToken(ctx context.Context, projectID, environmentI
 	return token, err
 }
 
-// EnsureServiceInstanceDeploy 幂等触发：后端一般会返回新的 deployment id；
-// 可结合 SubscribeDeploymentStatus 等实现“直到成功/失败”的幂等流程。
+// EnsureServiceInstanceDeploy 触发部署：每次成功调用都会创建新的 deployment，
+// 因此只在请求未发出或被限流时重试；可结合 WaitForDeployment 等待部署进入终态。
 func (c *Client) EnsureServiceInstanceDeploy(ctx context.Context, serviceID, environmentID string, retry RetryOption) (string, string, error) {
 	var depID, status string
-	err := withRetry(ctx, retry, func() error {
+	err := withRetry(ctx, retry, iclient.IsRetryableMutation, func() error {
 		id, st, err := c.DeployServiceInstance(ctx, serviceID, environmentID)
 		if err != nil {
 			return err
@@ -153,27 +164,13 @@ func (c *Client) EnsureServiceInstanceDeploy(ctx context.Context, serviceID, env
 	return depID, status, err
 }
 
-// WaitDeploymentSuccess 阻塞直到部署成功或失败
+// WaitDeploymentSuccess 阻塞直到部署进入终态，返回最终状态
+//
+// Deprecated: 使用 WaitForDeployment，可设置超时、进度回调并区分最终状态
 func (c *Client) WaitDeploymentSuccess(ctx context.Context, deploymentID string) (finalStatus string, err error) {
-	done := make(chan struct{})
-	var st string
-	err = c.SubscribeDeploymentStatus(ctx, deploymentID, func(_ string, status string, _ bool) {
-		s := strings.ToUpper(status)
-		switch s {
-		case "SUCCESS":
-			st = s
-			close(done)
-		case "FAILED", "CRASHED":
-			st = s
-			close(done)
-		}
-	})
+	res, err := c.WaitForDeployment(ctx, deploymentID, WaitOptions{})
 	if err != nil {
 		return "", err
 	}
-	<-done
-	if st == "" {
-		return "", fmt.Errorf("unknown final status")
-	}
-	return st, nil
+	return string(res.Status), nil
 }
@@ -0,0 +1,135 @@
+package railway_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/railwayapp/cli/pkg/railway"
+	"github.com/railwayapp/cli/pkg/railway/railwaytest"
+)
+
+// flakyTransport 让匹配 match（GraphQL 操作名或 URL 路径后缀）的前 n 次请求失败：
+// sent=false 时在发出前返回拨号错误，sent=true 时请求送达服务端后丢失响应
+type flakyTransport struct {
+	mu    sync.Mutex
+	match string
+	n     int
+	sent  bool
+}
+
+func (f *flakyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	hit := strings.HasSuffix(req.URL.Path, f.match)
+	if !hit && req.Body != nil && !strings.HasSuffix(req.URL.Path, "/up") {
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(b))
+		hit = strings.Contains(string(b), "mutation "+f.match)
+	}
+	f.mu.Lock()
+	fail := hit && f.n > 0
+	if fail {
+		f.n--
+	}
+	f.mu.Unlock()
+	if !fail {
+		return http.DefaultTransport.RoundTrip(req)
+	}
+	if !f.sent {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	}
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return nil, io.ErrUnexpectedEOF
+}
+
+func flakyClient(t *testing.T, srv *railwaytest.Server, ft *flakyTransport) *railway.Client {
+	t.Helper()
+	c, err := railway.New(append(srv.ClientOptions(), railway.WithHTTPClient(&http.Client{Transport: ft}))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+var fastRetry = railway.RetryOption{MaxAttempts: 3, Backoff: time.Millisecond}
+
+func TestEnsureProjectTokenRetriesOnlyBeforeSend(t *testing.T) {
+	ctx := context.Background()
+	for _, sent := range []bool{false, true} {
+		srv := railwaytest.NewServer()
+		defer srv.Close()
+		p, env := srv.AddProject("demo")
+		c := flakyClient(t, srv, &flakyTransport{match: "ProjectTokenCreate", n: 1, sent: sent})
+
+		token, err := c.EnsureProjectToken(ctx, p.ID, env.ID, "ci", fastRetry)
+		// 请求未发出：重试后创建一次；请求已送达：Token 可能已创建，不再重试
+		if !sent && (err != nil || token == "") {
+			t.Fatalf("not sent: token %q, err %v", token, err)
+		}
+		if sent && err == nil {
+			t.Fatal("sent: expected the lost response to be returned")
+		}
+		if n := countOps(srv, "ProjectTokenCreate"); n != 1 {
+			t.Fatalf("sent=%v: ProjectTokenCreate requests = %d, want 1", sent, n)
+		}
+	}
+}
+
+func TestEnsureServiceRechecksAfterLostCreate(t *testing.T) {
+	srv := railwaytest.NewServer()
+	defer srv.Close()
+	p, _ := srv.AddProject("demo")
+	c := flakyClient(t, srv, &flakyTransport{match: "ServiceCreate", n: 1, sent: true})
+
+	// 创建已生效但响应丢失：重试时查询到该服务并直接返回
+	svc, err := c.EnsureService(context.Background(), p.ID, "web", fastRetry)
+	if err != nil || svc == nil || svc.Name != "web" {
+		t.Fatalf("service %+v, err %v", svc, err)
+	}
+	if n := countOps(srv, "ServiceCreate"); n != 1 {
+		t.Fatalf("ServiceCreate requests = %d, want 1", n)
+	}
+}
+
+func TestEnsureUpDoesNotRetrySentUpload(t *testing.T) {
+	ctx := context.Background()
+	for _, sent := range []bool{false, true} {
+		srv := railwaytest.NewServer()
+		defer srv.Close()
+		p, env := srv.AddProject("demo")
+		svc := srv.AddService(p.ID, "web")
+		root := t.TempDir()
+		if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		c := flakyClient(t, srv, &flakyTransport{match: "/up", n: 1, sent: sent})
+
+		_, _, err := c.EnsureUp(ctx, railway.UpParams{ProjectID: p.ID, EnvironmentID: env.ID, ServiceID: svc.ID, ProjectRoot: root, Detach: true}, fastRetry)
+		if (err != nil) != sent {
+			t.Fatalf("sent=%v: err = %v", sent, err)
+		}
+		// 归档送达后响应丢失时 deployment 已创建，不会再上传一次
+		if n := len(srv.Deployments(env.ID, svc.ID)); n != 1 {
+			t.Fatalf("sent=%v: deployments = %d, want 1", sent, n)
+		}
+	}
+}
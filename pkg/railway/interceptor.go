@@ -0,0 +1,30 @@
+package railway
+
+import (
+	iclient "github.com/railwayapp/cli/internal/client"
+)
+
+// Operation 一次 GraphQL 调用（v2 与 internal 端点均适用）。
+// 调用 next 前可修改 Variables、Header；next 返回后可读取 RawResponse、StatusCode、Latency。
+type Operation = iclient.Operation
+
+// Invoker 执行 Operation（拦截器链中的下一环）
+type Invoker = iclient.Invoker
+
+// Interceptor 包裹每次 GraphQL 调用，可用于审计日志、自定义请求头、指标与故障注入
+type Interceptor = iclient.Interceptor
+
+// 端点标识（Operation.Endpoint）
+const (
+	EndpointPublic   = iclient.EndpointPublic
+	EndpointInternal = iclient.EndpointInternal
+)
+
+// WithInterceptor 追加一个拦截器；可多次使用，先追加者位于最外层
+func WithInterceptor(fn Interceptor) Option {
+	return func(o *options) {
+		if fn != nil {
+			o.interceptors = append(o.interceptors, fn)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package railway_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/railwayapp/cli/pkg/railway"
+	"github.com/railwayapp/cli/pkg/railway/railwaytest"
+)
+
+func TestWithInterceptor(t *testing.T) {
+	srv := railwaytest.NewServer()
+	defer srv.Close()
+	var trace []string
+	var ops []railway.Operation
+	c, err := railway.New(append(srv.ClientOptions(),
+		railway.WithInterceptor(func(ctx context.Context, op *railway.Operation, next railway.Invoker) error {
+			trace = append(trace, "first")
+			err := next(ctx, op)
+			ops = append(ops, *op)
+			return err
+		}),
+		railway.WithInterceptor(func(ctx context.Context, op *railway.Operation, next railway.Invoker) error {
+			trace = append(trace, "second")
+			return next(ctx, op)
+		}),
+	)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, _ := srv.AddProject("demo")
+	ctx := context.Background()
+
+	// v2 与 internal 端点都经过拦截器，next 返回后可读取响应字段
+	if _, err := c.GetProject(ctx, p.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetVolumeBackupSchedules(ctx, "vol_1"); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(trace, ","); got != "first,second,first,second" {
+		t.Fatalf("order = %s", got)
+	}
+	for i, endpoint := range []string{railway.EndpointPublic, railway.EndpointInternal} {
+		op := ops[i]
+		if op.Endpoint != endpoint || !strings.HasSuffix(op.URL, "/graphql/"+endpoint) {
+			t.Fatalf("op %d: endpoint %s, url %s", i, op.Endpoint, op.URL)
+		}
+		if len(op.RawResponse) == 0 || op.StatusCode != 200 || op.Latency <= 0 {
+			t.Fatalf("op %d: raw %s, status %d, latency %v", i, op.RawResponse, op.StatusCode, op.Latency)
+		}
+	}
+	if reqs := srv.Requests(); len(reqs) != 2 || reqs[0].Endpoint != railway.EndpointPublic || reqs[1].Endpoint != railway.EndpointInternal {
+		t.Fatalf("requests = %+v", reqs)
+	}
+}
+
+func TestWithInterceptorShortCircuit(t *testing.T) {
+	srv := railwaytest.NewServer()
+	defer srv.Close()
+	injected := errors.New("injected")
+	c, err := railway.New(append(srv.ClientOptions(),
+		railway.WithInterceptor(func(ctx context.Context, op *railway.Operation, next railway.Invoker) error {
+			return injected
+		}),
+	)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetProject(context.Background(), "prj_1"); !errors.Is(err, injected) {
+		t.Fatalf("err = %v", err)
+	}
+	if n := len(srv.Requests()); n != 0 {
+		t.Fatalf("requests = %d, want none", n)
+	}
+}
@@ -0,0 +1,60 @@
+package railway
+
+import (
+	"context"
+
+	"github.com/railwayapp/cli/internal/logquery"
+)
+
+// LogQuery 历史日志查询条件：时间范围（闭区间）、分页游标、过滤表达式与每页行数。
+// 设置 After、或只设置 Start 时从旧到新翻页，否则从 End（默认当前时间）向前取最近的行。
+type LogQuery = logquery.Query
+
+// LogPage 一页历史日志，Lines 按时间升序
+type LogPage struct {
+	Lines []LogLine
+	// Before 作为下一次查询的 LogQuery.Before 取更早的一页
+	Before string
+	// After 作为下一次查询的 LogQuery.After 取更新的一页
+	After string
+	// HasMore 查询方向上是否可能还有更多日志
+	HasMore bool
+}
+
+// GetBuildLogs 查询构建日志
+func (c *Client) GetBuildLogs(ctx context.Context, deploymentID string, q LogQuery) (*LogPage, error) {
+	return c.getLogs(ctx, logquery.Build, deploymentID, q)
+}
+
+// GetDeploymentLogs 查询部署（运行时）日志
+func (c *Client) GetDeploymentLogs(ctx context.Context, deploymentID string, q LogQuery) (*LogPage, error) {
+	return c.getLogs(ctx, logquery.Deployment, deploymentID, q)
+}
+
+// GetEnvironmentLogs 查询环境内全部服务的日志，LogLine.Tags 标明来源
+func (c *Client) GetEnvironmentLogs(ctx context.Context, environmentID string, q LogQuery) (*LogPage, error) {
+	return c.getLogs(ctx, logquery.Environment, environmentID, q)
+}
+
+func (c *Client) getLogs(ctx context.Context, kind logquery.Kind, id string, q LogQuery) (*LogPage, error) {
+	p, err := logquery.Fetch(ctx, c.gqlClient, kind, id, q)
+	if err != nil {
+		return nil, err
+	}
+	out := &LogPage{Lines: make([]LogLine, 0, len(p.Lines)), Before: p.Before, After: p.After, HasMore: p.HasMore}
+	for _, l := range p.Lines {
+		attrs := make(map[string]string, len(l.Attributes))
+		for _, a := range l.Attributes {
+			attrs[a.Key] = a.Value
+		}
+		out.Lines = append(out.Lines, LogLine{
+			Timestamp:    l.Time,
+			Message:      l.Message,
+			Severity:     l.Severity,
+			Attributes:   attrs,
+			Tags:         l.Tags,
+			rawTimestamp: l.Timestamp,
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,29 @@
+package railway
+
+import (
+	"context"
+
+	"github.com/railwayapp/cli/pkg/railway/logs"
+)
+
+// Record 转换为可写入 logs.Sink 的记录
+func (l LogLine) Record() logs.Record {
+	return logs.Record{Time: l.Timestamp, Severity: l.Severity, Message: l.Message, Attributes: l.Attributes, Tags: l.Tags}
+}
+
+// ForwardLogs 将日志流写入 logs.Buffer，直到流结束或 ctx 取消。Buffer 为 Block 策略时，
+// 导出目标跟不上会暂停读取订阅。返回流的错误或写入缓冲区的错误；不会关闭 Buffer。
+//
+//	sink, _ := logs.OpenSink("loki+http://loki:3100")
+//	buf := logs.NewBuffer(sink, logs.BufferOptions{})
+//	defer buf.Close()
+//	err := railway.ForwardLogs(ctx, cli.EnvironmentLogStream(ctx, envID, railway.EnvironmentLogOptions{}), buf)
+func ForwardLogs(ctx context.Context, s *LogStream, buf *logs.Buffer) error {
+	defer s.Close()
+	for s.Next() {
+		if err := buf.Send(ctx, s.Value().Record()); err != nil {
+			return err
+		}
+	}
+	return s.Err()
+}
@@ -0,0 +1,276 @@
+package logs
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BuildPhase 构建阶段
+type BuildPhase string
+
+const (
+	// PhaseSetup 拉取代码、检测构建方式、加载 Dockerfile 等准备工作
+	PhaseSetup BuildPhase = "setup"
+	// PhaseInstall 安装依赖
+	PhaseInstall BuildPhase = "install"
+	// PhaseBuild 编译、打包
+	PhaseBuild BuildPhase = "build"
+	// PhasePush 导出并推送镜像
+	PhasePush BuildPhase = "push"
+)
+
+// PhaseSpan 一个构建阶段在日志中的范围
+type PhaseSpan struct {
+	Phase BuildPhase
+	Start time.Time
+	End   time.Time
+	// Lines 该阶段的日志行数
+	Lines int
+}
+
+// BuildFailureReport 构建失败摘要
+type BuildFailureReport struct {
+	// Phase 出错的阶段；未找到错误时为最后一个阶段
+	Phase BuildPhase
+	// Step 出错时正在执行的构建步骤（如 "RUN npm run build"），未知时为空
+	Step string
+	// Error 首个致命错误行；未识别出错误时为空
+	Error string
+	// Context 错误块：错误行之前的少量上下文与之后的后续行；未识别出错误时为日志末尾若干行
+	Context []string
+	// ExitCode 日志中报告的退出码，未知时为 0
+	ExitCode int
+	// At 错误行的时间
+	At time.Time
+	// Phases 按出现顺序排列的各阶段
+	Phases []PhaseSpan
+	// Lines 分析的日志总行数
+	Lines int
+}
+
+// Found 是否识别出了错误行
+func (r *BuildFailureReport) Found() bool { return r != nil && r.Error != "" }
+
+const (
+	reportContextBefore = 3
+	reportContextAfter  = 15
+	reportTail          = 20
+)
+
+var (
+	// buildkitPrefix BuildKit 纯文本输出的行前缀，如 "#12 3.456 "；输出空行时只有 "#12 3.456"
+	buildkitPrefix = regexp.MustCompile(`^#\d+ (\d+\.\d+( |$))?`)
+	// stepPattern Dockerfile 步骤行，如 "[stage-0 5/11] RUN npm ci"、"[build 2/4] COPY . ."
+	stepPattern = regexp.MustCompile(`^\[[^\]]*\d+/\d+\] (.+)$`)
+	// nixpacksPhase Nixpacks 计划表中的阶段行，如 "║ install │ npm ci"
+	nixpacksPhase   = regexp.MustCompile(`^[║|]\s*(setup|install|build|start)\s*[│|]`)
+	exitCodePattern = regexp.MustCompile(`exit code:? (\d+)`)
+	blockEndPattern = regexp.MustCompile(`did not complete successfully|failed to solve`)
+
+	installPattern = regexp.MustCompile(`(?i)\b(npm (ci|install|i)\b|yarn( install)?$|yarn install|pnpm (i|install)\b|bun install|pip3? install|poetry install|pipenv install|uv (sync|pip)|bundle install|composer install|go mod download|cargo fetch|apt-get install|apk add|mix deps\.get)`)
+	buildPattern   = regexp.MustCompile(`(?i)\b(npm run build|yarn( run)? build|pnpm( run)? build|bun( run)? build|go build|cargo build|mvn\b|gradle|\./gradlew|dotnet (build|publish)|mix (compile|release)|next build|vite build|tsc\b|make\b|python .*manage\.py collectstatic)`)
+	pushPattern    = regexp.MustCompile(`(?i)(exporting to image|exporting layers|writing image|naming to|pushing (layers|image|manifest)|publishing image|image push)`)
+
+	// fatalPatterns 致命错误行；依次匹配，避免把 "0 errors"、警告等误判为错误
+	fatalPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^(error|ERROR|Error)(\[[^\]]*\])?(:|\s)`),
+		regexp.MustCompile(`npm ERR!|^npm error |ERR_PNPM_|error Command failed|yarn run .* exited`),
+		regexp.MustCompile(`(?i)^(fatal|panic):`),
+		regexp.MustCompile(`Traceback \(most recent call last\)`),
+		regexp.MustCompile(`^\S*\b[A-Z]\w*(Error|Exception):`),
+		regexp.MustCompile(`\berror TS\d+:|\berror\[E\d+\]|: error:|\bFailed to compile\b|\bBuild failed\b|\bcommand not found\b|\bModule not found\b`),
+		// Go 编译错误，如 "internal/x.go:42:9: undefined: y"
+		regexp.MustCompile(`^[\w./-]+\.go:\d+:\d+: `),
+		regexp.MustCompile(`did not complete successfully|failed to solve|exit code: [1-9]`),
+	}
+)
+
+// BuildAnalyzer 逐行分析构建日志，划分阶段并定位首个致命错误。可直接接在 SubscribeBuildLogs
+// 或历史日志查询之后；非并发安全。
+type BuildAnalyzer struct {
+	phases []PhaseSpan
+	step   string
+	lines  int
+	recent []string
+	report *BuildFailureReport
+	after  int
+	tail   []string
+	lastAt time.Time
+}
+
+// NewBuildAnalyzer 创建分析器
+func NewBuildAnalyzer() *BuildAnalyzer {
+	return &BuildAnalyzer{}
+}
+
+// Add 追加一行构建日志
+func (a *BuildAnalyzer) Add(t time.Time, message string) {
+	a.lines++
+	line := strings.TrimRight(buildkitPrefix.ReplaceAllString(message, ""), " \t\r")
+	if !t.IsZero() {
+		a.lastAt = t
+	}
+
+	if m := stepPattern.FindStringSubmatch(line); m != nil {
+		a.step = m[1]
+		// 上一步骤的输出不作为错误的前置上下文
+		a.recent = a.recent[:0]
+	}
+	a.enter(a.classify(line), t)
+	a.phases[len(a.phases)-1].Lines++
+
+	a.tail = append(a.tail, line)
+	if len(a.tail) > reportTail {
+		a.tail = a.tail[1:]
+	}
+
+	switch {
+	case a.report == nil && isFatal(line):
+		a.report = &BuildFailureReport{
+			Phase: a.phases[len(a.phases)-1].Phase,
+			Step:  a.step,
+			Error: strings.TrimSpace(line),
+			At:    t,
+		}
+		a.report.Context = append(append([]string{}, a.recent...), line)
+		a.after = reportContextAfter
+	case a.report != nil && a.after > 0:
+		// 错误块在分隔线或下一个构建步骤处结束，BuildKit 的失败汇总行是块的最后一行；
+		// next、tsc、pip 等在错误块内部使用空行，空行不结束错误块
+		if strings.HasPrefix(strings.TrimSpace(line), "---") || stepPattern.MatchString(line) {
+			a.after = 0
+			break
+		}
+		a.report.Context = append(a.report.Context, line)
+		a.after--
+		if blockEndPattern.MatchString(line) {
+			a.after = 0
+		}
+	}
+	if a.report != nil && a.report.ExitCode == 0 {
+		if m := exitCodePattern.FindStringSubmatch(line); m != nil {
+			a.report.ExitCode, _ = strconv.Atoi(m[1])
+		}
+	}
+
+	if strings.TrimSpace(line) != "" {
+		a.recent = append(a.recent, line)
+		if len(a.recent) > reportContextBefore {
+			a.recent = a.recent[1:]
+		}
+	}
+}
+
+// classify 判断一行所属的阶段；无法判断时沿用当前阶段。阶段只前进不后退，
+// 镜像推送之后出现的行仍归入推送阶段
+func (a *BuildAnalyzer) classify(line string) BuildPhase {
+	cur := PhaseSetup
+	if len(a.phases) > 0 {
+		cur = a.phases[len(a.phases)-1].Phase
+	}
+	next := cur
+	if nixpacksPhase.MatchString(line) {
+		// 计划表只描述各阶段的命令，不代表进入该阶段
+		return cur
+	}
+	switch {
+	case pushPattern.MatchString(line):
+		next = PhasePush
+	case stepPattern.MatchString(line) || strings.HasPrefix(line, "$ ") || strings.HasPrefix(line, "> "):
+		cmd := line
+		if m := stepPattern.FindStringSubmatch(line); m != nil {
+			cmd = m[1]
+		}
+		switch {
+		case buildPattern.MatchString(cmd):
+			next = PhaseBuild
+		case installPattern.MatchString(cmd):
+			next = PhaseInstall
+		}
+	}
+	if phaseOrder(next) < phaseOrder(cur) {
+		return cur
+	}
+	return next
+}
+
+func phaseOrder(p BuildPhase) int {
+	switch p {
+	case PhaseInstall:
+		return 1
+	case PhaseBuild:
+		return 2
+	case PhasePush:
+		return 3
+	}
+	return 0
+}
+
+func (a *BuildAnalyzer) enter(p BuildPhase, t time.Time) {
+	if n := len(a.phases); n > 0 {
+		if a.phases[n-1].Phase == p {
+			if !t.IsZero() {
+				a.phases[n-1].End = t
+			}
+			return
+		}
+	}
+	a.phases = append(a.phases, PhaseSpan{Phase: p, Start: t, End: t})
+}
+
+func isFatal(line string) bool {
+	s := strings.TrimSpace(line)
+	if s == "" {
+		return false
+	}
+	for _, p := range fatalPatterns {
+		if p.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Phases 目前为止划分出的阶段
+func (a *BuildAnalyzer) Phases() []PhaseSpan {
+	return append([]PhaseSpan(nil), a.phases...)
+}
+
+// Report 生成失败摘要。未识别出错误行时 Error 为空，Context 为日志末尾若干行
+func (a *BuildAnalyzer) Report() *BuildFailureReport {
+	var r BuildFailureReport
+	if a.report != nil {
+		r = *a.report
+		r.Context = append([]string(nil), a.report.Context...)
+		for len(r.Context) > 0 && strings.TrimSpace(r.Context[len(r.Context)-1]) == "" {
+			r.Context = r.Context[:len(r.Context)-1]
+		}
+	} else {
+		r.Context = append([]string(nil), a.tail...)
+		r.Step = a.step
+		r.At = a.lastAt
+		if n := len(a.phases); n > 0 {
+			r.Phase = a.phases[n-1].Phase
+		}
+		for i := len(a.tail) - 1; i >= 0; i-- {
+			if m := exitCodePattern.FindStringSubmatch(a.tail[i]); m != nil {
+				r.ExitCode, _ = strconv.Atoi(m[1])
+				break
+			}
+		}
+	}
+	r.Phases = a.Phases()
+	r.Lines = a.lines
+	return &r
+}
+
+// AnalyzeBuild 分析一组构建日志
+func AnalyzeBuild(records []Record) *BuildFailureReport {
+	a := NewBuildAnalyzer()
+	for _, r := range records {
+		a.Add(r.Time, r.Message)
+	}
+	return a.Report()
+}
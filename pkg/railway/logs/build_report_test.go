@@ -0,0 +1,207 @@
+package logs
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// loadBuildLog 读取 testdata/build 下的构建日志，第 i 行的时间为基准时间加 i 秒
+func loadBuildLog(t *testing.T, name string) []Record {
+	t.Helper()
+	f, err := os.Open("testdata/build/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var out []Record
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		out = append(out, Record{Time: sinkTime.Add(time.Duration(len(out)) * time.Second), Message: sc.Text()})
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func phaseNames(spans []PhaseSpan) string {
+	var out []string
+	for _, s := range spans {
+		out = append(out, string(s.Phase))
+	}
+	return strings.Join(out, ",")
+}
+
+func TestAnalyzeBuildFixtures(t *testing.T) {
+	tests := []struct {
+		file     string
+		phase    BuildPhase
+		step     string
+		err      string
+		exitCode int
+		// 错误块的首行、末行与行数
+		first, last string
+		context     int
+		phases      string
+	}{
+		{
+			file:     "nixpacks-npm-ci.log",
+			phase:    PhaseInstall,
+			step:     "RUN --mount=type=cache,id=s/5f1c0d7a-/root/npm,target=/root/.npm npm ci",
+			err:      "npm ERR! code ERESOLVE",
+			exitCode: 1,
+			first:    "[stage-0  6/10] RUN --mount=type=cache,id=s/5f1c0d7a-/root/npm,target=/root/.npm npm ci",
+			last:     `ERROR: process "/bin/bash -ol pipefail -c npm ci" did not complete successfully: exit code: 1`,
+			context:  12,
+			phases:   "setup,install",
+		},
+		{
+			file:     "dockerfile-go.log",
+			phase:    PhaseBuild,
+			step:     "RUN CGO_ENABLED=0 go build -o /out/server ./cmd/server",
+			err:      "internal/handlers/users.go:42:9: undefined: store.FindUser",
+			exitCode: 1,
+			first:    "[build 5/5] RUN CGO_ENABLED=0 go build -o /out/server ./cmd/server",
+			last:     `ERROR: process "/bin/sh -c CGO_ENABLED=0 go build -o /out/server ./cmd/server" did not complete successfully: exit code: 1`,
+			context:  5,
+			phases:   "setup,install,build",
+		},
+		{
+			file:     "nixpacks-tsc.log",
+			phase:    PhaseBuild,
+			step:     "RUN --mount=type=cache,id=s/5f1c0d7a-node_modules/cache,target=/app/node_modules/.cache npm run build",
+			err:      "src/routes/orders.ts(14,7): error TS2322: Type 'string' is not assignable to type 'number'.",
+			exitCode: 2,
+			first:    "[stage-0  9/10] RUN --mount=type=cache,id=s/5f1c0d7a-node_modules/cache,target=/app/node_modules/.cache npm run build",
+			last:     `ERROR: process "/bin/bash -ol pipefail -c npm run build" did not complete successfully: exit code: 2`,
+			context:  7,
+			phases:   "install,build",
+		},
+		{
+			// 空行不结束错误块，块内容达到上限后截断
+			file:     "dockerfile-next-npm10.log",
+			phase:    PhaseBuild,
+			step:     "RUN npm run build",
+			err:      "Failed to compile.",
+			exitCode: 1,
+			first:    "> next build",
+			last:     "npm error command sh -c next build",
+			context:  19,
+			phases:   "install,build",
+		},
+		{
+			file:     "nixpacks-python.log",
+			phase:    PhaseInstall,
+			step:     "RUN --mount=type=cache,id=s/9a3e-/root/cache/pip,target=/root/.cache/pip python -m venv --copies /opt/venv && . /opt/venv/bin/activate && pip install -r requirements.txt",
+			err:      "error: subprocess-exited-with-error",
+			exitCode: 1,
+			first:    "  Downloading psycopg2-2.9.9.tar.gz (384 kB)",
+			last:     `ERROR: process "/bin/bash -ol pipefail -c python -m venv --copies /opt/venv && . /opt/venv/bin/activate && pip install -r requirements.txt" did not complete successfully: exit code: 1`,
+			context:  13,
+			phases:   "setup,install",
+		},
+		{
+			file:     "dockerfile-python-traceback.log",
+			phase:    PhaseBuild,
+			step:     "RUN python manage.py collectstatic --noinput",
+			err:      "Traceback (most recent call last):",
+			exitCode: 1,
+			first:    "[web 7/7] RUN python manage.py collectstatic --noinput",
+			last:     `ERROR: process "/bin/sh -c python manage.py collectstatic --noinput" did not complete successfully: exit code: 1`,
+			context:  8,
+			phases:   "install,build",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			records := loadBuildLog(t, tt.file)
+			r := AnalyzeBuild(records)
+			if !r.Found() {
+				t.Fatalf("no error found; tail = %q", r.Context)
+			}
+			if r.Phase != tt.phase || r.Step != tt.step || r.Error != tt.err || r.ExitCode != tt.exitCode {
+				t.Fatalf("report = phase %s, step %q, error %q, exit %d", r.Phase, r.Step, r.Error, r.ExitCode)
+			}
+			if len(r.Context) != tt.context || r.Context[0] != tt.first || r.Context[len(r.Context)-1] != tt.last {
+				t.Fatalf("context (%d lines):\n%s", len(r.Context), strings.Join(r.Context, "\n"))
+			}
+			if got := phaseNames(r.Phases); got != tt.phases {
+				t.Fatalf("phases = %s, want %s", got, tt.phases)
+			}
+			if r.Lines != len(records) {
+				t.Fatalf("Lines = %d, want %d", r.Lines, len(records))
+			}
+			// At 为错误行的时间
+			var at time.Time
+			for _, rec := range records {
+				if strings.HasSuffix(rec.Message, tt.err) {
+					at = rec.Time
+					break
+				}
+			}
+			if !r.At.Equal(at) {
+				t.Fatalf("At = %v, want %v", r.At, at)
+			}
+		})
+	}
+}
+
+func TestAnalyzeBuildSuccess(t *testing.T) {
+	records := loadBuildLog(t, "nixpacks-success.log")
+	r := AnalyzeBuild(records)
+	// "0 errors"、npm WARN、warning: 等不是错误
+	if r.Found() {
+		t.Fatalf("unexpected error %q", r.Error)
+	}
+	if r.Phase != PhasePush || r.Step != "COPY . /app" || r.ExitCode != 0 {
+		t.Fatalf("report = phase %s, step %q, exit %d", r.Phase, r.Step, r.ExitCode)
+	}
+	if len(r.Context) != reportTail || r.Context[len(r.Context)-1] != "DONE 4.1s" {
+		t.Fatalf("tail = %q", r.Context)
+	}
+	if got := phaseNames(r.Phases); got != "setup,install,build,push" {
+		t.Fatalf("phases = %s", got)
+	}
+	var total int
+	for i, p := range r.Phases {
+		total += p.Lines
+		if p.End.Before(p.Start) || (i > 0 && p.Start.Before(r.Phases[i-1].End)) {
+			t.Fatalf("phase %s spans %v..%v", p.Phase, p.Start, p.End)
+		}
+	}
+	if total != len(records) {
+		t.Fatalf("phase lines = %d, want %d", total, len(records))
+	}
+}
+
+func TestIsFatal(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{"error: failed to push some refs", true},
+		{"Error[E0425]: cannot find value", true},
+		{"npm ERR! code ELIFECYCLE", true},
+		{"npm error code 1", true},
+		{" ERR_PNPM_OUTDATED_LOCKFILE  Cannot install", true},
+		{"panic: runtime error: index out of range", true},
+		{"ModuleNotFoundError: No module named 'flask'", true},
+		{"main.go:3:2: no required module provides package", true},
+		{"sh: 1: vite: command not found", true},
+		{"", false},
+		{"Found 0 errors. Watching for file changes.", false},
+		{"eslint: 0 errors, 3 warnings", false},
+		{"npm WARN deprecated glob@7.2.3", false},
+		{"warning: unused variable", false},
+		{"errors.go compiled", false},
+		{"exit code: 0", false},
+	}
+	for _, tt := range tests {
+		if got := isFatal(tt.line); got != tt.want {
+			t.Errorf("isFatal(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
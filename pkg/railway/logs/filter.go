@@ -0,0 +1,155 @@
+// Package logs 提供日志相关的工具：过滤表达式的构建与解析、日志导出（Sink）、JSON 日志规范化与构建日志分析。
+//
+// Railway 的日志过滤语法：
+//
+//	timeout                 消息包含 timeout（不区分大小写）
+//	"connection reset"      含空格或特殊字符的文本用双引号包围，\" 与 \\ 转义
+//	@level:error            日志级别
+//	@path:/api              属性 path 等于 /api
+//	-@level:debug           取反
+//	a AND b、a OR b、(...)  组合；相邻的项默认 AND
+//
+// 该包不依赖 pkg/railway，CLI 与 SDK 均可使用。
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Levels 支持的日志级别
+var Levels = []string{"debug", "info", "warn", "error"}
+
+// levelAliases 常见写法到 Railway 级别的映射
+var levelAliases = map[string]string{
+	"warning": "warn",
+	"err":     "error",
+}
+
+// attrKeyPattern 属性名：字母、数字与 _ . -
+var attrKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_.\-]+$`)
+
+// FilterBuilder 以链式调用构建过滤表达式，各条件之间为 AND：
+//
+//	f, err := logs.Filter().Level("error").Attr("path", "/api").Contains("timeout").Build()
+//	// f == `@level:error AND @path:/api AND timeout`
+//
+// 参数不合法时记录第一个错误，由 Build 返回。
+type FilterBuilder struct {
+	terms []string
+	err   error
+}
+
+// Filter 创建空的过滤表达式（不过滤）
+func Filter() *FilterBuilder { return &FilterBuilder{} }
+
+func (b *FilterBuilder) fail(err error) *FilterBuilder {
+	if b.err == nil {
+		b.err = err
+	}
+	return b
+}
+
+// Level 限定日志级别；传入多个级别时匹配其中任意一个
+func (b *FilterBuilder) Level(levels ...string) *FilterBuilder {
+	if len(levels) == 0 {
+		return b.fail(errors.New("logs filter: Level requires at least one level"))
+	}
+	terms := make([]string, 0, len(levels))
+	for _, l := range levels {
+		lvl, err := NormalizeLevel(l)
+		if err != nil {
+			return b.fail(err)
+		}
+		terms = append(terms, "@level:"+lvl)
+	}
+	if len(terms) == 1 {
+		b.terms = append(b.terms, terms[0])
+	} else {
+		b.terms = append(b.terms, "("+strings.Join(terms, " OR ")+")")
+	}
+	return b
+}
+
+// Attr 限定属性 key 等于 value
+func (b *FilterBuilder) Attr(key, value string) *FilterBuilder {
+	if !attrKeyPattern.MatchString(key) {
+		return b.fail(fmt.Errorf("logs filter: invalid attribute name %q", key))
+	}
+	if value == "" {
+		return b.fail(fmt.Errorf("logs filter: empty value for attribute %q", key))
+	}
+	b.terms = append(b.terms, "@"+key+":"+quote(value))
+	return b
+}
+
+// Contains 限定消息包含 text（不区分大小写）
+func (b *FilterBuilder) Contains(text string) *FilterBuilder {
+	if strings.TrimSpace(text) == "" {
+		return b.fail(errors.New("logs filter: empty search text"))
+	}
+	b.terms = append(b.terms, quote(text))
+	return b
+}
+
+// Exclude 排除消息包含 text 的日志
+func (b *FilterBuilder) Exclude(text string) *FilterBuilder {
+	if strings.TrimSpace(text) == "" {
+		return b.fail(errors.New("logs filter: empty search text"))
+	}
+	b.terms = append(b.terms, "-"+quote(text))
+	return b
+}
+
+// Raw 追加一段原始过滤表达式（会校验语法），用于构建器未覆盖的写法
+func (b *FilterBuilder) Raw(expr string) *FilterBuilder {
+	if strings.TrimSpace(expr) == "" {
+		return b
+	}
+	if _, err := ParseFilter(expr); err != nil {
+		return b.fail(err)
+	}
+	b.terms = append(b.terms, "("+expr+")")
+	return b
+}
+
+// Build 返回过滤表达式；没有任何条件时为空串
+func (b *FilterBuilder) Build() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+	return strings.Join(b.terms, " AND "), nil
+}
+
+// String 返回过滤表达式，存在错误时为空串（不过滤）；需要校验时使用 Build
+func (b *FilterBuilder) String() string {
+	s, _ := b.Build()
+	return s
+}
+
+// NormalizeLevel 校验并规范化日志级别（小写，warning→warn）
+func NormalizeLevel(level string) (string, error) {
+	l := strings.ToLower(strings.TrimSpace(level))
+	if a, ok := levelAliases[l]; ok {
+		l = a
+	}
+	for _, v := range Levels {
+		if l == v {
+			return l, nil
+		}
+	}
+	return "", fmt.Errorf("logs filter: unknown level %q (want one of %s)", level, strings.Join(Levels, ", "))
+}
+
+// quote 含空白、引号、括号、冒号、前导 -/@ 或与运算符同名的值加双引号
+func quote(s string) string {
+	needs := s == "AND" || s == "OR" || strings.HasPrefix(s, "-") || strings.HasPrefix(s, "@") ||
+		strings.ContainsAny(s, " \t\r\n\"\\():")
+	if !needs {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(s) + `"`
+}
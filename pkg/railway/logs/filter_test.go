@@ -0,0 +1,193 @@
+package logs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilterBuilder(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   func() *FilterBuilder
+		want    string
+		wantErr string
+	}{
+		{"empty", Filter, "", ""},
+		{"level", func() *FilterBuilder { return Filter().Level("ERROR") }, "@level:error", ""},
+		{"level aliases", func() *FilterBuilder { return Filter().Level("warning", " err ") }, "(@level:warn OR @level:error)", ""},
+		{"attr plain", func() *FilterBuilder { return Filter().Attr("http.path", "/api") }, "@http.path:/api", ""},
+		{"attr quoted", func() *FilterBuilder { return Filter().Attr("route", "GET /a") }, `@route:"GET /a"`, ""},
+		{"contains", func() *FilterBuilder { return Filter().Contains("timeout") }, "timeout", ""},
+		{"contains space", func() *FilterBuilder { return Filter().Contains("connection reset") }, `"connection reset"`, ""},
+		{"contains escapes", func() *FilterBuilder { return Filter().Contains(`say "hi" \o/`) }, `"say \"hi\" \\o/"`, ""},
+		{"operator word", func() *FilterBuilder { return Filter().Contains("OR") }, `"OR"`, ""},
+		{"leading dash", func() *FilterBuilder { return Filter().Contains("-v") }, `"-v"`, ""},
+		{"leading at", func() *FilterBuilder { return Filter().Contains("@home") }, `"@home"`, ""},
+		{"colon and parens", func() *FilterBuilder { return Filter().Contains("f(x):y") }, `"f(x):y"`, ""},
+		{"exclude", func() *FilterBuilder { return Filter().Exclude("healthcheck") }, "-healthcheck", ""},
+		{"raw", func() *FilterBuilder { return Filter().Raw("a OR b").Contains("c") }, "(a OR b) AND c", ""},
+		{"raw empty ignored", func() *FilterBuilder { return Filter().Raw("  ").Contains("c") }, "c", ""},
+		{"combined", func() *FilterBuilder { return Filter().Level("error").Attr("path", "/api").Contains("timeout") },
+			"@level:error AND @path:/api AND timeout", ""},
+		{"no levels", func() *FilterBuilder { return Filter().Level() }, "", "at least one level"},
+		{"unknown level", func() *FilterBuilder { return Filter().Level("fatal") }, "", `unknown level "fatal"`},
+		{"invalid attr key", func() *FilterBuilder { return Filter().Attr("bad key", "x") }, "", "invalid attribute name"},
+		{"attr key with colon", func() *FilterBuilder { return Filter().Attr("a:b", "x") }, "", "invalid attribute name"},
+		{"empty attr value", func() *FilterBuilder { return Filter().Attr("path", "") }, "", "empty value"},
+		{"blank contains", func() *FilterBuilder { return Filter().Contains(" \t") }, "", "empty search text"},
+		{"blank exclude", func() *FilterBuilder { return Filter().Exclude("") }, "", "empty search text"},
+		{"invalid raw", func() *FilterBuilder { return Filter().Raw("(a") }, "", "missing )"},
+		{"first error wins", func() *FilterBuilder { return Filter().Level("fatal").Attr("bad key", "x") }, "", "unknown level"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.build()
+			got, err := b.Build()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Build() err = %v, want %q", err, tt.wantErr)
+				}
+				if s := b.String(); s != "" {
+					t.Fatalf("String() = %q on error", s)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("Build() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeLevel(t *testing.T) {
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"debug", "debug", true},
+		{"INFO", "info", true},
+		{" Warn ", "warn", true},
+		{"warning", "warn", true},
+		{"WARNING", "warn", true},
+		{"err", "error", true},
+		{"error", "error", true},
+		{"fatal", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeLevel(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("NormalizeLevel(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := []struct {
+		filter, wantErr string
+	}{
+		{`"abc`, "unterminated quote"},
+		{`(a`, "missing )"},
+		{`a)`, `unexpected ")"`},
+		{`()`, "missing term"},
+		{`AND a`, "unexpected AND"},
+		{`a AND`, "missing term after AND"},
+		{`a AND OR b`, "missing term after AND"},
+		{`a OR`, "missing term"},
+		{`@:x`, "invalid attribute condition"},
+		{`@path:`, "invalid attribute condition"},
+		{`@path`, "invalid attribute condition"},
+		{`@level:fatal`, "unknown level"},
+		{`""`, "empty search text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			_, err := ParseFilter(tt.filter)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ParseFilter(%q) err = %v, want %q", tt.filter, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExprMatch(t *testing.T) {
+	attrs := map[string]string{"path": "/api", "route": "GET /a"}
+	tests := []struct {
+		filter   string
+		message  string
+		severity string
+		want     bool
+	}{
+		{"", "anything", "", true},
+		{"timeout", "Request TIMEOUT after 30s", "info", true},
+		{"timeout", "ok", "info", false},
+		{`"connection reset"`, "read: connection reset by peer", "error", true},
+		{"@level:error", "x", "ERROR", true},
+		{"@level:warn", "x", "warning", true},
+		{"@severity:err", "x", "error", true},
+		{"@level:error", "x", "info", false},
+		{"@path:/api", "x", "", true},
+		{"@path:/API", "x", "", false},
+		{`@route:"GET /a"`, "x", "", true},
+		{"@missing:x", "x", "", false},
+		{"-@level:debug", "x", "debug", false},
+		{"-@level:debug", "x", "info", true},
+		{"-(a OR b)", "c", "", true},
+		{"-(a OR b)", "b", "", false},
+		{"a b", "a and b", "", true},
+		{"a b", "a only", "", false},
+		{"a AND b", "b then a", "", true},
+		// AND 的优先级高于 OR
+		{"a b OR c", "c", "", true},
+		{"a b OR c", "a", "", false},
+		{"a (b OR c)", "a c", "", true},
+		{"a (b OR c)", "c", "", false},
+		{`"AND"`, "this AND that", "", true},
+		{`"@home"`, "cd @home", "", true},
+		{"x-y", "x-y", "", true},
+		{"-", "a - b", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter+"/"+tt.message, func(t *testing.T) {
+			e, err := ParseFilter(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := e.Match(tt.message, tt.severity, attrs); got != tt.want {
+				t.Fatalf("Match(%q, %q) = %v, want %v", tt.message, tt.severity, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterBuildParseRoundTrip(t *testing.T) {
+	texts := []string{"timeout", "connection reset", `say "hi"`, `C:\tmp`, "AND", "OR", "-v", "@home", "f(x):y", "tab\there"}
+	for _, text := range texts {
+		t.Run(text, func(t *testing.T) {
+			f, err := Filter().Level("warning", "error").Attr("route", "GET /"+text).Contains(text).Exclude("ignored").Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			e, err := ParseFilter(f)
+			if err != nil {
+				t.Fatalf("ParseFilter(%q): %v", f, err)
+			}
+			attrs := map[string]string{"route": "GET /" + text}
+			if !e.Match("prefix "+text+" suffix", "WARN", attrs) {
+				t.Fatalf("%q does not match its own input", f)
+			}
+			if e.Match("prefix "+text+" ignored", "error", attrs) {
+				t.Fatalf("%q matches excluded text", f)
+			}
+			if e.Match("prefix "+text, "info", attrs) {
+				t.Fatalf("%q matches other level", f)
+			}
+			if e.Match("prefix "+text, "error", map[string]string{"route": "GET /other"}) {
+				t.Fatalf("%q matches other attribute value", f)
+			}
+		})
+	}
+}
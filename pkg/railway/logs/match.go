@@ -0,0 +1,293 @@
+package logs
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Expr 解析后的过滤表达式，可在客户端对日志求值
+type Expr struct {
+	root node
+}
+
+// ParseFilter 解析过滤表达式；空串匹配所有日志
+func ParseFilter(filter string) (*Expr, error) {
+	toks, err := tokenize(filter)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	if len(toks) == 0 {
+		return &Expr{}, nil
+	}
+	n, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("logs filter: unexpected %q", p.toks[p.pos].text)
+	}
+	return &Expr{root: n}, nil
+}
+
+// Match 判断一行日志是否满足表达式：文本不区分大小写地匹配消息子串，@level/@severity 匹配级别，
+// 其余 @key 匹配同名属性（值相等）
+func (e *Expr) Match(message, severity string, attributes map[string]string) bool {
+	if e == nil || e.root == nil {
+		return true
+	}
+	return e.root.match(&entry{message: strings.ToLower(message), severity: severity, attrs: attributes})
+}
+
+type entry struct {
+	message  string
+	severity string
+	attrs    map[string]string
+}
+
+type node interface {
+	match(e *entry) bool
+}
+
+type andNode []node
+
+func (n andNode) match(e *entry) bool {
+	for _, c := range n {
+		if !c.match(e) {
+			return false
+		}
+	}
+	return true
+}
+
+type orNode []node
+
+func (n orNode) match(e *entry) bool {
+	for _, c := range n {
+		if c.match(e) {
+			return true
+		}
+	}
+	return false
+}
+
+type notNode struct{ n node }
+
+func (n notNode) match(e *entry) bool { return !n.n.match(e) }
+
+type textNode string
+
+func (n textNode) match(e *entry) bool { return strings.Contains(e.message, string(n)) }
+
+type attrNode struct{ key, value string }
+
+func (n attrNode) match(e *entry) bool {
+	switch n.key {
+	case "level", "severity":
+		lvl, err := NormalizeLevel(e.severity)
+		if err != nil {
+			lvl = strings.ToLower(e.severity)
+		}
+		return lvl == n.value
+	}
+	v, ok := e.attrs[n.key]
+	return ok && v == n.value
+}
+
+// ---- 词法与语法分析 ----
+
+type tokKind int
+
+const (
+	tokWord tokKind = iota // 文本或 @key:value
+	tokLParen
+	tokRParen
+	tokNot
+	tokAnd
+	tokOr
+)
+
+type token struct {
+	kind   tokKind
+	text   string
+	quoted bool // 含引号段
+	attr   bool // 以未加引号的 @ 开头
+}
+
+func tokenize(s string) ([]token, error) {
+	var toks []token
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			toks = append(toks, token{kind: tokLParen, text: "("})
+			i++
+		case r == ')':
+			toks = append(toks, token{kind: tokRParen, text: ")"})
+			i++
+		case r == '-' && i+1 < len(rs) && !unicode.IsSpace(rs[i+1]):
+			toks = append(toks, token{kind: tokNot, text: "-"})
+			i++
+		default:
+			// 一个词：连续的非空白非括号字符，其中的双引号段整体保留
+			var b strings.Builder
+			quoted := false
+			attr := r == '@'
+			for i < len(rs) && !unicode.IsSpace(rs[i]) && rs[i] != '(' && rs[i] != ')' {
+				if rs[i] != '"' {
+					b.WriteRune(rs[i])
+					i++
+					continue
+				}
+				quoted = true
+				i++
+				closed := false
+				for i < len(rs) {
+					if rs[i] == '\\' && i+1 < len(rs) {
+						b.WriteRune(rs[i+1])
+						i += 2
+						continue
+					}
+					if rs[i] == '"' {
+						closed = true
+						i++
+						break
+					}
+					b.WriteRune(rs[i])
+					i++
+				}
+				if !closed {
+					return nil, fmt.Errorf("logs filter: unterminated quote in %q", s)
+				}
+			}
+			t := token{kind: tokWord, text: b.String(), quoted: quoted, attr: attr}
+			if !quoted && t.text == "AND" {
+				t.kind = tokAnd
+			} else if !quoted && t.text == "OR" {
+				t.kind = tokOr
+			}
+			toks = append(toks, t)
+		}
+	}
+	return toks, nil
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.toks) {
+		return token{}, false
+	}
+	return p.toks[p.pos], true
+}
+
+func (p *parser) or() (node, error) {
+	var alts orNode
+	for {
+		n, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		alts = append(alts, n)
+		if t, ok := p.peek(); !ok || t.kind != tokOr {
+			break
+		}
+		p.pos++
+	}
+	if len(alts) == 1 {
+		return alts[0], nil
+	}
+	return alts, nil
+}
+
+func (p *parser) and() (node, error) {
+	var all andNode
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind == tokRParen || t.kind == tokOr {
+			break
+		}
+		if t.kind == tokAnd {
+			if len(all) == 0 {
+				return nil, fmt.Errorf("logs filter: unexpected AND")
+			}
+			p.pos++
+			if t, ok := p.peek(); !ok || t.kind == tokRParen || t.kind == tokOr || t.kind == tokAnd {
+				return nil, fmt.Errorf("logs filter: missing term after AND")
+			}
+			continue
+		}
+		n, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, n)
+	}
+	switch len(all) {
+	case 0:
+		return nil, fmt.Errorf("logs filter: missing term")
+	case 1:
+		return all[0], nil
+	}
+	return all, nil
+}
+
+func (p *parser) unary() (node, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("logs filter: missing term")
+	}
+	switch t.kind {
+	case tokNot:
+		p.pos++
+		n, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{n}, nil
+	case tokLParen:
+		p.pos++
+		n, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := p.peek(); !ok || t.kind != tokRParen {
+			return nil, fmt.Errorf("logs filter: missing )")
+		}
+		p.pos++
+		return n, nil
+	case tokWord:
+		p.pos++
+		return term(t)
+	}
+	return nil, fmt.Errorf("logs filter: unexpected %q", t.text)
+}
+
+// term 解析一个词：以未加引号的 @ 开头的是属性条件 @key:value，其余为文本
+func term(t token) (node, error) {
+	if t.attr {
+		key, value, ok := strings.Cut(t.text[1:], ":")
+		if !ok || !attrKeyPattern.MatchString(key) || value == "" {
+			return nil, fmt.Errorf("logs filter: invalid attribute condition %q", t.text)
+		}
+		if key == "level" || key == "severity" {
+			lvl, err := NormalizeLevel(value)
+			if err != nil {
+				return nil, err
+			}
+			value = lvl
+		}
+		return attrNode{key: key, value: value}, nil
+	}
+	if t.text == "" {
+		return nil, fmt.Errorf("logs filter: empty search text")
+	}
+	return textNode(strings.ToLower(t.text)), nil
+}
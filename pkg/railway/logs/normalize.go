@@ -0,0 +1,157 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry 规范化后的一行日志，JSON 形式为稳定的 {ts, level, service, deployment, msg, fields}，
+// 不随后端订阅帧结构变化，适合 jq 等工具处理
+type Entry struct {
+	Time       time.Time `json:"ts"`
+	Level      string    `json:"level"`
+	Service    string    `json:"service"`
+	Deployment string    `json:"deployment"`
+	Message    string    `json:"msg"`
+	// Fields JSON 消息中的其余字段与 Railway 日志属性；始终非 nil
+	Fields map[string]any `json:"fields"`
+}
+
+// 从 JSON 消息中识别的保留字段，按优先级排列
+var (
+	messageKeys = []string{"msg", "message", "text", "event"}
+	levelKeys   = []string{"level", "severity", "lvl", "log.level", "loglevel"}
+	timeKeys    = []string{"ts", "time", "timestamp", "@timestamp", "t"}
+)
+
+// Normalize 将 Record 转换为 Entry：
+//   - 消息是 JSON 对象时，msg/message、level/severity、ts/time 等字段提升为对应列，其余字段放入 Fields；
+//     不是 JSON 对象时原样作为 msg
+//   - Railway 日志属性并入 Fields，与消息中的字段同名时以消息为准
+//   - service 取标签 serviceName，没有时取 serviceId；deployment 取标签 deploymentId
+//   - level 规范为 debug/info/warn/error（无法识别时保留小写原值），缺省为 info；pino/bunyan 的数字级别
+//     （10 trace、20 debug、30 info、40 warn、50 error、60 fatal）映射到对应名称；ts 以 Railway 时间戳为准，缺失时取消息中的时间
+func (r Record) Normalize() Entry {
+	e := Entry{
+		Time:       r.Time,
+		Level:      r.Severity,
+		Service:    r.Tags["serviceName"],
+		Deployment: r.Tags["deploymentId"],
+		Message:    r.Message,
+		Fields:     map[string]any{},
+	}
+	if e.Service == "" {
+		e.Service = r.Tags["serviceId"]
+	}
+	if fields, ok := parseJSONMessage(r.Message); ok {
+		if v, ok := takeString(fields, messageKeys); ok {
+			e.Message = v
+		}
+		if v, ok := takeString(fields, levelKeys); ok && v != "" {
+			// 应用自报的级别比采集端推断的更准确
+			e.Level = v
+		}
+		if v, ok := takeString(fields, timeKeys); ok && e.Time.IsZero() {
+			if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				e.Time = t
+			}
+		}
+		e.Fields = fields
+	}
+	if e.Level == "" {
+		for _, k := range levelKeys {
+			if v := strings.Trim(r.Attributes[k], `"`); v != "" {
+				e.Level = v
+				break
+			}
+		}
+	}
+	for k, v := range r.Attributes {
+		if _, ok := e.Fields[k]; !ok && !isReserved(k) {
+			e.Fields[k] = v
+		}
+	}
+	if lvl, ok := numericLevel(e.Level); ok {
+		e.Level = lvl
+	} else if lvl, err := NormalizeLevel(e.Level); err == nil {
+		e.Level = lvl
+	} else {
+		e.Level = strings.ToLower(strings.TrimSpace(e.Level))
+	}
+	if e.Level == "" {
+		e.Level = "info"
+	}
+	return e
+}
+
+// parseJSONMessage 消息为 JSON 对象时返回其字段；数字保留原始精度（json.Number）
+func parseJSONMessage(msg string) (map[string]any, bool) {
+	msg = strings.TrimSpace(msg)
+	if !strings.HasPrefix(msg, "{") || !strings.HasSuffix(msg, "}") {
+		return nil, false
+	}
+	dec := json.NewDecoder(bytes.NewReader([]byte(msg)))
+	dec.UseNumber()
+	var fields map[string]any
+	if err := dec.Decode(&fields); err != nil || dec.More() {
+		return nil, false
+	}
+	if fields == nil {
+		fields = map[string]any{}
+	}
+	return fields, true
+}
+
+// takeString 取出 keys 中第一个存在的字段（从 fields 中删除），非字符串值转为 JSON 文本
+func takeString(fields map[string]any, keys []string) (string, bool) {
+	for _, k := range keys {
+		v, ok := fields[k]
+		if !ok {
+			continue
+		}
+		delete(fields, k)
+		switch v := v.(type) {
+		case string:
+			return v, true
+		case nil:
+			return "", true
+		default:
+			b, _ := json.Marshal(v)
+			return string(b), true
+		}
+	}
+	return "", false
+}
+
+// numericLevel 按 pino/bunyan 的数字级别映射，自定义级别（如 35）归入不高于它的标准级别；
+// 小于 10 的数字不是 pino 级别（可能是 syslog severity），不做映射
+func numericLevel(level string) (string, bool) {
+	n, err := strconv.Atoi(strings.TrimSpace(level))
+	if err != nil || n < 10 {
+		return "", false
+	}
+	switch {
+	case n < 30:
+		return "debug", true
+	case n < 40:
+		return "info", true
+	case n < 50:
+		return "warn", true
+	}
+	return "error", true
+}
+
+// isReserved Railway 属性中与 Entry 列重复的键（如 level），不再放入 Fields
+func isReserved(key string) bool {
+	for _, keys := range [][]string{messageKeys, levelKeys, timeKeys} {
+		for _, k := range keys {
+			if k == key {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+// go test ./pkg/railway/logs -run NormalizeGolden -update 重新生成 testdata/normalize.golden
+var update = flag.Bool("update", false, "rewrite testdata/normalize.golden")
+
+func TestNormalizeGolden(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tags := map[string]string{"serviceId": "svc-1", "serviceName": "api", "deploymentId": "dep-1"}
+	cases := []struct {
+		name string
+		r    Record
+	}{
+		{"plain text", Record{Time: ts, Severity: "info", Message: "server listening on :8080", Tags: tags}},
+		{"plain text without severity", Record{Time: ts, Message: "hello", Tags: map[string]string{"serviceId": "svc-1"}}},
+		{"plain text unknown severity", Record{Time: ts, Severity: "NOTICE", Message: "hello"}},
+		{"json msg and level", Record{Time: ts, Severity: "info", Tags: tags,
+			Message: `{"msg":"request done","level":"warning","status":503,"latency_ms":12.5}`}},
+		{"json aliases", Record{Severity: "info",
+			Message: `{"message":"m","severity":"ERR","@timestamp":"2024-05-06T07:08:09.123Z","text":"kept as field"}`}},
+		{"json railway time wins", Record{Time: ts, Message: `{"event":"e","time":"2030-01-01T00:00:00Z"}`}},
+		{"json non-string message", Record{Time: ts, Message: `{"msg":{"nested":true},"level":null}`}},
+		{"json large number", Record{Time: ts, Message: `{"msg":"id","id":12345678901234567890}`}},
+		{"pino levels", Record{Time: ts, Severity: "info", Message: `{"level":30,"msg":"pino info","pid":1}`}},
+		{"pino trace", Record{Time: ts, Message: `{"level":10,"msg":"t"}`}},
+		{"pino warn", Record{Time: ts, Message: `{"level":40,"msg":"w"}`}},
+		{"pino custom level", Record{Time: ts, Message: `{"level":35,"msg":"c"}`}},
+		{"bunyan fatal", Record{Time: ts, Message: `{"level":60,"msg":"f","name":"app","v":0}`}},
+		{"string numeric level", Record{Time: ts, Message: `{"level":"50","msg":"e"}`}},
+		{"small numeric level", Record{Time: ts, Message: `{"level":3,"msg":"s"}`}},
+		{"attributes merged", Record{Time: ts, Message: "plain", Tags: tags,
+			Attributes: map[string]string{"path": "/api", "status": "200"}}},
+		{"message fields win over attributes", Record{Time: ts, Message: `{"msg":"m","path":"/from-msg","n":1}`,
+			Attributes: map[string]string{"path": "/from-attr", "extra": "x"}}},
+		{"attribute level used when severity empty", Record{Time: ts, Message: "plain",
+			Attributes: map[string]string{"level": `"error"`, "msg": "dropped", "ts": "dropped"}}},
+		{"attribute pino level", Record{Time: ts, Message: "plain", Attributes: map[string]string{"level": "20"}}},
+		{"json array", Record{Time: ts, Severity: "info", Message: `[1,2,3]`}},
+		{"json string", Record{Time: ts, Severity: "info", Message: `"quoted"`}},
+		{"json number", Record{Time: ts, Severity: "info", Message: `42`}},
+		{"json null", Record{Time: ts, Severity: "info", Message: `null`}},
+		{"invalid json object", Record{Time: ts, Severity: "info", Message: `{"msg": oops}`}},
+		{"two json objects", Record{Time: ts, Severity: "info", Message: `{"msg":"a"} {"msg":"b"}`}},
+		{"empty json object", Record{Time: ts, Severity: "debug", Message: ` {} `}},
+	}
+	var got bytes.Buffer
+	for _, c := range cases {
+		b, err := json.Marshal(c.r.Normalize())
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(&got, "# %s\n%s\n", c.name, b)
+	}
+	const golden = "testdata/normalize.golden"
+	if *update {
+		if err := os.WriteFile(golden, got.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), want) {
+		t.Fatalf("normalized entries differ from %s (run with -update):\n%s", golden, got.Bytes())
+	}
+}
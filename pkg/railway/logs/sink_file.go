@@ -0,0 +1,124 @@
+package logs
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileSinkOptions NDJSON 文件参数
+type FileSinkOptions struct {
+	// Path 当前写入的文件；轮转后的历史文件为 Path.1（最新）… Path.N
+	Path string
+	// MaxSize 单个文件的字节数上限，0 使用默认值 100MiB，<0 不轮转
+	MaxSize int64
+	// MaxFiles 保留的历史文件数，0 使用默认值 5
+	MaxFiles int
+}
+
+// FileSink 每行一个 JSON 对象（Record.Normalize 得到的 Entry，与 `railway logs --json` 相同）写入文件，超过 MaxSize 时轮转
+type FileSink struct {
+	opts FileSinkOptions
+	f    *os.File
+	size int64
+}
+
+// NewFileSink 以追加方式打开（必要时创建）文件
+func NewFileSink(opts FileSinkOptions) (*FileSink, error) {
+	if opts.Path == "" {
+		return nil, errors.New("logs: file sink requires a path")
+	}
+	if opts.MaxSize == 0 {
+		opts.MaxSize = 100 << 20
+	}
+	if opts.MaxFiles <= 0 {
+		opts.MaxFiles = 5
+	}
+	s := &FileSink{opts: opts}
+	if err := os.MkdirAll(filepath.Dir(opts.Path), 0o755); err != nil {
+		return nil, err
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileSink) open() error {
+	f, err := os.OpenFile(s.opts.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	s.f, s.size = f, st.Size()
+	return nil
+}
+
+// Write 追加一批日志；一行写不下当前文件时先轮转
+func (s *FileSink) Write(_ context.Context, records []Record) error {
+	if s.f == nil {
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+	w := bufio.NewWriter(s.f)
+	for _, r := range records {
+		line, err := json.Marshal(r.Normalize())
+		if err != nil {
+			return err
+		}
+		line = append(line, '\n')
+		if s.opts.MaxSize > 0 && s.size > 0 && s.size+int64(len(line)) > s.opts.MaxSize {
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			if err := s.rotate(); err != nil {
+				return err
+			}
+			w.Reset(s.f)
+		}
+		n, err := w.Write(line)
+		s.size += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// rotate Path.N-1 → Path.N … Path → Path.1，然后重新打开 Path
+func (s *FileSink) rotate() error {
+	if err := s.f.Close(); err != nil {
+		return err
+	}
+	s.f = nil
+	p := s.opts.Path
+	_ = os.Remove(fmt.Sprintf("%s.%d", p, s.opts.MaxFiles))
+	for i := s.opts.MaxFiles - 1; i >= 1; i-- {
+		if err := os.Rename(fmt.Sprintf("%s.%d", p, i), fmt.Sprintf("%s.%d", p, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(p, p+".1"); err != nil {
+		return err
+	}
+	return s.open()
+}
+
+// Close 关闭文件
+func (s *FileSink) Close() error {
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
@@ -0,0 +1,148 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LokiOptions Loki push 参数
+type LokiOptions struct {
+	// URL 推送地址；未给出路径时使用 /loki/api/v1/push
+	URL string
+	// Labels 附加到每条流的静态标签
+	Labels map[string]string
+	// TenantID 多租户时的 X-Scope-OrgID
+	TenantID string
+	// Username / Password 基本认证
+	Username string
+	Password string
+	// HTTPClient 默认超时 10s
+	HTTPClient *http.Client
+}
+
+// LokiSink 以 Loki push API（JSON）批量发送日志。流标签为静态标签加上 service_id、environment_id
+// 与 level，日志行为 Record.Normalize 得到的 Entry 的 JSON 形式（与 `railway logs --json` 相同，可用 LogQL 的 | json 解析）
+type LokiSink struct {
+	opts LokiOptions
+	hc   *http.Client
+}
+
+// NewLokiSink 创建 Loki Sink
+func NewLokiSink(opts LokiOptions) (*LokiSink, error) {
+	if opts.URL == "" {
+		return nil, errors.New("logs: loki sink requires a URL")
+	}
+	if i := strings.Index(opts.URL, "://"); i >= 0 && !strings.Contains(strings.TrimSuffix(opts.URL[i+3:], "/"), "/") {
+		opts.URL = strings.TrimSuffix(opts.URL, "/") + "/loki/api/v1/push"
+	}
+	hc := opts.HTTPClient
+	if hc == nil {
+		hc = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &LokiSink{opts: opts, hc: hc}, nil
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// Write 按标签分组为流后一次推送
+func (s *LokiSink) Write(ctx context.Context, records []Record) error {
+	streams := map[string]*lokiStream{}
+	var order []string
+	for _, r := range records {
+		labels := s.labels(r)
+		key := labelKey(labels)
+		st, ok := streams[key]
+		if !ok {
+			st = &lokiStream{Stream: labels}
+			streams[key] = st
+			order = append(order, key)
+		}
+		e := r.Normalize()
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		ts := e.Time
+		if ts.IsZero() {
+			ts = time.Now()
+		}
+		st.Values = append(st.Values, [2]string{strconv.FormatInt(ts.UnixNano(), 10), string(line)})
+	}
+	body := struct {
+		Streams []*lokiStream `json:"streams"`
+	}{}
+	for _, k := range order {
+		body.Streams = append(body.Streams, streams[k])
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.opts.URL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.opts.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", s.opts.TenantID)
+	}
+	if s.opts.Username != "" || s.opts.Password != "" {
+		req.SetBasicAuth(s.opts.Username, s.opts.Password)
+	}
+	resp, err := s.hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("logs: loki push: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func (s *LokiSink) labels(r Record) map[string]string {
+	labels := map[string]string{"source": "railway"}
+	for k, v := range s.opts.Labels {
+		labels[k] = v
+	}
+	if v := r.Tags["serviceId"]; v != "" {
+		labels["service_id"] = v
+	}
+	if v := r.Tags["environmentId"]; v != "" {
+		labels["environment_id"] = v
+	}
+	if lvl, err := NormalizeLevel(r.Severity); err == nil {
+		labels["level"] = lvl
+	}
+	return labels
+}
+
+func labelKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k + "=" + labels[k] + "\x00")
+	}
+	return b.String()
+}
+
+// Close 无需释放资源
+func (s *LokiSink) Close() error { return nil }
@@ -0,0 +1,248 @@
+package logs
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var sinkTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func TestSyslogFormat(t *testing.T) {
+	s, err := NewSyslogSink(SyslogOptions{Addr: "127.0.0.1:514", Hostname: "host one\x01", AppName: "my app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		r    Record
+		want string
+	}{
+		{"error with data", Record{Time: sinkTime, Severity: "ERROR", Message: "boom",
+			Tags: map[string]string{"serviceId": "s1"}, Attributes: map[string]string{"path": `a"b]\c`, "bad key": "x"}},
+			`<11>1 2024-01-02T03:04:05.000000Z hostone myapp - - [railway@32473 serviceId="s1" bad_key="x" path="a\"b\]\\c"] boom`},
+		{"warning alias", Record{Time: sinkTime, Severity: "warning", Message: "w"},
+			`<12>1 2024-01-02T03:04:05.000000Z hostone myapp - - - w`},
+		{"info", Record{Time: sinkTime, Severity: "info", Message: "i"},
+			`<14>1 2024-01-02T03:04:05.000000Z hostone myapp - - - i`},
+		{"debug", Record{Time: sinkTime, Severity: "debug", Message: "d"},
+			`<15>1 2024-01-02T03:04:05.000000Z hostone myapp - - - d`},
+		{"unknown level no time", Record{Message: "n"},
+			`<13>1 - hostone myapp - - - n`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.format(tt.r); got != tt.want {
+				t.Fatalf("format =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyslogSinkUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	s, err := OpenSink("syslog://" + pc.LocalAddr().String() + "?app=web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.(*SyslogSink).opts.Hostname = "h"
+	if err := s.Write(context.Background(), []Record{
+		{Time: sinkTime, Severity: "info", Message: "one"},
+		{Time: sinkTime, Severity: "error", Message: "two"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 2048)
+	// 每条日志一个数据报，不加长度前缀
+	for _, want := range []string{
+		"<14>1 2024-01-02T03:04:05.000000Z h web - - - one",
+		"<11>1 2024-01-02T03:04:05.000000Z h web - - - two",
+	} {
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(buf[:n]); got != want {
+			t.Fatalf("datagram = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestSyslogSinkTCPOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	frames := make(chan string, 4)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		br := bufio.NewReader(conn)
+		for {
+			// MSG-LEN SP SYSLOG-MSG
+			prefix, err := br.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, err := strconv.Atoi(strings.TrimSuffix(prefix, " "))
+			if err != nil {
+				frames <- "bad prefix " + prefix
+				return
+			}
+			msg := make([]byte, n)
+			if _, err := io.ReadFull(br, msg); err != nil {
+				return
+			}
+			frames <- string(msg)
+		}
+	}()
+	s, err := NewSyslogSink(SyslogOptions{Network: "tcp", Addr: ln.Addr().String(), Hostname: "h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	// 消息含换行，仅靠长度前缀分帧
+	if err := s.Write(context.Background(), []Record{
+		{Time: sinkTime, Severity: "warn", Message: "multi\nline"},
+		{Time: sinkTime, Severity: "info", Message: "ünïcode"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<12>1 2024-01-02T03:04:05.000000Z h railway - - - multi\nline",
+		"<14>1 2024-01-02T03:04:05.000000Z h railway - - - ünïcode",
+	} {
+		select {
+		case got := <-frames:
+			if got != want {
+				t.Fatalf("frame = %q, want %q", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("frame not received")
+		}
+	}
+}
+
+func TestNewSyslogSinkErrors(t *testing.T) {
+	if _, err := NewSyslogSink(SyslogOptions{Addr: "x:1", Network: "unix"}); err == nil {
+		t.Fatal("unix network accepted")
+	}
+	if _, err := NewSyslogSink(SyslogOptions{}); err == nil {
+		t.Fatal("empty address accepted")
+	}
+}
+
+type lokiPush struct {
+	Streams []struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	} `json:"streams"`
+}
+
+func TestLokiSinkPush(t *testing.T) {
+	var (
+		got          lokiPush
+		path, tenant string
+		user, pass   string
+		hasAuth      bool
+		contentType  string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, tenant, contentType = r.URL.Path, r.Header.Get("X-Scope-OrgID"), r.Header.Get("Content-Type")
+		user, pass, hasAuth = r.BasicAuth()
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	u := strings.Replace(srv.URL, "http://", "loki+http://bot:pw@", 1) + "?tenant=acme&label.env=prod"
+	s, err := OpenSink(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	tags := map[string]string{"serviceId": "s1", "environmentId": "e1", "deploymentId": "d1"}
+	records := []Record{
+		{Time: sinkTime, Severity: "ERROR", Message: "a", Tags: tags},
+		{Time: sinkTime.Add(time.Second), Severity: "info", Message: "b", Tags: tags},
+		{Time: sinkTime.Add(2 * time.Second), Severity: "err", Message: "c", Tags: tags, Attributes: map[string]string{"k": "v"}},
+		{Time: sinkTime, Severity: "trace", Message: "d"},
+	}
+	if err := s.Write(context.Background(), records); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/loki/api/v1/push" || tenant != "acme" || contentType != "application/json" || !hasAuth || user != "bot" || pass != "pw" {
+		t.Fatalf("path=%q tenant=%q type=%q auth=%v %q:%q", path, tenant, contentType, hasAuth, user, pass)
+	}
+	// 按首次出现的顺序分组为流；未知级别不加 level 标签
+	wantLabels := []map[string]string{
+		{"source": "railway", "env": "prod", "service_id": "s1", "environment_id": "e1", "level": "error"},
+		{"source": "railway", "env": "prod", "service_id": "s1", "environment_id": "e1", "level": "info"},
+		{"source": "railway", "env": "prod"},
+	}
+	wantMsgs := [][]string{{"a", "c"}, {"b"}, {"d"}}
+	if len(got.Streams) != len(wantLabels) {
+		t.Fatalf("streams = %+v", got.Streams)
+	}
+	for i, st := range got.Streams {
+		if !reflect.DeepEqual(st.Stream, wantLabels[i]) {
+			t.Errorf("stream %d labels = %v, want %v", i, st.Stream, wantLabels[i])
+		}
+		var msgs []string
+		for _, v := range st.Values {
+			var e Entry
+			if err := json.Unmarshal([]byte(v[1]), &e); err != nil {
+				t.Fatalf("line %q: %v", v[1], err)
+			}
+			if v[0] != strconv.FormatInt(e.Time.UnixNano(), 10) {
+				t.Errorf("timestamp %s for entry at %v", v[0], e.Time)
+			}
+			// 推送的是与 --json 相同的规范化 Entry
+			if e.Message == "c" && (e.Level != "error" || e.Fields["k"] != "v") {
+				t.Errorf("entry c = %+v", e)
+			}
+			msgs = append(msgs, e.Message)
+		}
+		if !reflect.DeepEqual(msgs, wantMsgs[i]) {
+			t.Errorf("stream %d messages = %v, want %v", i, msgs, wantMsgs[i])
+		}
+	}
+}
+
+func TestLokiSinkErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "entry out of order", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+	s, err := NewLokiSink(LokiOptions{URL: srv.URL + "/custom"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Write(context.Background(), []Record{{Time: sinkTime, Message: "x"}})
+	if err == nil || !strings.Contains(err.Error(), "400") || !strings.Contains(err.Error(), "entry out of order") {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := NewLokiSink(LokiOptions{}); err == nil {
+		t.Fatal("empty URL accepted")
+	}
+}
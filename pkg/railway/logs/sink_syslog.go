@@ -0,0 +1,192 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SyslogOptions syslog 参数
+type SyslogOptions struct {
+	// Network "udp"（默认）或 "tcp"；TCP 使用 RFC6587 octet-counting 分帧
+	Network string
+	// Addr host:port
+	Addr string
+	// AppName APP-NAME 字段，默认 "railway"
+	AppName string
+	// Hostname HOSTNAME 字段，默认本机主机名
+	Hostname string
+	// Facility 默认 1（user-level）
+	Facility int
+	// Timeout 连接与写入超时，0 使用默认值 5s
+	Timeout time.Duration
+}
+
+// sdID 结构化数据 ID；32473 为 RFC 5612 中供文档与示例使用的企业号
+const sdID = "railway@32473"
+
+// SyslogSink 以 RFC5424 格式发送日志，来源标签与属性放入结构化数据 [railway@32473 ...]
+type SyslogSink struct {
+	opts SyslogOptions
+	conn net.Conn
+}
+
+// NewSyslogSink 创建 syslog Sink；连接在首次写入时建立
+func NewSyslogSink(opts SyslogOptions) (*SyslogSink, error) {
+	if opts.Network == "" {
+		opts.Network = "udp"
+	}
+	if opts.Network != "udp" && opts.Network != "tcp" {
+		return nil, fmt.Errorf("logs: unsupported syslog network %q", opts.Network)
+	}
+	if opts.Addr == "" {
+		return nil, errors.New("logs: syslog sink requires an address")
+	}
+	if opts.AppName == "" {
+		opts.AppName = "railway"
+	}
+	if opts.Hostname == "" {
+		opts.Hostname, _ = os.Hostname()
+	}
+	if opts.Facility == 0 {
+		opts.Facility = 1
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	return &SyslogSink{opts: opts}, nil
+}
+
+// Write 逐条发送；写入失败时重连一次后重发该条
+func (s *SyslogSink) Write(ctx context.Context, records []Record) error {
+	for _, r := range records {
+		msg := s.format(r)
+		if s.opts.Network == "tcp" {
+			msg = fmt.Sprintf("%d %s", len(msg), msg)
+		}
+		if err := s.send(ctx, msg); err != nil {
+			s.reset()
+			if err := s.send(ctx, msg); err != nil {
+				s.reset()
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *SyslogSink) send(ctx context.Context, msg string) error {
+	if s.conn == nil {
+		d := net.Dialer{Timeout: s.opts.Timeout}
+		conn, err := d.DialContext(ctx, s.opts.Network, s.opts.Addr)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	_ = s.conn.SetWriteDeadline(time.Now().Add(s.opts.Timeout))
+	_, err := s.conn.Write([]byte(msg))
+	return err
+}
+
+func (s *SyslogSink) reset() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+// Close 关闭连接
+func (s *SyslogSink) Close() error {
+	s.reset()
+	return nil
+}
+
+// format 生成 <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD] MSG
+func (s *SyslogSink) format(r Record) string {
+	ts := "-"
+	if !r.Time.IsZero() {
+		ts = r.Time.UTC().Format("2006-01-02T15:04:05.000000Z07:00")
+	}
+	pri := s.opts.Facility*8 + syslogSeverity(r.Severity)
+	return fmt.Sprintf("<%d>1 %s %s %s - - %s %s",
+		pri, ts, headerField(s.opts.Hostname, 255), headerField(s.opts.AppName, 48), structuredData(r), r.Message)
+}
+
+// syslogSeverity 日志级别到 RFC5424 severity 的映射，未知级别视为 notice
+func syslogSeverity(level string) int {
+	lvl, _ := NormalizeLevel(level)
+	switch lvl {
+	case "error":
+		return 3
+	case "warn":
+		return 4
+	case "info":
+		return 6
+	case "debug":
+		return 7
+	}
+	return 5
+}
+
+// headerField 头部字段只允许可打印 ASCII 且不含空格，空值为 "-"
+func headerField(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return -1
+		}
+		return r
+	}, s)
+	if len(s) > max {
+		s = s[:max]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func structuredData(r Record) string {
+	if len(r.Tags) == 0 && len(r.Attributes) == 0 {
+		return "-"
+	}
+	var b strings.Builder
+	b.WriteString("[" + sdID)
+	for _, m := range []map[string]string{r.Tags, r.Attributes} {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			name := sdName(k)
+			if name == "" {
+				continue
+			}
+			b.WriteString(" " + name + `="` + sdEscape.Replace(m[k]) + `"`)
+		}
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// sdName PARAM-NAME 为 1~32 个可打印 ASCII，不含 = 空格 ] "
+func sdName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > 32 {
+		s = s[:32]
+	}
+	return s
+}
+
+var sdEscape = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
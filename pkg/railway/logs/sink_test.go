@@ -0,0 +1,168 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSink 记录写入；fail 为 true 时每次写入失败，block 为 true 时阻塞到 ctx 结束
+type fakeSink struct {
+	mu     sync.Mutex
+	fail   bool
+	block  bool
+	writes int
+	got    []Record
+	closed bool
+}
+
+func (s *fakeSink) Write(ctx context.Context, records []Record) error {
+	s.mu.Lock()
+	s.writes++
+	fail, block := s.fail, s.block
+	s.mu.Unlock()
+	if block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if fail {
+		return errors.New("sink unavailable")
+	}
+	s.mu.Lock()
+	s.got = append(s.got, records...)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *fakeSink) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	return nil
+}
+
+func send(t *testing.T, b *Buffer, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := b.Send(context.Background(), Record{Message: "m"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBufferCloseFlushes(t *testing.T) {
+	sink := &fakeSink{}
+	b := NewBuffer(sink, BufferOptions{BatchSize: 2, FlushInterval: time.Hour})
+	send(t, b, 5)
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.got) != 5 || !sink.closed || b.Failed() != 0 {
+		t.Fatalf("wrote %d, closed %v, failed %d", len(sink.got), sink.closed, b.Failed())
+	}
+	if err := b.Send(context.Background(), Record{}); !errors.Is(err, ErrBufferClosed) {
+		t.Fatalf("Send after Close = %v", err)
+	}
+}
+
+func TestBufferCloseTimeout(t *testing.T) {
+	tests := []struct {
+		name string
+		sink *fakeSink
+	}{
+		// 写入失败后进入很长的重试等待
+		{"backoff", &fakeSink{fail: true}},
+		// Write 本身阻塞
+		{"blocked write", &fakeSink{block: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reported []error
+			b := NewBuffer(tt.sink, BufferOptions{
+				FlushInterval: time.Hour,
+				RetryBackoff:  time.Hour,
+				CloseTimeout:  50 * time.Millisecond,
+				OnError:       func(err error) { reported = append(reported, err) },
+			})
+			send(t, b, 3)
+			start := time.Now()
+			err := b.Close()
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("Close err = %v, want DeadlineExceeded", err)
+			}
+			if d := time.Since(start); d > 5*time.Second {
+				t.Fatalf("Close blocked for %v", d)
+			}
+			if b.Failed() != 3 || len(reported) != 1 || !tt.sink.closed {
+				t.Fatalf("failed %d, reported %v, closed %v", b.Failed(), reported, tt.sink.closed)
+			}
+		})
+	}
+}
+
+func TestBufferShutdownContext(t *testing.T) {
+	sink := &fakeSink{fail: true}
+	b := NewBuffer(sink, BufferOptions{FlushInterval: time.Hour, RetryBackoff: time.Hour, CloseTimeout: -1})
+	send(t, b, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	if err := b.Shutdown(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Shutdown err = %v", err)
+	}
+	// 取消后不再重试
+	if sink.writes != 1 {
+		t.Fatalf("writes = %d, want 1", sink.writes)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatalf("second Close = %v", err)
+	}
+}
+
+func TestBufferShutdownUnblocksSend(t *testing.T) {
+	sink := &fakeSink{block: true}
+	b := NewBuffer(sink, BufferOptions{Size: 1, BatchSize: 1, FlushInterval: time.Hour, MaxRetries: -1, CloseTimeout: 50 * time.Millisecond})
+	// 第一条进入阻塞的 Write，第二条占满缓冲区，第三条阻塞在 Send
+	send(t, b, 2)
+	sent := make(chan error, 1)
+	go func() { sent <- b.Send(context.Background(), Record{Message: "blocked"}) }()
+	time.Sleep(20 * time.Millisecond)
+
+	closed := make(chan error, 1)
+	go func() { closed <- b.Close() }()
+	select {
+	case err := <-closed:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Close err = %v, want DeadlineExceeded", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked by a pending Send")
+	}
+	select {
+	case err := <-sent:
+		if !errors.Is(err, ErrBufferClosed) {
+			t.Fatalf("blocked Send err = %v, want ErrBufferClosed", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Send still blocked after Close")
+	}
+	if b.Failed() != 2 {
+		t.Fatalf("failed = %d, want 2", b.Failed())
+	}
+}
+
+func TestBufferDropPolicies(t *testing.T) {
+	for _, policy := range []OverflowPolicy{DropNewest, DropOldest} {
+		sink := &fakeSink{block: true}
+		b := NewBuffer(sink, BufferOptions{Size: 2, BatchSize: 1, FlushInterval: time.Hour, MaxRetries: -1, CloseTimeout: 50 * time.Millisecond, Overflow: policy})
+		// Write 阻塞：最多一条在写、两条在缓冲区，其余都被丢弃且 Send 不阻塞
+		send(t, b, 100)
+		if err := b.Close(); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("policy %d: Close err = %v", policy, err)
+		}
+		if b.Dropped() < 97 || b.Dropped()+b.Failed() != 100 {
+			t.Fatalf("policy %d: dropped %d, failed %d", policy, b.Dropped(), b.Failed())
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package logs
+
+import (
+	"errors"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// TriggerOptions Trigger 参数
+type TriggerOptions struct {
+	// Match 匹配日志消息的正则表达式，必填
+	Match *regexp.Regexp
+	// Dedup 去重窗口：同一服务的相同消息（数字不计）在窗口内只触发一次；0 不去重
+	Dedup time.Duration
+	// Rate / Per 限速：每 Per 时间内最多触发 Rate 次，超出的匹配被抑制；Rate 为 0 不限速
+	Rate int
+	Per  time.Duration
+}
+
+// TriggerEvent 一次触发；JSON 形式为 Entry 的字段加上 match、groups、suppressed 与 tags
+type TriggerEvent struct {
+	Entry
+	// Match 正则匹配到的文本
+	Match string `json:"match"`
+	// Groups 捕获组
+	Groups []string `json:"groups,omitempty"`
+	// Suppressed 自上次触发以来因去重、限速或处理积压被抑制的匹配数
+	Suppressed int `json:"suppressed"`
+	// Tags 来源标签
+	Tags map[string]string `json:"tags"`
+}
+
+// Trigger 从日志中挑出匹配的行，并按去重窗口与限速决定是否触发。并发安全。
+type Trigger struct {
+	opts TriggerOptions
+
+	mu         sync.Mutex
+	seen       map[string]time.Time
+	tokens     float64
+	refilled   time.Time
+	suppressed int
+	// now 当前时间，测试时替换
+	now func() time.Time
+}
+
+// NewTrigger 创建 Trigger
+func NewTrigger(opts TriggerOptions) (*Trigger, error) {
+	if opts.Match == nil {
+		return nil, errors.New("logs: trigger requires a match pattern")
+	}
+	if opts.Rate < 0 {
+		return nil, errors.New("logs: trigger rate must not be negative")
+	}
+	if opts.Rate > 0 && opts.Per <= 0 {
+		opts.Per = time.Minute
+	}
+	return &Trigger{opts: opts, seen: map[string]time.Time{}, tokens: float64(opts.Rate), now: time.Now}, nil
+}
+
+// Check 判断一行日志是否触发；不匹配或被抑制时返回 false。去重窗口从实际触发时起算，
+// 被限速抑制的匹配不会阻止之后的相同消息触发
+func (t *Trigger) Check(r Record) (*TriggerEvent, bool) {
+	m := t.opts.Match.FindStringSubmatch(r.Message)
+	if m == nil {
+		return nil, false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	var key string
+	if t.opts.Dedup > 0 {
+		key = r.Tags["serviceId"] + "\x00" + maskDigits(r.Message)
+		if last, ok := t.seen[key]; ok && now.Sub(last) < t.opts.Dedup {
+			t.suppressed++
+			return nil, false
+		}
+	}
+	if t.opts.Rate > 0 {
+		// 令牌桶：容量 Rate，每 Per 补满
+		if !t.refilled.IsZero() {
+			t.tokens += now.Sub(t.refilled).Seconds() / t.opts.Per.Seconds() * float64(t.opts.Rate)
+			if t.tokens > float64(t.opts.Rate) {
+				t.tokens = float64(t.opts.Rate)
+			}
+		}
+		t.refilled = now
+		if t.tokens < 1 {
+			t.suppressed++
+			return nil, false
+		}
+		t.tokens--
+	}
+	if t.opts.Dedup > 0 {
+		t.seen[key] = now
+		// 清理过期的键，避免长时间运行时无限增长
+		if len(t.seen) > 1024 {
+			for k, v := range t.seen {
+				if now.Sub(v) >= t.opts.Dedup {
+					delete(t.seen, k)
+				}
+			}
+		}
+	}
+	ev := &TriggerEvent{Entry: r.Normalize(), Match: m[0], Groups: m[1:], Suppressed: t.suppressed, Tags: r.Tags}
+	if ev.Tags == nil {
+		ev.Tags = map[string]string{}
+	}
+	t.suppressed = 0
+	return ev, true
+}
+
+// Drop 记录一个已触发但未能处理（如处理队列已满）的事件：该事件及其携带的抑制数
+// 计入下一次触发的 Suppressed
+func (t *Trigger) Drop(ev *TriggerEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.suppressed += 1 + ev.Suppressed
+}
+
+// Suppressed 自上次触发以来被抑制的匹配数
+func (t *Trigger) Suppressed() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.suppressed
+}
+
+// maskDigits 将连续数字替换为 #，使只有计数、ID、耗时不同的消息视为相同
+func maskDigits(s string) string {
+	b := make([]byte, 0, len(s))
+	digit := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= '0' && c <= '9' {
+			if !digit {
+				b = append(b, '#')
+			}
+			digit = true
+			continue
+		}
+		digit = false
+		b = append(b, c)
+	}
+	return string(b)
+}
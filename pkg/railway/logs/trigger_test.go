@@ -0,0 +1,183 @@
+package logs
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// fakeClock 手动推进的时钟
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestTrigger(t *testing.T, opts TriggerOptions) (*Trigger, *fakeClock) {
+	t.Helper()
+	tr, err := NewTrigger(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := &fakeClock{t: sinkTime}
+	tr.now = clock.now
+	return tr, clock
+}
+
+func svcRecord(service, msg string) Record {
+	return Record{Time: sinkTime, Message: msg, Tags: map[string]string{"serviceId": service}}
+}
+
+func TestNewTriggerErrors(t *testing.T) {
+	if _, err := NewTrigger(TriggerOptions{}); err == nil {
+		t.Fatal("missing pattern accepted")
+	}
+	if _, err := NewTrigger(TriggerOptions{Match: regexp.MustCompile("x"), Rate: -1}); err == nil {
+		t.Fatal("negative rate accepted")
+	}
+}
+
+func TestTriggerMatchGroups(t *testing.T) {
+	tr, _ := newTestTrigger(t, TriggerOptions{Match: regexp.MustCompile(`status=(5\d\d) path=(\S+)`)})
+	if _, ok := tr.Check(svcRecord("s1", "status=200 path=/ok")); ok {
+		t.Fatal("non-matching line triggered")
+	}
+	ev, ok := tr.Check(Record{Time: sinkTime, Severity: "error", Message: "GET status=503 path=/api took 12ms"})
+	if !ok {
+		t.Fatal("matching line did not trigger")
+	}
+	if ev.Match != "status=503 path=/api" || !reflect.DeepEqual(ev.Groups, []string{"503", "/api"}) {
+		t.Fatalf("match = %q, groups = %q", ev.Match, ev.Groups)
+	}
+	if ev.Level != "error" || ev.Message != "GET status=503 path=/api took 12ms" || ev.Tags == nil || ev.Suppressed != 0 {
+		t.Fatalf("event = %+v", ev)
+	}
+}
+
+func TestTriggerDedup(t *testing.T) {
+	tr, clock := newTestTrigger(t, TriggerOptions{Match: regexp.MustCompile(`timeout`), Dedup: time.Minute})
+	steps := []struct {
+		advance    time.Duration
+		record     Record
+		fire       bool
+		suppressed int
+	}{
+		{0, svcRecord("s1", "timeout after 30s (req 1234)"), true, 0},
+		// 只有数字不同的消息视为相同
+		{time.Second, svcRecord("s1", "timeout after 31s (req 98)"), false, 0},
+		{time.Second, svcRecord("s1", "timeout after 5s (req 7)"), false, 0},
+		// 不同服务与不同文本各自去重
+		{0, svcRecord("s2", "timeout after 30s (req 1234)"), true, 2},
+		{0, svcRecord("s1", "read timeout"), true, 0},
+		// 窗口从首次触发时起算
+		{58 * time.Second, svcRecord("s1", "timeout after 1s (req 1)"), true, 0},
+	}
+	for i, s := range steps {
+		clock.advance(s.advance)
+		ev, ok := tr.Check(s.record)
+		if ok != s.fire {
+			t.Fatalf("step %d: fired = %v", i, ok)
+		}
+		if ok && ev.Suppressed != s.suppressed {
+			t.Fatalf("step %d: suppressed = %d, want %d", i, ev.Suppressed, s.suppressed)
+		}
+	}
+}
+
+func TestMaskDigits(t *testing.T) {
+	tests := map[string]string{
+		"":                   "",
+		"no digits":          "no digits",
+		"took 123ms":         "took #ms",
+		"a1b22c333":          "a#b#c#",
+		"2024-01-02 err 500": "#-#-# err #",
+	}
+	for in, want := range tests {
+		if got := maskDigits(in); got != want {
+			t.Errorf("maskDigits(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestTriggerRateLimit(t *testing.T) {
+	tr, clock := newTestTrigger(t, TriggerOptions{Match: regexp.MustCompile(`boom`), Rate: 2, Per: 10 * time.Second})
+	fire := func() bool {
+		_, ok := tr.Check(svcRecord("s1", "boom"))
+		return ok
+	}
+	if !fire() || !fire() {
+		t.Fatal("burst of Rate events suppressed")
+	}
+	if fire() {
+		t.Fatal("event over rate fired")
+	}
+	// 每 Per/Rate 补充一个令牌
+	clock.advance(4 * time.Second)
+	if fire() {
+		t.Fatal("fired before a token was refilled")
+	}
+	clock.advance(time.Second)
+	if !fire() {
+		t.Fatal("refilled token not used")
+	}
+	if fire() {
+		t.Fatal("fired without tokens")
+	}
+	// 长时间空闲后最多补满 Rate 个
+	clock.advance(time.Hour)
+	if !fire() || !fire() || fire() {
+		t.Fatal("bucket not capped at Rate")
+	}
+	// 每次触发后清零，只剩最后一次被抑制的匹配
+	if got := tr.Suppressed(); got != 1 {
+		t.Fatalf("Suppressed() = %d, want 1", got)
+	}
+}
+
+func TestTriggerRateLimitedMatchDoesNotStartDedupWindow(t *testing.T) {
+	tr, clock := newTestTrigger(t, TriggerOptions{Match: regexp.MustCompile(`err`), Dedup: time.Minute, Rate: 1, Per: 10 * time.Second})
+	if _, ok := tr.Check(svcRecord("s1", "first err")); !ok {
+		t.Fatal("first event suppressed")
+	}
+	// 被限速抑制的消息不记入去重窗口，令牌补充后即可触发
+	if _, ok := tr.Check(svcRecord("s1", "second err")); ok {
+		t.Fatal("rate limit not applied")
+	}
+	clock.advance(10 * time.Second)
+	ev, ok := tr.Check(svcRecord("s1", "second err"))
+	if !ok {
+		t.Fatal("message suppressed by rate limit was deduplicated afterwards")
+	}
+	if ev.Suppressed != 1 {
+		t.Fatalf("suppressed = %d, want 1", ev.Suppressed)
+	}
+}
+
+func TestTriggerSuppressedCarriesOver(t *testing.T) {
+	tr, clock := newTestTrigger(t, TriggerOptions{Match: regexp.MustCompile(`oom`), Dedup: time.Minute})
+	ev, ok := tr.Check(svcRecord("s1", "oom killed pid 1"))
+	if !ok || ev.Suppressed != 0 {
+		t.Fatalf("first = %+v, %v", ev, ok)
+	}
+	for i := 0; i < 3; i++ {
+		tr.Check(svcRecord("s1", "oom killed pid 2"))
+	}
+	if got := tr.Suppressed(); got != 3 {
+		t.Fatalf("Suppressed() = %d, want 3", got)
+	}
+	// 未能处理的事件连同其携带的抑制数计入下一次触发
+	dropped, ok := tr.Check(svcRecord("s2", "oom"))
+	if !ok || dropped.Suppressed != 3 {
+		t.Fatalf("dropped = %+v, %v", dropped, ok)
+	}
+	tr.Drop(dropped)
+	tr.Check(svcRecord("s2", "oom"))
+	clock.advance(time.Minute)
+	ev, ok = tr.Check(svcRecord("s1", "oom killed pid 3"))
+	if !ok || ev.Suppressed != 5 {
+		t.Fatalf("next = %+v, %v; want suppressed 5", ev, ok)
+	}
+	if got := tr.Suppressed(); got != 0 {
+		t.Fatalf("Suppressed() after trigger = %d", got)
+	}
+}
@@ -0,0 +1,41 @@
+package railway
+
+import (
+	"context"
+
+	igql "github.com/railwayapp/cli/internal/gql"
+	"github.com/railwayapp/cli/internal/pagination"
+)
+
+// DefaultPageSize 未指定 PageSize 时每页请求的条数
+const DefaultPageSize = pagination.DefaultPageSize
+
+// PageOptions 分页控制：PageSize 每页请求条数（<=0 时使用 DefaultPageSize），
+// MaxItems 最多返回的条目数（<=0 表示不限制）
+type PageOptions = pagination.PageOptions
+
+// PageInfo 单页的游标信息
+type PageInfo = pagination.PageInfo
+
+// PageFunc 拉取一页：first 为本页请求条数，after 为上一页的结束游标（首页为空）
+type PageFunc[T any] func(ctx context.Context, first int, after string) ([]T, PageInfo, error)
+
+// Pager 基于 Relay 游标（first/after + pageInfo）的通用分页器，非并发安全。
+// Next 出错时游标保持不变，可再次调用 Next 重试同一页。
+//
+// Pages 与 Items 返回的函数签名与 iter.Seq2 兼容，Go 1.23+ 可直接 range：
+//
+//	for page, err := range cli.DeploymentsPager(pid, eid, nil, railway.PageOptions{}).Pages(ctx) { ... }
+type Pager[T any] struct {
+	*pagination.Pager[T]
+}
+
+// NewPager 以 fetch 构造分页器，可用于包装尚未内置分页变体的连接查询
+func NewPager[T any](fetch PageFunc[T], opts PageOptions) *Pager[T] {
+	return &Pager[T]{pagination.New(pagination.PageFunc[T](fetch), opts)}
+}
+
+// pageInfo 将 GraphQL pageInfo 转换为 PageInfo
+func pageInfo(pi igql.PageInfo) PageInfo {
+	return pagination.FromGQL(pi)
+}
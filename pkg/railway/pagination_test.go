@@ -0,0 +1,110 @@
+package railway_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/railwayapp/cli/pkg/railway"
+	"github.com/railwayapp/cli/pkg/railway/railwaytest"
+)
+
+// checkPager 比较逐页拉取与一次拉取的结果，并检查 MaxItems 截断与请求次数
+func checkPager[T any](t *testing.T, srv *railwaytest.Server, op string, want int, newPager func(railway.PageOptions) *railway.Pager[T]) {
+	t.Helper()
+	ctx := context.Background()
+	full, err := newPager(railway.PageOptions{PageSize: 100}).All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(full) != want {
+		t.Fatalf("%s: %d items, want %d", op, len(full), want)
+	}
+	before := countOps(srv, op)
+	paged, err := newPager(railway.PageOptions{PageSize: 2}).All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(paged, full) {
+		t.Fatalf("%s: paged = %+v, want %+v", op, paged, full)
+	}
+	if n, wantReqs := countOps(srv, op)-before, (want+1)/2; n != wantReqs {
+		t.Fatalf("%s: %d requests for page size 2, want %d", op, n, wantReqs)
+	}
+	limited, err := newPager(railway.PageOptions{PageSize: 2, MaxItems: 3}).All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(limited, full[:3]) {
+		t.Fatalf("%s: MaxItems 3 = %+v, want %+v", op, limited, full[:3])
+	}
+}
+
+func countOps(srv *railwaytest.Server, op string) int {
+	n := 0
+	for _, r := range srv.Requests() {
+		if r.Operation == op {
+			n++
+		}
+	}
+	return n
+}
+
+func TestSDKPagers(t *testing.T) {
+	srv := railwaytest.NewServer()
+	defer srv.Close()
+	c, err := railway.New(srv.ClientOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	p, env := srv.AddProject("demo")
+	for i := 1; i < 5; i++ {
+		srv.AddProject(fmt.Sprintf("other-%d", i))
+	}
+	web := srv.AddService(p.ID, "web")
+	for i := 1; i < 5; i++ {
+		srv.AddService(p.ID, fmt.Sprintf("svc-%d", i))
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := srv.AddDeployment(web.ID, env.ID, "SUCCESS"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.EnsureProjectToken(ctx, p.ID, env.ID, fmt.Sprintf("token-%d", i), railway.RetryOption{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	vol := srv.AddVolume(p.ID, env.ID, web.ID, "data")
+	for i := 0; i < 5; i++ {
+		if _, err := c.CreateVolumeBackup(ctx, vol.InstanceID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("projects", func(t *testing.T) {
+		checkPager(t, srv, "ProjectsPage", 5, func(o railway.PageOptions) *railway.Pager[railway.ProjectListItem] {
+			return c.ProjectsPager("", o)
+		})
+	})
+	t.Run("services", func(t *testing.T) {
+		checkPager(t, srv, "ProjectServices", 5, func(o railway.PageOptions) *railway.Pager[railway.ServiceInEnvironment] {
+			return c.ServicesPager(p.ID, env.ID, o)
+		})
+	})
+	t.Run("deployments", func(t *testing.T) {
+		checkPager(t, srv, "Deployments", 5, func(o railway.PageOptions) *railway.Pager[railway.Deployment] {
+			return c.DeploymentsPager(p.ID, env.ID, &web.ID, o)
+		})
+	})
+	t.Run("project tokens", func(t *testing.T) {
+		checkPager(t, srv, "ProjectTokens", 5, func(o railway.PageOptions) *railway.Pager[railway.ProjectToken] {
+			return c.ProjectTokensPager(p.ID, o)
+		})
+	})
+	t.Run("backups", func(t *testing.T) {
+		checkPager(t, srv, "Backups", 5, func(o railway.PageOptions) *railway.Pager[railway.ProjectBackup] {
+			return c.BackupsPager(p.ID, o)
+		})
+	})
+}
@@ -137,6 +137,24 @@ func (c *Client) ListProjects(ctx context.Context) ([]ProjectListItem, error) {
 	return out, nil
 }
 
+// ProjectsPager 按页列出项目（跳过已删除项目）；teamID 为空时列出个人项目
+func (c *Client) ProjectsPager(teamID string, opts PageOptions) *Pager[ProjectListItem] {
+	return NewPager(func(ctx context.Context, first int, after string) ([]ProjectListItem, PageInfo, error) {
+		resp, err := igql.ProjectsPage(ctx, c.gqlClient, igql.ProjectsPageVariables{TeamID: optString(teamID), First: &first, After: optString(after)})
+		if err != nil {
+			return nil, PageInfo{}, err
+		}
+		out := make([]ProjectListItem, 0, len(resp.Projects.Edges))
+		for _, e := range resp.Projects.Edges {
+			if e.Node.DeletedAt != nil {
+				continue
+			}
+			out = append(out, ProjectListItem{ID: e.Node.ID, Name: e.Node.Name})
+		}
+		return out, pageInfo(resp.Projects.PageInfo), nil
+	}, opts)
+}
+
 // ListProjectsFull 参考 link.go，返回包含环境与服务实例环境引用的完整项目列表
 // workspaceRef 可为空；非空时按工作区名称或团队ID过滤
 func (c *Client) ListProjectsFull(ctx context.Context, workspaceRef string) ([]ProjectInfo, error) {
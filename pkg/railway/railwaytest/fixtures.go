@@ -0,0 +1,220 @@
+package railwaytest
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// AddProject 创建项目及其 production 环境
+func (s *Server) AddProject(name string) (*Project, *Environment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.m.addProject(name, "", "")
+	return p, s.m.addEnvironment(p.ID, "production")
+}
+
+// AddEnvironment 在项目中创建环境
+func (s *Server) AddEnvironment(projectID, name string) *Environment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.addEnvironment(projectID, name)
+}
+
+// AddService 在项目中创建服务（并为每个环境创建实例）
+func (s *Server) AddService(projectID, name string) *Service {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.addService(projectID, name)
+}
+
+// AddDeployment 直接创建指定状态的部署（不会触发自动推进）
+func (s *Server) AddDeployment(serviceID, environmentID, status string) (*Deployment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.addDeployment(serviceID, environmentID, status)
+}
+
+// AddVolume 创建挂载到服务的卷
+func (s *Server) AddVolume(projectID, environmentID, serviceID, name string) *Volume {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v := &Volume{
+		ID:            s.m.newID("vol"),
+		ProjectID:     projectID,
+		Name:          name,
+		EnvironmentID: environmentID,
+		ServiceID:     serviceID,
+		CreatedAt:     time.Now().UTC(),
+	}
+	v.InstanceID = s.m.newID("vi")
+	s.m.volumes[v.ID] = v
+	return v
+}
+
+// SetVariables 覆盖变量；serviceID 为空时设置环境共享变量
+func (s *Server) SetVariables(projectID, environmentID, serviceID string, vars map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp := make(map[string]string, len(vars))
+	for k, v := range vars {
+		cp[k] = v
+	}
+	s.m.variables[varScope{projectID, environmentID, serviceID}] = cp
+}
+
+// Variables 返回服务可见的变量（共享变量被服务变量覆盖）
+func (s *Server) Variables(projectID, environmentID, serviceID string) map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.resolvedVariables(projectID, environmentID, serviceID)
+}
+
+// Deployment 返回部署快照
+func (s *Server) Deployment(id string) (Deployment, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.m.deployments[id]
+	if !ok {
+		return Deployment{}, false
+	}
+	cp := *d
+	cp.BuildLogs = append([]LogLine(nil), d.BuildLogs...)
+	cp.DeployLogs = append([]LogLine(nil), d.DeployLogs...)
+	return cp, true
+}
+
+// Deployments 返回服务在环境中的部署快照，最新的在前
+func (s *Server) Deployments(environmentID, serviceID string) []Deployment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Deployment
+	for _, d := range s.m.filterDeployments("", environmentID, serviceID) {
+		out = append(out, *d)
+	}
+	return out
+}
+
+// ServiceInstance 返回服务实例快照
+func (s *Server) ServiceInstance(serviceID, environmentID string) (ServiceInstance, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	si, ok := s.m.instances[instanceKey{serviceID, environmentID}]
+	if !ok {
+		return ServiceInstance{}, false
+	}
+	return *si, true
+}
+
+// SetDeploymentStatus 修改部署状态并推送给状态订阅者；
+// 变为 SUCCESS 时同一服务实例上一次成功的部署会被标记为 REMOVED
+func (s *Server) SetDeploymentStatus(id, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.setStatusLocked(id, status)
+}
+
+func (s *Server) setStatusLocked(id, status string) error {
+	d, ok := s.m.deployments[id]
+	if !ok {
+		return fmt.Errorf("deployment %s not found", id)
+	}
+	if status == "SUCCESS" {
+		for _, other := range s.m.deployments {
+			if other.ID != id && other.ServiceID == d.ServiceID && other.EnvironmentID == d.EnvironmentID && other.Status == "SUCCESS" {
+				other.Status = "REMOVED"
+				other.UpdatedAt = time.Now().UTC()
+				s.publishStatusLocked(other)
+			}
+		}
+	}
+	d.Status = status
+	d.UpdatedAt = time.Now().UTC()
+	s.publishStatusLocked(d)
+	return nil
+}
+
+// AppendBuildLog 追加构建日志并推送给订阅者
+func (s *Server) AppendBuildLog(deploymentID, message string, attrs map[string]string) error {
+	return s.appendLog(deploymentID, true, LogLine{Timestamp: time.Now().UTC(), Message: message, Severity: "info", Attributes: attrs})
+}
+
+// AppendDeployLog 追加运行日志并推送给订阅者
+func (s *Server) AppendDeployLog(deploymentID, message string, attrs map[string]string) error {
+	return s.appendLog(deploymentID, false, LogLine{Timestamp: time.Now().UTC(), Message: message, Severity: "info", Attributes: attrs})
+}
+
+// AppendLogLines 追加指定时间戳的日志行（build 为 true 时为构建日志），用于准备历史日志查询的数据；
+// 行按时间戳保存，同样推送给订阅者
+func (s *Server) AppendLogLines(deploymentID string, build bool, lines ...LogLine) error {
+	for _, l := range lines {
+		if err := s.appendLog(deploymentID, build, l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) appendLog(deploymentID string, build bool, line LogLine) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.m.deployments[deploymentID]
+	if !ok {
+		return fmt.Errorf("deployment %s not found", deploymentID)
+	}
+	if build {
+		d.BuildLogs = insertLogLine(d.BuildLogs, line)
+	} else {
+		d.DeployLogs = insertLogLine(d.DeployLogs, line)
+	}
+	s.publishLogLocked(d, build, line)
+	return nil
+}
+
+// insertLogLine 按时间戳插入，时间戳相同的行保持追加顺序
+func insertLogLine(lines []LogLine, line LogLine) []LogLine {
+	i := sort.Search(len(lines), func(i int) bool { return lines[i].Timestamp.After(line.Timestamp) })
+	lines = append(lines, LogLine{})
+	copy(lines[i+1:], lines[i:])
+	lines[i] = line
+	return lines
+}
+
+// startAutoDeploy 在启用 WithAutoDeploy 时后台推进部署状态
+func (s *Server) startAutoDeploy(deploymentID string) {
+	if s.autoDeploy <= 0 {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		steps := []func(){
+			func() { _ = s.AppendBuildLog(deploymentID, "Building image", nil) },
+			func() { _ = s.AppendBuildLog(deploymentID, "Build completed", nil) },
+			func() { _ = s.SetDeploymentStatus(deploymentID, "DEPLOYING") },
+			func() { _ = s.AppendDeployLog(deploymentID, "Starting container", nil) },
+			func() { _ = s.SetDeploymentStatus(deploymentID, "SUCCESS") },
+		}
+		for _, step := range steps {
+			select {
+			case <-s.closing:
+				return
+			case <-time.After(s.autoDeploy):
+			}
+			step()
+		}
+	}()
+}
+
+func (m *model) resolvedVariables(projectID, environmentID, serviceID string) map[string]string {
+	out := map[string]string{}
+	for k, v := range m.variables[varScope{projectID, environmentID, ""}] {
+		out[k] = v
+	}
+	if serviceID != "" {
+		for k, v := range m.variables[varScope{projectID, environmentID, serviceID}] {
+			out[k] = v
+		}
+	}
+	return out
+}
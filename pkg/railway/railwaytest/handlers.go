@@ -0,0 +1,698 @@
+package railwaytest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/railwayapp/cli/pkg/railway/logs"
+)
+
+// dispatch 按操作名执行内置处理；返回的对象只需覆盖查询选择的字段（多余字段由客户端忽略）
+func (s *Server) dispatch(r *http.Request, op string, vars map[string]interface{}) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := s.m
+	in := input(vars)
+
+	switch op {
+	// ---- 用户与工作区 ----
+	case "UserMeta":
+		return map[string]interface{}{"me": s.userJSON()}, nil
+	case "UserProjects":
+		var projects []interface{}
+		for _, p := range m.sortedProjects() {
+			projects = append(projects, s.projectJSON(p))
+		}
+		return map[string]interface{}{
+			"externalWorkspaces": []interface{}{},
+			"me": map[string]interface{}{
+				"workspaces": []interface{}{map[string]interface{}{
+					"id":   "ws_test",
+					"name": s.user.Name,
+					"team": map[string]interface{}{"id": "team_test", "projects": edges(projects)},
+				}},
+			},
+		}, nil
+
+	case "ProjectsPage":
+		var projects []interface{}
+		for _, p := range m.sortedProjects() {
+			if teamID := str(vars, "teamId"); teamID == "" || p.TeamID == teamID {
+				projects = append(projects, s.projectJSON(p))
+			}
+		}
+		return map[string]interface{}{"projects": pagedEdges(projects, vars)}, nil
+
+	// ---- 项目与环境 ----
+	case "Project":
+		p, ok := m.projects[str(vars, "id")]
+		if !ok {
+			return nil, notFound("project", str(vars, "id"))
+		}
+		return map[string]interface{}{"project": s.projectJSON(p)}, nil
+	case "ProjectServices":
+		p, ok := m.projects[str(vars, "id")]
+		if !ok {
+			return nil, notFound("project", str(vars, "id"))
+		}
+		var nodes []interface{}
+		for _, sv := range m.projectServices(p.ID) {
+			nodes = append(nodes, s.serviceJSON(sv))
+		}
+		return map[string]interface{}{"project": map[string]interface{}{"services": pagedEdges(nodes, vars)}}, nil
+	case "ProjectCreate":
+		p := m.addProject(str(vars, "name"), str(vars, "description"), str(vars, "teamId"))
+		m.addEnvironment(p.ID, "production")
+		return map[string]interface{}{"projectCreate": s.projectJSON(p)}, nil
+	case "ProjectDelete":
+		if !m.deleteProject(str(vars, "id")) {
+			return nil, notFound("project", str(vars, "id"))
+		}
+		return map[string]interface{}{"projectDelete": true}, nil
+	case "EnvironmentCreate":
+		if _, ok := m.projects[str(in, "projectId")]; !ok {
+			return nil, notFound("project", str(in, "projectId"))
+		}
+		e := m.addEnvironment(str(in, "projectId"), str(in, "name"))
+		return map[string]interface{}{"environmentCreate": map[string]interface{}{"id": e.ID, "name": e.Name}}, nil
+
+	// ---- 服务 ----
+	case "ServiceCreate":
+		projectID := str(in, "projectId")
+		if _, ok := m.projects[projectID]; !ok {
+			return nil, notFound("project", projectID)
+		}
+		for _, sv := range m.projectServices(projectID) {
+			if strings.EqualFold(sv.Name, str(in, "name")) {
+				return nil, Errorf("CONFLICT", "service %s already exists", sv.Name)
+			}
+		}
+		sv := m.addService(projectID, str(in, "name"))
+		if vs, ok := in["variables"].(map[string]interface{}); ok && str(in, "environmentId") != "" {
+			m.upsertVariables(projectID, str(in, "environmentId"), sv.ID, vs, false)
+		}
+		return map[string]interface{}{"serviceCreate": map[string]interface{}{"id": sv.ID, "name": sv.Name}}, nil
+	case "ServiceDelete":
+		if !m.deleteService(str(vars, "id")) {
+			return nil, notFound("service", str(vars, "id"))
+		}
+		return map[string]interface{}{"serviceDelete": true}, nil
+	case "ServiceInstanceDeploy":
+		d, err := m.addDeployment(str(in, "serviceId"), str(in, "environmentId"), "BUILDING")
+		if err != nil {
+			return nil, Errorf("NOT_FOUND", "%s", err.Error())
+		}
+		s.startAutoDeploy(d.ID)
+		return map[string]interface{}{"serviceInstanceDeploy": map[string]interface{}{"id": d.ID, "status": d.Status}}, nil
+	case "ServiceInstanceStop", "ServiceInstanceStopByParams":
+		serviceID, envID := firstStr(in, vars, "serviceId"), firstStr(in, vars, "environmentId")
+		si, ok := m.instances[instanceKey{serviceID, envID}]
+		if !ok {
+			return nil, notFound("service instance", serviceID)
+		}
+		si.Stopped = true
+		for _, d := range m.filterDeployments("", envID, serviceID) {
+			if d.Status == "SUCCESS" {
+				d.Stopped = true
+				s.publishStatusLocked(d)
+			}
+		}
+		return map[string]interface{}{"serviceInstanceStop": true}, nil
+	case "ServiceInstanceScale", "ServiceInstanceScaleByParams":
+		serviceID, envID := firstStr(in, vars, "serviceId"), firstStr(in, vars, "environmentId")
+		si, ok := m.instances[instanceKey{serviceID, envID}]
+		if !ok {
+			return nil, notFound("service instance", serviceID)
+		}
+		si.Replicas = intVar(in, "replicas")
+		return map[string]interface{}{"serviceInstanceScale": true}, nil
+
+	// ---- 部署 ----
+	case "Deployments":
+		deps := m.filterDeployments(firstStr(in, vars, "projectId"), firstStr(in, vars, "environmentId"), firstStr(in, vars, "serviceId"))
+		nodes := make([]interface{}, 0, len(deps))
+		for _, d := range deps {
+			nodes = append(nodes, s.deploymentJSON(d))
+		}
+		return map[string]interface{}{"deployments": pagedEdges(nodes, vars)}, nil
+	case "DeploymentStatus":
+		d, ok := m.deployments[str(vars, "id")]
+		if !ok {
+			return nil, notFound("deployment", str(vars, "id"))
+		}
+		return map[string]interface{}{"deployment": s.deploymentJSON(d)}, nil
+	case "BuildLogHistory", "DeploymentLogHistory":
+		d, ok := m.deployments[str(vars, "deploymentId")]
+		if !ok {
+			return nil, notFound("deployment", str(vars, "deploymentId"))
+		}
+		lines, key := d.DeployLogs, "deploymentLogs"
+		if op == "BuildLogHistory" {
+			lines, key = d.BuildLogs, "buildLogs"
+		}
+		filter, err := logs.ParseFilter(str(vars, "filter"))
+		if err != nil {
+			return nil, Errorf("BAD_USER_INPUT", "%v", err)
+		}
+		lines = filterLogLines(lines, filter)
+		// 只给 startDate 时从该时间起向后取，否则取 endDate 之前最近的行
+		start, end := str(vars, "startDate"), str(vars, "endDate")
+		lines = logWindow(lines, start, end, intVar(vars, "limit"), start != "" && end == "")
+		return map[string]interface{}{key: logsJSON(d, lines)}, nil
+	case "EnvironmentLogHistory":
+		filter, err := logs.ParseFilter(str(vars, "filter"))
+		if err != nil {
+			return nil, Errorf("BAD_USER_INPUT", "%v", err)
+		}
+		return map[string]interface{}{"environmentLogs": m.environmentLogWindow(vars, filter)}, nil
+	case "DeploymentRedeploy":
+		old, ok := m.deployments[str(vars, "id")]
+		if !ok {
+			return nil, notFound("deployment", str(vars, "id"))
+		}
+		d, err := m.addDeployment(old.ServiceID, old.EnvironmentID, "BUILDING")
+		if err != nil {
+			return nil, err
+		}
+		s.startAutoDeploy(d.ID)
+		return map[string]interface{}{"deploymentRedeploy": map[string]interface{}{"id": d.ID, "status": d.Status}}, nil
+	case "deploymentRollback":
+		old, ok := m.deployments[str(vars, "id")]
+		if !ok {
+			return nil, notFound("deployment", str(vars, "id"))
+		}
+		d, err := m.addDeployment(old.ServiceID, old.EnvironmentID, "BUILDING")
+		if err != nil {
+			return nil, err
+		}
+		s.startAutoDeploy(d.ID)
+		return map[string]interface{}{"deploymentRollback": true}, nil
+	case "DeploymentStop", "DeploymentStopSimple":
+		d, ok := m.deployments[str(vars, "id")]
+		if !ok {
+			return nil, notFound("deployment", str(vars, "id"))
+		}
+		d.Stopped = true
+		d.UpdatedAt = time.Now().UTC()
+		s.publishStatusLocked(d)
+		if op == "DeploymentStopSimple" {
+			return map[string]interface{}{"deploymentStop": true}, nil
+		}
+		return map[string]interface{}{"deploymentStop": map[string]interface{}{"id": d.ID, "status": d.Status, "deploymentStopped": true}}, nil
+	case "DeploymentCancel", "DeploymentAbort":
+		if _, ok := m.deployments[str(vars, "id")]; !ok {
+			return nil, notFound("deployment", str(vars, "id"))
+		}
+		if err := s.setStatusLocked(str(vars, "id"), "REMOVED"); err != nil {
+			return nil, err
+		}
+		key := "deploymentCancel"
+		if op == "DeploymentAbort" {
+			key = "deploymentAbort"
+		}
+		return map[string]interface{}{key: true}, nil
+	case "DeploymentRemove":
+		if _, ok := m.deployments[str(vars, "id")]; !ok {
+			return nil, notFound("deployment", str(vars, "id"))
+		}
+		if err := s.setStatusLocked(str(vars, "id"), "REMOVED"); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"deploymentRemove": true}, nil
+
+	// ---- 变量 ----
+	case "VariablesForServiceDeployment":
+		return map[string]interface{}{"variables": m.resolvedVariables(str(vars, "projectId"), str(vars, "environmentId"), str(vars, "serviceId"))}, nil
+	case "VariableCollectionUpsert":
+		vs, _ := in["variables"].(map[string]interface{})
+		replace, _ := in["replace"].(bool)
+		m.upsertVariables(str(in, "projectId"), str(in, "environmentId"), str(in, "serviceId"), vs, replace)
+		return map[string]interface{}{"variableCollectionUpsert": true}, nil
+
+	// ---- 域名 ----
+	case "Domains":
+		serviceDomains, customDomains := []interface{}{}, []interface{}{}
+		for _, d := range m.sortedDomains() {
+			if d.ServiceID != str(vars, "serviceId") || d.EnvironmentID != str(vars, "environmentId") {
+				continue
+			}
+			if d.Custom {
+				customDomains = append(customDomains, customDomainJSON(d))
+			} else {
+				serviceDomains = append(serviceDomains, map[string]interface{}{"id": d.ID, "domain": d.Domain})
+			}
+		}
+		return map[string]interface{}{"domains": map[string]interface{}{"serviceDomains": serviceDomains, "customDomains": customDomains}}, nil
+	case "ServiceDomainCreate":
+		sv, ok := m.services[str(vars, "serviceId")]
+		if !ok {
+			return nil, notFound("service", str(vars, "serviceId"))
+		}
+		d := &Domain{ID: m.newID("sd"), ServiceID: sv.ID, EnvironmentID: str(vars, "environmentId")}
+		d.Domain = fmt.Sprintf("%s-%s.up.railway.app", strings.ToLower(sv.Name), d.ID)
+		m.domains[d.ID] = d
+		return map[string]interface{}{"serviceDomainCreate": map[string]interface{}{"id": d.ID, "domain": d.Domain}}, nil
+	case "CustomDomainAvailable":
+		for _, d := range m.domains {
+			if strings.EqualFold(d.Domain, str(vars, "domain")) {
+				return map[string]interface{}{"customDomainAvailable": map[string]interface{}{"available": false, "message": "domain is already in use"}}, nil
+			}
+		}
+		return map[string]interface{}{"customDomainAvailable": map[string]interface{}{"available": true, "message": ""}}, nil
+	case "CustomDomainCreate":
+		d := &Domain{ID: m.newID("cd"), Domain: str(in, "domain"), ServiceID: str(in, "serviceId"), EnvironmentID: str(in, "environmentId"), Custom: true}
+		m.domains[d.ID] = d
+		return map[string]interface{}{"customDomainCreate": customDomainJSON(d)}, nil
+	case "ServiceDomainDelete", "CustomDomainDelete":
+		d, ok := m.domains[str(vars, "id")]
+		if !ok || d.Custom != (op == "CustomDomainDelete") {
+			return nil, notFound("domain", str(vars, "id"))
+		}
+		delete(m.domains, d.ID)
+		key := "serviceDomainDelete"
+		if d.Custom {
+			key = "customDomainDelete"
+		}
+		return map[string]interface{}{key: true}, nil
+
+	// ---- 项目令牌 ----
+	case "ProjectTokenCreate", "ProjectTokenCreateByParams":
+		t := &ProjectToken{ID: m.newID("pt"), Name: firstStr(in, vars, "name"), ProjectID: firstStr(in, vars, "projectId"), EnvironmentID: firstStr(in, vars, "environmentId")}
+		t.Token = "railwaytest-project-token-" + t.ID
+		m.tokens[t.ID] = t
+		return map[string]interface{}{"projectTokenCreate": t.Token}, nil
+	case "ProjectTokenDelete", "ProjectTokenDeleteByInput":
+		id := firstStr(in, vars, "id")
+		if _, ok := m.tokens[id]; !ok {
+			return nil, notFound("project token", id)
+		}
+		delete(m.tokens, id)
+		return map[string]interface{}{"projectTokenDelete": true}, nil
+	case "ProjectTokens":
+		var nodes []interface{}
+		for _, t := range m.sortedTokens() {
+			if t.ProjectID != str(vars, "projectId") {
+				continue
+			}
+			nodes = append(nodes, s.tokenJSON(t))
+		}
+		return map[string]interface{}{"projectTokens": pagedEdges(nodes, vars)}, nil
+	case "ProjectToken":
+		return s.projectTokenLocked(r)
+
+	// ---- 卷与备份 ----
+	case "volumeInstanceBackupList":
+		var out []interface{}
+		for _, b := range m.sortedBackups() {
+			if b.VolumeInstanceID == str(vars, "volumeInstanceId") {
+				out = append(out, map[string]interface{}{"id": b.ID, "name": b.Name, "createdAt": b.CreatedAt.Format(time.RFC3339)})
+			}
+		}
+		return map[string]interface{}{"volumeInstanceBackupList": orEmpty(out)}, nil
+	case "Backups":
+		var out []interface{}
+		for _, b := range m.sortedBackups() {
+			for _, v := range m.volumes {
+				if v.InstanceID != b.VolumeInstanceID || v.ProjectID != str(vars, "projectId") {
+					continue
+				}
+				svc := map[string]interface{}{"id": v.ServiceID, "name": ""}
+				if sv, ok := m.services[v.ServiceID]; ok {
+					svc["name"] = sv.Name
+				}
+				out = append(out, map[string]interface{}{
+					"id": b.ID, "name": b.Name, "createdAt": b.CreatedAt.Format(time.RFC3339),
+					"status": "COMPLETED", "size": nil, "service": svc,
+				})
+			}
+		}
+		return map[string]interface{}{"backups": pagedEdges(out, vars)}, nil
+	case "VolumeInstanceBackupCreate":
+		b := &Backup{ID: m.newID("bak"), VolumeInstanceID: str(vars, "volumeInstanceId"), CreatedAt: time.Now().UTC()}
+		b.Name = "backup-" + b.ID
+		m.backups[b.ID] = b
+		return map[string]interface{}{"volumeInstanceBackupCreate": map[string]interface{}{"workflowId": m.completedWorkflow()}}, nil
+	case "VolumeInstanceBackupRestore":
+		if _, ok := m.backups[str(vars, "volumeInstanceBackupId")]; !ok {
+			return nil, notFound("backup", str(vars, "volumeInstanceBackupId"))
+		}
+		return map[string]interface{}{"volumeInstanceBackupRestore": map[string]interface{}{"workflowId": m.completedWorkflow()}}, nil
+	case "volumeInstanceBackupBatchDelete":
+		ids, _ := vars["volumeInstanceBackupIds"].([]interface{})
+		for _, id := range ids {
+			if s, ok := id.(string); ok {
+				delete(m.backups, s)
+			}
+		}
+		return map[string]interface{}{"volumeInstanceBackupBatchDelete": map[string]interface{}{"workflowId": m.completedWorkflow()}}, nil
+	case "volumeInstanceBackupScheduleList":
+		var out []interface{}
+		for _, sc := range m.schedules {
+			if sc.VolumeInstanceID == str(vars, "volumeInstanceId") {
+				out = append(out, map[string]interface{}{"id": sc.ID, "name": sc.Kind, "cron": "", "kind": sc.Kind, "retentionSeconds": 0, "createdAt": ""})
+			}
+		}
+		return map[string]interface{}{"volumeInstanceBackupScheduleList": orEmpty(out)}, nil
+	case "volumeInstanceBackupScheduleUpdate":
+		viID := str(vars, "volumeInstanceId")
+		for id, sc := range m.schedules {
+			if sc.VolumeInstanceID == viID {
+				delete(m.schedules, id)
+			}
+		}
+		kinds, _ := vars["kinds"].([]interface{})
+		for _, k := range kinds {
+			if ks, ok := k.(string); ok {
+				sc := &BackupSchedule{ID: m.newID("bs"), VolumeInstanceID: viID, Kind: ks}
+				m.schedules[sc.ID] = sc
+			}
+		}
+		return map[string]interface{}{"volumeInstanceBackupScheduleUpdate": true}, nil
+
+	// ---- 模板与 workflow ----
+	case "TemplateDeploy":
+		if _, ok := m.projects[str(vars, "projectId")]; !ok {
+			return nil, notFound("project", str(vars, "projectId"))
+		}
+		return map[string]interface{}{"templateDeployV2": map[string]interface{}{"projectId": str(vars, "projectId"), "workflowId": m.completedWorkflow()}}, nil
+	case "WorkflowStatus":
+		status, ok := m.workflows[str(vars, "workflowId")]
+		if !ok {
+			return nil, notFound("workflow", str(vars, "workflowId"))
+		}
+		return map[string]interface{}{"workflowStatus": map[string]interface{}{"__typename": "WorkflowResult", "status": status, "error": nil}}, nil
+	}
+	return nil, Errorf("UNSUPPORTED", "railwaytest: unsupported operation %q", op)
+}
+
+// projectTokenLocked 按请求中的项目访问令牌返回其作用域；未知令牌时返回 NOT_FOUND
+func (s *Server) projectTokenLocked(r *http.Request) (interface{}, error) {
+	tok := r.Header.Get("project-access-token")
+	for _, t := range s.m.tokens {
+		if t.Token != tok {
+			continue
+		}
+		p, e := s.m.projects[t.ProjectID], s.m.environments[t.EnvironmentID]
+		if p == nil || e == nil {
+			break
+		}
+		return map[string]interface{}{"projectToken": map[string]interface{}{
+			"project":     map[string]interface{}{"id": p.ID, "name": p.Name},
+			"environment": map[string]interface{}{"id": e.ID, "name": e.Name},
+		}}, nil
+	}
+	return nil, notFound("project token", "")
+}
+
+func (s *Server) userJSON() map[string]interface{} {
+	return map[string]interface{}{"id": s.user.ID, "name": s.user.Name, "email": s.user.Email, "avatar": nil}
+}
+
+func (s *Server) projectJSON(p *Project) map[string]interface{} {
+	var envs, svcs, vols []interface{}
+	for _, e := range s.m.projectEnvironments(p.ID) {
+		envs = append(envs, map[string]interface{}{"id": e.ID, "name": e.Name})
+	}
+	for _, sv := range s.m.projectServices(p.ID) {
+		svcs = append(svcs, s.serviceJSON(sv))
+	}
+	for _, v := range s.m.volumes {
+		if v.ProjectID == p.ID {
+			vols = append(vols, map[string]interface{}{"id": v.ID, "name": v.Name, "createdAt": v.CreatedAt.Format(time.RFC3339), "projectId": p.ID})
+		}
+	}
+	var teamID interface{}
+	if p.TeamID != "" {
+		teamID = map[string]interface{}{"id": p.TeamID, "name": p.TeamID}
+	}
+	return map[string]interface{}{
+		"id":           p.ID,
+		"name":         p.Name,
+		"description":  nullable(p.Description),
+		"createdAt":    p.CreatedAt.Format(time.RFC3339),
+		"updatedAt":    p.CreatedAt.Format(time.RFC3339),
+		"deletedAt":    nil,
+		"team":         teamID,
+		"environments": edges(envs),
+		"services":     edges(svcs),
+		"volumes":      edges(vols),
+	}
+}
+
+func (s *Server) serviceJSON(sv *Service) map[string]interface{} {
+	var insts []interface{}
+	for _, si := range s.m.serviceInstances(sv.ID) {
+		insts = append(insts, map[string]interface{}{
+			"id":            sv.ID + ":" + si.EnvironmentID,
+			"serviceName":   sv.Name,
+			"environmentId": si.EnvironmentID,
+		})
+	}
+	return map[string]interface{}{"id": sv.ID, "name": sv.Name, "icon": nil, "serviceInstances": edges(insts)}
+}
+
+func (s *Server) deploymentJSON(d *Deployment) map[string]interface{} {
+	name := ""
+	if sv, ok := s.m.services[d.ServiceID]; ok {
+		name = sv.Name
+	}
+	return map[string]interface{}{
+		"id":                d.ID,
+		"status":            d.Status,
+		"createdAt":         d.CreatedAt.Format(time.RFC3339Nano),
+		"updatedAt":         d.UpdatedAt.Format(time.RFC3339Nano),
+		"staticUrl":         nullable(d.StaticURL),
+		"url":               nullable(d.StaticURL),
+		"deploymentStopped": d.Stopped,
+		"service":           map[string]interface{}{"id": d.ServiceID, "name": name},
+	}
+}
+
+func (s *Server) tokenJSON(t *ProjectToken) map[string]interface{} {
+	out := map[string]interface{}{"id": t.ID, "name": t.Name}
+	if p, ok := s.m.projects[t.ProjectID]; ok {
+		out["project"] = map[string]interface{}{"id": p.ID, "name": p.Name}
+	}
+	if e, ok := s.m.environments[t.EnvironmentID]; ok {
+		out["environment"] = map[string]interface{}{"id": e.ID, "name": e.Name}
+	}
+	return out
+}
+
+func customDomainJSON(d *Domain) map[string]interface{} {
+	return map[string]interface{}{
+		"id":     d.ID,
+		"domain": d.Domain,
+		"status": map[string]interface{}{"dnsRecords": []interface{}{map[string]interface{}{
+			"hostlabel":     "",
+			"fqdn":          d.Domain,
+			"recordType":    "DNS_RECORD_TYPE_CNAME",
+			"requiredValue": d.ID + ".up.railway.app",
+			"currentValue":  "",
+			"status":        "DNS_RECORD_STATUS_REQUIRES_UPDATE",
+			"zone":          "",
+			"purpose":       "DNS_RECORD_PURPOSE_TRAFFIC_ROUTE",
+		}}},
+	}
+}
+
+// upsertVariables 合并或替换变量；值为 null 表示删除
+func (m *model) upsertVariables(projectID, environmentID, serviceID string, vs map[string]interface{}, replace bool) {
+	key := varScope{projectID, environmentID, serviceID}
+	cur := m.variables[key]
+	if cur == nil || replace {
+		cur = map[string]string{}
+	}
+	for k, v := range vs {
+		if v == nil {
+			delete(cur, k)
+			continue
+		}
+		cur[k] = fmt.Sprint(v)
+	}
+	m.variables[key] = cur
+}
+
+// completedWorkflow 登记一个已完成的 workflow 并返回其 ID
+func (m *model) completedWorkflow() string {
+	id := m.newID("wf")
+	m.workflows[id] = "Complete"
+	return id
+}
+
+func (m *model) sortedDomains() []*Domain {
+	out := make([]*Domain, 0, len(m.domains))
+	for _, d := range m.domains {
+		out = append(out, d)
+	}
+	sortByID(out, func(d *Domain) string { return d.ID })
+	return out
+}
+
+func (m *model) sortedTokens() []*ProjectToken {
+	out := make([]*ProjectToken, 0, len(m.tokens))
+	for _, t := range m.tokens {
+		out = append(out, t)
+	}
+	sortByID(out, func(t *ProjectToken) string { return t.ID })
+	return out
+}
+
+func (m *model) sortedBackups() []*Backup {
+	out := make([]*Backup, 0, len(m.backups))
+	for _, b := range m.backups {
+		out = append(out, b)
+	}
+	sortByID(out, func(b *Backup) string { return b.ID })
+	return out
+}
+
+// ---- 日志 ----
+
+// logWindow 返回 [start, end]（闭区间，空串不限）内的日志：forward 时取最早的 limit 行，否则取最近的 limit 行
+func logWindow(lines []LogLine, start, end string, limit int, forward bool) []LogLine {
+	lo, hi := logWindowBounds(lines, start, end, limit, forward)
+	return lines[lo:hi]
+}
+
+func logWindowBounds(lines []LogLine, start, end string, limit int, forward bool) (int, int) {
+	lo, hi := 0, len(lines)
+	if t, err := time.Parse(time.RFC3339Nano, start); err == nil {
+		lo = sort.Search(len(lines), func(i int) bool { return !lines[i].Timestamp.Before(t) })
+	}
+	if t, err := time.Parse(time.RFC3339Nano, end); err == nil {
+		hi = sort.Search(len(lines), func(i int) bool { return lines[i].Timestamp.After(t) })
+	}
+	if hi < lo {
+		hi = lo
+	}
+	if limit > 0 && hi-lo > limit {
+		if forward {
+			hi = lo + limit
+		} else {
+			lo = hi - limit
+		}
+	}
+	return lo, hi
+}
+
+// environmentLogWindow 环境内全部部署日志按时间排序后的窗口：给定 afterLimit 时从 afterDate 起向后取，
+// 否则取 beforeDate 之前最近的 beforeLimit 行（0 表示不限）；查询与订阅共用
+func (m *model) environmentLogWindow(vars map[string]interface{}, filter *logs.Expr) []interface{} {
+	type entry struct {
+		d *Deployment
+		l LogLine
+	}
+	var all []entry
+	for _, d := range m.filterDeployments("", str(vars, "environmentId"), "") {
+		for _, l := range filterLogLines(d.DeployLogs, filter) {
+			all = append(all, entry{d, l})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].l.Timestamp.Before(all[j].l.Timestamp) })
+	lines := make([]LogLine, len(all))
+	for i, e := range all {
+		lines[i] = e.l
+	}
+	forward := intVar(vars, "afterLimit") > 0
+	limit := intVar(vars, "beforeLimit")
+	if forward {
+		limit = intVar(vars, "afterLimit")
+	}
+	lo, hi := logWindowBounds(lines, str(vars, "afterDate"), str(vars, "beforeDate"), limit, forward)
+	out := make([]interface{}, 0, hi-lo)
+	for _, e := range all[lo:hi] {
+		out = append(out, logJSON(e.d, e.l))
+	}
+	return out
+}
+
+func logsJSON(d *Deployment, lines []LogLine) []interface{} {
+	out := make([]interface{}, 0, len(lines))
+	for _, l := range lines {
+		out = append(out, logJSON(d, l))
+	}
+	return out
+}
+
+// ---- 变量读取辅助 ----
+
+func input(vars map[string]interface{}) map[string]interface{} {
+	in, _ := vars["input"].(map[string]interface{})
+	if in == nil {
+		return map[string]interface{}{}
+	}
+	return in
+}
+
+func str(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+// firstStr 依次从 input 对象与顶层变量取值，兼容两种调用形式
+func firstStr(in, vars map[string]interface{}, key string) string {
+	if s := str(in, key); s != "" {
+		return s
+	}
+	return str(vars, key)
+}
+
+func intVar(m map[string]interface{}, key string) int {
+	switch v := m[key].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+func nullable(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func edges(nodes []interface{}) map[string]interface{} {
+	out := make([]interface{}, 0, len(nodes))
+	for _, n := range nodes {
+		out = append(out, map[string]interface{}{"node": n})
+	}
+	return map[string]interface{}{"edges": out, "pageInfo": map[string]interface{}{"hasNextPage": false, "endCursor": nil}}
+}
+
+// pagedEdges 按 first/after 切片返回连接；游标为下一条的偏移量
+func pagedEdges(nodes []interface{}, vars map[string]interface{}) map[string]interface{} {
+	start := 0
+	if after := str(vars, "after"); after != "" {
+		if n, err := strconv.Atoi(after); err == nil && n >= 0 && n <= len(nodes) {
+			start = n
+		}
+	}
+	end := len(nodes)
+	if first := intVar(vars, "first"); first > 0 && start+first < end {
+		end = start + first
+	}
+	out := make([]interface{}, 0, end-start)
+	for i, n := range nodes[start:end] {
+		out = append(out, map[string]interface{}{"cursor": strconv.Itoa(start + i + 1), "node": n})
+	}
+	var endCursor interface{}
+	if end > start {
+		endCursor = strconv.Itoa(end)
+	}
+	return map[string]interface{}{"edges": out, "pageInfo": map[string]interface{}{"hasNextPage": end < len(nodes), "endCursor": endCursor}}
+}
+
+func orEmpty[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
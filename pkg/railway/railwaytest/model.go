@@ -0,0 +1,332 @@
+package railwaytest
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Project 内存中的项目
+type Project struct {
+	ID          string
+	Name        string
+	Description string
+	TeamID      string
+	CreatedAt   time.Time
+}
+
+// Environment 内存中的环境
+type Environment struct {
+	ID        string
+	ProjectID string
+	Name      string
+}
+
+// Service 内存中的服务
+type Service struct {
+	ID        string
+	ProjectID string
+	Name      string
+}
+
+// ServiceInstance 服务在某个环境中的实例状态
+type ServiceInstance struct {
+	ServiceID     string
+	EnvironmentID string
+	Replicas      int
+	Stopped       bool
+}
+
+// LogLine 构建或运行日志
+type LogLine struct {
+	Timestamp  time.Time
+	Message    string
+	Severity   string
+	Attributes map[string]string
+}
+
+// Deployment 内存中的部署
+type Deployment struct {
+	ID            string
+	ProjectID     string
+	EnvironmentID string
+	ServiceID     string
+	Status        string
+	Stopped       bool
+	StaticURL     string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	BuildLogs     []LogLine
+	DeployLogs    []LogLine
+	// ArchiveSize 通过 /up 上传的归档大小（非上传创建时为 0）
+	ArchiveSize int64
+}
+
+// Domain 服务域名或自定义域名
+type Domain struct {
+	ID            string
+	Domain        string
+	ServiceID     string
+	EnvironmentID string
+	Custom        bool
+}
+
+// Volume 卷及其（单个）卷实例
+type Volume struct {
+	ID            string
+	ProjectID     string
+	Name          string
+	InstanceID    string
+	EnvironmentID string
+	ServiceID     string
+	CreatedAt     time.Time
+}
+
+// Backup 卷实例备份
+type Backup struct {
+	ID               string
+	VolumeInstanceID string
+	Name             string
+	CreatedAt        time.Time
+}
+
+// BackupSchedule 卷实例备份调度
+type BackupSchedule struct {
+	ID               string
+	VolumeInstanceID string
+	Kind             string
+}
+
+// ProjectToken 项目访问令牌
+type ProjectToken struct {
+	ID            string
+	Name          string
+	Token         string
+	ProjectID     string
+	EnvironmentID string
+}
+
+// varScope 变量作用域：服务 ID 为空表示环境共享变量
+type varScope struct {
+	projectID, environmentID, serviceID string
+}
+
+type instanceKey struct {
+	serviceID, environmentID string
+}
+
+// model 全部状态，由 Server.mu 保护
+type model struct {
+	seq          int
+	projects     map[string]*Project
+	environments map[string]*Environment
+	services     map[string]*Service
+	instances    map[instanceKey]*ServiceInstance
+	deployments  map[string]*Deployment
+	domains      map[string]*Domain
+	volumes      map[string]*Volume
+	backups      map[string]*Backup
+	schedules    map[string]*BackupSchedule
+	tokens       map[string]*ProjectToken
+	variables    map[varScope]map[string]string
+	workflows    map[string]string
+}
+
+func newModel() *model {
+	return &model{
+		projects:     map[string]*Project{},
+		environments: map[string]*Environment{},
+		services:     map[string]*Service{},
+		instances:    map[instanceKey]*ServiceInstance{},
+		deployments:  map[string]*Deployment{},
+		domains:      map[string]*Domain{},
+		volumes:      map[string]*Volume{},
+		backups:      map[string]*Backup{},
+		schedules:    map[string]*BackupSchedule{},
+		tokens:       map[string]*ProjectToken{},
+		variables:    map[varScope]map[string]string{},
+		workflows:    map[string]string{},
+	}
+}
+
+// newID 生成带前缀的确定性 ID（如 prj_1、svc_2）
+func (m *model) newID(prefix string) string {
+	m.seq++
+	return fmt.Sprintf("%s_%d", prefix, m.seq)
+}
+
+func (m *model) addProject(name, description, teamID string) *Project {
+	p := &Project{ID: m.newID("prj"), Name: name, Description: description, TeamID: teamID, CreatedAt: time.Now().UTC()}
+	m.projects[p.ID] = p
+	return p
+}
+
+func (m *model) addEnvironment(projectID, name string) *Environment {
+	e := &Environment{ID: m.newID("env"), ProjectID: projectID, Name: name}
+	m.environments[e.ID] = e
+	for _, s := range m.services {
+		if s.ProjectID == projectID {
+			m.instance(s.ID, e.ID)
+		}
+	}
+	return e
+}
+
+func (m *model) addService(projectID, name string) *Service {
+	s := &Service{ID: m.newID("svc"), ProjectID: projectID, Name: name}
+	m.services[s.ID] = s
+	for _, e := range m.environments {
+		if e.ProjectID == projectID {
+			m.instance(s.ID, e.ID)
+		}
+	}
+	return s
+}
+
+func (m *model) instance(serviceID, environmentID string) *ServiceInstance {
+	k := instanceKey{serviceID, environmentID}
+	si, ok := m.instances[k]
+	if !ok {
+		si = &ServiceInstance{ServiceID: serviceID, EnvironmentID: environmentID, Replicas: 1}
+		m.instances[k] = si
+	}
+	return si
+}
+
+func (m *model) addDeployment(serviceID, environmentID, status string) (*Deployment, error) {
+	svc, ok := m.services[serviceID]
+	if !ok {
+		return nil, fmt.Errorf("service %s not found", serviceID)
+	}
+	if _, ok := m.environments[environmentID]; !ok {
+		return nil, fmt.Errorf("environment %s not found", environmentID)
+	}
+	now := time.Now().UTC()
+	d := &Deployment{
+		ID:            m.newID("dep"),
+		ProjectID:     svc.ProjectID,
+		EnvironmentID: environmentID,
+		ServiceID:     serviceID,
+		Status:        status,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	m.deployments[d.ID] = d
+	m.instance(serviceID, environmentID).Stopped = false
+	return d, nil
+}
+
+func (m *model) deleteProject(id string) bool {
+	if _, ok := m.projects[id]; !ok {
+		return false
+	}
+	delete(m.projects, id)
+	for sid, s := range m.services {
+		if s.ProjectID == id {
+			m.deleteService(sid)
+		}
+	}
+	for eid, e := range m.environments {
+		if e.ProjectID == id {
+			delete(m.environments, eid)
+		}
+	}
+	for vid, v := range m.volumes {
+		if v.ProjectID == id {
+			delete(m.volumes, vid)
+		}
+	}
+	return true
+}
+
+func (m *model) deleteService(id string) bool {
+	if _, ok := m.services[id]; !ok {
+		return false
+	}
+	delete(m.services, id)
+	for k := range m.instances {
+		if k.serviceID == id {
+			delete(m.instances, k)
+		}
+	}
+	for did, d := range m.deployments {
+		if d.ServiceID == id {
+			delete(m.deployments, did)
+		}
+	}
+	for did, d := range m.domains {
+		if d.ServiceID == id {
+			delete(m.domains, did)
+		}
+	}
+	return true
+}
+
+// sortedProjects 按创建顺序返回项目
+func (m *model) sortedProjects() []*Project {
+	out := make([]*Project, 0, len(m.projects))
+	for _, p := range m.projects {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return idLess(out[i].ID, out[j].ID) })
+	return out
+}
+
+func (m *model) projectEnvironments(projectID string) []*Environment {
+	var out []*Environment
+	for _, e := range m.environments {
+		if e.ProjectID == projectID {
+			out = append(out, e)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return idLess(out[i].ID, out[j].ID) })
+	return out
+}
+
+func (m *model) projectServices(projectID string) []*Service {
+	var out []*Service
+	for _, s := range m.services {
+		if s.ProjectID == projectID {
+			out = append(out, s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return idLess(out[i].ID, out[j].ID) })
+	return out
+}
+
+func (m *model) serviceInstances(serviceID string) []*ServiceInstance {
+	var out []*ServiceInstance
+	for k, si := range m.instances {
+		if k.serviceID == serviceID {
+			out = append(out, si)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return idLess(out[i].EnvironmentID, out[j].EnvironmentID) })
+	return out
+}
+
+// filterDeployments 按项目/环境/服务过滤部署，最新的在前
+func (m *model) filterDeployments(projectID, environmentID, serviceID string) []*Deployment {
+	var out []*Deployment
+	for _, d := range m.deployments {
+		if (projectID == "" || d.ProjectID == projectID) &&
+			(environmentID == "" || d.EnvironmentID == environmentID) &&
+			(serviceID == "" || d.ServiceID == serviceID) {
+			out = append(out, d)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return idLess(out[j].ID, out[i].ID) })
+	return out
+}
+
+// idLess 按 ID 中的序号比较，保证输出顺序与创建顺序一致
+func idLess(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+func sortByID[T any](s []T, id func(T) string) {
+	sort.Slice(s, func(i, j int) bool { return idLess(id(s[i]), id(s[j])) })
+}
@@ -0,0 +1,93 @@
+package railwaytest
+
+import "strings"
+
+// MutationField __schema 内省返回的 Mutation 根字段
+type MutationField struct {
+	Name string
+	// Args 参数名
+	Args []string
+	// Type 返回类型名，如 "Boolean"、"Deployment"
+	Type string
+	// Object 返回类型为对象（否则为标量）
+	Object bool
+}
+
+// defaultMutationSchema 内置处理覆盖的变更；存在多种形态的字段取 input 形式
+var defaultMutationSchema = []MutationField{
+	{Name: "projectCreate", Args: []string{"input"}, Type: "Project", Object: true},
+	{Name: "projectDelete", Args: []string{"id"}, Type: "Boolean"},
+	{Name: "environmentCreate", Args: []string{"input"}, Type: "Environment", Object: true},
+	{Name: "serviceCreate", Args: []string{"input"}, Type: "Service", Object: true},
+	{Name: "serviceDelete", Args: []string{"id"}, Type: "Boolean"},
+	{Name: "serviceInstanceDeploy", Args: []string{"input"}, Type: "Deployment", Object: true},
+	{Name: "serviceInstanceStop", Args: []string{"input"}, Type: "Boolean"},
+	{Name: "serviceInstanceScale", Args: []string{"input"}, Type: "Boolean"},
+	{Name: "deploymentRedeploy", Args: []string{"id"}, Type: "Deployment", Object: true},
+	{Name: "deploymentRollback", Args: []string{"id"}, Type: "Boolean"},
+	{Name: "deploymentStop", Args: []string{"id"}, Type: "Deployment", Object: true},
+	{Name: "deploymentCancel", Args: []string{"id"}, Type: "Boolean"},
+	{Name: "deploymentAbort", Args: []string{"id"}, Type: "Boolean"},
+	{Name: "deploymentRemove", Args: []string{"id"}, Type: "Boolean"},
+	{Name: "variableCollectionUpsert", Args: []string{"input"}, Type: "Boolean"},
+	{Name: "serviceDomainCreate", Args: []string{"input"}, Type: "ServiceDomain", Object: true},
+	{Name: "serviceDomainDelete", Args: []string{"id"}, Type: "Boolean"},
+	{Name: "customDomainCreate", Args: []string{"input"}, Type: "CustomDomain", Object: true},
+	{Name: "customDomainDelete", Args: []string{"id"}, Type: "Boolean"},
+	{Name: "projectTokenCreate", Args: []string{"input"}, Type: "String"},
+	{Name: "projectTokenDelete", Args: []string{"id"}, Type: "Boolean"},
+	{Name: "volumeInstanceBackupCreate", Args: []string{"volumeInstanceId"}, Type: "WorkflowId", Object: true},
+	{Name: "volumeInstanceBackupRestore", Args: []string{"volumeInstanceBackupId", "volumeInstanceId"}, Type: "WorkflowId", Object: true},
+	{Name: "volumeInstanceBackupBatchDelete", Args: []string{"volumeInstanceBackupIds", "volumeInstanceId"}, Type: "WorkflowId", Object: true},
+	{Name: "volumeInstanceBackupScheduleUpdate", Args: []string{"volumeInstanceId", "kinds"}, Type: "Boolean"},
+	{Name: "templateDeployV2", Args: []string{"input"}, Type: "TemplateDeployPayload", Object: true},
+}
+
+// WithMutationSchema 指定 __schema 内省返回的 Mutation 根字段（默认为内置处理覆盖的变更），
+// 用于模拟只支持部分变更形态的后端
+func WithMutationSchema(fields ...MutationField) Option {
+	return func(s *Server) {
+		s.schema = append([]MutationField{}, fields...)
+	}
+}
+
+// WithoutIntrospection 禁用内省：__schema 查询返回 GRAPHQL_VALIDATION_FAILED
+func WithoutIntrospection() Option {
+	return func(s *Server) {
+		s.schema = nil
+	}
+}
+
+// isIntrospection 查询是否选择了 __schema
+func isIntrospection(query string) bool {
+	return strings.Contains(query, "__schema")
+}
+
+// introspect 返回 { __schema { mutationType { fields } } }；只覆盖 Mutation 根字段
+func (s *Server) introspect() (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.schema == nil {
+		return nil, Errorf("GRAPHQL_VALIDATION_FAILED", "GraphQL introspection is not allowed")
+	}
+	fields := make([]interface{}, 0, len(s.schema))
+	for _, f := range s.schema {
+		args := make([]interface{}, 0, len(f.Args))
+		for _, a := range f.Args {
+			args = append(args, map[string]interface{}{"name": a})
+		}
+		kind := "SCALAR"
+		if f.Object {
+			kind = "OBJECT"
+		}
+		fields = append(fields, map[string]interface{}{
+			"name": f.Name,
+			"args": args,
+			"type": map[string]interface{}{
+				"name": nil, "kind": "NON_NULL",
+				"ofType": map[string]interface{}{"name": f.Type, "kind": kind},
+			},
+		})
+	}
+	return map[string]interface{}{"__schema": map[string]interface{}{"mutationType": map[string]interface{}{"fields": fields}}}, nil
+}
@@ -0,0 +1,299 @@
+// Package railwaytest 提供进程内的 Railway 后端替身，用于离线集成测试。
+//
+// Server 在内存中维护项目、环境、服务、变量、部署、域名与卷，
+// 在 /graphql/v2 与 /graphql/internal 上按操作名响应 internal/gql 中的 GraphQL 操作（含 Mutation 根字段的 __schema 内省），
+// 支持 graphql-transport-ws 日志与部署状态订阅，以及 /project/{id}/environment/{id}/up 上传。
+//
+//	srv := railwaytest.NewServer()
+//	defer srv.Close()
+//	cli, _ := railway.New(srv.ClientOptions()...)
+package railwaytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	iclient "github.com/railwayapp/cli/internal/client"
+	"github.com/railwayapp/cli/pkg/railway"
+)
+
+// TestToken Server 接受的默认 API Token
+const TestToken = "railwaytest-token"
+
+// HandlerFunc 自定义操作处理函数，返回值作为 GraphQL data 编码
+type HandlerFunc func(variables map[string]interface{}) (interface{}, error)
+
+// Option 配置 Server
+type Option func(*Server)
+
+// WithAutoDeploy 上传或触发部署后自动推进状态 BUILDING → DEPLOYING → SUCCESS 并写入示例日志；
+// step 为每个阶段的间隔（<=0 时为 10ms）
+func WithAutoDeploy(step time.Duration) Option {
+	return func(s *Server) {
+		if step <= 0 {
+			step = 10 * time.Millisecond
+		}
+		s.autoDeploy = step
+	}
+}
+
+// WithUser 指定 me 查询返回的用户
+func WithUser(id, name, email string) Option {
+	return func(s *Server) {
+		s.user = user{ID: id, Name: name, Email: email}
+	}
+}
+
+type user struct {
+	ID, Name, Email string
+}
+
+// Server 内存中的 Railway 后端
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	m        *model
+	handlers map[string]HandlerFunc
+	subs     map[*subscription]struct{}
+	conns    map[*websocket.Conn]struct{}
+	requests []Request
+
+	user       user
+	schema     []MutationField
+	autoDeploy time.Duration
+	upgrader   websocket.Upgrader
+	wg         sync.WaitGroup
+	closing    chan struct{}
+}
+
+// Request 已收到的 GraphQL 请求记录
+type Request struct {
+	Endpoint  string
+	Operation string
+	Variables map[string]interface{}
+}
+
+// NewServer 启动 Server；测试结束时调用 Close
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		m:        newModel(),
+		handlers: map[string]HandlerFunc{},
+		subs:     map[*subscription]struct{}{},
+		conns:    map[*websocket.Conn]struct{}{},
+		user:     user{ID: "usr_test", Name: "Railway Test", Email: "test@railway.invalid"},
+		schema:   defaultMutationSchema,
+		upgrader: websocket.Upgrader{
+			Subprotocols: []string{"graphql-transport-ws"},
+			CheckOrigin:  func(*http.Request) bool { return true },
+		},
+		closing: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql/v2", s.serveGraphQL(iclient.EndpointPublic))
+	mux.HandleFunc("/graphql/internal", s.serveGraphQL(iclient.EndpointInternal))
+	mux.HandleFunc("/project/", s.serveUpload)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Close 关闭所有订阅并停止 Server
+func (s *Server) Close() {
+	close(s.closing)
+	s.mu.Lock()
+	subs := make([]*subscription, 0, len(s.subs))
+	for sub := range s.subs {
+		subs = append(subs, sub)
+	}
+	conns := make([]*websocket.Conn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.mu.Unlock()
+	for _, sub := range subs {
+		sub.cancel()
+	}
+	// 被升级为 WebSocket 的连接不受 httptest 管理，需要单独关闭
+	for _, c := range conns {
+		c.Close()
+	}
+	s.Server.Close()
+	s.wg.Wait()
+}
+
+// DropConnections 立即断开当前所有 WebSocket 连接（不发送 complete），用于模拟网络中断；
+// Server 继续接受新连接
+func (s *Server) DropConnections() {
+	s.mu.Lock()
+	conns := make([]*websocket.Conn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.mu.Unlock()
+	for _, c := range conns {
+		c.Close()
+	}
+}
+
+// ClientOptions 返回连接到该 Server 的 railway.New 选项
+func (s *Server) ClientOptions() []railway.Option {
+	return []railway.Option{railway.WithEndpoint(s.URL), railway.WithAPIToken(TestToken)}
+}
+
+// Handle 注册（或覆盖）操作名对应的处理函数，可用于模拟尚未内置的操作或注入错误
+func (s *Server) Handle(operation string, fn HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[operation] = fn
+}
+
+// Requests 返回已收到的 GraphQL 请求（按到达顺序）
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// graphQLError 以 GraphQL errors 形式返回的错误，可携带 extensions.code
+type graphQLError struct {
+	Message string
+	Code    string
+}
+
+func (e *graphQLError) Error() string { return e.Message }
+
+// Errorf 构造带 extensions.code 的 GraphQL 错误，供自定义 HandlerFunc 使用
+func Errorf(code, format string, args ...interface{}) error {
+	return &graphQLError{Message: fmt.Sprintf(format, args...), Code: code}
+}
+
+func notFound(kind, id string) error {
+	return Errorf("NOT_FOUND", "%s %s not found", kind, id)
+}
+
+func (s *Server) serveGraphQL(endpoint string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if websocket.IsWebSocketUpgrade(r) {
+			s.serveWS(w, r)
+			return
+		}
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !authorized(r) {
+			writeJSON(w, http.StatusUnauthorized, map[string]interface{}{
+				"errors": []map[string]interface{}{{"message": "Not Authorized", "extensions": map[string]interface{}{"code": "UNAUTHENTICATED"}}},
+			})
+			return
+		}
+		var req struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		_, name := iclient.ParseOperation(req.Query)
+		if req.Variables == nil {
+			req.Variables = map[string]interface{}{}
+		}
+
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{Endpoint: endpoint, Operation: name, Variables: req.Variables})
+		fn, custom := s.handlers[name]
+		s.mu.Unlock()
+
+		var data interface{}
+		var err error
+		switch {
+		case custom:
+			data, err = fn(req.Variables)
+		case isIntrospection(req.Query):
+			data, err = s.introspect()
+		default:
+			data, err = s.dispatch(r, name, req.Variables)
+		}
+		if err != nil {
+			ge := map[string]interface{}{"message": err.Error(), "path": []string{name}}
+			if e, ok := err.(*graphQLError); ok && e.Code != "" {
+				ge["extensions"] = map[string]interface{}{"code": e.Code}
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"data": nil, "errors": []interface{}{ge}})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"data": data})
+	}
+}
+
+// authorized 接受任意非空 Bearer token 或项目访问令牌
+func authorized(r *http.Request) bool {
+	if r.Header.Get("project-access-token") != "" {
+		return true
+	}
+	return strings.HasPrefix(r.Header.Get("authorization"), "Bearer ") && len(r.Header.Get("authorization")) > len("Bearer ")
+}
+
+// serveUpload 处理 /project/{projectId}/environment/{environmentId}/up?serviceId=...
+func (s *Server) serveUpload(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 5 || parts[0] != "project" || parts[2] != "environment" || parts[4] != "up" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !authorized(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	projectID, environmentID, serviceID := parts[1], parts[3], r.URL.Query().Get("serviceId")
+	n, err := io.Copy(io.Discard, r.Body)
+	if err != nil {
+		http.Error(w, "read body failed", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	svc, ok := s.m.services[serviceID]
+	if !ok || svc.ProjectID != projectID {
+		s.mu.Unlock()
+		http.Error(w, fmt.Sprintf("service %s not found", serviceID), http.StatusNotFound)
+		return
+	}
+	d, err := s.m.addDeployment(serviceID, environmentID, "BUILDING")
+	if err != nil {
+		s.mu.Unlock()
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	d.ArchiveSize = n
+	id := d.ID
+	s.mu.Unlock()
+
+	s.startAutoDeploy(id)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"deploymentId": id,
+		"logsUrl":      fmt.Sprintf("%s/project/%s/service/%s?id=%s", s.URL, projectID, serviceID, id),
+		"url":          "",
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,167 @@
+package railwaytest_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/railwayapp/cli/pkg/railway"
+	"github.com/railwayapp/cli/pkg/railway/railwaytest"
+)
+
+// newProject 返回连接 srv 的 Client 与一个含 web 服务的项目，以及可上传的项目目录
+func newProject(t *testing.T, srv *railwaytest.Server) (*railway.Client, railway.UpParams) {
+	t.Helper()
+	c, err := railway.New(srv.ClientOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, env := srv.AddProject("demo")
+	svc := srv.AddService(p.ID, "web")
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return c, railway.UpParams{ProjectID: p.ID, EnvironmentID: env.ID, ServiceID: svc.ID, ProjectRoot: root}
+}
+
+// recorder 收集 Up 的回调（回调可能来自不同 goroutine）
+type recorder struct {
+	mu       sync.Mutex
+	statuses []string
+	build    []string
+	deploy   []string
+}
+
+func (r *recorder) hook(p *railway.UpParams) {
+	add := func(dst *[]string) func(string) {
+		return func(s string) {
+			r.mu.Lock()
+			*dst = append(*dst, s)
+			r.mu.Unlock()
+		}
+	}
+	p.OnStatus, p.OnBuildLog, p.OnDeploymentLog = add(&r.statuses), add(&r.build), add(&r.deploy)
+}
+
+func TestUpFollowDown(t *testing.T) {
+	srv := railwaytest.NewServer(railwaytest.WithAutoDeploy(0))
+	defer srv.Close()
+	c, p := newProject(t, srv)
+	var rec recorder
+	rec.hook(&p)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	id, logsURL, err := c.Up(ctx, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logsURL == "" {
+		t.Error("empty logs URL")
+	}
+	d, ok := srv.Deployment(id)
+	if !ok {
+		t.Fatalf("deployment %s not in fake", id)
+	}
+	if d.Status != "SUCCESS" || d.ArchiveSize == 0 {
+		t.Fatalf("fake deployment = %s, archive %d bytes", d.Status, d.ArchiveSize)
+	}
+	rec.mu.Lock()
+	if last := rec.statuses[len(rec.statuses)-1]; last != "SUCCESS" {
+		t.Errorf("statuses = %v", rec.statuses)
+	}
+	if strings.Join(rec.build, ",") != "Building image,Build completed" {
+		t.Errorf("build logs = %v", rec.build)
+	}
+	if strings.Join(rec.deploy, ",") != "Starting container" {
+		t.Errorf("deploy logs = %v", rec.deploy)
+	}
+	rec.mu.Unlock()
+
+	if err := c.Down(ctx, p.ProjectID, p.EnvironmentID, p.ServiceID); err != nil {
+		t.Fatal(err)
+	}
+	if d, _ := srv.Deployment(id); d.Status != "REMOVED" {
+		t.Fatalf("after Down status = %s", d.Status)
+	}
+	if err := c.Down(ctx, p.ProjectID, p.EnvironmentID, p.ServiceID); !errors.Is(err, railway.ErrNotFound) {
+		t.Fatalf("second Down err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestUpBuildFailed(t *testing.T) {
+	srv := railwaytest.NewServer()
+	defer srv.Close()
+	c, p := newProject(t, srv)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// 没有自动推进：部署出现后写入构建错误并置为 FAILED
+	go func() {
+		for ctx.Err() == nil {
+			if ds := srv.Deployments(p.EnvironmentID, p.ServiceID); len(ds) > 0 {
+				_ = srv.AppendBuildLog(ds[0].ID, "error: go build failed", nil)
+				_ = srv.SetDeploymentStatus(ds[0].ID, "FAILED")
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+	id, _, err := c.Up(ctx, p)
+	var failed *railway.DeploymentFailedError
+	if !errors.As(err, &failed) || !errors.Is(err, railway.ErrDeploymentFailed) {
+		t.Fatalf("Up err = %v, want DeploymentFailedError", err)
+	}
+	if failed.DeploymentID != id || failed.Status != railway.DeploymentStatusFailed {
+		t.Fatalf("failed = %+v", failed)
+	}
+	if !failed.Report.Found() || !strings.Contains(failed.Report.Error, "go build failed") {
+		t.Fatalf("report = %+v", failed.Report)
+	}
+}
+
+func TestEnsureUpFollowsOnce(t *testing.T) {
+	srv := railwaytest.NewServer()
+	defer srv.Close()
+	c, p := newProject(t, srv)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Detach：上传后立即返回
+	id, _, err := c.EnsureUp(ctx, railway.UpParams{ProjectID: p.ProjectID, EnvironmentID: p.EnvironmentID, ServiceID: p.ServiceID, ProjectRoot: p.ProjectRoot, Detach: true}, railway.RetryOption{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, _ := srv.Deployment(id); d.Status != "BUILDING" {
+		t.Fatalf("detached deployment status = %s", d.Status)
+	}
+	_ = srv.SetDeploymentStatus(id, "REMOVED")
+
+	// 跟随期间的失败直接返回，不会重新上传
+	var uploads int
+	p.OnUploaded = func(string, string) { uploads++ }
+	go func() {
+		for ctx.Err() == nil {
+			if ds := srv.Deployments(p.EnvironmentID, p.ServiceID); len(ds) > 1 {
+				_ = srv.AppendBuildLog(ds[0].ID, "error: go build failed", nil)
+				_ = srv.SetDeploymentStatus(ds[0].ID, "FAILED")
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+	id, _, err = c.EnsureUp(ctx, p, railway.RetryOption{MaxAttempts: 3, Backoff: time.Millisecond})
+	var failed *railway.DeploymentFailedError
+	if !errors.As(err, &failed) || failed.DeploymentID != id {
+		t.Fatalf("EnsureUp err = %v, want DeploymentFailedError for %s", err, id)
+	}
+	if n := len(srv.Deployments(p.EnvironmentID, p.ServiceID)); uploads != 1 || n != 2 {
+		t.Fatalf("uploads = %d, deployments = %d", uploads, n)
+	}
+}
@@ -0,0 +1,265 @@
+package railwaytest
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	iclient "github.com/railwayapp/cli/internal/client"
+	"github.com/railwayapp/cli/pkg/railway/logs"
+)
+
+// subscription 一个 graphql-transport-ws 订阅
+type subscription struct {
+	id            string
+	operation     string
+	deploymentID  string
+	environmentID string
+	filter        *logs.Expr
+
+	conn   *wsConn
+	once   sync.Once
+	done   chan struct{}
+	cancel func()
+}
+
+// wsConn 串行化写入的连接
+type wsConn struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+type wsFrame struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+func (c *wsConn) send(id, typ string, payload interface{}) error {
+	f := wsFrame{ID: id, Type: typ}
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		f.Payload = b
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_ = c.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	return c.conn.WriteJSON(f)
+}
+
+func (s *Server) serveWS(w http.ResponseWriter, r *http.Request) {
+	if !authorized(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	raw, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	conn := &wsConn{conn: raw}
+	s.mu.Lock()
+	s.conns[raw] = struct{}{}
+	s.mu.Unlock()
+	s.wg.Add(1)
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, raw)
+		s.mu.Unlock()
+		raw.Close()
+	}()
+
+	active := map[string]*subscription{}
+	defer func() {
+		for _, sub := range active {
+			sub.cancel()
+		}
+	}()
+
+	for {
+		var f wsFrame
+		if err := raw.ReadJSON(&f); err != nil {
+			return
+		}
+		switch f.Type {
+		case "connection_init":
+			_ = conn.send("", "connection_ack", nil)
+		case "ping":
+			_ = conn.send("", "pong", nil)
+		case "subscribe":
+			var p struct {
+				Query     string                 `json:"query"`
+				Variables map[string]interface{} `json:"variables"`
+			}
+			_ = json.Unmarshal(f.Payload, &p)
+			sub := s.subscribe(conn, f.ID, p.Query, p.Variables)
+			if sub != nil {
+				active[f.ID] = sub
+			}
+		case "complete":
+			if sub, ok := active[f.ID]; ok {
+				sub.cancel()
+				delete(active, f.ID)
+			}
+		}
+	}
+}
+
+// subscribe 注册订阅并立即推送已有日志或当前状态；不支持的操作返回 error 帧
+func (s *Server) subscribe(conn *wsConn, id, query string, vars map[string]interface{}) *subscription {
+	_, name := iclient.ParseOperation(query)
+	sub := &subscription{
+		id:            id,
+		operation:     name,
+		deploymentID:  str(vars, "deploymentId"),
+		environmentID: str(vars, "environmentId"),
+		conn:          conn,
+		done:          make(chan struct{}),
+	}
+	filter, err := logs.ParseFilter(str(vars, "filter"))
+	if err != nil {
+		_ = conn.send(id, "error", []map[string]string{{"message": err.Error()}})
+		return nil
+	}
+	sub.filter = filter
+	if name == "Deployment" {
+		sub.deploymentID = str(vars, "id")
+	}
+	sub.cancel = func() {
+		sub.once.Do(func() {
+			close(sub.done)
+			s.mu.Lock()
+			delete(s.subs, sub)
+			s.mu.Unlock()
+		})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var initial interface{}
+	switch name {
+	case "BuildLogs", "DeploymentLogs":
+		d, ok := s.m.deployments[sub.deploymentID]
+		if !ok {
+			_ = conn.send(id, "error", []map[string]string{{"message": "deployment " + sub.deploymentID + " not found"}})
+			return nil
+		}
+		lines := d.DeployLogs
+		if name == "BuildLogs" {
+			lines = d.BuildLogs
+		}
+		lines = sub.filterLines(lines)
+		if limit := intVar(vars, "limit"); limit > 0 && len(lines) > limit {
+			lines = lines[len(lines)-limit:]
+		}
+		initial = logPayload(name, d, lines)
+	case "Deployment":
+		d, ok := s.m.deployments[sub.deploymentID]
+		if !ok {
+			_ = conn.send(id, "error", []map[string]string{{"message": "deployment " + sub.deploymentID + " not found"}})
+			return nil
+		}
+		initial = statusPayload(d)
+	case "streamEnvironmentLogs":
+		// 先按 before*/after* 变量回放历史窗口，之后推送新日志
+		initial = map[string]interface{}{"environmentLogs": s.m.environmentLogWindow(vars, filter)}
+	default:
+		_ = conn.send(id, "error", []map[string]string{{"message": "railwaytest: unsupported subscription " + name}})
+		return nil
+	}
+	s.subs[sub] = struct{}{}
+	_ = conn.send(id, "next", map[string]interface{}{"data": initial})
+	return sub
+}
+
+func (sub *subscription) filterLines(lines []LogLine) []LogLine {
+	return filterLogLines(lines, sub.filter)
+}
+
+// filterLogLines 按 Railway 日志过滤语法（见 pkg/railway/logs）筛选日志
+func filterLogLines(lines []LogLine, filter *logs.Expr) []LogLine {
+	var out []LogLine
+	for _, l := range lines {
+		if filter.Match(l.Message, l.Severity, l.Attributes) {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func (sub *subscription) push(payload interface{}) {
+	select {
+	case <-sub.done:
+		return
+	default:
+	}
+	_ = sub.conn.send(sub.id, "next", map[string]interface{}{"data": payload})
+}
+
+func (s *Server) publishStatusLocked(d *Deployment) {
+	for sub := range s.subs {
+		if sub.operation == "Deployment" && sub.deploymentID == d.ID {
+			sub.push(statusPayload(d))
+		}
+	}
+}
+
+func (s *Server) publishLogLocked(d *Deployment, build bool, line LogLine) {
+	for sub := range s.subs {
+		if len(sub.filterLines([]LogLine{line})) == 0 {
+			continue
+		}
+		switch {
+		case build && sub.operation == "BuildLogs" && sub.deploymentID == d.ID:
+			sub.push(logPayload("BuildLogs", d, []LogLine{line}))
+		case !build && sub.operation == "DeploymentLogs" && sub.deploymentID == d.ID:
+			sub.push(logPayload("DeploymentLogs", d, []LogLine{line}))
+		case !build && sub.operation == "streamEnvironmentLogs" && sub.environmentID == d.EnvironmentID:
+			sub.push(map[string]interface{}{"environmentLogs": []interface{}{logJSON(d, line)}})
+		}
+	}
+}
+
+func statusPayload(d *Deployment) map[string]interface{} {
+	return map[string]interface{}{"deployment": map[string]interface{}{
+		"id":                d.ID,
+		"status":            d.Status,
+		"deploymentStopped": d.Stopped,
+	}}
+}
+
+func logPayload(operation string, d *Deployment, lines []LogLine) map[string]interface{} {
+	key := "deploymentLogs"
+	if operation == "BuildLogs" {
+		key = "buildLogs"
+	}
+	out := make([]map[string]interface{}, 0, len(lines))
+	for _, l := range lines {
+		out = append(out, logJSON(d, l))
+	}
+	return map[string]interface{}{key: out}
+}
+
+func logJSON(d *Deployment, l LogLine) map[string]interface{} {
+	attrs := make([]map[string]string, 0, len(l.Attributes))
+	for k, v := range l.Attributes {
+		attrs = append(attrs, map[string]string{"key": k, "value": v})
+	}
+	return map[string]interface{}{
+		"timestamp":  l.Timestamp.Format(time.RFC3339Nano),
+		"message":    l.Message,
+		"severity":   l.Severity,
+		"attributes": attrs,
+		"tags": map[string]interface{}{
+			"projectId":     d.ProjectID,
+			"environmentId": d.EnvironmentID,
+			"serviceId":     d.ServiceID,
+			"deploymentId":  d.ID,
+		},
+	}
+}
@@ -22,7 +22,7 @@ func (c *Client) ResolveEnvironmentID(ctx context.Context, projectID, environmen
 			return e.Node.ID, nil
 		}
 	}
-	return "", fmt.Errorf("environment not found: %s", environmentRef)
+	return "", fmt.Errorf("environment %s: %w", environmentRef, ErrNotFound)
 }
 
 // ResolveServiceID 根据 ID 或名称解析服务 ID
@@ -39,5 +39,5 @@ func (c *Client) ResolveServiceID(ctx context.Context, projectID, serviceRef str
 			return s.Node.ID, nil
 		}
 	}
-	return "", fmt.Errorf("service not found: %s", serviceRef)
+	return "", fmt.Errorf("service %s: %w", serviceRef, ErrNotFound)
 }
@@ -75,6 +75,30 @@ func (c *Client) ListServices(ctx context.Context, projectID string, environment
 	return out, nil
 }
 
+// ServicesPager 按页列出项目服务；environmentID 非空时计算 HasInstance
+func (c *Client) ServicesPager(projectID, environmentID string, opts PageOptions) *Pager[ServiceInEnvironment] {
+	return NewPager(func(ctx context.Context, first int, after string) ([]ServiceInEnvironment, PageInfo, error) {
+		resp, err := igql.ProjectServices(ctx, c.gqlClient, igql.ProjectServicesVariables{ID: projectID, First: &first, After: optString(after)})
+		if err != nil {
+			return nil, PageInfo{}, err
+		}
+		out := make([]ServiceInEnvironment, 0, len(resp.Project.Services.Edges))
+		for _, s := range resp.Project.Services.Edges {
+			has := false
+			if environmentID != "" {
+				for _, inst := range s.Node.ServiceInstances.Edges {
+					if inst.Node.EnvironmentID == environmentID {
+						has = true
+						break
+					}
+				}
+			}
+			out = append(out, ServiceInEnvironment{Service: Service{ID: s.Node.ID, Name: s.Node.Name}, HasInstance: has})
+		}
+		return out, pageInfo(resp.Project.Services.PageInfo), nil
+	}, opts)
+}
+
 // RollbackDeployment 回滚部署
 func (c *Client) RollbackDeployment(ctx context.Context, deploymentID string) (bool, error) {
 	var resp igql.DeploymentRollbackResponse
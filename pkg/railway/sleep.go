@@ -117,7 +117,7 @@ func (c *Client) EnsureServiceSleepApplication(ctx context.Context, environmentI
 	// 读取当前环境配置
 	config, err := c.GetEnvironmentConfig(ctx, environmentID, false, true)
 	if err != nil {
-		return false, nil, nil, fmt.Errorf("failed to get environment config: %v", err)
+		return false, nil, nil, fmt.Errorf("failed to get environment config: %w", err)
 	}
 
 	// 检查当前 sleepApplication 状态
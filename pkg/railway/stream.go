@@ -0,0 +1,56 @@
+package railway
+
+import (
+	"time"
+
+	"github.com/railwayapp/cli/internal/stream"
+)
+
+// Stream 订阅结果流，用法与 bufio.Scanner 相同：
+//
+//	s := cli.DeploymentLogStream(ctx, deploymentID, "", 500)
+//	defer s.Close()
+//	for s.Next() {
+//		fmt.Println(s.Value().Message)
+//	}
+//	if err := s.Err(); err != nil { ... }
+//
+// 订阅错误帧（携带服务端的 GraphQL 错误信息）、无法解析的帧、消费过慢导致的丢帧（ErrFramesDropped）
+// 以及重连耗尽后的连接错误都会结束流，并由 Err 返回；服务端正常结束或调用 Close 时 Err 为 nil。Next/Value 不可并发调用。
+type Stream[T any] struct {
+	*stream.Stream[T]
+}
+
+// LogLine 一行构建、部署或环境日志
+type LogLine struct {
+	// Timestamp 日志时间；服务端返回的时间无法解析时为零值
+	Timestamp time.Time
+	Message   string
+	// Severity 日志级别（环境日志与历史日志查询提供）
+	Severity   string
+	Attributes map[string]string
+	// Tags 环境日志的来源标签（serviceId、deploymentId 等），仅包含非空值
+	Tags map[string]string
+	// Gap 为 true 表示订阅重连后服务端的回放未能覆盖断线位置，该行之前可能缺少断线期间的日志
+	Gap bool
+
+	rawTimestamp string
+}
+
+// RawTimestamp 返回服务端原始的时间戳字符串
+func (l LogLine) RawTimestamp() string { return l.rawTimestamp }
+
+// LogStream 日志流
+type LogStream = Stream[LogLine]
+
+// DeploymentStatusEvent 部署状态变化
+type DeploymentStatusEvent struct {
+	DeploymentID string
+	Status       DeploymentStatus
+	Stopped      bool
+	// Resumed 为 true 表示该事件是订阅重连后补发的当前状态
+	Resumed bool
+}
+
+// DeploymentStatusStream 部署状态流
+type DeploymentStatusStream = Stream[DeploymentStatusEvent]
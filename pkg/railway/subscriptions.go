@@ -2,106 +2,238 @@ package railway
 
 import (
 	"context"
-	"encoding/json"
+	"time"
 
 	iclient "github.com/railwayapp/cli/internal/client"
 	igql "github.com/railwayapp/cli/internal/gql"
+	"github.com/railwayapp/cli/internal/stream"
 )
 
-// 订阅封装
-func (c *Client) SubscribeBuildLogs(ctx context.Context, deploymentID string, filter string, limit int, onLog func(timestamp, message string, attributes map[string]string)) error {
+// 订阅封装。连接断开时按 WithReconnect 的策略重新订阅：日志订阅从最后一条已输出日志的
+// 时间戳继续并丢弃重叠的回放行，状态订阅在重连后补发一次当前状态。环境日志以 afterDate 从该时间戳
+// 重新订阅；构建与部署日志只能回放最近 limit 行，断线期间的日志更多时第一条新行的 LogLine.Gap 为 true。
+// *Stream 形式的订阅错误帧、无法解析的帧与丢帧会结束订阅并由 Err 返回；回调形式的 Subscribe*
+// 保持原有行为，跳过这些帧继续订阅，只在订阅本身结束（服务端报错结束、重连耗尽或 ctx 取消）时返回错误。
+
+// logEntry 构建日志与部署日志共用的帧结构
+type logEntry struct {
+	Timestamp  string `json:"timestamp"`
+	Message    string `json:"message"`
+	Attributes []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	} `json:"attributes"`
+}
+
+func (l logEntry) line() LogLine {
+	attrs := make(map[string]string, len(l.Attributes))
+	for _, a := range l.Attributes {
+		attrs[a.Key] = a.Value
+	}
+	return LogLine{Timestamp: parseLogTimestamp(l.Timestamp), Message: l.Message, Attributes: attrs, rawTimestamp: l.Timestamp}
+}
+
+func parseLogTimestamp(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, s)
+	return t
+}
+
+// BuildLogStream 订阅构建日志
+func (c *Client) BuildLogStream(ctx context.Context, deploymentID string, filter string, limit int) *LogStream {
+	return c.deploymentLogStream(ctx, igql.BuildLogsSub, "buildLogs", deploymentID, filter, limit, nil)
+}
+
+// DeploymentLogStream 订阅部署（运行时）日志
+func (c *Client) DeploymentLogStream(ctx context.Context, deploymentID string, filter string, limit int) *LogStream {
+	return c.deploymentLogStream(ctx, igql.DeploymentLogsSub, "deploymentLogs", deploymentID, filter, limit, nil)
+}
+
+func (c *Client) deploymentLogStream(ctx context.Context, query, field, deploymentID, filter string, limit int, skip func(error)) *LogStream {
 	vars := map[string]any{"deploymentId": deploymentID, "filter": filter, "limit": limit}
-	return iclient.Subscribe(ctx, c.cfg, igql.BuildLogsSub, vars,
-		func(data json.RawMessage) {
-			var pl igql.BuildLogsPayload
-			if err := json.Unmarshal(data, &pl); err == nil {
-				for _, l := range pl.BuildLogs {
-					attrs := map[string]string{}
-					for _, a := range l.Attributes {
-						attrs[a.Key] = a.Value
-					}
-
-					if onLog != nil {
-						onLog(l.Timestamp, l.Message, attrs)
-					}
-				}
+	var cursor iclient.LogCursor
+	return subscribeStream(ctx, c, query, vars, func(pl map[string][]logEntry, emit func(LogLine) bool) {
+		for _, l := range pl[field] {
+			if !cursor.Admit(l.Timestamp, l.Message) {
+				continue
 			}
-		}, func(err error) {},
-	)
+			line := l.line()
+			line.Gap = cursor.Gap()
+			if !emit(line) {
+				return
+			}
+		}
+	}, stream.Config[LogLine]{
+		OnReconnect:    func(int, error, func(LogLine) bool) { cursor.Resume() },
+		SkipFrameError: skip,
+	})
 }
 
-func (c *Client) SubscribeDeploymentLogs(ctx context.Context, deploymentID string, filter string, limit int, onLog func(timestamp, message string, attributes map[string]string)) error {
-	vars := map[string]any{"deploymentId": deploymentID, "filter": filter, "limit": limit}
-	return iclient.Subscribe(ctx, c.cfg, igql.DeploymentLogsSub, vars,
-		func(data json.RawMessage) {
-			var pl igql.DeploymentLogsPayload
-			if err := json.Unmarshal(data, &pl); err == nil {
-				for _, l := range pl.DeploymentLogs {
-					attrs := map[string]string{}
-					for _, a := range l.Attributes {
-						attrs[a.Key] = a.Value
-					}
-					if onLog != nil {
-						onLog(l.Timestamp, l.Message, attrs)
-					}
+// subscribeStream 经 internal/stream 订阅 query，与 CLI 共用帧的解码与错误处理
+func subscribeStream[P, T any](ctx context.Context, c *Client, query string, vars map[string]interface{}, each func(P, func(T) bool), cfg stream.Config[T]) *Stream[T] {
+	return &Stream[T]{stream.SubscribeWith(ctx, c.gqlClient, query, vars, each, cfg)}
+}
+
+// skipFrameError 回调形式的订阅忽略帧级错误，与引入 *Stream 之前的行为一致
+func skipFrameError(error) {}
+
+// EnvironmentLogOptions 环境日志订阅参数；日期为 RFC3339 字符串，留空表示不限制
+type EnvironmentLogOptions struct {
+	Filter      string
+	BeforeLimit int
+	BeforeDate  string
+	AnchorDate  string
+	AfterDate   string
+	AfterLimit  *int
+}
+
+// environmentLogTags 环境日志的标签键（与 EnvironmentLogsPayload.Tags 的字段顺序一致）
+var environmentLogTags = []string{"projectId", "environmentId", "pluginId", "serviceId", "deploymentId", "deploymentInstanceId", "snapshotId"}
+
+// EnvironmentLogStream 订阅环境内全部服务的日志
+func (c *Client) EnvironmentLogStream(ctx context.Context, environmentID string, opts EnvironmentLogOptions) *LogStream {
+	return c.environmentLogStream(ctx, environmentID, opts, nil)
+}
+
+func (c *Client) environmentLogStream(ctx context.Context, environmentID string, opts EnvironmentLogOptions, skip func(error)) *LogStream {
+	vars := map[string]interface{}{
+		"environmentId": environmentID,
+		"filter":        opts.Filter,
+		"beforeLimit":   opts.BeforeLimit,
+		"beforeDate":    opts.BeforeDate,
+		"anchorDate":    opts.AnchorDate,
+		"afterDate":     opts.AfterDate,
+		"afterLimit":    opts.AfterLimit,
+	}
+	var cursor iclient.LogCursor
+	return subscribeStream(ctx, c, igql.EnvironmentLogsSub, vars, func(pl igql.EnvironmentLogsPayload, emit func(LogLine) bool) {
+		for _, l := range pl.EnvironmentLogs {
+			tags := map[string]string{}
+			for i, v := range []*string{l.Tags.ProjectID, l.Tags.EnvironmentID, l.Tags.PluginID, l.Tags.ServiceID, l.Tags.DeploymentID, l.Tags.DeploymentInstanceID, l.Tags.SnapshotID} {
+				if v != nil && *v != "" {
+					tags[environmentLogTags[i]] = *v
 				}
 			}
-		}, func(err error) {},
-	)
+			// 环境日志混合多个服务，同一时间戳的行按来源区分
+			if !cursor.Admit(l.Timestamp, tags["deploymentInstanceId"]+"\x00"+l.Message) {
+				continue
+			}
+			attrs := make(map[string]string, len(l.Attributes))
+			for _, a := range l.Attributes {
+				attrs[a.Key] = a.Value
+			}
+			line := LogLine{
+				Timestamp:    parseLogTimestamp(l.Timestamp),
+				Message:      l.Message,
+				Severity:     l.Severity,
+				Attributes:   attrs,
+				Tags:         tags,
+				Gap:          cursor.Gap(),
+				rawTimestamp: l.Timestamp,
+			}
+			if !emit(line) {
+				return
+			}
+		}
+	}, stream.Config[LogLine]{
+		OnReconnect: func(int, error, func(LogLine) bool) {
+			igql.ResumeEnvironmentLogs(vars, cursor.Last())
+			cursor.Resume()
+		},
+		SkipFrameError: skip,
+	})
 }
 
-func (c *Client) SubscribeDeploymentStatus(ctx context.Context, deploymentID string, onStatus func(id, status string, stopped bool)) error {
+// DeploymentStatusStream 订阅部署状态变化
+func (c *Client) DeploymentStatusStream(ctx context.Context, deploymentID string) *DeploymentStatusStream {
+	return c.deploymentStatusStream(ctx, deploymentID, nil)
+}
+
+func (c *Client) deploymentStatusStream(ctx context.Context, deploymentID string, skip func(error)) *DeploymentStatusStream {
 	vars := map[string]any{"id": deploymentID}
-	return iclient.Subscribe(ctx, c.cfg, igql.DeploymentStatusSub, vars,
-		func(data json.RawMessage) {
-			var st igql.DeploymentStatusPayload
-			if err := json.Unmarshal(data, &st); err == nil {
-				if onStatus != nil {
-					onStatus(st.Deployment.ID, st.Deployment.Status, st.Deployment.DeploymentStopped)
-				}
+	var last DeploymentStatus
+	skipSame := false
+	return subscribeStream(ctx, c, igql.DeploymentStatusSub, vars, func(st igql.DeploymentStatusPayload, emit func(DeploymentStatusEvent) bool) {
+		status := parseDeploymentStatus(st.Deployment.Status)
+		if skipSame && status == last {
+			skipSame = false
+			return
+		}
+		skipSame = false
+		emit(DeploymentStatusEvent{DeploymentID: st.Deployment.ID, Status: status, Stopped: st.Deployment.DeploymentStopped})
+	}, stream.Config[DeploymentStatusEvent]{
+		OnReconnect: func(_ int, _ error, emit func(DeploymentStatusEvent) bool) {
+			// 断线期间的状态变化可能已错过：补发当前状态，并跳过紧随其后的相同状态帧
+			resp, err := igql.DeploymentStatus(ctx, c.gqlClient, igql.DeploymentStatusVariables{ID: deploymentID})
+			if err != nil {
+				return
 			}
-		}, func(err error) {},
-	)
+			d := resp.Deployment
+			last = parseDeploymentStatus(d.Status)
+			skipSame = true
+			emit(DeploymentStatusEvent{DeploymentID: d.ID, Status: last, Stopped: d.DeploymentStopped, Resumed: true})
+		},
+		SkipFrameError: skip,
+	})
 }
 
-// SubscribeEnvironmentLogs 订阅环境日志
-func (c *Client) SubscribeEnvironmentLogs(ctx context.Context, environmentID string, filter string, beforeLimit int, beforeDate, anchorDate, afterDate string, afterLimit *int, onLog func(timestamp, message, severity string, tags map[string]*string, attributes map[string]string)) error {
-	vars := map[string]interface{}{
-		"environmentId": environmentID,
-		"filter":        filter,
-		"beforeLimit":   beforeLimit,
-		"beforeDate":    beforeDate,
-		"anchorDate":    anchorDate,
-		"afterDate":     afterDate,
-		"afterLimit":    afterLimit,
+// SubscribeBuildLogs 订阅构建日志（回调形式，见 BuildLogStream）。
+// 无法解析的帧、订阅错误帧与丢帧被跳过，需要感知这些错误时请使用 BuildLogStream
+func (c *Client) SubscribeBuildLogs(ctx context.Context, deploymentID string, filter string, limit int, onLog func(timestamp, message string, attributes map[string]string)) error {
+	return forEachLog(c.deploymentLogStream(ctx, igql.BuildLogsSub, "buildLogs", deploymentID, filter, limit, skipFrameError), func(l LogLine) {
+		if onLog != nil {
+			onLog(l.rawTimestamp, l.Message, l.Attributes)
+		}
+	})
+}
+
+// SubscribeDeploymentLogs 订阅部署日志（回调形式，见 DeploymentLogStream）。
+// 无法解析的帧、订阅错误帧与丢帧被跳过，需要感知这些错误时请使用 DeploymentLogStream
+func (c *Client) SubscribeDeploymentLogs(ctx context.Context, deploymentID string, filter string, limit int, onLog func(timestamp, message string, attributes map[string]string)) error {
+	return forEachLog(c.deploymentLogStream(ctx, igql.DeploymentLogsSub, "deploymentLogs", deploymentID, filter, limit, skipFrameError), func(l LogLine) {
+		if onLog != nil {
+			onLog(l.rawTimestamp, l.Message, l.Attributes)
+		}
+	})
+}
+
+// SubscribeDeploymentStatus 订阅部署状态（回调形式，见 DeploymentStatusStream）。
+// 无法解析的帧、订阅错误帧与丢帧被跳过，需要感知这些错误时请使用 DeploymentStatusStream
+func (c *Client) SubscribeDeploymentStatus(ctx context.Context, deploymentID string, onStatus func(id, status string, stopped bool)) error {
+	s := c.deploymentStatusStream(ctx, deploymentID, skipFrameError)
+	defer s.Close()
+	for s.Next() {
+		if onStatus != nil {
+			ev := s.Value()
+			onStatus(ev.DeploymentID, string(ev.Status), ev.Stopped)
+		}
 	}
-	return iclient.Subscribe(ctx, c.cfg, igql.EnvironmentLogsSub, vars,
-		func(data json.RawMessage) {
-			var pl igql.EnvironmentLogsPayload
-			if err := json.Unmarshal(data, &pl); err == nil {
-				for _, l := range pl.EnvironmentLogs {
-					// 转换tags为map
-					tags := map[string]*string{
-						"projectId":            l.Tags.ProjectID,
-						"environmentId":        l.Tags.EnvironmentID,
-						"pluginId":             l.Tags.PluginID,
-						"serviceId":            l.Tags.ServiceID,
-						"deploymentId":         l.Tags.DeploymentID,
-						"deploymentInstanceId": l.Tags.DeploymentInstanceID,
-						"snapshotId":           l.Tags.SnapshotID,
-					}
-
-					// 转换attributes为map
-					attrs := map[string]string{}
-					for _, a := range l.Attributes {
-						attrs[a.Key] = a.Value
-					}
-
-					if onLog != nil {
-						onLog(l.Timestamp, l.Message, l.Severity, tags, attrs)
-					}
-				}
+	return s.Err()
+}
+
+// SubscribeEnvironmentLogs 订阅环境日志（回调形式，见 EnvironmentLogStream）。
+// 无法解析的帧、订阅错误帧与丢帧被跳过，需要感知这些错误时请使用 EnvironmentLogStream
+func (c *Client) SubscribeEnvironmentLogs(ctx context.Context, environmentID string, filter string, beforeLimit int, beforeDate, anchorDate, afterDate string, afterLimit *int, onLog func(timestamp, message, severity string, tags map[string]*string, attributes map[string]string)) error {
+	opts := EnvironmentLogOptions{Filter: filter, BeforeLimit: beforeLimit, BeforeDate: beforeDate, AnchorDate: anchorDate, AfterDate: afterDate, AfterLimit: afterLimit}
+	return forEachLog(c.environmentLogStream(ctx, environmentID, opts, skipFrameError), func(l LogLine) {
+		if onLog == nil {
+			return
+		}
+		tags := make(map[string]*string, len(environmentLogTags))
+		for _, k := range environmentLogTags {
+			if v, ok := l.Tags[k]; ok {
+				tags[k] = &v
+			} else {
+				tags[k] = nil
 			}
-		}, func(err error) {},
-	)
+		}
+		onLog(l.rawTimestamp, l.Message, l.Severity, tags, l.Attributes)
+	})
+}
+
+func forEachLog(s *LogStream, fn func(LogLine)) error {
+	defer s.Close()
+	for s.Next() {
+		fn(s.Value())
+	}
+	return s.Err()
 }
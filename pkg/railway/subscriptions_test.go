@@ -0,0 +1,121 @@
+package railway_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/railwayapp/cli/pkg/railway"
+	"github.com/railwayapp/cli/pkg/railway/railwaytest"
+)
+
+// logFixture 返回重连等待固定为 200ms 的 Client 与一个部署；断线后的这段时间用于写入“断线期间”的日志
+func logFixture(t *testing.T) (*railwaytest.Server, *railway.Client, *railwaytest.Deployment) {
+	t.Helper()
+	srv := railwaytest.NewServer()
+	t.Cleanup(srv.Close)
+	backoff := 200 * time.Millisecond
+	c, err := railway.New(append(srv.ClientOptions(), railway.WithReconnect(railway.ReconnectOptions{MinBackoff: backoff, MaxBackoff: backoff}))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, env := srv.AddProject("demo")
+	d, err := srv.AddDeployment(srv.AddService(p.ID, "web").ID, env.ID, "SUCCESS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv, c, d
+}
+
+var logBase = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// appendLines 追加第 from..to 秒的部署日志，消息为 "line <秒>"
+func appendLines(t *testing.T, srv *railwaytest.Server, deploymentID string, from, to int) {
+	t.Helper()
+	for i := from; i <= to; i++ {
+		l := railwaytest.LogLine{Timestamp: logBase.Add(time.Duration(i) * time.Second), Message: fmt.Sprintf("line %d", i), Severity: "info"}
+		if err := srv.AppendLogLines(deploymentID, false, l); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readLines 读取 n 行，返回消息与 Gap 标记（"line 5*" 表示该行之前报告了间隙）
+func readLines(t *testing.T, s *railway.LogStream, n int) string {
+	t.Helper()
+	var got []string
+	for len(got) < n {
+		if !s.Next() {
+			t.Fatalf("stream ended after %v: %v", got, s.Err())
+		}
+		l := s.Value()
+		if l.Gap {
+			l.Message += "*"
+		}
+		got = append(got, l.Message)
+	}
+	return strings.Join(got, ",")
+}
+
+func TestLogStreamResumesWithoutDuplicates(t *testing.T) {
+	srv, c, d := logFixture(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	appendLines(t, srv, d.ID, 1, 3)
+
+	s := c.DeploymentLogStream(ctx, d.ID, "", 10)
+	defer s.Close()
+	if got := readLines(t, s, 3); got != "line 1,line 2,line 3" {
+		t.Fatalf("initial = %s", got)
+	}
+	// 断线期间的日志在重新订阅时随回放到达，已输出的行不再重复
+	srv.DropConnections()
+	appendLines(t, srv, d.ID, 4, 5)
+	if got := readLines(t, s, 2); got != "line 4,line 5" {
+		t.Fatalf("after reconnect = %s", got)
+	}
+	appendLines(t, srv, d.ID, 6, 6)
+	if got := readLines(t, s, 1); got != "line 6" {
+		t.Fatalf("live after reconnect = %s", got)
+	}
+}
+
+func TestLogStreamReportsGap(t *testing.T) {
+	srv, c, d := logFixture(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	appendLines(t, srv, d.ID, 1, 2)
+
+	s := c.DeploymentLogStream(ctx, d.ID, "", 2)
+	defer s.Close()
+	if got := readLines(t, s, 2); got != "line 1,line 2" {
+		t.Fatalf("initial = %s", got)
+	}
+	// 部署日志只能回放最近 limit 行：断线期间多于 limit 行时第一条新行标记 Gap
+	srv.DropConnections()
+	appendLines(t, srv, d.ID, 3, 5)
+	if got := readLines(t, s, 2); got != "line 4*,line 5" {
+		t.Fatalf("after reconnect = %s", got)
+	}
+}
+
+func TestEnvironmentLogStreamResumesAfterLastLine(t *testing.T) {
+	srv, c, d := logFixture(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	appendLines(t, srv, d.ID, 1, 3)
+
+	s := c.EnvironmentLogStream(ctx, d.EnvironmentID, railway.EnvironmentLogOptions{BeforeLimit: 2})
+	defer s.Close()
+	if got := readLines(t, s, 2); got != "line 2,line 3" {
+		t.Fatalf("initial = %s", got)
+	}
+	// 环境日志以 afterDate 从最后一条日志继续，断线期间的日志多于 BeforeLimit 也不丢失
+	srv.DropConnections()
+	appendLines(t, srv, d.ID, 4, 6)
+	if got := readLines(t, s, 3); got != "line 4,line 5,line 6" {
+		t.Fatalf("after reconnect = %s", got)
+	}
+}
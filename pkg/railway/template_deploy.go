@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/railwayapp/cli/internal/commands"
-
 	gql "github.com/railwayapp/cli/internal/gql"
 )
 
@@ -37,7 +35,7 @@ func (c *Client) DeployTemplateWithConfig(ctx context.Context, opts TemplateDepl
 	}
 
 	// 2. 解析模板配置
-	var templateConfig commands.DeserializedTemplateConfig
+	var templateConfig gql.DeserializedTemplateConfig
 	if len(templateDetail.Template.SerializedConfig) > 0 {
 		if err := json.Unmarshal(templateDetail.Template.SerializedConfig, &templateConfig); err != nil {
 			return nil, fmt.Errorf("解析模板配置失败: %w", err)
@@ -79,7 +77,7 @@ func (c *Client) GetTemplateDetail(ctx context.Context, templateCode string) (*g
 }
 
 // processTemplateVariables 处理模板变量
-func (c *Client) processTemplateVariables(templateConfig *commands.DeserializedTemplateConfig, userVars map[string]string) error {
+func (c *Client) processTemplateVariables(templateConfig *gql.DeserializedTemplateConfig, userVars map[string]string) error {
 	if templateConfig.Services == nil {
 		return nil
 	}
@@ -127,7 +125,7 @@ func (c *Client) processTemplateVariables(templateConfig *commands.DeserializedT
 }
 
 // setCustomServiceName 设置自定义服务名称
-func (c *Client) setCustomServiceName(templateConfig *commands.DeserializedTemplateConfig, serviceName string) error {
+func (c *Client) setCustomServiceName(templateConfig *gql.DeserializedTemplateConfig, serviceName string) error {
 	if templateConfig.Services == nil {
 		return nil
 	}
@@ -142,7 +140,7 @@ func (c *Client) setCustomServiceName(templateConfig *commands.DeserializedTempl
 }
 
 // convertToSerializedConfig 转换为序列化配置
-func (c *Client) convertToSerializedConfig(templateConfig commands.DeserializedTemplateConfig) (gql.SerializedTemplateConfig, error) {
+func (c *Client) convertToSerializedConfig(templateConfig gql.DeserializedTemplateConfig) (gql.SerializedTemplateConfig, error) {
 	serializedConfig := make(gql.SerializedTemplateConfig)
 	configBytes, err := json.Marshal(templateConfig)
 	if err != nil {
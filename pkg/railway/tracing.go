@@ -0,0 +1,12 @@
+package railway
+
+import (
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithTracerProvider 指定 OpenTelemetry TracerProvider（默认使用 otel 全局 provider）。
+// Query/QueryInternal、订阅生命周期以及 Up 的打包与上传阶段都会以调用方 ctx 中的 span 为父节点创建 span，
+// 属性包含操作名与项目/环境/服务 ID。
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(o *options) { o.tracerProvider = tp }
+}
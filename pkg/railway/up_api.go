@@ -1,23 +1,23 @@
 package railway
 
 import (
-	"archive/tar"
-	"bytes"
-	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
-	"os"
 	"path/filepath"
 	"strings"
-	"time"
 
-	"github.com/railwayapp/cli/internal/config"
+	"github.com/railwayapp/cli/internal/archive"
+	iclient "github.com/railwayapp/cli/internal/client"
 	ignore "github.com/sabhiram/go-gitignore"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// UpProgress Up 的打包上传进度：已写入的文件数、已读取的源文件字节（与 TotalBytes 之比即完成比例）、
+// 已发送的压缩字节与耗时；归档在上传时才生成，压缩后的总大小事先未知
+type UpProgress = archive.Progress
+
 // UpParams 控制 Up 行为
 type UpParams struct {
 	ProjectID     string
@@ -29,16 +29,40 @@ type UpParams struct {
 	NoGitignore bool
 	PathAsRoot  bool
 	Verbose     bool
-	Detach      bool
-	CI          bool
+	// Detach 上传后立即返回，不跟随日志与状态
+	Detach bool
+	// CI 只跟随构建：构建完成（进入 DEPLOYING）或因无文件变更被跳过时即返回，不订阅部署日志
+	CI bool
+
+	// OnProgress 上传进度回调，在上传请求读取归档的 goroutine 中调用
+	OnProgress func(UpProgress)
+	// OnUploaded 上传成功、开始跟随之前调用（Detach 时同样调用）
+	OnUploaded func(deploymentID, logsURL string)
 
+	// OnBuildLog / OnDeploymentLog 每行构建、部署日志；OnStatus 每次状态变化。均在 Up 返回前结束
 	OnBuildLog      func(line string)
 	OnDeploymentLog func(line string)
 	OnStatus        func(status string)
+	// OnDeploymentLogLine 与 OnDeploymentLog 相同，但传入带级别与属性的完整日志行；可与其同时设置
+	OnDeploymentLogLine func(LogLine)
 }
 
-// Up 打包上传并可选跟随日志，返回 (deploymentID, logsURL)
-func (c *Client) Up(ctx context.Context, p UpParams) (string, string, error) {
+// Up 打包上传，返回 (deploymentID, logsURL)。未设置 Detach 时跟随部署直到终态（CI 模式为构建完成）：
+// 依次订阅构建日志、部署日志与状态并调用对应回调；部署失败、崩溃或被移除时返回
+// *DeploymentFailedError（构建失败时附带构建失败摘要），ctx 取消时返回包装了 ctx.Err() 的错误。
+func (c *Client) Up(ctx context.Context, p UpParams) (deploymentID, logsURL string, err error) {
+	ctx, span := c.gqlClient.Tracer().Start(ctx, "railway.up", trace.WithAttributes(
+		iclient.AttrProjectID.String(p.ProjectID),
+		iclient.AttrEnvironmentID.String(p.EnvironmentID),
+		iclient.AttrServiceID.String(p.ServiceID),
+	))
+	defer func() {
+		if deploymentID != "" {
+			span.SetAttributes(iclient.AttrDeploymentID.String(deploymentID))
+		}
+		iclient.EndSpan(span, err)
+	}()
+
 	if strings.TrimSpace(p.ProjectRoot) == "" {
 		return "", "", fmt.Errorf("ProjectRoot is required")
 	}
@@ -54,135 +78,60 @@ func (c *Client) Up(ctx context.Context, p UpParams) (string, string, error) {
 	// ignore 规则
 	var gi *ignore.GitIgnore
 	if !p.NoGitignore {
-		var patterns []string
-		if b, err := os.ReadFile(filepath.Join(p.ProjectRoot, ".railwayignore")); err == nil {
-			patterns = append(patterns, strings.Split(string(b), "\n")...)
-		}
-		if b, err := os.ReadFile(filepath.Join(p.ProjectRoot, ".gitignore")); err == nil {
-			patterns = append(patterns, strings.Split(string(b), "\n")...)
-		}
-		if len(patterns) > 0 {
-			gi = ignore.CompileIgnoreLines(patterns...)
-		}
+		gi = archive.LoadIgnore(p.ProjectRoot)
 	}
 
 	if p.Verbose {
 		fmt.Println("Indexing & archiving...")
 	}
 
-	// 打包 tar.gz
-	buf := &bytes.Buffer{}
-	gz := gzip.NewWriter(buf)
-	tw := tar.NewWriter(gz)
-	addFile := func(absPath, arcPath string, info os.FileInfo) error {
-		hdr, err := tar.FileInfoHeader(info, "")
-		if err != nil {
-			return err
-		}
-		hdr.Name = arcPath
-		if err := tw.WriteHeader(hdr); err != nil {
-			return err
-		}
-		f, err := os.Open(absPath)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
-		if _, err := io.Copy(tw, f); err != nil {
-			return err
-		}
-		return nil
-	}
-	rootForPrefix := p.ProjectRoot
-	if p.PathAsRoot {
-		rootForPrefix = deployRoot
-	}
-	if err := filepath.Walk(deployRoot, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		relFromRoot := path
-		r, _ := filepath.Rel(rootForPrefix, path)
-		relFromRoot = r
-		if relFromRoot == "." {
-			return nil
-		}
-		base := filepath.Base(path)
-		if base == ".git" || base == "node_modules" {
-			if info.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-		if gi != nil {
-			relForIgnore, _ := filepath.Rel(p.ProjectRoot, path)
-			relForIgnore = filepath.ToSlash(relForIgnore)
-			if gi.MatchesPath(relForIgnore) {
-				if info.IsDir() {
-					return filepath.SkipDir
-				}
-				return nil
-			}
-		}
-		if info.Mode().IsRegular() {
-			arcPath := filepath.ToSlash(filepath.Join(".", relFromRoot))
-			return addFile(path, arcPath, info)
-		}
-		return nil
-	}); err != nil {
-		return "", "", fmt.Errorf("archive failed: %w", err)
-	}
-	if err := tw.Close(); err != nil {
-		return "", "", err
-	}
-	if err := gz.Close(); err != nil {
+	// 边打包边上传：归档经管道直接写入请求体，不在内存中缓存
+	_, archiveSpan := c.gqlClient.Tracer().Start(ctx, "railway.up.archive")
+	stream, err := archive.Open(archive.Options{ProjectRoot: p.ProjectRoot, DeployRoot: deployRoot, PathAsRoot: p.PathAsRoot, Ignore: gi}, p.OnProgress)
+	if err != nil {
+		iclient.EndSpan(archiveSpan, err)
 		return "", "", err
 	}
-
+	progress := stream.Progress()
+	archiveSpan.SetAttributes(iclient.AttrArchiveFiles.Int(progress.TotalFiles))
 	if p.Verbose {
-		fmt.Printf("archive bytes: %d\n", buf.Len())
+		fmt.Printf("archiving %d files (%d bytes)\n", progress.TotalFiles, progress.TotalBytes)
 	}
 
 	// 上传
-	host := c.cfg.GetHost()
-	uploadURL := fmt.Sprintf("https://backboard.%s/project/%s/environment/%s/up?serviceId=%s", host, p.ProjectID, p.EnvironmentID, p.ServiceID)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, bytes.NewReader(buf.Bytes()))
-	if err != nil {
-		return "", "", err
-	}
-	req.Header.Set("Content-Type", "application/octet-stream")
-	if t := config.GetRailwayToken(); t != nil {
-		req.Header.Set("project-access-token", *t)
-	} else if t := c.cfg.GetRailwayAuthToken(); t != nil {
-		req.Header.Set("authorization", fmt.Sprintf("Bearer %s", *t))
+	resp, err := c.gqlClient.Upload(ctx, p.ProjectID, p.EnvironmentID, p.ServiceID, stream)
+	archiveSpan.SetAttributes(iclient.AttrArchiveBytes.Int64(stream.Progress().Sent))
+	if archiveErr := stream.Err(); archiveErr != nil {
+		iclient.EndSpan(archiveSpan, archiveErr)
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return "", "", archiveErr
 	}
-	req.Header.Set("x-source", fmt.Sprintf("railway-cli/%s", "4.6.1"))
-	req.Header.Set("user-agent", fmt.Sprintf("railway-cli/%s", "4.6.1"))
-
-	httpClient := &http.Client{Timeout: 300 * time.Second}
-	resp, err := httpClient.Do(req)
+	iclient.EndSpan(archiveSpan, nil)
 	if err != nil {
 		return "", "", fmt.Errorf("upload failed: %w", err)
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		b, _ := io.ReadAll(resp.Body)
-		return "", "", fmt.Errorf("upload failed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+
+	if p.Verbose {
+		fmt.Printf("archive bytes: %d\n", stream.Progress().Sent)
 	}
 
 	bodyBytes, _ := io.ReadAll(resp.Body)
 	var raw map[string]any
 	_ = json.Unmarshal(bodyBytes, &raw)
-	deploymentID := getString(raw, "deployment_id", "deploymentId")
-	logsURL := getString(raw, "logs_url", "logsUrl")
+	deploymentID = getString(raw, "deployment_id", "deploymentId")
+	logsURL = getString(raw, "logs_url", "logsUrl")
+	if p.OnUploaded != nil {
+		p.OnUploaded(deploymentID, logsURL)
+	}
 
 	if p.Detach {
 		return deploymentID, logsURL, nil
 	}
-
-	// 如果需要，跟随日志（交由调用方通过订阅接口实现）
 	if deploymentID == "" {
-		return deploymentID, logsURL, nil
+		return deploymentID, logsURL, fmt.Errorf("upload response did not include a deployment id")
 	}
-	return deploymentID, logsURL, nil
+	return deploymentID, logsURL, c.followUp(ctx, p, deploymentID, logsURL)
 }
@@ -0,0 +1,180 @@
+package railway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/railwayapp/cli/pkg/railway/logs"
+)
+
+// ErrDeploymentFailed Up 跟随的部署失败、崩溃或被移除（配合 errors.Is 使用）
+var ErrDeploymentFailed = errors.New("railway: deployment failed")
+
+// DeploymentFailedError Up 跟随的部署以失败状态结束。可通过 errors.As 取出详情，
+// 或通过 errors.Is(err, ErrDeploymentFailed) 判断
+type DeploymentFailedError struct {
+	DeploymentID string
+	// Status 最终状态：FAILED、CRASHED 或 REMOVED
+	Status  DeploymentStatus
+	LogsURL string
+	// Report 构建失败摘要，仅 Status 为 FAILED 时非 nil
+	Report *BuildFailureReport
+}
+
+func (e *DeploymentFailedError) Error() string {
+	msg := fmt.Sprintf("railway: deployment %s %s", e.DeploymentID, strings.ToLower(string(e.Status)))
+	if e.Report.Found() {
+		msg += ": " + e.Report.Error
+	}
+	return msg
+}
+
+// Is 使 errors.Is(err, ErrDeploymentFailed) 成立
+func (e *DeploymentFailedError) Is(target error) bool { return target == ErrDeploymentFailed }
+
+// upFollowLimit 跟随时日志订阅回放的行数（与 CLI 一致）
+const upFollowLimit = 500
+
+// 到达终态后等待日志订阅送达剩余的行：连续 followDrainIdle 无新行或最多 followDrainMax 后停止
+const (
+	followDrainIdle = 300 * time.Millisecond
+	followDrainMax  = 3 * time.Second
+)
+
+// noChangesMessage 构建因没有文件匹配 watch patterns 而被跳过时的构建日志
+const noChangesMessage = "No changed files matched patterns"
+
+// followUp 跟随 Up 创建的部署：构建日志从一开始订阅；构建完成（进入 DEPLOYING 或之后的状态）后
+// 订阅部署日志；状态由 WaitForDeployment 跟踪。到达终态时返回，失败状态返回 *DeploymentFailedError。
+// CI 模式下不订阅部署日志，构建完成即返回。所有回调在 followUp 返回前结束。
+func (c *Client) followUp(ctx context.Context, p UpParams, deploymentID, logsURL string) error {
+	followCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	waitCtx, cancelWait := context.WithCancel(followCtx)
+	defer cancelWait()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		analyzer = logs.NewBuildAnalyzer()
+		lastLine time.Time
+	)
+	touch := func() {
+		mu.Lock()
+		lastLine = time.Now()
+		mu.Unlock()
+	}
+	// 日志订阅的错误只结束该订阅，不影响按状态判断结果
+	follow := func(s *LogStream, fn func(LogLine)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer s.Close()
+			for s.Next() {
+				fn(s.Value())
+			}
+		}()
+	}
+
+	var noChanges, built atomic.Bool
+	follow(c.BuildLogStream(followCtx, deploymentID, "", upFollowLimit), func(l LogLine) {
+		mu.Lock()
+		analyzer.Add(l.Timestamp, l.Message)
+		lastLine = time.Now()
+		mu.Unlock()
+		if p.OnBuildLog != nil {
+			p.OnBuildLog(l.Message)
+		}
+		if p.CI && strings.HasPrefix(l.Message, noChangesMessage) {
+			noChanges.Store(true)
+			cancelWait()
+		}
+	})
+
+	deployLogs := false
+	res, err := c.WaitForDeployment(waitCtx, deploymentID, WaitOptions{OnStatus: func(ev DeploymentStatusEvent) {
+		if p.OnStatus != nil {
+			p.OnStatus(string(ev.Status))
+		}
+		switch ev.Status {
+		case DeploymentStatusDeploying, DeploymentStatusSuccess, DeploymentStatusSleeping, DeploymentStatusCrashed:
+		default:
+			return
+		}
+		if p.CI {
+			if !ev.Status.Terminal() {
+				built.Store(true)
+				cancelWait()
+			}
+			return
+		}
+		if !deployLogs && (p.OnDeploymentLog != nil || p.OnDeploymentLogLine != nil) {
+			deployLogs = true
+			follow(c.DeploymentLogStream(followCtx, deploymentID, "", upFollowLimit), func(l LogLine) {
+				touch()
+				if p.OnDeploymentLog != nil {
+					p.OnDeploymentLog(l.Message)
+				}
+				if p.OnDeploymentLogLine != nil {
+					p.OnDeploymentLogLine(l)
+				}
+			})
+		}
+	}})
+
+	drain := func() {
+		touch()
+		deadline := time.Now().Add(followDrainMax)
+		for {
+			mu.Lock()
+			idle := time.Since(lastLine)
+			mu.Unlock()
+			if idle >= followDrainIdle || !time.Now().Before(deadline) || ctx.Err() != nil {
+				break
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(followDrainIdle - idle):
+			}
+		}
+		stop()
+		wg.Wait()
+	}
+
+	switch {
+	case ctx.Err() != nil && err != nil:
+		stop()
+		wg.Wait()
+		return err
+	case noChanges.Load(), built.Load():
+		drain()
+		return nil
+	case err != nil:
+		stop()
+		wg.Wait()
+		return err
+	}
+	drain()
+	if res.Status.Succeeded() || res.Status == DeploymentStatusSkipped {
+		return nil
+	}
+	failed := &DeploymentFailedError{DeploymentID: deploymentID, Status: res.Status, LogsURL: logsURL}
+	if res.Status == DeploymentStatusFailed {
+		mu.Lock()
+		report := analyzer.Report()
+		mu.Unlock()
+		if !report.Found() {
+			// 状态可能先于最后几行构建日志到达，未定位到错误时再查询一次历史日志
+			if r, err := c.AnalyzeBuildFailure(ctx, deploymentID); err == nil && (r.Found() || report.Lines == 0) {
+				report = r
+			}
+		}
+		failed.Report = report
+	}
+	return failed
+}
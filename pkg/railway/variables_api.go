@@ -7,33 +7,20 @@ import (
 	igql "github.com/railwayapp/cli/internal/gql"
 )
 
-// GetVariables 拉取服务在指定环境下的变量
+// GetVariables 拉取服务在指定环境下的变量（忽略空值）
 func (c *Client) GetVariables(ctx context.Context, projectID, environmentID, serviceID string) (map[string]string, error) {
-	var raw map[string]any
-	if err := c.gqlClient.Query(ctx, igql.VariablesForServiceDeploymentQuery, map[string]any{
-		"projectId":     projectID,
-		"environmentId": environmentID,
-		"serviceId":     serviceID,
-	}, &raw); err != nil {
+	resp, err := igql.VariablesForServiceDeployment(ctx, c.gqlClient, igql.VariablesForServiceDeploymentVariables{
+		ProjectID:     projectID,
+		EnvironmentID: environmentID,
+		ServiceID:     serviceID,
+	})
+	if err != nil {
 		return nil, err
 	}
-	// 兼容不同根键
-	var varsMap map[string]any
-	if v, ok := raw["variables"]; ok {
-		varsMap, _ = v.(map[string]any)
-	}
-	if varsMap == nil {
-		if v, ok := raw["variablesForServiceDeployment"]; ok {
-			varsMap, _ = v.(map[string]any)
-		}
-	}
-	if varsMap == nil {
-		return nil, fmt.Errorf("unsupported response shape")
-	}
-	out := make(map[string]string)
-	for k, v := range varsMap {
-		if s, ok := v.(string); ok && s != "" {
-			out[k] = s
+	out := make(map[string]string, len(resp.Variables))
+	for k, v := range resp.Variables {
+		if v != "" {
+			out[k] = v
 		}
 	}
 	return out, nil
@@ -17,6 +17,16 @@ type Backup struct {
 	ScheduleID   *string
 }
 
+// ProjectBackup 项目级备份（跨卷列出）
+type ProjectBackup struct {
+	ID        string
+	Name      string
+	CreatedAt string
+	Status    string
+	Size      *int64
+	Service   Service
+}
+
 // RollbackResult 回滚结果
 type RollbackResult struct {
 	ID           string
@@ -47,6 +57,22 @@ func (c *Client) GetAllVolumeBackups(ctx context.Context, volumeInstanceID strin
 	return backups, nil
 }
 
+// BackupsPager 按页列出项目下的备份
+func (c *Client) BackupsPager(projectID string, opts PageOptions) *Pager[ProjectBackup] {
+	return NewPager(func(ctx context.Context, first int, after string) ([]ProjectBackup, PageInfo, error) {
+		resp, err := igql.Backups(ctx, c.gqlClient, igql.BackupsVariables{ProjectID: projectID, First: &first, After: optString(after)})
+		if err != nil {
+			return nil, PageInfo{}, err
+		}
+		out := make([]ProjectBackup, 0, len(resp.Backups.Edges))
+		for _, e := range resp.Backups.Edges {
+			n := e.Node
+			out = append(out, ProjectBackup{ID: n.ID, Name: n.Name, CreatedAt: n.CreatedAt, Status: n.Status, Size: n.Size, Service: Service{ID: n.Service.ID, Name: n.Service.Name}})
+		}
+		return out, pageInfo(resp.Backups.PageInfo), nil
+	}, opts)
+}
+
 // CreateVolumeBackup 通过卷实例ID创建备份，返回工作流ID
 func (c *Client) CreateVolumeBackup(ctx context.Context, volumeInstanceID string) (string, error) {
 	var resp igql.VolumeInstanceBackupCreateResponse
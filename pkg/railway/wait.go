@@ -0,0 +1,260 @@
+package railway
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	igql "github.com/railwayapp/cli/internal/gql"
+)
+
+// DeploymentStatus 部署状态
+type DeploymentStatus string
+
+const (
+	DeploymentStatusBuilding      DeploymentStatus = "BUILDING"
+	DeploymentStatusCrashed       DeploymentStatus = "CRASHED"
+	DeploymentStatusDeploying     DeploymentStatus = "DEPLOYING"
+	DeploymentStatusFailed        DeploymentStatus = "FAILED"
+	DeploymentStatusInitializing  DeploymentStatus = "INITIALIZING"
+	DeploymentStatusNeedsApproval DeploymentStatus = "NEEDS_APPROVAL"
+	DeploymentStatusQueued        DeploymentStatus = "QUEUED"
+	DeploymentStatusRemoved       DeploymentStatus = "REMOVED"
+	DeploymentStatusRemoving      DeploymentStatus = "REMOVING"
+	DeploymentStatusSkipped       DeploymentStatus = "SKIPPED"
+	DeploymentStatusSleeping      DeploymentStatus = "SLEEPING"
+	DeploymentStatusSuccess       DeploymentStatus = "SUCCESS"
+	DeploymentStatusWaiting       DeploymentStatus = "WAITING"
+)
+
+// parseDeploymentStatus 服务端返回的状态统一为大写
+func parseDeploymentStatus(s string) DeploymentStatus {
+	return DeploymentStatus(strings.ToUpper(s))
+}
+
+// Terminal 部署是否已不会再自行推进（SLEEPING 为成功部署进入休眠）
+func (s DeploymentStatus) Terminal() bool {
+	switch s {
+	case DeploymentStatusSuccess, DeploymentStatusSleeping,
+		DeploymentStatusFailed, DeploymentStatusCrashed,
+		DeploymentStatusRemoved, DeploymentStatusSkipped:
+		return true
+	}
+	return false
+}
+
+// Succeeded 部署是否成功运行（SUCCESS 或 SLEEPING）
+func (s DeploymentStatus) Succeeded() bool {
+	return s == DeploymentStatusSuccess || s == DeploymentStatusSleeping
+}
+
+// WaitOptions WaitForDeployment 参数
+type WaitOptions struct {
+	// Timeout 最长等待时间，0 表示仅受 ctx 约束
+	Timeout time.Duration
+	// PollInterval 订阅不可用时轮询部署状态的间隔，0 使用默认值 3s
+	PollInterval time.Duration
+	// Settle 部署成功后继续观察的时长；期间变为 CRASHED 则以 CRASHED 作为最终状态。
+	// 0 表示成功即返回
+	Settle time.Duration
+	// OnStatus 每次状态变化时调用（相同状态只回调一次）
+	OnStatus func(DeploymentStatusEvent)
+	// OnFallback 订阅不可用、改为轮询时调用，参数为订阅的错误（服务端结束订阅时为 nil）
+	OnFallback func(err error)
+}
+
+const defaultWaitPollInterval = 3 * time.Second
+
+// StatusTransition 一次状态变化及观察到它的时间
+type StatusTransition struct {
+	Status DeploymentStatus
+	At     time.Time
+}
+
+// WaitResult WaitForDeployment 的结果
+type WaitResult struct {
+	DeploymentID string
+	// Status 最终状态；超时或取消时为最后观察到的状态
+	Status  DeploymentStatus
+	Stopped bool
+	// CreatedAt / UpdatedAt 服务端记录的部署创建与最后更新时间
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// StartedAt / FinishedAt 本地开始等待与得到结果的时间
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// Transitions 等待期间观察到的状态变化，按时间排序
+	Transitions []StatusTransition
+	// Polled 为 true 表示订阅不可用，结果来自轮询
+	Polled bool
+}
+
+// Succeeded 最终状态是否为成功
+func (r *WaitResult) Succeeded() bool { return r.Status.Succeeded() }
+
+// Duration 本地等待耗时
+func (r *WaitResult) Duration() time.Duration { return r.FinishedAt.Sub(r.StartedAt) }
+
+// WaitForDeployment 等待部署进入终态（见 DeploymentStatus.Terminal）并返回最终状态。
+// 优先通过订阅接收状态变化，订阅失败或被服务端结束时改为按 PollInterval 轮询。
+// 部署失败、崩溃、被移除或跳过都作为正常结果返回，由调用方检查 Status；
+// 超时或 ctx 取消时返回已观察到的结果以及包装了 ctx.Err() 的错误。
+func (c *Client) WaitForDeployment(ctx context.Context, deploymentID string, opts WaitOptions) (*WaitResult, error) {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = defaultWaitPollInterval
+	}
+	res := &WaitResult{DeploymentID: deploymentID, StartedAt: time.Now()}
+	finish := func(err error) (*WaitResult, error) {
+		res.FinishedAt = time.Now()
+		return res, err
+	}
+
+	// observe 记录一次状态；返回 true 表示等待结束
+	var settle <-chan time.Time
+	observe := func(ev DeploymentStatusEvent) bool {
+		res.Stopped = ev.Stopped
+		if ev.Status != res.Status {
+			res.Status = ev.Status
+			res.Transitions = append(res.Transitions, StatusTransition{Status: ev.Status, At: time.Now()})
+			if opts.OnStatus != nil {
+				opts.OnStatus(ev)
+			}
+		}
+		if ev.Status == DeploymentStatusSuccess && opts.Settle > 0 {
+			if settle == nil {
+				settle = time.After(opts.Settle)
+			}
+			return false
+		}
+		return ev.Status.Terminal()
+	}
+	// fetch 查询当前状态并刷新服务端时间戳
+	fetch := func() (DeploymentStatusEvent, error) {
+		resp, err := igql.DeploymentStatus(ctx, c.gqlClient, igql.DeploymentStatusVariables{ID: deploymentID})
+		if err != nil {
+			return DeploymentStatusEvent{}, err
+		}
+		d := resp.Deployment
+		res.CreatedAt = parseLogTimestamp(d.CreatedAt)
+		res.UpdatedAt = parseLogTimestamp(d.UpdatedAt)
+		return DeploymentStatusEvent{DeploymentID: d.ID, Status: parseDeploymentStatus(d.Status), Stopped: d.DeploymentStopped}, nil
+	}
+	poll := func() (bool, error) {
+		ev, err := fetch()
+		if err != nil {
+			return false, err
+		}
+		return observe(ev), nil
+	}
+	// timedOut 超时或取消：附带最后观察到的状态
+	timedOut := func() (*WaitResult, error) {
+		return finish(fmt.Errorf("wait for deployment %s (last status %q): %w", deploymentID, res.Status, ctx.Err()))
+	}
+
+	// 先查询一次：已处于终态的部署无需订阅，同时取得创建时间
+	if done, err := poll(); err != nil {
+		if ctx.Err() != nil {
+			return timedOut()
+		}
+		if !IsRetryable(err) {
+			return finish(err)
+		}
+	} else if done {
+		return finish(nil)
+	}
+
+	subCtx, stopSub := context.WithCancel(ctx)
+	defer stopSub()
+	events := make(chan DeploymentStatusEvent)
+	subEnded := make(chan error, 1)
+	go func() {
+		s := c.DeploymentStatusStream(subCtx, deploymentID)
+		defer s.Close()
+		for s.Next() {
+			select {
+			case events <- s.Value():
+			case <-subCtx.Done():
+				return
+			}
+		}
+		subEnded <- s.Err()
+	}()
+
+	var ticker *time.Ticker
+	var tick <-chan time.Time
+	defer func() {
+		if ticker != nil {
+			ticker.Stop()
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return timedOut()
+		case <-settle:
+			// 观察期内未崩溃：刷新时间戳后以成功返回
+			if _, err := poll(); err != nil {
+				if ctx.Err() != nil {
+					return timedOut()
+				}
+				if !IsRetryable(err) {
+					return finish(err)
+				}
+			}
+			if res.Status.Terminal() {
+				return finish(nil)
+			}
+			// 状态已回到非终态（如重新部署中），继续等待
+			settle = nil
+		case ev := <-events:
+			if observe(ev) {
+				// 订阅事件不含时间戳，结束前补查一次（失败不影响结果）
+				_, _ = fetch()
+				return finish(nil)
+			}
+		case err := <-subEnded:
+			if ctx.Err() != nil {
+				return timedOut()
+			}
+			if opts.OnFallback != nil {
+				opts.OnFallback(err)
+			}
+			res.Polled = true
+			ticker = time.NewTicker(interval)
+			tick = ticker.C
+			done, err := poll()
+			if err != nil {
+				if ctx.Err() != nil {
+					return timedOut()
+				}
+				if !IsRetryable(err) {
+					return finish(err)
+				}
+			} else if done {
+				return finish(nil)
+			}
+		case <-tick:
+			done, err := poll()
+			if err != nil {
+				if ctx.Err() != nil {
+					return timedOut()
+				}
+				// 轮询期间的临时错误忽略，等待下一次
+				if !IsRetryable(err) {
+					return finish(err)
+				}
+				continue
+			}
+			if done {
+				return finish(nil)
+			}
+		}
+	}
+}
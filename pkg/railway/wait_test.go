@@ -0,0 +1,298 @@
+package railway_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/railwayapp/cli/pkg/railway"
+	"github.com/railwayapp/cli/pkg/railway/railwaytest"
+)
+
+// waitFixture 返回连接 srv 的 Client 与一个处于 status 的部署
+func waitFixture(t *testing.T, srv *railwaytest.Server, status string, opts ...railway.Option) (*railway.Client, string) {
+	t.Helper()
+	c, err := railway.New(append(srv.ClientOptions(), opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, env := srv.AddProject("demo")
+	svc := srv.AddService(p.ID, "web")
+	d, err := srv.AddDeployment(svc.ID, env.ID, status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, d.ID
+}
+
+// statusLog 转发 OnStatus 回调，可等待某个状态出现
+type statusLog struct {
+	ch chan railway.DeploymentStatus
+}
+
+func newStatusLog() *statusLog { return &statusLog{ch: make(chan railway.DeploymentStatus, 16)} }
+
+func (l *statusLog) on(ev railway.DeploymentStatusEvent) {
+	l.ch <- ev.Status
+}
+
+func (l *statusLog) await(t *testing.T, want railway.DeploymentStatus) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case s := <-l.ch:
+			if s == want {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("status %s not observed", want)
+		}
+	}
+}
+
+func transitions(res *railway.WaitResult) string {
+	var out []string
+	for _, tr := range res.Transitions {
+		out = append(out, string(tr.Status))
+	}
+	return strings.Join(out, ",")
+}
+
+func TestWaitForDeploymentAlreadyTerminal(t *testing.T) {
+	for _, status := range []string{"SUCCESS", "FAILED", "CRASHED", "REMOVED", "SKIPPED", "SLEEPING"} {
+		t.Run(status, func(t *testing.T) {
+			srv := railwaytest.NewServer()
+			defer srv.Close()
+			c, id := waitFixture(t, srv, status)
+			res, err := c.WaitForDeployment(context.Background(), id, railway.WaitOptions{Timeout: 5 * time.Second})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(res.Status) != status || res.Polled || res.CreatedAt.IsZero() {
+				t.Fatalf("result = %+v", res)
+			}
+			if res.Succeeded() != (status == "SUCCESS" || status == "SLEEPING") {
+				t.Fatalf("Succeeded() = %v for %s", res.Succeeded(), status)
+			}
+			// 初次查询即为终态时不订阅
+			if n := countOps(srv, "DeploymentStatus"); n != 1 {
+				t.Fatalf("DeploymentStatus queried %d times", n)
+			}
+		})
+	}
+}
+
+func TestWaitForDeploymentSubscription(t *testing.T) {
+	srv := railwaytest.NewServer()
+	defer srv.Close()
+	c, id := waitFixture(t, srv, "BUILDING")
+	log := newStatusLog()
+	done := make(chan struct{})
+	var res *railway.WaitResult
+	var err error
+	go func() {
+		defer close(done)
+		res, err = c.WaitForDeployment(context.Background(), id, railway.WaitOptions{Timeout: 5 * time.Second, OnStatus: log.on})
+	}()
+	log.await(t, railway.DeploymentStatusBuilding)
+	if err := srv.SetDeploymentStatus(id, "DEPLOYING"); err != nil {
+		t.Fatal(err)
+	}
+	// DEPLOYING 只能经订阅观察到（订阅建立时推送当前状态），此后的变化都会推送
+	log.await(t, railway.DeploymentStatusDeploying)
+	if err := srv.SetDeploymentStatus(id, "REMOVED"); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != railway.DeploymentStatusRemoved || res.Polled || res.Succeeded() {
+		t.Fatalf("result = %+v", res)
+	}
+	if got := transitions(res); got != "BUILDING,DEPLOYING,REMOVED" {
+		t.Fatalf("transitions = %s", got)
+	}
+	if res.UpdatedAt.IsZero() || res.Duration() <= 0 {
+		t.Fatalf("timestamps = %+v", res)
+	}
+}
+
+func TestWaitForDeploymentPollingFallback(t *testing.T) {
+	srv := railwaytest.NewServer()
+	defer srv.Close()
+	c, id := waitFixture(t, srv, "BUILDING", railway.WithReconnect(railway.ReconnectOptions{MaxAttempts: -1}))
+	fellBack := make(chan error, 1)
+	done := make(chan struct{})
+	var res *railway.WaitResult
+	var err error
+	go func() {
+		defer close(done)
+		res, err = c.WaitForDeployment(context.Background(), id, railway.WaitOptions{
+			Timeout:      5 * time.Second,
+			PollInterval: 20 * time.Millisecond,
+			OnFallback:   func(err error) { fellBack <- err },
+		})
+	}()
+	// 订阅建立前断开不影响后续连接，反复断开直到订阅失败
+	for waiting := true; waiting; {
+		srv.DropConnections()
+		select {
+		case ferr := <-fellBack:
+			if ferr == nil {
+				t.Fatal("OnFallback err = nil for dropped connection")
+			}
+			waiting = false
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	if err := srv.SetDeploymentStatus(id, "SKIPPED"); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != railway.DeploymentStatusSkipped || !res.Polled {
+		t.Fatalf("result = %+v", res)
+	}
+}
+
+func TestWaitForDeploymentTransientPollErrors(t *testing.T) {
+	srv := railwaytest.NewServer()
+	defer srv.Close()
+	c, id := waitFixture(t, srv, "BUILDING", railway.WithReconnect(railway.ReconnectOptions{MaxAttempts: -1}))
+	// 奇数次查询返回限流错误（可重试），偶数次返回真实状态
+	var calls atomic.Int32
+	srv.Handle("DeploymentStatus", func(vars map[string]interface{}) (interface{}, error) {
+		if calls.Add(1)%2 == 1 {
+			return nil, railwaytest.Errorf("RATE_LIMITED", "slow down")
+		}
+		d, _ := srv.Deployment(id)
+		return map[string]interface{}{"deployment": map[string]interface{}{
+			"id": d.ID, "status": d.Status, "deploymentStopped": d.Stopped,
+			"createdAt": d.CreatedAt.Format(time.RFC3339Nano), "updatedAt": d.UpdatedAt.Format(time.RFC3339Nano),
+		}}, nil
+	})
+	fellBack := make(chan struct{}, 1)
+	done := make(chan struct{})
+	var res *railway.WaitResult
+	var err error
+	go func() {
+		defer close(done)
+		res, err = c.WaitForDeployment(context.Background(), id, railway.WaitOptions{
+			Timeout:      5 * time.Second,
+			PollInterval: 10 * time.Millisecond,
+			OnFallback:   func(error) { fellBack <- struct{}{} },
+		})
+	}()
+	for waiting := true; waiting; {
+		srv.DropConnections()
+		select {
+		case <-fellBack:
+			waiting = false
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	// 让几次轮询（含失败的）发生后再结束部署
+	for calls.Load() < 5 {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := srv.SetDeploymentStatus(id, "SUCCESS"); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if err != nil {
+		t.Fatalf("transient poll errors ended the wait: %v", err)
+	}
+	if res.Status != railway.DeploymentStatusSuccess || !res.Polled {
+		t.Fatalf("result = %+v", res)
+	}
+}
+
+func TestWaitForDeploymentPermanentPollError(t *testing.T) {
+	srv := railwaytest.NewServer()
+	defer srv.Close()
+	c, err := railway.New(srv.ClientOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.WaitForDeployment(context.Background(), "missing", railway.WaitOptions{Timeout: 5 * time.Second})
+	if !errors.Is(err, railway.ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestWaitForDeploymentSettle(t *testing.T) {
+	t.Run("crash during settle", func(t *testing.T) {
+		srv := railwaytest.NewServer()
+		defer srv.Close()
+		c, id := waitFixture(t, srv, "DEPLOYING")
+		log := newStatusLog()
+		done := make(chan struct{})
+		var res *railway.WaitResult
+		var err error
+		go func() {
+			defer close(done)
+			res, err = c.WaitForDeployment(context.Background(), id, railway.WaitOptions{Timeout: 5 * time.Second, Settle: 2 * time.Second, OnStatus: log.on})
+		}()
+		log.await(t, railway.DeploymentStatusDeploying)
+		if err := srv.SetDeploymentStatus(id, "SUCCESS"); err != nil {
+			t.Fatal(err)
+		}
+		log.await(t, railway.DeploymentStatusSuccess)
+		if err := srv.SetDeploymentStatus(id, "CRASHED"); err != nil {
+			t.Fatal(err)
+		}
+		<-done
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Status != railway.DeploymentStatusCrashed || transitions(res) != "DEPLOYING,SUCCESS,CRASHED" {
+			t.Fatalf("result = %+v", res)
+		}
+	})
+	t.Run("stays up", func(t *testing.T) {
+		srv := railwaytest.NewServer()
+		defer srv.Close()
+		c, id := waitFixture(t, srv, "SUCCESS")
+		settle := 100 * time.Millisecond
+		res, err := c.WaitForDeployment(context.Background(), id, railway.WaitOptions{Timeout: 5 * time.Second, Settle: settle})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Status != railway.DeploymentStatusSuccess || res.Duration() < settle {
+			t.Fatalf("result = %+v after %v", res, res.Duration())
+		}
+	})
+}
+
+func TestWaitForDeploymentTimeout(t *testing.T) {
+	srv := railwaytest.NewServer()
+	defer srv.Close()
+	c, id := waitFixture(t, srv, "BUILDING")
+	res, err := c.WaitForDeployment(context.Background(), id, railway.WaitOptions{Timeout: 150 * time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+	if !strings.Contains(err.Error(), `last status "BUILDING"`) {
+		t.Fatalf("err = %v", err)
+	}
+	if res == nil || res.Status != railway.DeploymentStatusBuilding {
+		t.Fatalf("result = %+v", res)
+	}
+}
+
+func TestWaitDeploymentSuccess(t *testing.T) {
+	srv := railwaytest.NewServer()
+	defer srv.Close()
+	c, id := waitFixture(t, srv, "FAILED")
+	status, err := c.WaitDeploymentSuccess(context.Background(), id)
+	if err != nil || status != "FAILED" {
+		t.Fatalf("WaitDeploymentSuccess = %q, %v", status, err)
+	}
+}